@@ -10,6 +10,7 @@ import (
 	"github.com/stashapp/stash/internal/manager"
 	"github.com/stashapp/stash/internal/manager/config"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/session"
 )
 
@@ -30,6 +31,16 @@ func allowUnauthenticated(r *http.Request) bool {
 	return strings.HasPrefix(r.URL.Path, loginEndPoint) || r.URL.Path == "/css" || strings.HasPrefix(r.URL.Path, "/assets")
 }
 
+// authenticationRequired returns true if credentials are configured or if
+// user accounts exist.
+func authenticationRequired(r *http.Request) (bool, error) {
+	if config.GetInstance().HasCredentials() {
+		return true, nil
+	}
+
+	return manager.GetInstance().SessionStore.HasUsers(r.Context())
+}
+
 func authenticateHandler() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -56,23 +67,32 @@ func authenticateHandler() func(http.Handler) http.Handler {
 				return
 			}
 
-			if err := session.CheckAllowPublicWithoutAuth(c, r); err != nil {
-				var externalAccess session.ExternalAccessError
-				switch {
-				case errors.As(err, &externalAccess):
-					securityActivateTripwireAccessedFromInternetWithoutAuth(c, externalAccess, w)
-					return
-				default:
-					logger.Errorf("Error checking external access security: %v", err)
-					w.WriteHeader(http.StatusInternalServerError)
-					return
+			authRequired, err := authenticationRequired(r)
+			if err != nil {
+				logger.Errorf("Error checking for users: %v", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			// public access is only restricted if authentication is disabled
+			if !authRequired {
+				if err := session.CheckAllowPublicWithoutAuth(c, r); err != nil {
+					var externalAccess session.ExternalAccessError
+					switch {
+					case errors.As(err, &externalAccess):
+						securityActivateTripwireAccessedFromInternetWithoutAuth(c, externalAccess, w)
+						return
+					default:
+						logger.Errorf("Error checking external access security: %v", err)
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
 				}
 			}
 
 			ctx := r.Context()
 
-			if c.HasCredentials() {
-				// authentication is required
+			if authRequired {
 				if userID == "" && !allowUnauthenticated(r) {
 					// authentication was not received, redirect
 					// if graphql was requested, we just return a forbidden error
@@ -96,7 +116,29 @@ func authenticateHandler() func(http.Handler) http.Handler {
 				}
 			}
 
+			user, err := manager.GetInstance().SessionStore.GetUser(ctx, userID)
+			if err != nil {
+				if !errors.Is(err, session.ErrUnauthorized) {
+					logger.Errorf("Error getting user %q: %v", userID, err)
+				}
+
+				w.Header().Add("WWW-Authenticate", `FormBased`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
 			ctx = session.SetCurrentUserID(ctx, userID)
+			ctx = session.SetCurrentUser(ctx, user)
+
+			// hide the content restricted for the user from all queries
+			if user != nil && user.RestrictionProfileID.Valid {
+				ctx, err = models.WithRestrictionProfile(ctx, manager.GetInstance().TxnManager, int(user.RestrictionProfileID.Int64))
+				if err != nil {
+					logger.Errorf("Error getting restriction profile for user %q: %v", userID, err)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+			}
 
 			r = r.WithContext(ctx)
 
@@ -132,3 +174,18 @@ func securityActivateTripwireAccessedFromInternetWithoutAuth(c *config.Instance,
 		logger.Error(err)
 	}
 }
+
+// requireRole returns a middleware that responds with a forbidden error if
+// the current user does not have the required role.
+func requireRole(role models.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !session.HasRole(r.Context(), role) {
+				http.Error(w, session.ErrForbidden.Error(), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+// mutationRoles are the roles required to run mutations. Mutations not
+// listed require the editor role, unless they match adminMutationPrefixes.
+var mutationRoles = map[string]models.UserRole{
+	// resolvers restrict viewers to their own data
+	"sceneUpdate":        models.UserRoleViewer,
+	"sceneIncrementO":    models.UserRoleViewer,
+	"sceneDecrementO":    models.UserRoleViewer,
+	"sceneResetO":        models.UserRoleViewer,
+	"sceneAddPlay":       models.UserRoleViewer,
+	"sceneSaveActivity":  models.UserRoleViewer,
+	"userUpdate":         models.UserRoleViewer,
+	"userGenerateAPIKey": models.UserRoleViewer,
+
+	"setup":                        models.UserRoleAdmin,
+	"migrate":                      models.UserRoleAdmin,
+	"generateAPIKey":               models.UserRoleAdmin,
+	"exportObjects":                models.UserRoleAdmin,
+	"importObjects":                models.UserRoleAdmin,
+	"migrateHashNaming":            models.UserRoleAdmin,
+	"reloadScrapers":               models.UserRoleAdmin,
+	"testScraper":                  models.UserRoleAdmin,
+	"runPluginTask":                models.UserRoleAdmin,
+	"reloadPlugins":                models.UserRoleAdmin,
+	"stopJob":                      models.UserRoleAdmin,
+	"stopAllJobs":                  models.UserRoleAdmin,
+	"requeueJob":                   models.UserRoleAdmin,
+	"retryJobFailures":             models.UserRoleAdmin,
+	"pauseJob":                     models.UserRoleAdmin,
+	"resumeJob":                    models.UserRoleAdmin,
+	"setJobPriority":               models.UserRoleAdmin,
+	"moveJob":                      models.UserRoleAdmin,
+	"submitStashBoxFingerprints":   models.UserRoleAdmin,
+	"submitStashBoxSceneDraft":     models.UserRoleAdmin,
+	"submitStashBoxPerformerDraft": models.UserRoleAdmin,
+	"backupDatabase":               models.UserRoleAdmin,
+	"stashBoxBatchPerformerTag":    models.UserRoleAdmin,
+	"enableDLNA":                   models.UserRoleAdmin,
+	"disableDLNA":                  models.UserRoleAdmin,
+	"addTempDLNAIP":                models.UserRoleAdmin,
+	"removeTempDLNAIP":             models.UserRoleAdmin,
+	"userCreate":                   models.UserRoleAdmin,
+	"userDestroy":                  models.UserRoleAdmin,
+	"runSchedule":                  models.UserRoleAdmin,
+}
+
+// adminMutationPrefixes are the prefixes of mutations that require the admin
+// role.
+var adminMutationPrefixes = []string{
+	"configure",
+	"metadata",
+	"restrictionProfile",
+	"schedule",
+}
+
+// adminFields are the query and subscription fields that require the admin
+// role.
+var adminFields = map[string]bool{
+	"findUsers":               true,
+	"findRestrictionProfiles": true,
+	"findRestrictionProfile":  true,
+	"findSchedules":           true,
+	"logs":                    true,
+	"directory":               true,
+	"loggingSubscribe":        true,
+}
+
+// secretFields are the fields of each object that are hidden from users
+// without the admin role.
+var secretFields = map[string][]string{
+	"ConfigGeneralResult": {"apiKey", "password"},
+	"StashBox":            {"api_key"},
+	"ScraperCredential":   {"value"},
+}
+
+func getMutationRole(name string) models.UserRole {
+	if role, found := mutationRoles[name]; found {
+		return role
+	}
+
+	for _, prefix := range adminMutationPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return models.UserRoleAdmin
+		}
+	}
+
+	return models.UserRoleEditor
+}
+
+func forbiddenError(name string) error {
+	return fmt.Errorf("%w: %s requires a different role", session.ErrForbidden, name)
+}
+
+// authorizeField is a field middleware that enforces the role of the current
+// user.
+func authorizeField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	name := fc.Field.Name
+
+	switch fc.Object {
+	case "Mutation":
+		if !session.HasRole(ctx, getMutationRole(name)) {
+			return nil, forbiddenError(name)
+		}
+	case "Query", "Subscription":
+		if adminFields[name] && !session.HasRole(ctx, models.UserRoleAdmin) {
+			return nil, forbiddenError(name)
+		}
+	default:
+		for _, f := range secretFields[fc.Object] {
+			if f == name && !session.HasRole(ctx, models.UserRoleAdmin) {
+				return "", nil
+			}
+		}
+	}
+
+	return next(ctx)
+}
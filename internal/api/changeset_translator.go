@@ -33,6 +33,21 @@ func getUpdateInputMap(ctx context.Context) map[string]interface{} {
 	return ret
 }
 
+// getNestedUpdateInputMap returns the map of the named field of the input
+// argument, for update inputs that are nested within another input.
+func getNestedUpdateInputMap(ctx context.Context, field string) map[string]interface{} {
+	var ret map[string]interface{}
+	if input := getUpdateInputMap(ctx)[field]; input != nil {
+		ret, _ = input.(map[string]interface{})
+	}
+
+	if ret == nil {
+		ret = make(map[string]interface{})
+	}
+
+	return ret
+}
+
 func getUpdateInputMaps(ctx context.Context) []map[string]interface{} {
 	args := getArgumentMap(ctx)
 
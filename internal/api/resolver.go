@@ -53,6 +53,12 @@ func (r *Resolver) Query() models.QueryResolver {
 func (r *Resolver) Scene() models.SceneResolver {
 	return &sceneResolver{r}
 }
+func (r *Resolver) SceneFile() models.SceneFileResolver {
+	return &sceneFileResolver{r}
+}
+func (r *Resolver) ScenePlay() models.ScenePlayResolver {
+	return &scenePlayResolver{r}
+}
 func (r *Resolver) Image() models.ImageResolver {
 	return &imageResolver{r}
 }
@@ -71,6 +77,15 @@ func (r *Resolver) Subscription() models.SubscriptionResolver {
 func (r *Resolver) Tag() models.TagResolver {
 	return &tagResolver{r}
 }
+func (r *Resolver) User() models.UserResolver {
+	return &userResolver{r}
+}
+func (r *Resolver) RestrictionProfile() models.RestrictionProfileResolver {
+	return &restrictionProfileResolver{r}
+}
+func (r *Resolver) Schedule() models.ScheduleResolver {
+	return &scheduleResolver{r}
+}
 
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
@@ -79,11 +94,16 @@ type subscriptionResolver struct{ *Resolver }
 type galleryResolver struct{ *Resolver }
 type performerResolver struct{ *Resolver }
 type sceneResolver struct{ *Resolver }
+type sceneFileResolver struct{ *Resolver }
+type scenePlayResolver struct{ *Resolver }
 type sceneMarkerResolver struct{ *Resolver }
 type imageResolver struct{ *Resolver }
 type studioResolver struct{ *Resolver }
 type movieResolver struct{ *Resolver }
 type tagResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
+type restrictionProfileResolver struct{ *Resolver }
+type scheduleResolver struct{ *Resolver }
 
 func (r *Resolver) withTxn(ctx context.Context, fn func(r models.Repository) error) error {
 	return r.txnManager.WithTxn(ctx, fn)
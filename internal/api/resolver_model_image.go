@@ -7,6 +7,7 @@ import (
 	"github.com/stashapp/stash/internal/api/urlbuilders"
 	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 func (r *imageResolver) Title(ctx context.Context, obj *models.Image) (*string, error) {
@@ -14,6 +15,14 @@ func (r *imageResolver) Title(ctx context.Context, obj *models.Image) (*string,
 	return &ret, nil
 }
 
+func (r *imageResolver) Phash(ctx context.Context, obj *models.Image) (*string, error) {
+	if obj.Phash.Valid {
+		hexval := utils.PhashToString(obj.Phash.Int64)
+		return &hexval, nil
+	}
+	return nil, nil
+}
+
 func (r *imageResolver) Rating(ctx context.Context, obj *models.Image) (*int, error) {
 	if obj.Rating.Valid {
 		rating := int(obj.Rating.Int64)
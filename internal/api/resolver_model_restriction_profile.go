@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *restrictionProfileResolver) ExcludedTags(ctx context.Context, obj *models.RestrictionProfile) (ret []*models.Tag, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ids, err := repo.RestrictionProfile().GetTagIDs(obj.ID)
+		if err != nil {
+			return err
+		}
+
+		ret, err = repo.Tag().FindMany(ids)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *restrictionProfileResolver) ExcludedStudios(ctx context.Context, obj *models.RestrictionProfile) (ret []*models.Studio, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ids, err := repo.RestrictionProfile().GetStudioIDs(obj.ID)
+		if err != nil {
+			return err
+		}
+
+		ret, err = repo.Studio().FindMany(ids)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *restrictionProfileResolver) ExcludedPerformers(ctx context.Context, obj *models.RestrictionProfile) (ret []*models.Performer, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ids, err := repo.RestrictionProfile().GetPerformerIDs(obj.ID)
+		if err != nil {
+			return err
+		}
+
+		ret, err = repo.Performer().FindMany(ids)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *restrictionProfileResolver) CreatedAt(ctx context.Context, obj *models.RestrictionProfile) (*time.Time, error) {
+	return &obj.CreatedAt.Timestamp, nil
+}
+
+func (r *restrictionProfileResolver) UpdatedAt(ctx context.Context, obj *models.RestrictionProfile) (*time.Time, error) {
+	return &obj.UpdatedAt.Timestamp, nil
+}
@@ -2,11 +2,14 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/stashapp/stash/internal/api/urlbuilders"
 	"github.com/stashapp/stash/internal/manager"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
@@ -53,14 +56,53 @@ func (r *sceneResolver) Date(ctx context.Context, obj *models.Scene) (*string, e
 	return nil, nil
 }
 
+// getUserData returns the scene data of the current stored user, or nil if
+// the scene data is not per-user.
+func (r *sceneResolver) getUserData(ctx context.Context, obj *models.Scene) (ret *models.SceneUserData, err error) {
+	userID := session.GetCurrentUserAccountID(ctx)
+	if userID == nil {
+		return nil, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Scene().GetUserData(obj.ID, *userID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
 func (r *sceneResolver) Rating(ctx context.Context, obj *models.Scene) (*int, error) {
-	if obj.Rating.Valid {
-		rating := int(obj.Rating.Int64)
-		return &rating, nil
+	rating := obj.Rating
+	userData, err := r.getUserData(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	if userData != nil {
+		rating = userData.Rating
+	}
+
+	if rating.Valid {
+		ret := int(rating.Int64)
+		return &ret, nil
 	}
 	return nil, nil
 }
 
+func (r *sceneResolver) OCounter(ctx context.Context, obj *models.Scene) (*int, error) {
+	userData, err := r.getUserData(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	if userData != nil {
+		return &userData.OCounter, nil
+	}
+
+	return &obj.OCounter, nil
+}
+
 func (r *sceneResolver) InteractiveSpeed(ctx context.Context, obj *models.Scene) (*int, error) {
 	if obj.InteractiveSpeed.Valid {
 		interactive_speed := int(obj.InteractiveSpeed.Int64)
@@ -252,11 +294,119 @@ func (r *sceneResolver) FileModTime(ctx context.Context, obj *models.Scene) (*ti
 	return &obj.FileModTime.Timestamp, nil
 }
 
-func (r *sceneResolver) SceneStreams(ctx context.Context, obj *models.Scene) ([]*models.SceneStreamEndpoint, error) {
+func (r *sceneResolver) Files(ctx context.Context, obj *models.Scene) (ret []*models.SceneFile, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Scene().GetFiles(obj.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *sceneResolver) ResumeTime(ctx context.Context, obj *models.Scene) (*float64, error) {
+	userData, err := r.getUserData(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	if userData != nil {
+		return &userData.ResumeTime, nil
+	}
+
+	return &obj.ResumeTime, nil
+}
+
+func (r *sceneResolver) PlayHistory(ctx context.Context, obj *models.Scene) (ret []*models.ScenePlay, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Scene().GetPlayHistory(obj.ID, session.GetCurrentUserAccountID(ctx))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *sceneResolver) PlayCount(ctx context.Context, obj *models.Scene) (int, error) {
+	history, err := r.PlayHistory(ctx, obj)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(history), nil
+}
+
+func (r *sceneResolver) PlayDuration(ctx context.Context, obj *models.Scene) (float64, error) {
+	history, err := r.PlayHistory(ctx, obj)
+	if err != nil {
+		return 0, err
+	}
+
+	var ret float64
+	for _, p := range history {
+		ret += p.PlayDuration
+	}
+
+	return ret, nil
+}
+
+func (r *sceneResolver) LastPlayedAt(ctx context.Context, obj *models.Scene) (*time.Time, error) {
+	history, err := r.PlayHistory(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// history is ordered most recent first
+	if len(history) == 0 {
+		return nil, nil
+	}
+
+	return &history[0].PlayedAt.Timestamp, nil
+}
+
+func (r *sceneResolver) SceneStreams(ctx context.Context, obj *models.Scene, fileID *string) ([]*models.SceneStreamEndpoint, error) {
+	return r.getSceneStreamPaths(ctx, obj, fileID)
+}
+
+// getSceneStreamPaths returns the stream endpoints of the scene. If fileID is
+// set, then the endpoints stream the given file of the scene rather than its
+// primary file.
+func (r *Resolver) getSceneStreamPaths(ctx context.Context, scene *models.Scene, fileID *string) ([]*models.SceneStreamEndpoint, error) {
 	config := manager.GetInstance().Config
 
 	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
-	builder := urlbuilders.NewSceneURLBuilder(baseURL, obj.ID)
+	builder := urlbuilders.NewSceneURLBuilder(baseURL, scene.ID)
+	streamURL := builder.GetStreamURL()
+
+	if fileID != nil {
+		fileIDInt, err := strconv.Atoi(*fileID)
+		if err != nil {
+			return nil, err
+		}
+
+		var file *models.SceneFile
+		if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+			file, err = repo.Scene().FindFile(fileIDInt)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+
+		if file == nil || file.SceneID != scene.ID {
+			return nil, fmt.Errorf("file %d not found for scene %d", fileIDInt, scene.ID)
+		}
+
+		// stream the file using a copy of the scene
+		fileScene := *scene
+		fileScene.SetSceneFile(*file)
+		scene = &fileScene
+		streamURL = builder.GetFileStreamURL(file.ID)
+	}
+
+	return manager.GetSceneStreamPaths(scene, streamURL, config.GetMaxStreamingTranscodeSize())
+}
 
-	return manager.GetSceneStreamPaths(obj, builder.GetStreamURL(), config.GetMaxStreamingTranscodeSize())
+func (r *scenePlayResolver) PlayedAt(ctx context.Context, obj *models.ScenePlay) (*time.Time, error) {
+	return &obj.PlayedAt.Timestamp, nil
 }
@@ -24,6 +24,13 @@ func (r *sceneMarkerResolver) Scene(ctx context.Context, obj *models.SceneMarker
 	return ret, nil
 }
 
+func (r *sceneMarkerResolver) EndSeconds(ctx context.Context, obj *models.SceneMarker) (*float64, error) {
+	if obj.EndSeconds.Valid {
+		return &obj.EndSeconds.Float64, nil
+	}
+	return nil, nil
+}
+
 func (r *sceneMarkerResolver) PrimaryTag(ctx context.Context, obj *models.SceneMarker) (ret *models.Tag, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
 		ret, err = repo.Tag().Find(obj.PrimaryTagID)
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *scheduleResolver) Cron(ctx context.Context, obj *models.Schedule) (*string, error) {
+	if obj.Cron.Valid {
+		return &obj.Cron.String, nil
+	}
+	return nil, nil
+}
+
+func (r *scheduleResolver) Interval(ctx context.Context, obj *models.Schedule) (*int, error) {
+	if obj.Interval.Valid {
+		ret := int(obj.Interval.Int64)
+		return &ret, nil
+	}
+	return nil, nil
+}
+
+func (r *scheduleResolver) PluginID(ctx context.Context, obj *models.Schedule) (*string, error) {
+	if obj.PluginID.Valid {
+		return &obj.PluginID.String, nil
+	}
+	return nil, nil
+}
+
+func (r *scheduleResolver) PluginTaskName(ctx context.Context, obj *models.Schedule) (*string, error) {
+	if obj.PluginTaskName.Valid {
+		return &obj.PluginTaskName.String, nil
+	}
+	return nil, nil
+}
+
+func (r *scheduleResolver) NextRunAt(ctx context.Context, obj *models.Schedule) (*time.Time, error) {
+	return manager.GetNextScheduleRun(obj), nil
+}
+
+func (r *scheduleResolver) LastRunAt(ctx context.Context, obj *models.Schedule) (*time.Time, error) {
+	if obj.LastRunAt.Valid {
+		return &obj.LastRunAt.Timestamp, nil
+	}
+	return nil, nil
+}
+
+func (r *scheduleResolver) LastJobID(ctx context.Context, obj *models.Schedule) (*string, error) {
+	if obj.LastJobID.Valid {
+		ret := strconv.FormatInt(obj.LastJobID.Int64, 10)
+		return &ret, nil
+	}
+	return nil, nil
+}
+
+func (r *scheduleResolver) LastResult(ctx context.Context, obj *models.Schedule) (*models.ScheduleRunResult, error) {
+	if obj.LastResult.Valid {
+		ret := models.ScheduleRunResult(obj.LastResult.String)
+		return &ret, nil
+	}
+	return nil, nil
+}
+
+func (r *scheduleResolver) LastError(ctx context.Context, obj *models.Schedule) (*string, error) {
+	if obj.LastError.Valid {
+		return &obj.LastError.String, nil
+	}
+	return nil, nil
+}
+
+func (r *scheduleResolver) CreatedAt(ctx context.Context, obj *models.Schedule) (*time.Time, error) {
+	return &obj.CreatedAt.Timestamp, nil
+}
+
+func (r *scheduleResolver) UpdatedAt(ctx context.Context, obj *models.Schedule) (*time.Time, error) {
+	return &obj.UpdatedAt.Timestamp, nil
+}
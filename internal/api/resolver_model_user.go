@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *userResolver) APIKey(ctx context.Context, obj *models.User) (*string, error) {
+	if obj.APIKey.Valid {
+		return &obj.APIKey.String, nil
+	}
+	return nil, nil
+}
+
+func (r *userResolver) CreatedAt(ctx context.Context, obj *models.User) (*time.Time, error) {
+	return &obj.CreatedAt.Timestamp, nil
+}
+
+func (r *userResolver) UpdatedAt(ctx context.Context, obj *models.User) (*time.Time, error) {
+	return &obj.UpdatedAt.Timestamp, nil
+}
+
+func (r *userResolver) RestrictionProfile(ctx context.Context, obj *models.User) (ret *models.RestrictionProfile, err error) {
+	if !obj.RestrictionProfileID.Valid {
+		return nil, nil
+	}
+
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.RestrictionProfile().Find(int(obj.RestrictionProfileID.Int64))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/stashapp/stash/internal/manager"
 	"github.com/stashapp/stash/internal/manager/config"
@@ -147,6 +148,17 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input models.Co
 		c.Set(config.ParallelTasks, *input.ParallelTasks)
 	}
 
+	if input.JobLaneConcurrency != nil {
+		concurrency := make(map[string]int)
+		for _, l := range input.JobLaneConcurrency {
+			if l.Concurrency < 1 {
+				return makeConfigGeneralResult(), fmt.Errorf("concurrency of job lane %s must be at least 1", l.Lane)
+			}
+			concurrency[strings.ToLower(l.Lane.String())] = l.Concurrency
+		}
+		c.Set(config.JobLaneConcurrency, concurrency)
+	}
+
 	if input.PreviewAudio != nil {
 		c.Set(config.PreviewAudio, *input.PreviewAudio)
 	}
@@ -175,6 +187,25 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input models.Co
 		c.Set(config.MaxStreamingTranscodeSize, input.MaxStreamingTranscodeSize.String())
 	}
 
+	if input.TranscodeProfiles != nil || input.GeneratedTranscodeProfile != nil {
+		profiles := c.GetTranscodeProfiles()
+		if input.TranscodeProfiles != nil {
+			profiles = transcodeProfilesFromInput(input.TranscodeProfiles)
+		}
+
+		generatedProfile := c.GetGeneratedTranscodeProfile()
+		if input.GeneratedTranscodeProfile != nil {
+			generatedProfile = *input.GeneratedTranscodeProfile
+		}
+
+		if err := c.ValidateTranscodeProfiles(profiles, generatedProfile); err != nil {
+			return nil, err
+		}
+
+		c.Set(config.TranscodeProfiles, profiles)
+		c.Set(config.GeneratedTranscodeProfile, generatedProfile)
+	}
+
 	if input.WriteImageThumbnails != nil {
 		c.Set(config.WriteImageThumbnails, *input.WriteImageThumbnails)
 	}
@@ -223,6 +254,14 @@ func (r *mutationResolver) ConfigureGeneral(ctx context.Context, input models.Co
 		c.Set(config.ImageExclude, input.ImageExcludes)
 	}
 
+	if input.WatchStashPaths != nil {
+		c.Set(config.WatchStashPaths, *input.WatchStashPaths)
+	}
+
+	if input.WatchDebounce != nil {
+		c.Set(config.WatchDebounce, *input.WatchDebounce)
+	}
+
 	if input.VideoExtensions != nil {
 		c.Set(config.VideoExtensions, input.VideoExtensions)
 	}
@@ -406,6 +445,10 @@ func (r *mutationResolver) ConfigureDlna(ctx context.Context, input models.Confi
 		c.Set(config.DLNAInterfaces, input.Interfaces)
 	}
 
+	if input.RestrictionProfileID != nil {
+		c.Set(config.DLNARestrictionProfileID, *input.RestrictionProfileID)
+	}
+
 	if err := c.Write(); err != nil {
 		return makeConfigDLNAResult(), err
 	}
@@ -435,6 +478,24 @@ func (r *mutationResolver) ConfigureScraping(ctx context.Context, input models.C
 		c.Set(config.ScraperCertCheck, input.ScraperCertCheck)
 	}
 
+	if input.ScraperCacheTTL != nil {
+		if *input.ScraperCacheTTL < 0 {
+			return makeConfigScrapingResult(), errors.New("scraper cache TTL must not be negative")
+		}
+		c.Set(config.ScraperCacheTTL, input.ScraperCacheTTL)
+	}
+
+	if input.ScraperCredentials != nil {
+		for _, cred := range input.ScraperCredentials {
+			if strings.TrimSpace(cred.ScraperID) == "" || strings.TrimSpace(cred.Key) == "" {
+				return makeConfigScrapingResult(), errors.New("scraper credentials require a scraper id and key")
+			}
+		}
+		c.Set(config.ScraperCredentials, input.ScraperCredentials)
+		// log out the scrapers, so that they log in with the new credentials
+		refreshScraperCache = true
+	}
+
 	if refreshScraperCache {
 		manager.GetInstance().RefreshScraperCache()
 	}
@@ -521,3 +582,37 @@ func (r *mutationResolver) ConfigureUISetting(ctx context.Context, key string, v
 
 	return r.ConfigureUI(ctx, cfg)
 }
+
+func transcodeProfilesFromInput(input []*models.TranscodeProfileInput) models.TranscodeProfiles {
+	var ret models.TranscodeProfiles
+	for _, v := range input {
+		p := &models.TranscodeProfile{
+			Name:       v.Name,
+			Container:  v.Container,
+			VideoCodec: v.VideoCodec,
+		}
+
+		if v.Preset != nil {
+			p.Preset = *v.Preset
+		}
+		if v.Crf != nil {
+			p.CRF = *v.Crf
+		}
+		if v.VideoBitrate != nil {
+			p.VideoBitrate = *v.VideoBitrate
+		}
+		if v.AudioCodec != nil {
+			p.AudioCodec = *v.AudioCodec
+		}
+		if v.AudioBitrate != nil {
+			p.AudioBitrate = *v.AudioBitrate
+		}
+		if v.MaxHeight != nil {
+			p.MaxHeight = *v.MaxHeight
+		}
+
+		ret = append(ret, p)
+	}
+
+	return ret
+}
@@ -21,3 +21,67 @@ func (r *mutationResolver) StopAllJobs(ctx context.Context) (bool, error) {
 	manager.GetInstance().JobManager.CancelAll()
 	return true, nil
 }
+
+func (r *mutationResolver) RequeueJob(ctx context.Context, jobID string) (string, error) {
+	idInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return "", err
+	}
+
+	newID, err := manager.GetInstance().RequeueJob(ctx, idInt)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(newID), nil
+}
+
+func (r *mutationResolver) RetryJobFailures(ctx context.Context, jobID string) (string, error) {
+	idInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return "", err
+	}
+
+	newID, err := manager.GetInstance().RetryJobFailures(ctx, idInt)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(newID), nil
+}
+
+func (r *mutationResolver) PauseJob(ctx context.Context, jobID string) (bool, error) {
+	idInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return false, err
+	}
+
+	return manager.GetInstance().JobManager.PauseJob(idInt), nil
+}
+
+func (r *mutationResolver) ResumeJob(ctx context.Context, jobID string) (bool, error) {
+	idInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return false, err
+	}
+
+	return manager.GetInstance().JobManager.ResumeJob(idInt), nil
+}
+
+func (r *mutationResolver) SetJobPriority(ctx context.Context, jobID string, priority int) (bool, error) {
+	idInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return false, err
+	}
+
+	return manager.GetInstance().JobManager.SetJobPriority(idInt, priority), nil
+}
+
+func (r *mutationResolver) MoveJob(ctx context.Context, jobID string, position int) (bool, error) {
+	idInt, err := strconv.Atoi(jobID)
+	if err != nil {
+		return false, err
+	}
+
+	return manager.GetInstance().JobManager.MoveJob(idInt, position), nil
+}
@@ -102,6 +102,15 @@ func (r *mutationResolver) MetadataClean(ctx context.Context, input models.Clean
 	return strconv.Itoa(jobID), nil
 }
 
+func (r *mutationResolver) MetadataResolveDuplicates(ctx context.Context, input models.ResolveDuplicatesMetadataInput) (string, error) {
+	jobID, err := manager.GetInstance().ResolveDuplicates(ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(jobID), nil
+}
+
 func (r *mutationResolver) MigrateHashNaming(ctx context.Context) (string, error) {
 	jobID := manager.GetInstance().MigrateHash(ctx)
 	return strconv.Itoa(jobID), nil
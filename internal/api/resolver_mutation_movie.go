@@ -124,6 +124,40 @@ func (r *mutationResolver) MovieCreate(ctx context.Context, input models.MovieCr
 }
 
 func (r *mutationResolver) MovieUpdate(ctx context.Context, input models.MovieUpdateInput) (*models.Movie, error) {
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	updater, err := newMovieUpdater(ctx, input, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start the transaction and save the movie
+	var movie *models.Movie
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		var err error
+		movie, err = updater.update(ctx, repo.Movie())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, movie.ID, plugin.MovieUpdatePost, input, translator.getFields())
+	return r.getMovie(ctx, movie.ID)
+}
+
+// movieUpdater applies a MovieUpdateInput to a movie. The images of the
+// input are processed when the updater is created, so that they are not
+// fetched within a transaction.
+type movieUpdater struct {
+	translator     changesetTranslator
+	partial        models.MoviePartial
+	frontImageData []byte
+	backImageData  []byte
+}
+
+func newMovieUpdater(ctx context.Context, input models.MovieUpdateInput, translator changesetTranslator) (*movieUpdater, error) {
 	// Populate movie from the input
 	movieID, err := strconv.Atoi(input.ID)
 	if err != nil {
@@ -135,19 +169,13 @@ func (r *mutationResolver) MovieUpdate(ctx context.Context, input models.MovieUp
 		UpdatedAt: &models.SQLiteTimestamp{Timestamp: time.Now()},
 	}
 
-	translator := changesetTranslator{
-		inputMap: getUpdateInputMap(ctx),
-	}
-
 	var frontimageData []byte
-	frontImageIncluded := translator.hasField("front_image")
 	if input.FrontImage != nil {
 		frontimageData, err = utils.ProcessImageInput(ctx, *input.FrontImage)
 		if err != nil {
 			return nil, err
 		}
 	}
-	backImageIncluded := translator.hasField("back_image")
 	var backimageData []byte
 	if input.BackImage != nil {
 		backimageData, err = utils.ProcessImageInput(ctx, *input.BackImage)
@@ -172,55 +200,60 @@ func (r *mutationResolver) MovieUpdate(ctx context.Context, input models.MovieUp
 	updatedMovie.Synopsis = translator.nullString(input.Synopsis, "synopsis")
 	updatedMovie.URL = translator.nullString(input.URL, "url")
 
-	// Start the transaction and save the movie
-	var movie *models.Movie
-	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Movie()
-		movie, err = qb.Update(updatedMovie)
-		if err != nil {
-			return err
+	return &movieUpdater{
+		translator:     translator,
+		partial:        updatedMovie,
+		frontImageData: frontimageData,
+		backImageData:  backimageData,
+	}, nil
+}
+
+func (u *movieUpdater) update(ctx context.Context, qb models.MovieReaderWriter) (*models.Movie, error) {
+	updatedMovie := u.partial
+	frontimageData := u.frontImageData
+	backimageData := u.backImageData
+	frontImageIncluded := u.translator.hasField("front_image")
+	backImageIncluded := u.translator.hasField("back_image")
+
+	movie, err := qb.Update(updatedMovie)
+	if err != nil {
+		return nil, err
+	}
+
+	// update image table
+	if frontImageIncluded || backImageIncluded {
+		if !frontImageIncluded {
+			frontimageData, err = qb.GetFrontImage(updatedMovie.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !backImageIncluded {
+			backimageData, err = qb.GetBackImage(updatedMovie.ID)
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		// update image table
-		if frontImageIncluded || backImageIncluded {
-			if !frontImageIncluded {
-				frontimageData, err = qb.GetFrontImage(updatedMovie.ID)
-				if err != nil {
-					return err
-				}
+		if len(frontimageData) == 0 && len(backimageData) == 0 {
+			// both images are being nulled. Destroy them.
+			if err := qb.DestroyImages(movie.ID); err != nil {
+				return nil, err
 			}
-			if !backImageIncluded {
-				backimageData, err = qb.GetBackImage(updatedMovie.ID)
-				if err != nil {
-					return err
-				}
+		} else {
+			// HACK - if front image is null and back image is not null, then set the front image
+			// to the default image since we can't have a null front image and a non-null back image
+			if frontimageData == nil && backimageData != nil {
+				frontimageData, _ = utils.ProcessImageInput(ctx, models.DefaultMovieImage)
 			}
 
-			if len(frontimageData) == 0 && len(backimageData) == 0 {
-				// both images are being nulled. Destroy them.
-				if err := qb.DestroyImages(movie.ID); err != nil {
-					return err
-				}
-			} else {
-				// HACK - if front image is null and back image is not null, then set the front image
-				// to the default image since we can't have a null front image and a non-null back image
-				if frontimageData == nil && backimageData != nil {
-					frontimageData, _ = utils.ProcessImageInput(ctx, models.DefaultMovieImage)
-				}
-
-				if err := qb.UpdateImages(movie.ID, frontimageData, backimageData); err != nil {
-					return err
-				}
+			if err := qb.UpdateImages(movie.ID, frontimageData, backimageData); err != nil {
+				return nil, err
 			}
 		}
-
-		return nil
-	}); err != nil {
-		return nil, err
 	}
 
-	r.hookExecutor.ExecutePostHooks(ctx, movie.ID, plugin.MovieUpdatePost, input, translator.getFields())
-	return r.getMovie(ctx, movie.ID)
+	return movie, nil
 }
 
 func (r *mutationResolver) BulkMovieUpdate(ctx context.Context, input models.BulkMovieUpdateInput) ([]*models.Movie, error) {
@@ -330,3 +363,53 @@ func (r *mutationResolver) MoviesDestroy(ctx context.Context, movieIDs []string)
 
 	return true, nil
 }
+
+func (r *mutationResolver) MoviesMerge(ctx context.Context, input models.MoviesMergeInput) (*models.Movie, error) {
+	source, err := stringslice.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(source) == 0 {
+		return nil, nil
+	}
+
+	// the values are applied to the destination after merging
+	values := models.MovieUpdateInput{}
+	if input.Values != nil {
+		values = *input.Values
+	}
+	values.ID = input.Destination
+
+	translator := changesetTranslator{
+		inputMap: getNestedUpdateInputMap(ctx, "values"),
+	}
+
+	updater, err := newMovieUpdater(ctx, values, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	var movie *models.Movie
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Movie()
+
+		if err := qb.Merge(source, destination); err != nil {
+			return err
+		}
+
+		var err error
+		movie, err = updater.update(ctx, qb)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, movie.ID, plugin.MovieUpdatePost, input, translator.getFields())
+	return r.getMovie(ctx, movie.ID)
+}
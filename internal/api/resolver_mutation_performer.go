@@ -168,6 +168,40 @@ func (r *mutationResolver) PerformerCreate(ctx context.Context, input models.Per
 }
 
 func (r *mutationResolver) PerformerUpdate(ctx context.Context, input models.PerformerUpdateInput) (*models.Performer, error) {
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	updater, err := newPerformerUpdater(ctx, input, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start the transaction and save the p
+	var p *models.Performer
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		var err error
+		p, err = updater.update(repo.Performer())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, p.ID, plugin.PerformerUpdatePost, input, translator.getFields())
+	return r.getPerformer(ctx, p.ID)
+}
+
+// performerUpdater applies a PerformerUpdateInput to a performer. The image
+// of the input is processed when the updater is created, so that it is not
+// fetched within a transaction.
+type performerUpdater struct {
+	input      models.PerformerUpdateInput
+	translator changesetTranslator
+	partial    models.PerformerPartial
+	imageData  []byte
+}
+
+func newPerformerUpdater(ctx context.Context, input models.PerformerUpdateInput, translator changesetTranslator) (*performerUpdater, error) {
 	// Populate performer from the input
 	performerID, _ := strconv.Atoi(input.ID)
 	updatedPerformer := models.PerformerPartial{
@@ -175,14 +209,9 @@ func (r *mutationResolver) PerformerUpdate(ctx context.Context, input models.Per
 		UpdatedAt: &models.SQLiteTimestamp{Timestamp: time.Now()},
 	}
 
-	translator := changesetTranslator{
-		inputMap: getUpdateInputMap(ctx),
-	}
-
 	var imageData []byte
-	var err error
-	imageIncluded := translator.hasField("image")
 	if input.Image != nil {
+		var err error
 		imageData, err = utils.ProcessImageInput(ctx, *input.Image)
 		if err != nil {
 			return nil, err
@@ -228,66 +257,71 @@ func (r *mutationResolver) PerformerUpdate(ctx context.Context, input models.Per
 	updatedPerformer.Weight = translator.nullInt64(input.Weight, "weight")
 	updatedPerformer.IgnoreAutoTag = input.IgnoreAutoTag
 
-	// Start the transaction and save the p
-	var p *models.Performer
-	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Performer()
+	return &performerUpdater{
+		input:      input,
+		translator: translator,
+		partial:    updatedPerformer,
+		imageData:  imageData,
+	}, nil
+}
 
-		// need to get existing performer
-		existing, err := qb.Find(updatedPerformer.ID)
-		if err != nil {
-			return err
-		}
+func (u *performerUpdater) update(qb models.PerformerReaderWriter) (*models.Performer, error) {
+	input := u.input
+	translator := u.translator
+	updatedPerformer := u.partial
 
-		if existing == nil {
-			return fmt.Errorf("performer with id %d not found", updatedPerformer.ID)
-		}
+	// need to get existing performer
+	existing, err := qb.Find(updatedPerformer.ID)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := performer.ValidateDeathDate(existing, input.Birthdate, input.DeathDate); err != nil {
-			if err != nil {
-				return err
-			}
-		}
+	if existing == nil {
+		return nil, fmt.Errorf("performer with id %d not found", updatedPerformer.ID)
+	}
+
+	if err := performer.ValidateDeathDate(existing, input.Birthdate, input.DeathDate); err != nil {
+		return nil, err
+	}
+
+	p, err := qb.Update(updatedPerformer)
+	if err != nil {
+		return nil, err
+	}
 
-		p, err = qb.Update(updatedPerformer)
+	// Save the tags
+	if translator.hasField("tag_ids") {
+		tagIDs, err := stringslice.StringSliceToIntSlice(input.TagIds)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// Save the tags
-		if translator.hasField("tag_ids") {
-			if err := r.updatePerformerTags(qb, p.ID, input.TagIds); err != nil {
-				return err
-			}
+		if err := qb.UpdateTags(p.ID, tagIDs); err != nil {
+			return nil, err
 		}
+	}
 
-		// update image table
-		if len(imageData) > 0 {
-			if err := qb.UpdateImage(p.ID, imageData); err != nil {
-				return err
-			}
-		} else if imageIncluded {
-			// must be unsetting
-			if err := qb.DestroyImage(p.ID); err != nil {
-				return err
-			}
+	// update image table
+	if len(u.imageData) > 0 {
+		if err := qb.UpdateImage(p.ID, u.imageData); err != nil {
+			return nil, err
 		}
-
-		// Save the stash_ids
-		if translator.hasField("stash_ids") {
-			stashIDJoins := models.StashIDsFromInput(input.StashIds)
-			if err := qb.UpdateStashIDs(performerID, stashIDJoins); err != nil {
-				return err
-			}
+	} else if translator.hasField("image") {
+		// must be unsetting
+		if err := qb.DestroyImage(p.ID); err != nil {
+			return nil, err
 		}
+	}
 
-		return nil
-	}); err != nil {
-		return nil, err
+	// Save the stash_ids
+	if translator.hasField("stash_ids") {
+		stashIDJoins := models.StashIDsFromInput(input.StashIds)
+		if err := qb.UpdateStashIDs(p.ID, stashIDJoins); err != nil {
+			return nil, err
+		}
 	}
 
-	r.hookExecutor.ExecutePostHooks(ctx, p.ID, plugin.PerformerUpdatePost, input, translator.getFields())
-	return r.getPerformer(ctx, p.ID)
+	return p, nil
 }
 
 func (r *mutationResolver) updatePerformerTags(qb models.PerformerReaderWriter, performerID int, tagsIDs []string) error {
@@ -451,3 +485,64 @@ func (r *mutationResolver) PerformersDestroy(ctx context.Context, performerIDs [
 
 	return true, nil
 }
+
+func (r *mutationResolver) PerformersMerge(ctx context.Context, input models.PerformersMergeInput) (*models.Performer, error) {
+	source, err := stringslice.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(source) == 0 {
+		return nil, nil
+	}
+
+	// the values are applied to the destination after merging
+	values := models.PerformerUpdateInput{}
+	if input.Values != nil {
+		values = *input.Values
+	}
+	values.ID = input.Destination
+
+	translator := changesetTranslator{
+		inputMap: getNestedUpdateInputMap(ctx, "values"),
+	}
+
+	updater, err := newPerformerUpdater(ctx, values, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *models.Performer
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Performer()
+
+		for _, id := range append([]int{destination}, source...) {
+			existing, err := qb.Find(id)
+			if err != nil {
+				return err
+			}
+
+			if existing == nil {
+				return fmt.Errorf("performer with id %d not found", id)
+			}
+		}
+
+		if err := qb.Merge(source, destination); err != nil {
+			return err
+		}
+
+		var err error
+		p, err = updater.update(qb)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, p.ID, plugin.PerformerUpdatePost, input, translator.getFields())
+	return r.getPerformer(ctx, p.ID)
+}
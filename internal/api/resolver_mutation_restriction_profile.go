@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+)
+
+func validateRestrictionProfileName(repo models.RestrictionProfileReader, name string, id int) error {
+	if name == "" {
+		return errors.New("name must not be blank")
+	}
+
+	existing, err := repo.FindByName(name)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.ID != id {
+		return fmt.Errorf("restriction profile with name %q already exists", name)
+	}
+
+	return nil
+}
+
+func updateRestrictionProfileExclusions(qb models.RestrictionProfileWriter, id int, tagIDs []string, studioIDs []string, performerIDs []string, translator changesetTranslator) error {
+	if translator.hasField("excluded_tag_ids") {
+		ids, err := stringslice.StringSliceToIntSlice(tagIDs)
+		if err != nil {
+			return err
+		}
+		if err := qb.UpdateTags(id, ids); err != nil {
+			return err
+		}
+	}
+
+	if translator.hasField("excluded_studio_ids") {
+		ids, err := stringslice.StringSliceToIntSlice(studioIDs)
+		if err != nil {
+			return err
+		}
+		if err := qb.UpdateStudios(id, ids); err != nil {
+			return err
+		}
+	}
+
+	if translator.hasField("excluded_performer_ids") {
+		ids, err := stringslice.StringSliceToIntSlice(performerIDs)
+		if err != nil {
+			return err
+		}
+		if err := qb.UpdatePerformers(id, ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *mutationResolver) RestrictionProfileCreate(ctx context.Context, input models.RestrictionProfileCreateInput) (ret *models.RestrictionProfile, err error) {
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	name := strings.TrimSpace(input.Name)
+	currentTime := time.Now()
+	newProfile := models.RestrictionProfile{
+		Name:      name,
+		CreatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+		UpdatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.RestrictionProfile()
+		if err := validateRestrictionProfileName(qb, name, 0); err != nil {
+			return err
+		}
+
+		ret, err = qb.Create(newProfile)
+		if err != nil {
+			return err
+		}
+
+		return updateRestrictionProfileExclusions(qb, ret.ID, input.ExcludedTagIds, input.ExcludedStudioIds, input.ExcludedPerformerIds, translator)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) RestrictionProfileUpdate(ctx context.Context, input models.RestrictionProfileUpdateInput) (ret *models.RestrictionProfile, err error) {
+	id, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.RestrictionProfile()
+		profile, err := qb.Find(id)
+		if err != nil {
+			return err
+		}
+
+		if profile == nil {
+			return fmt.Errorf("restriction profile with id %d not found", id)
+		}
+
+		if input.Name != nil {
+			name := strings.TrimSpace(*input.Name)
+			if err := validateRestrictionProfileName(qb, name, id); err != nil {
+				return err
+			}
+			profile.Name = name
+		}
+
+		profile.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+
+		ret, err = qb.Update(*profile)
+		if err != nil {
+			return err
+		}
+
+		return updateRestrictionProfileExclusions(qb, id, input.ExcludedTagIds, input.ExcludedStudioIds, input.ExcludedPerformerIds, translator)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) RestrictionProfileDestroy(ctx context.Context, id string) (bool, error) {
+	profileID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		return repo.RestrictionProfile().Destroy(profileID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
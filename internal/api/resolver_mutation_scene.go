@@ -13,6 +13,7 @@ import (
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/plugin"
 	"github.com/stashapp/stash/pkg/scene"
+	"github.com/stashapp/stash/pkg/session"
 	"github.com/stashapp/stash/pkg/sliceutil/intslice"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 	"github.com/stashapp/stash/pkg/utils"
@@ -96,6 +97,15 @@ func (r *mutationResolver) sceneUpdate(ctx context.Context, input models.SceneUp
 		return nil, err
 	}
 
+	// viewers may only set their own rating
+	if !session.HasRole(ctx, models.UserRoleEditor) {
+		for _, field := range translator.getFields() {
+			if field != "id" && field != "rating" {
+				return nil, session.ErrForbidden
+			}
+		}
+	}
+
 	var coverImageData []byte
 
 	updatedTime := time.Now()
@@ -112,6 +122,17 @@ func (r *mutationResolver) sceneUpdate(ctx context.Context, input models.SceneUp
 	updatedScene.StudioID = translator.nullInt64FromString(input.StudioID, "studio_id")
 	updatedScene.Organized = input.Organized
 
+	qb := repo.Scene()
+
+	// stored users have their own ratings
+	userID := session.GetCurrentUserAccountID(ctx)
+	if userID != nil && updatedScene.Rating != nil {
+		if err := updateUserRating(qb, sceneID, *userID, *updatedScene.Rating); err != nil {
+			return nil, err
+		}
+		updatedScene.Rating = nil
+	}
+
 	if input.CoverImage != nil && *input.CoverImage != "" {
 		var err error
 		coverImageData, err = utils.ProcessImageInput(ctx, *input.CoverImage)
@@ -122,7 +143,14 @@ func (r *mutationResolver) sceneUpdate(ctx context.Context, input models.SceneUp
 		// update the cover after updating the scene
 	}
 
-	qb := repo.Scene()
+	// change the primary file before updating the scene, so that the scene
+	// reflects the new file
+	if translator.hasField("primary_file_id") && input.PrimaryFileID != nil {
+		if err := r.setScenePrimaryFile(qb, sceneID, *input.PrimaryFileID); err != nil {
+			return nil, err
+		}
+	}
+
 	s, err := qb.Update(updatedScene)
 	if err != nil {
 		return nil, err
@@ -182,6 +210,46 @@ func (r *mutationResolver) sceneUpdate(ctx context.Context, input models.SceneUp
 	return s, nil
 }
 
+func (r *mutationResolver) setScenePrimaryFile(qb models.SceneReaderWriter, sceneID int, fileID string) error {
+	fileIDInt, err := strconv.Atoi(fileID)
+	if err != nil {
+		return err
+	}
+
+	s, err := qb.Find(sceneID)
+	if err != nil {
+		return err
+	}
+
+	if s == nil {
+		return fmt.Errorf("scene with id %d not found", sceneID)
+	}
+
+	oldHash := s.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm())
+
+	if err := qb.SetPrimaryFile(sceneID, fileIDInt); err != nil {
+		return err
+	}
+
+	s, err = qb.Find(sceneID)
+	if err != nil {
+		return err
+	}
+
+	migrateSceneHash(oldHash, s)
+	return nil
+}
+
+// migrateSceneHash moves the generated files of the scene if its hash has
+// changed from oldHash. This is required when the primary file of the scene
+// changes.
+func migrateSceneHash(oldHash string, s *models.Scene) {
+	newHash := s.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm())
+	if oldHash != "" && newHash != "" && oldHash != newHash {
+		scene.MigrateHash(manager.GetInstance().Paths, oldHash, newHash)
+	}
+}
+
 func (r *mutationResolver) updateScenePerformers(qb models.SceneReaderWriter, sceneID int, performerIDs []string) error {
 	ids, err := stringslice.StringSliceToIntSlice(performerIDs)
 	if err != nil {
@@ -232,6 +300,88 @@ func (r *mutationResolver) updateSceneGalleries(qb models.SceneReaderWriter, sce
 	return qb.UpdateGalleries(sceneID, ids)
 }
 
+func (r *mutationResolver) SceneAssignFile(ctx context.Context, input models.AssignSceneFileInput) (bool, error) {
+	sceneID, err := strconv.Atoi(input.SceneID)
+	if err != nil {
+		return false, err
+	}
+
+	fileID, err := strconv.Atoi(input.FileID)
+	if err != nil {
+		return false, err
+	}
+
+	fileNamingAlgo := config.GetInstance().GetVideoFileNamingAlgorithm()
+	var oldSceneID int
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		s, err := qb.Find(sceneID)
+		if err != nil {
+			return err
+		}
+
+		if s == nil {
+			return fmt.Errorf("scene with id %d not found", sceneID)
+		}
+
+		f, err := qb.FindFile(fileID)
+		if err != nil {
+			return err
+		}
+
+		if f == nil {
+			return fmt.Errorf("file with id %d not found", fileID)
+		}
+
+		oldSceneID = f.SceneID
+		if oldSceneID == sceneID {
+			return nil
+		}
+
+		files, err := qb.GetFiles(oldSceneID)
+		if err != nil {
+			return err
+		}
+
+		if len(files) < 2 {
+			return fmt.Errorf("cannot reassign the only file of scene %d", oldSceneID)
+		}
+
+		oldScene, err := qb.Find(oldSceneID)
+		if err != nil {
+			return err
+		}
+
+		oldHash := oldScene.GetHash(fileNamingAlgo)
+
+		f.SceneID = sceneID
+		f.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+		if _, err := qb.UpdateFile(*f); err != nil {
+			return err
+		}
+
+		// the source scene gets a new primary file if the file was primary
+		oldScene, err = qb.Find(oldSceneID)
+		if err != nil {
+			return err
+		}
+
+		migrateSceneHash(oldHash, oldScene)
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	if oldSceneID != sceneID {
+		r.hookExecutor.ExecutePostHooks(ctx, oldSceneID, plugin.SceneUpdatePost, input, nil)
+		r.hookExecutor.ExecutePostHooks(ctx, sceneID, plugin.SceneUpdatePost, input, nil)
+	}
+
+	return true, nil
+}
+
 func (r *mutationResolver) BulkSceneUpdate(ctx context.Context, input models.BulkSceneUpdateInput) ([]*models.Scene, error) {
 	sceneIDs, err := stringslice.StringSliceToIntSlice(input.Ids)
 	if err != nil {
@@ -257,6 +407,13 @@ func (r *mutationResolver) BulkSceneUpdate(ctx context.Context, input models.Bul
 	updatedScene.StudioID = translator.nullInt64FromString(input.StudioID, "studio_id")
 	updatedScene.Organized = input.Organized
 
+	// stored users have their own ratings
+	userID := session.GetCurrentUserAccountID(ctx)
+	userRating := updatedScene.Rating
+	if userID != nil {
+		updatedScene.Rating = nil
+	}
+
 	ret := []*models.Scene{}
 
 	// Start the transaction and save the scene marker
@@ -266,6 +423,12 @@ func (r *mutationResolver) BulkSceneUpdate(ctx context.Context, input models.Bul
 		for _, sceneID := range sceneIDs {
 			updatedScene.ID = sceneID
 
+			if userID != nil && userRating != nil {
+				if err := updateUserRating(qb, sceneID, *userID, *userRating); err != nil {
+					return err
+				}
+			}
+
 			scene, err := qb.Update(updatedScene)
 			if err != nil {
 				return err
@@ -563,6 +726,81 @@ func (r *mutationResolver) ScenesDestroy(ctx context.Context, input models.Scene
 	return true, nil
 }
 
+func (r *mutationResolver) ScenesMerge(ctx context.Context, input models.ScenesMergeInput) (*models.Scene, error) {
+	source, err := stringslice.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	options := scene.MergeOptions{
+		DeleteFiles: utils.IsTrue(input.DeleteFiles),
+	}
+	if input.PrimaryFileID != nil {
+		options.PrimaryFileID, err = strconv.Atoi(*input.PrimaryFileID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(source) == 0 {
+		return nil, nil
+	}
+
+	fileNamingAlgo := manager.GetInstance().Config.GetVideoFileNamingAlgorithm()
+	fileDeleter := &scene.FileDeleter{
+		Deleter:        *file.NewDeleter(),
+		FileNamingAlgo: fileNamingAlgo,
+		Paths:          manager.GetInstance().Paths,
+	}
+
+	var ret *models.Scene
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		dest, err := qb.Find(destination)
+		if err != nil {
+			return err
+		}
+
+		if dest == nil {
+			return fmt.Errorf("scene with id %d not found", destination)
+		}
+
+		var sources []*models.Scene
+		for _, id := range source {
+			s, err := qb.Find(id)
+			if err != nil {
+				return err
+			}
+
+			if s == nil {
+				return fmt.Errorf("scene with id %d not found", id)
+			}
+
+			// kill any running encoders
+			manager.KillRunningStreams(s, fileNamingAlgo)
+			sources = append(sources, s)
+		}
+
+		ret, err = scene.Merge(dest, sources, repo, fileDeleter, options)
+		return err
+	}); err != nil {
+		fileDeleter.Rollback()
+		return nil, err
+	}
+
+	// perform the post-commit actions
+	fileDeleter.Commit()
+
+	r.hookExecutor.ExecutePostHooks(ctx, ret.ID, plugin.SceneMergePost, input, nil)
+	return ret, nil
+}
+
 func (r *mutationResolver) getSceneMarker(ctx context.Context, id int) (ret *models.SceneMarker, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
 		ret, err = repo.SceneMarker().Find(id)
@@ -589,6 +827,7 @@ func (r *mutationResolver) SceneMarkerCreate(ctx context.Context, input models.S
 	newSceneMarker := models.SceneMarker{
 		Title:        input.Title,
 		Seconds:      input.Seconds,
+		EndSeconds:   models.NullFloat64FromPtr(input.EndSeconds),
 		PrimaryTagID: primaryTagID,
 		SceneID:      sql.NullInt64{Int64: int64(sceneID), Valid: sceneID != 0},
 		CreatedAt:    models.SQLiteTimestamp{Timestamp: currentTime},
@@ -626,6 +865,10 @@ func (r *mutationResolver) SceneMarkerUpdate(ctx context.Context, input models.S
 		return nil, err
 	}
 
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
 	updatedSceneMarker := models.SceneMarker{
 		ID:           sceneMarkerID,
 		Title:        input.Title,
@@ -635,6 +878,20 @@ func (r *mutationResolver) SceneMarkerUpdate(ctx context.Context, input models.S
 		UpdatedAt:    models.SQLiteTimestamp{Timestamp: time.Now()},
 	}
 
+	// retain the existing end time if it was not provided
+	if translator.hasField("end_seconds") {
+		updatedSceneMarker.EndSeconds = models.NullFloat64FromPtr(input.EndSeconds)
+	} else {
+		existing, err := r.getSceneMarker(ctx, sceneMarkerID)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing != nil {
+			updatedSceneMarker.EndSeconds = existing.EndSeconds
+		}
+	}
+
 	tagIDs, err := stringslice.StringSliceToIntSlice(input.TagIds)
 	if err != nil {
 		return nil, err
@@ -645,9 +902,6 @@ func (r *mutationResolver) SceneMarkerUpdate(ctx context.Context, input models.S
 		return nil, err
 	}
 
-	translator := changesetTranslator{
-		inputMap: getUpdateInputMap(ctx),
-	}
 	r.hookExecutor.ExecutePostHooks(ctx, ret.ID, plugin.SceneMarkerUpdatePost, input, translator.getFields())
 	return r.getSceneMarker(ctx, ret.ID)
 }
@@ -700,6 +954,10 @@ func (r *mutationResolver) SceneMarkerDestroy(ctx context.Context, id string) (b
 }
 
 func (r *mutationResolver) changeMarker(ctx context.Context, changeType int, changedMarker models.SceneMarker, tagIDs []int) (*models.SceneMarker, error) {
+	if changedMarker.EndSeconds.Valid && changedMarker.EndSeconds.Float64 <= changedMarker.Seconds {
+		return nil, fmt.Errorf("end_seconds (%v) must be greater than seconds (%v)", changedMarker.EndSeconds.Float64, changedMarker.Seconds)
+	}
+
 	var existingMarker *models.SceneMarker
 	var sceneMarker *models.SceneMarker
 	var s *models.Scene
@@ -738,8 +996,8 @@ func (r *mutationResolver) changeMarker(ctx context.Context, changeType int, cha
 			return err
 		}
 
-		// remove the marker preview if the timestamp was changed
-		if s != nil && existingMarker != nil && existingMarker.Seconds != changedMarker.Seconds {
+		// remove the marker preview if the timestamp or range was changed
+		if s != nil && existingMarker != nil && (existingMarker.Seconds != changedMarker.Seconds || existingMarker.EndSeconds != changedMarker.EndSeconds) {
 			seconds := int(existingMarker.Seconds)
 			if err := fileDeleter.MarkMarkerFiles(s, seconds); err != nil {
 				return err
@@ -769,6 +1027,13 @@ func (r *mutationResolver) SceneIncrementO(ctx context.Context, id string) (ret
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Scene()
 
+		if userID := session.GetCurrentUserAccountID(ctx); userID != nil {
+			ret, err = updateUserOCounter(qb, sceneID, *userID, func(o int) int {
+				return o + 1
+			})
+			return err
+		}
+
 		ret, err = qb.IncrementOCounter(sceneID)
 		return err
 	}); err != nil {
@@ -787,6 +1052,16 @@ func (r *mutationResolver) SceneDecrementO(ctx context.Context, id string) (ret
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Scene()
 
+		if userID := session.GetCurrentUserAccountID(ctx); userID != nil {
+			ret, err = updateUserOCounter(qb, sceneID, *userID, func(o int) int {
+				if o > 0 {
+					return o - 1
+				}
+				return 0
+			})
+			return err
+		}
+
 		ret, err = qb.DecrementOCounter(sceneID)
 		return err
 	}); err != nil {
@@ -805,6 +1080,13 @@ func (r *mutationResolver) SceneResetO(ctx context.Context, id string) (ret int,
 	if err := r.withTxn(ctx, func(repo models.Repository) error {
 		qb := repo.Scene()
 
+		if userID := session.GetCurrentUserAccountID(ctx); userID != nil {
+			ret, err = updateUserOCounter(qb, sceneID, *userID, func(o int) int {
+				return 0
+			})
+			return err
+		}
+
 		ret, err = qb.ResetOCounter(sceneID)
 		return err
 	}); err != nil {
@@ -814,6 +1096,66 @@ func (r *mutationResolver) SceneResetO(ctx context.Context, id string) (ret int,
 	return ret, nil
 }
 
+// updateUserRating sets the rating of the scene for the user.
+func updateUserRating(qb models.SceneReaderWriter, sceneID int, userID int, rating sql.NullInt64) error {
+	data, err := qb.GetUserData(sceneID, userID)
+	if err != nil {
+		return err
+	}
+
+	data.Rating = rating
+	return qb.UpdateUserData(*data)
+}
+
+// updateUserOCounter sets the o-counter of the scene for the user to the
+// result of fn and returns the new value.
+func updateUserOCounter(qb models.SceneReaderWriter, sceneID int, userID int, fn func(o int) int) (int, error) {
+	data, err := qb.GetUserData(sceneID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	data.OCounter = fn(data.OCounter)
+	if err := qb.UpdateUserData(*data); err != nil {
+		return 0, err
+	}
+
+	return data.OCounter, nil
+}
+
+func (r *mutationResolver) SceneAddPlay(ctx context.Context, id string) (ret int, err error) {
+	sceneID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		ret, err = qb.AddPlay(sceneID, session.GetCurrentUserAccountID(ctx), time.Now())
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) SceneSaveActivity(ctx context.Context, id string, resumeTime *float64, playDuration *float64) (ret bool, err error) {
+	sceneID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		return repo.Scene().SaveActivity(sceneID, session.GetCurrentUserAccountID(ctx), resumeTime, playDuration)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (r *mutationResolver) SceneGenerateScreenshot(ctx context.Context, id string, at *float64) (string, error) {
 	if at != nil {
 		manager.GetInstance().GenerateScreenshot(ctx, id, *at)
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func validateScheduleName(repo models.ScheduleReader, name string, id int) error {
+	if name == "" {
+		return errors.New("name must not be blank")
+	}
+
+	existing, err := repo.FindByName(name)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.ID != id {
+		return fmt.Errorf("schedule with name %q already exists", name)
+	}
+
+	return nil
+}
+
+func nullStringFromPtr(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: *value, Valid: true}
+}
+
+func (r *mutationResolver) ScheduleCreate(ctx context.Context, input models.ScheduleCreateInput) (ret *models.Schedule, err error) {
+	name := strings.TrimSpace(input.Name)
+	currentTime := time.Now()
+	newSchedule := models.Schedule{
+		Name:           name,
+		Task:           input.Task,
+		Cron:           nullStringFromPtr(input.Cron),
+		PluginID:       nullStringFromPtr(input.PluginID),
+		PluginTaskName: nullStringFromPtr(input.PluginTaskName),
+		Enabled:        true,
+		CreatedAt:      models.SQLiteTimestamp{Timestamp: currentTime},
+		UpdatedAt:      models.SQLiteTimestamp{Timestamp: currentTime},
+	}
+
+	if input.Interval != nil {
+		newSchedule.Interval = sql.NullInt64{Int64: int64(*input.Interval), Valid: true}
+	}
+	if input.Enabled != nil {
+		newSchedule.Enabled = *input.Enabled
+	}
+
+	if err := manager.ValidateSchedule(newSchedule); err != nil {
+		return nil, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Schedule()
+		if err := validateScheduleName(qb, name, 0); err != nil {
+			return err
+		}
+
+		ret, err = qb.Create(newSchedule)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) ScheduleUpdate(ctx context.Context, input models.ScheduleUpdateInput) (ret *models.Schedule, err error) {
+	id, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Schedule()
+		schedule, err := qb.Find(id)
+		if err != nil {
+			return err
+		}
+
+		if schedule == nil {
+			return fmt.Errorf("schedule with id %d not found", id)
+		}
+
+		if input.Name != nil {
+			name := strings.TrimSpace(*input.Name)
+			if err := validateScheduleName(qb, name, id); err != nil {
+				return err
+			}
+			schedule.Name = name
+		}
+
+		if input.Task != nil {
+			schedule.Task = *input.Task
+		}
+		if v := translator.nullString(input.Cron, "cron"); v != nil {
+			schedule.Cron = *v
+		}
+		if v := translator.nullInt64(input.Interval, "interval"); v != nil {
+			schedule.Interval = *v
+		}
+		if v := translator.nullString(input.PluginID, "plugin_id"); v != nil {
+			schedule.PluginID = *v
+		}
+		if v := translator.nullString(input.PluginTaskName, "plugin_task_name"); v != nil {
+			schedule.PluginTaskName = *v
+		}
+		if input.Enabled != nil {
+			schedule.Enabled = *input.Enabled
+		}
+
+		if err := manager.ValidateSchedule(*schedule); err != nil {
+			return err
+		}
+
+		schedule.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+
+		ret, err = qb.Update(*schedule)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) ScheduleDestroy(ctx context.Context, id string) (bool, error) {
+	scheduleID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		return repo.Schedule().Destroy(scheduleID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) RunSchedule(ctx context.Context, id string) (string, error) {
+	scheduleID, err := strconv.Atoi(id)
+	if err != nil {
+		return "", err
+	}
+
+	jobID, err := manager.GetInstance().Scheduler.Run(ctx, scheduleID)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(jobID), nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/scraper"
 )
 
 func (r *mutationResolver) ReloadScrapers(ctx context.Context) (bool, error) {
@@ -15,3 +17,63 @@ func (r *mutationResolver) ReloadScrapers(ctx context.Context) (bool, error) {
 
 	return true, nil
 }
+
+func (r *mutationResolver) TestScraper(ctx context.Context, input models.ScraperTestInput) (*models.ScraperTestResult, error) {
+	var testInput scraper.TestInput
+	if input.ScraperID != nil {
+		testInput.ScraperID = *input.ScraperID
+	}
+	if input.Type != nil {
+		testInput.Type = *input.Type
+	}
+	if input.URL != nil {
+		testInput.URL = *input.URL
+	}
+	if input.Query != nil {
+		testInput.Query = *input.Query
+	}
+	if input.Fixture != nil {
+		testInput.Fixture = *input.Fixture
+	}
+	if input.Record != nil {
+		testInput.Record = *input.Record
+	}
+
+	result, err := manager.GetInstance().ScraperCache.TestScraper(ctx, testInput)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &models.ScraperTestResult{
+		Passed: result.Passed,
+	}
+
+	for _, c := range result.Content {
+		// graphql schema requires results to be non-nil
+		if c != nil {
+			ret.Results = append(ret.Results, c)
+		}
+	}
+
+	for _, f := range result.Trace.Fields {
+		field := &models.ScraperTestField{
+			Name:     f.Name,
+			Selector: f.Selector,
+			Fixed:    f.Fixed,
+			Found:    f.Found,
+			Result:   f.Result,
+		}
+
+		for _, s := range f.Steps {
+			field.Steps = append(field.Steps, &models.ScraperTestStep{
+				Action: s.Action,
+				Input:  s.Input,
+				Output: s.Output,
+			})
+		}
+
+		ret.Fields = append(ret.Fields, field)
+	}
+
+	return ret, nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -116,14 +117,44 @@ func (r *mutationResolver) StudioCreate(ctx context.Context, input models.Studio
 }
 
 func (r *mutationResolver) StudioUpdate(ctx context.Context, input models.StudioUpdateInput) (*models.Studio, error) {
-	// Populate studio from the input
-	studioID, err := strconv.Atoi(input.ID)
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+
+	updater, err := newStudioUpdater(ctx, input, translator)
 	if err != nil {
 		return nil, err
 	}
 
-	translator := changesetTranslator{
-		inputMap: getUpdateInputMap(ctx),
+	// Start the transaction and save the studio
+	var s *models.Studio
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		var err error
+		s, err = updater.update(repo.Studio())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, s.ID, plugin.StudioUpdatePost, input, translator.getFields())
+	return r.getStudio(ctx, s.ID)
+}
+
+// studioUpdater applies a StudioUpdateInput to a studio. The image of the
+// input is processed when the updater is created, so that it is not fetched
+// within a transaction.
+type studioUpdater struct {
+	input      models.StudioUpdateInput
+	translator changesetTranslator
+	partial    models.StudioPartial
+	imageData  []byte
+}
+
+func newStudioUpdater(ctx context.Context, input models.StudioUpdateInput, translator changesetTranslator) (*studioUpdater, error) {
+	// Populate studio from the input
+	studioID, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	updatedStudio := models.StudioPartial{
@@ -132,7 +163,6 @@ func (r *mutationResolver) StudioUpdate(ctx context.Context, input models.Studio
 	}
 
 	var imageData []byte
-	imageIncluded := translator.hasField("image")
 	if input.Image != nil {
 		var err error
 		imageData, err = utils.ProcessImageInput(ctx, *input.Image)
@@ -153,58 +183,59 @@ func (r *mutationResolver) StudioUpdate(ctx context.Context, input models.Studio
 	updatedStudio.Rating = translator.nullInt64(input.Rating, "rating")
 	updatedStudio.IgnoreAutoTag = input.IgnoreAutoTag
 
-	// Start the transaction and save the studio
-	var s *models.Studio
-	if err := r.withTxn(ctx, func(repo models.Repository) error {
-		qb := repo.Studio()
+	return &studioUpdater{
+		input:      input,
+		translator: translator,
+		partial:    updatedStudio,
+		imageData:  imageData,
+	}, nil
+}
 
-		if err := manager.ValidateModifyStudio(updatedStudio, qb); err != nil {
-			return err
-		}
+func (u *studioUpdater) update(qb models.StudioReaderWriter) (*models.Studio, error) {
+	input := u.input
+	translator := u.translator
+	updatedStudio := u.partial
 
-		var err error
-		s, err = qb.Update(updatedStudio)
-		if err != nil {
-			return err
-		}
+	if err := manager.ValidateModifyStudio(updatedStudio, qb); err != nil {
+		return nil, err
+	}
 
-		// update image table
-		if len(imageData) > 0 {
-			if err := qb.UpdateImage(s.ID, imageData); err != nil {
-				return err
-			}
-		} else if imageIncluded {
-			// must be unsetting
-			if err := qb.DestroyImage(s.ID); err != nil {
-				return err
-			}
-		}
+	s, err := qb.Update(updatedStudio)
+	if err != nil {
+		return nil, err
+	}
 
-		// Save the stash_ids
-		if translator.hasField("stash_ids") {
-			stashIDJoins := models.StashIDsFromInput(input.StashIds)
-			if err := qb.UpdateStashIDs(studioID, stashIDJoins); err != nil {
-				return err
-			}
+	// update image table
+	if len(u.imageData) > 0 {
+		if err := qb.UpdateImage(s.ID, u.imageData); err != nil {
+			return nil, err
+		}
+	} else if translator.hasField("image") {
+		// must be unsetting
+		if err := qb.DestroyImage(s.ID); err != nil {
+			return nil, err
 		}
+	}
 
-		if translator.hasField("aliases") {
-			if err := studio.EnsureAliasesUnique(studioID, input.Aliases, qb); err != nil {
-				return err
-			}
+	// Save the stash_ids
+	if translator.hasField("stash_ids") {
+		stashIDJoins := models.StashIDsFromInput(input.StashIds)
+		if err := qb.UpdateStashIDs(s.ID, stashIDJoins); err != nil {
+			return nil, err
+		}
+	}
 
-			if err := qb.UpdateAliases(studioID, input.Aliases); err != nil {
-				return err
-			}
+	if translator.hasField("aliases") {
+		if err := studio.EnsureAliasesUnique(s.ID, input.Aliases, qb); err != nil {
+			return nil, err
 		}
 
-		return nil
-	}); err != nil {
-		return nil, err
+		if err := qb.UpdateAliases(s.ID, input.Aliases); err != nil {
+			return nil, err
+		}
 	}
 
-	r.hookExecutor.ExecutePostHooks(ctx, s.ID, plugin.StudioUpdatePost, input, translator.getFields())
-	return r.getStudio(ctx, s.ID)
+	return s, nil
 }
 
 func (r *mutationResolver) StudioDestroy(ctx context.Context, input models.StudioDestroyInput) (bool, error) {
@@ -249,3 +280,64 @@ func (r *mutationResolver) StudiosDestroy(ctx context.Context, studioIDs []strin
 
 	return true, nil
 }
+
+func (r *mutationResolver) StudiosMerge(ctx context.Context, input models.StudiosMergeInput) (*models.Studio, error) {
+	source, err := stringslice.StringSliceToIntSlice(input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := strconv.Atoi(input.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(source) == 0 {
+		return nil, nil
+	}
+
+	// the values are applied to the destination after merging
+	values := models.StudioUpdateInput{}
+	if input.Values != nil {
+		values = *input.Values
+	}
+	values.ID = input.Destination
+
+	translator := changesetTranslator{
+		inputMap: getNestedUpdateInputMap(ctx, "values"),
+	}
+
+	updater, err := newStudioUpdater(ctx, values, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	var s *models.Studio
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Studio()
+
+		for _, id := range append([]int{destination}, source...) {
+			existing, err := qb.Find(id)
+			if err != nil {
+				return err
+			}
+
+			if existing == nil {
+				return fmt.Errorf("studio with id %d not found", id)
+			}
+		}
+
+		if err := qb.Merge(source, destination); err != nil {
+			return err
+		}
+
+		var err error
+		s, err = updater.update(qb)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	r.hookExecutor.ExecutePostHooks(ctx, s.ID, plugin.StudioUpdatePost, input, translator.getFields())
+	return r.getStudio(ctx, s.ID)
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+// checkUserAccess returns an error if the current user may not modify the
+// user with the provided id. Admins may modify any user, other users may
+// only modify themselves.
+func checkUserAccess(ctx context.Context, id int) error {
+	if session.HasRole(ctx, models.UserRoleAdmin) {
+		return nil
+	}
+
+	if current := session.GetCurrentUser(ctx); current != nil && current.ID == id {
+		return nil
+	}
+
+	return session.ErrForbidden
+}
+
+func validateUsername(repo models.UserReader, username string, id int) error {
+	if username == "" {
+		return errors.New("username must not be blank")
+	}
+
+	if username == config.GetInstance().GetUsername() {
+		return fmt.Errorf("username %q is used by the configured credentials", username)
+	}
+
+	existing, err := repo.FindByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.ID != id {
+		return fmt.Errorf("user with name %q already exists", username)
+	}
+
+	return nil
+}
+
+func (r *mutationResolver) UserCreate(ctx context.Context, input models.UserCreateInput) (ret *models.User, err error) {
+	// users are only authenticated when credentials are configured
+	if !config.GetInstance().HasCredentials() {
+		return nil, errors.New("username and password must be configured before adding users")
+	}
+
+	username := strings.TrimSpace(input.Username)
+	if input.Password == "" {
+		return nil, errors.New("password must not be blank")
+	}
+	if !input.Role.IsValid() {
+		return nil, fmt.Errorf("invalid role %q", input.Role)
+	}
+
+	hash, err := session.HashPassword(input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime := time.Now()
+	newUser := models.User{
+		Username:  username,
+		Password:  hash,
+		Role:      input.Role,
+		CreatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+		UpdatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+	}
+
+	if input.RestrictionProfileID != nil {
+		profileID, err := strconv.ParseInt(*input.RestrictionProfileID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		newUser.RestrictionProfileID = sql.NullInt64{Int64: profileID, Valid: true}
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.User()
+		if err := validateUsername(qb, username, 0); err != nil {
+			return err
+		}
+
+		ret, err = qb.Create(newUser)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) UserUpdate(ctx context.Context, input models.UserUpdateInput) (ret *models.User, err error) {
+	id, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUserAccess(ctx, id); err != nil {
+		return nil, err
+	}
+
+	translator := changesetTranslator{
+		inputMap: getUpdateInputMap(ctx),
+	}
+	restrictionProfileID := translator.nullInt64FromString(input.RestrictionProfileID, "restriction_profile_id")
+
+	// only admins may change usernames, roles and restrictions
+	if (input.Username != nil || input.Role != nil || restrictionProfileID != nil) && !session.HasRole(ctx, models.UserRoleAdmin) {
+		return nil, session.ErrForbidden
+	}
+
+	if input.Role != nil && !input.Role.IsValid() {
+		return nil, fmt.Errorf("invalid role %q", *input.Role)
+	}
+
+	var hash string
+	if input.Password != nil {
+		if *input.Password == "" {
+			return nil, errors.New("password must not be blank")
+		}
+
+		hash, err = session.HashPassword(*input.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.User()
+		user, err := qb.Find(id)
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return fmt.Errorf("user with id %d not found", id)
+		}
+
+		if input.Username != nil {
+			username := strings.TrimSpace(*input.Username)
+			if err := validateUsername(qb, username, id); err != nil {
+				return err
+			}
+			user.Username = username
+		}
+
+		if input.Role != nil {
+			user.Role = *input.Role
+		}
+
+		if restrictionProfileID != nil {
+			user.RestrictionProfileID = *restrictionProfileID
+		}
+
+		if hash != "" {
+			user.Password = hash
+		}
+
+		user.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+
+		ret, err = qb.Update(*user)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *mutationResolver) UserDestroy(ctx context.Context, id string) (bool, error) {
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		return repo.User().Destroy(userID)
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) UserGenerateAPIKey(ctx context.Context, input models.UserGenerateAPIKeyInput) (string, error) {
+	id, err := strconv.Atoi(input.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkUserAccess(ctx, id); err != nil {
+		return "", err
+	}
+
+	var newAPIKey string
+	if err := r.withTxn(ctx, func(repo models.Repository) error {
+		qb := repo.User()
+		user, err := qb.Find(id)
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			return fmt.Errorf("user with id %d not found", id)
+		}
+
+		user.APIKey = sql.NullString{}
+		if input.Clear == nil || !*input.Clear {
+			newAPIKey, err = manager.GenerateAPIKey(user.Username)
+			if err != nil {
+				return err
+			}
+
+			user.APIKey = sql.NullString{String: newAPIKey, Valid: true}
+		}
+
+		user.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+
+		_, err = qb.Update(*user)
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	return newAPIKey, nil
+}
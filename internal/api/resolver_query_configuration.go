@@ -70,12 +70,32 @@ func makeConfigResult() *models.ConfigResult {
 	}
 }
 
+func makeJobLaneConcurrencyResult() []*models.JobLaneConcurrency {
+	concurrency := config.GetInstance().GetJobLaneConcurrency()
+
+	var ret []*models.JobLaneConcurrency
+	for _, lane := range models.AllJobLane {
+		n := concurrency[strings.ToLower(lane.String())]
+		if n < 1 {
+			n = 1
+		}
+
+		ret = append(ret, &models.JobLaneConcurrency{
+			Lane:        lane,
+			Concurrency: n,
+		})
+	}
+
+	return ret
+}
+
 func makeConfigGeneralResult() *models.ConfigGeneralResult {
 	config := config.GetInstance()
 	logFile := config.GetLogFile()
 
 	maxTranscodeSize := config.GetMaxTranscodeSize()
 	maxStreamingTranscodeSize := config.GetMaxStreamingTranscodeSize()
+	generatedTranscodeProfile := config.GetGeneratedTranscodeProfile()
 
 	customPerformerImageLocation := config.GetCustomPerformerImageLocation()
 
@@ -93,6 +113,7 @@ func makeConfigGeneralResult() *models.ConfigGeneralResult {
 		CalculateMd5:                 config.IsCalculateMD5(),
 		VideoFileNamingAlgorithm:     config.GetVideoFileNamingAlgorithm(),
 		ParallelTasks:                config.GetParallelTasks(),
+		JobLaneConcurrency:           makeJobLaneConcurrencyResult(),
 		PreviewAudio:                 config.GetPreviewAudio(),
 		PreviewSegments:              config.GetPreviewSegments(),
 		PreviewSegmentDuration:       config.GetPreviewSegmentDuration(),
@@ -101,6 +122,8 @@ func makeConfigGeneralResult() *models.ConfigGeneralResult {
 		PreviewPreset:                config.GetPreviewPreset(),
 		MaxTranscodeSize:             &maxTranscodeSize,
 		MaxStreamingTranscodeSize:    &maxStreamingTranscodeSize,
+		TranscodeProfiles:            config.GetTranscodeProfiles(),
+		GeneratedTranscodeProfile:    &generatedTranscodeProfile,
 		WriteImageThumbnails:         config.IsWriteImageThumbnails(),
 		APIKey:                       config.GetAPIKey(),
 		Username:                     config.GetUsername(),
@@ -116,6 +139,8 @@ func makeConfigGeneralResult() *models.ConfigGeneralResult {
 		CreateGalleriesFromFolders:   config.GetCreateGalleriesFromFolders(),
 		Excludes:                     config.GetExcludes(),
 		ImageExcludes:                config.GetImageExcludes(),
+		WatchStashPaths:              config.GetWatchStashPaths(),
+		WatchDebounce:                config.GetWatchDebounce(),
 		CustomPerformerImageLocation: &customPerformerImageLocation,
 		ScraperUserAgent:             &scraperUserAgent,
 		ScraperCertCheck:             config.GetScraperCertCheck(),
@@ -181,10 +206,11 @@ func makeConfigDLNAResult() *models.ConfigDLNAResult {
 	config := config.GetInstance()
 
 	return &models.ConfigDLNAResult{
-		ServerName:     config.GetDLNAServerName(),
-		Enabled:        config.GetDLNADefaultEnabled(),
-		WhitelistedIPs: config.GetDLNADefaultIPWhitelist(),
-		Interfaces:     config.GetDLNAInterfaces(),
+		ServerName:           config.GetDLNAServerName(),
+		Enabled:              config.GetDLNADefaultEnabled(),
+		WhitelistedIPs:       config.GetDLNADefaultIPWhitelist(),
+		Interfaces:           config.GetDLNAInterfaces(),
+		RestrictionProfileID: config.GetDLNARestrictionProfileID(),
 	}
 }
 
@@ -199,6 +225,8 @@ func makeConfigScrapingResult() *models.ConfigScrapingResult {
 		ScraperCertCheck:   config.GetScraperCertCheck(),
 		ScraperCDPPath:     &scraperCDPPath,
 		ExcludeTagPatterns: config.GetScraperExcludeTagPatterns(),
+		ScraperCacheTTL:    config.GetScraperCacheTTL(),
+		ScraperCredentials: config.GetScraperCredentials(),
 	}
 }
 
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"reflect"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// applyFilterQuery sets the criteria of the filter query in the filter
+// pointed to by filter, creating the filter if it is nil. The free text of
+// the query is added to the q value of the find filter.
+func applyFilterQuery(repo models.ReaderRepository, query *string, filter interface{}, findFilter **models.FindFilterType) error {
+	if query == nil {
+		return nil
+	}
+
+	q, err := models.ParseFilterQuery(*query)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(filter).Elem()
+	if v.IsNil() {
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+
+	if err := q.Apply(v.Interface(), models.NewFilterQueryResolver(repo)); err != nil {
+		return err
+	}
+
+	if q.Text == "" {
+		return nil
+	}
+
+	ret := models.FindFilterType{}
+	if *findFilter != nil {
+		ret = **findFilter
+	}
+
+	text := q.Text
+	if ret.Q != nil && *ret.Q != "" {
+		text = *ret.Q + " " + text
+	}
+	ret.Q = &text
+	*findFilter = &ret
+
+	return nil
+}
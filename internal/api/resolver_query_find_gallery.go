@@ -23,8 +23,12 @@ func (r *queryResolver) FindGallery(ctx context.Context, id string) (ret *models
 	return ret, nil
 }
 
-func (r *queryResolver) FindGalleries(ctx context.Context, galleryFilter *models.GalleryFilterType, filter *models.FindFilterType) (ret *models.FindGalleriesResultType, err error) {
+func (r *queryResolver) FindGalleries(ctx context.Context, galleryFilter *models.GalleryFilterType, filter *models.FindFilterType, query *string) (ret *models.FindGalleriesResultType, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		if err := applyFilterQuery(repo, query, &galleryFilter, &filter); err != nil {
+			return err
+		}
+
 		galleries, total, err := repo.Gallery().Query(galleryFilter, filter)
 		if err != nil {
 			return err
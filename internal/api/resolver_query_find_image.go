@@ -38,8 +38,12 @@ func (r *queryResolver) FindImage(ctx context.Context, id *string, checksum *str
 	return image, nil
 }
 
-func (r *queryResolver) FindImages(ctx context.Context, imageFilter *models.ImageFilterType, imageIds []int, filter *models.FindFilterType) (ret *models.FindImagesResultType, err error) {
+func (r *queryResolver) FindImages(ctx context.Context, imageFilter *models.ImageFilterType, imageIds []int, filter *models.FindFilterType, query *string) (ret *models.FindImagesResultType, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		if err := applyFilterQuery(repo, query, &imageFilter, &filter); err != nil {
+			return err
+		}
+
 		qb := repo.Image()
 
 		fields := graphql.CollectAllFields(ctx)
@@ -76,3 +80,21 @@ func (r *queryResolver) FindImages(ctx context.Context, imageFilter *models.Imag
 
 	return ret, nil
 }
+
+func (r *queryResolver) FindDuplicateImages(ctx context.Context, distance *int, filter *models.FindFilterType) (ret [][]*models.Image, err error) {
+	options := models.DuplicateOptions{
+		DurationDiff: -1,
+		FindFilter:   filter,
+	}
+	if distance != nil {
+		options.Distance = *distance
+	}
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Image().FindDuplicates(options)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
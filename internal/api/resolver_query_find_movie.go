@@ -23,8 +23,12 @@ func (r *queryResolver) FindMovie(ctx context.Context, id string) (ret *models.M
 	return ret, nil
 }
 
-func (r *queryResolver) FindMovies(ctx context.Context, movieFilter *models.MovieFilterType, filter *models.FindFilterType) (ret *models.FindMoviesResultType, err error) {
+func (r *queryResolver) FindMovies(ctx context.Context, movieFilter *models.MovieFilterType, filter *models.FindFilterType, query *string) (ret *models.FindMoviesResultType, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		if err := applyFilterQuery(repo, query, &movieFilter, &filter); err != nil {
+			return err
+		}
+
 		movies, total, err := repo.Movie().Query(movieFilter, filter)
 		if err != nil {
 			return err
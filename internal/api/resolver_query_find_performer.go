@@ -23,8 +23,12 @@ func (r *queryResolver) FindPerformer(ctx context.Context, id string) (ret *mode
 	return ret, nil
 }
 
-func (r *queryResolver) FindPerformers(ctx context.Context, performerFilter *models.PerformerFilterType, filter *models.FindFilterType) (ret *models.FindPerformersResultType, err error) {
+func (r *queryResolver) FindPerformers(ctx context.Context, performerFilter *models.PerformerFilterType, filter *models.FindFilterType, query *string) (ret *models.FindPerformersResultType, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		if err := applyFilterQuery(repo, query, &performerFilter, &filter); err != nil {
+			return err
+		}
+
 		performers, total, err := repo.Performer().Query(performerFilter, filter)
 		if err != nil {
 			return err
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *queryResolver) FindRestrictionProfile(ctx context.Context, id string) (ret *models.RestrictionProfile, err error) {
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.RestrictionProfile().Find(idInt)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) FindRestrictionProfiles(ctx context.Context) (ret []*models.RestrictionProfile, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.RestrictionProfile().All()
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -64,8 +64,12 @@ func (r *queryResolver) FindSceneByHash(ctx context.Context, input models.SceneH
 	return scene, nil
 }
 
-func (r *queryResolver) FindScenes(ctx context.Context, sceneFilter *models.SceneFilterType, sceneIDs []int, filter *models.FindFilterType) (ret *models.FindScenesResultType, err error) {
+func (r *queryResolver) FindScenes(ctx context.Context, sceneFilter *models.SceneFilterType, sceneIDs []int, filter *models.FindFilterType, query *string) (ret *models.FindScenesResultType, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		if err := applyFilterQuery(repo, query, &sceneFilter, &filter); err != nil {
+			return err
+		}
+
 		var scenes []*models.Scene
 		var err error
 
@@ -194,13 +198,19 @@ func (r *queryResolver) ParseSceneFilenames(ctx context.Context, filter *models.
 	return ret, nil
 }
 
-func (r *queryResolver) FindDuplicateScenes(ctx context.Context, distance *int) (ret [][]*models.Scene, err error) {
-	dist := 0
+func (r *queryResolver) FindDuplicateScenes(ctx context.Context, distance *int, durationDiff *float64, filter *models.FindFilterType) (ret [][]*models.Scene, err error) {
+	options := models.DuplicateOptions{
+		DurationDiff: -1,
+		FindFilter:   filter,
+	}
 	if distance != nil {
-		dist = *distance
+		options.Distance = *distance
+	}
+	if durationDiff != nil {
+		options.DurationDiff = *durationDiff
 	}
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
-		ret, err = repo.Scene().FindDuplicates(dist)
+		ret, err = repo.Scene().FindDuplicates(options)
 		return err
 	}); err != nil {
 		return nil, err
@@ -6,8 +6,12 @@ import (
 	"github.com/stashapp/stash/pkg/models"
 )
 
-func (r *queryResolver) FindSceneMarkers(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, filter *models.FindFilterType) (ret *models.FindSceneMarkersResultType, err error) {
+func (r *queryResolver) FindSceneMarkers(ctx context.Context, sceneMarkerFilter *models.SceneMarkerFilterType, filter *models.FindFilterType, query *string) (ret *models.FindSceneMarkersResultType, err error) {
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		if err := applyFilterQuery(repo, query, &sceneMarkerFilter, &filter); err != nil {
+			return err
+		}
+
 		sceneMarkers, total, err := repo.SceneMarker().Query(sceneMarkerFilter, filter)
 		if err != nil {
 			return err
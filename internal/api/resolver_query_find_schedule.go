@@ -0,0 +1,18 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func (r *queryResolver) FindSchedules(ctx context.Context) (ret []*models.Schedule, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.Schedule().All()
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
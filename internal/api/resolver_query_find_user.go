@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+)
+
+func (r *queryResolver) CurrentUser(ctx context.Context) (ret *models.User, err error) {
+	current := session.GetCurrentUser(ctx)
+	if current == nil {
+		return nil, nil
+	}
+
+	// return the latest values
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.User().Find(current.ID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *queryResolver) FindUsers(ctx context.Context) (ret []*models.User, err error) {
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		ret, err = repo.User().All()
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
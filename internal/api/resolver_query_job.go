@@ -26,27 +26,93 @@ func (r *queryResolver) FindJob(ctx context.Context, input models.FindJobInput)
 		return nil, err
 	}
 	j := manager.GetInstance().JobManager.GetJob(jobID)
-	if j == nil {
+	if j != nil {
+		return jobToJobModel(*j), nil
+	}
+
+	// fall back to the job history
+	var record *models.JobRecord
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		record, err = repo.JobRecord().Find(jobID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if record == nil {
 		return nil, nil
 	}
 
-	return jobToJobModel(*j), nil
+	return jobToJobModel(manager.JobFromRecord(record)), nil
+}
+
+func (r *queryResolver) FindJobs(ctx context.Context, filter *models.FindFilterType, status []models.JobStatus) (*models.FindJobsResultType, error) {
+	var records []*models.JobRecord
+	var count int
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		var err error
+		records, count, err = repo.JobRecord().Query(status, filter)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	ret := &models.FindJobsResultType{
+		Count: count,
+		Jobs:  []*models.Job{},
+	}
+	for _, record := range records {
+		ret.Jobs = append(ret.Jobs, jobToJobModel(manager.JobFromRecord(record)))
+	}
+
+	return ret, nil
 }
 
 func jobToJobModel(j job.Job) *models.Job {
 	ret := &models.Job{
 		ID:          strconv.Itoa(j.ID),
 		Status:      models.JobStatus(j.Status),
+		Lane:        models.JobLane(j.Lane),
+		Priority:    j.Priority,
 		Description: j.Description,
 		SubTasks:    j.Details,
 		StartTime:   j.StartTime,
 		EndTime:     j.EndTime,
 		AddTime:     j.AddTime,
+		Error:       j.Error,
+		Requeueable: j.IsRequeueable(),
+		Issues:      []*models.JobIssue{},
+		IssueCount:  j.IssueCount,
 	}
 
 	if j.Progress != -1 {
 		ret.Progress = &j.Progress
 	}
 
+	for _, i := range j.Issues {
+		ret.Issues = append(ret.Issues, jobIssueToModel(i))
+	}
+
+	return ret
+}
+
+func jobIssueToModel(i job.Issue) *models.JobIssue {
+	ret := &models.JobIssue{
+		Level:   models.JobIssueLevel(i.Level),
+		Time:    i.Time,
+		Message: i.Message,
+	}
+
+	if i.Stage != "" {
+		ret.Stage = &i.Stage
+	}
+	if i.SceneID != 0 {
+		sceneID := strconv.Itoa(i.SceneID)
+		ret.SceneID = &sceneID
+	}
+	if i.Path != "" {
+		ret.Path = &i.Path
+	}
+
 	return ret
 }
@@ -5,13 +5,10 @@ import (
 	"errors"
 	"strconv"
 
-	"github.com/stashapp/stash/internal/api/urlbuilders"
-	"github.com/stashapp/stash/internal/manager"
-	"github.com/stashapp/stash/internal/manager/config"
 	"github.com/stashapp/stash/pkg/models"
 )
 
-func (r *queryResolver) SceneStreams(ctx context.Context, id *string) ([]*models.SceneStreamEndpoint, error) {
+func (r *queryResolver) SceneStreams(ctx context.Context, id *string, fileID *string) ([]*models.SceneStreamEndpoint, error) {
 	// find the scene
 	var scene *models.Scene
 	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
@@ -27,8 +24,5 @@ func (r *queryResolver) SceneStreams(ctx context.Context, id *string) ([]*models
 		return nil, errors.New("nil scene")
 	}
 
-	baseURL, _ := ctx.Value(BaseURLCtxKey).(string)
-	builder := urlbuilders.NewSceneURLBuilder(baseURL, scene.ID)
-
-	return manager.GetSceneStreamPaths(scene, builder.GetStreamURL(), config.GetInstance().GetMaxStreamingTranscodeSize())
+	return r.getSceneStreamPaths(ctx, scene, fileID)
 }
@@ -218,6 +218,15 @@ func (r *queryResolver) ScrapeGalleryURL(ctx context.Context, url string) (*mode
 	return marshalScrapedGallery(content)
 }
 
+func (r *queryResolver) ScrapeImageURL(ctx context.Context, url string) (*models.ScrapedImage, error) {
+	content, err := r.scraperCache().ScrapeURL(ctx, url, models.ScrapeContentTypeImage)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalScrapedImage(content)
+}
+
 func (r *queryResolver) ScrapeMovieURL(ctx context.Context, url string) (*models.ScrapedMovie, error) {
 	content, err := r.scraperCache().ScrapeURL(ctx, url, models.ScrapeContentTypeMovie)
 	if err != nil {
@@ -426,6 +435,37 @@ func (r *queryResolver) ScrapeSingleGallery(ctx context.Context, source models.S
 	}
 }
 
+func (r *queryResolver) ScrapeSingleImage(ctx context.Context, source models.ScraperSourceInput, input models.ScrapeSingleImageInput) ([]*models.ScrapedImage, error) {
+	if source.StashBoxIndex != nil || source.StashBoxEndpoint != nil {
+		return nil, ErrNotSupported
+	}
+
+	if source.ScraperID == nil {
+		return nil, fmt.Errorf("%w: scraper_id must be set", ErrInput)
+	}
+
+	switch {
+	case input.ImageID != nil:
+		imageID, err := strconv.Atoi(*input.ImageID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: image id is not an integer: '%s'", ErrInput, *input.ImageID)
+		}
+		c, err := r.scraperCache().ScrapeID(ctx, *source.ScraperID, imageID, models.ScrapeContentTypeImage)
+		if err != nil {
+			return nil, err
+		}
+		return marshalScrapedImages([]models.ScrapedContent{c})
+	case input.ImageInput != nil:
+		c, err := r.scraperCache().ScrapeFragment(ctx, *source.ScraperID, scraper.Input{Image: input.ImageInput})
+		if err != nil {
+			return nil, err
+		}
+		return marshalScrapedImages([]models.ScrapedContent{c})
+	default:
+		return nil, ErrNotImplemented
+	}
+}
+
 func (r *queryResolver) ScrapeSingleMovie(ctx context.Context, source models.ScraperSourceInput, input models.ScrapeSingleMovieInput) ([]*models.ScrapedMovie, error) {
 	return nil, ErrNotSupported
 }
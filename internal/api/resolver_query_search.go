@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const defaultSearchLimit = 25
+
+type searchResultKey struct {
+	resultType models.SearchResultType
+	id         int
+}
+
+func (r *queryResolver) Search(ctx context.Context, q string, types []models.SearchResultType, limit *int) (ret []models.SearchResult, err error) {
+	searchLimit := defaultSearchLimit
+	if limit != nil {
+		searchLimit = *limit
+	}
+
+	if err := r.withReadTxn(ctx, func(repo models.ReaderRepository) error {
+		matches, err := repo.Search().Search(q, types, searchLimit)
+		if err != nil {
+			return err
+		}
+
+		ids := make(map[models.SearchResultType][]int)
+		for _, m := range matches {
+			ids[m.Type] = append(ids[m.Type], m.ID)
+		}
+
+		results, err := findSearchResults(repo, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range matches {
+			// objects may be hidden by the content restriction
+			if result, found := results[searchResultKey{m.Type, m.ID}]; found {
+				ret = append(ret, result)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// findSearchResults returns the objects with the provided ids of each
+// search result type.
+func findSearchResults(repo models.ReaderRepository, ids map[models.SearchResultType][]int) (map[searchResultKey]models.SearchResult, error) {
+	ret := make(map[searchResultKey]models.SearchResult)
+	add := func(t models.SearchResultType, id int, result models.SearchResult) {
+		ret[searchResultKey{t, id}] = result
+	}
+
+	for t, typeIDs := range ids {
+		switch t {
+		case models.SearchResultTypeScene:
+			found, err := repo.Scene().FindMany(typeIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range found {
+				add(t, o.ID, o)
+			}
+		case models.SearchResultTypeSceneMarker:
+			found, err := repo.SceneMarker().FindMany(typeIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range found {
+				add(t, o.ID, o)
+			}
+		case models.SearchResultTypeImage:
+			found, err := repo.Image().FindMany(typeIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range found {
+				add(t, o.ID, o)
+			}
+		case models.SearchResultTypeGallery:
+			found, err := repo.Gallery().FindMany(typeIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range found {
+				add(t, o.ID, o)
+			}
+		case models.SearchResultTypePerformer:
+			found, err := repo.Performer().FindMany(typeIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range found {
+				add(t, o.ID, o)
+			}
+		case models.SearchResultTypeStudio:
+			found, err := repo.Studio().FindMany(typeIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range found {
+				add(t, o.ID, o)
+			}
+		case models.SearchResultTypeTag:
+			found, err := repo.Tag().FindMany(typeIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range found {
+				add(t, o.ID, o)
+			}
+		case models.SearchResultTypeMovie:
+			found, err := repo.Movie().FindMany(typeIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range found {
+				add(t, o.ID, o)
+			}
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stashapp/stash/internal/manager"
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+)
+
+type jobRoutes struct{}
+
+func (rs jobRoutes) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/{jobId}", func(r chi.Router) {
+		r.Use(JobCtx)
+		r.Get("/report", rs.Report)
+	})
+
+	return r
+}
+
+// Report writes the issues of the job as a CSV file, or as JSON if the
+// format query parameter is json.
+func (rs jobRoutes) Report(w http.ResponseWriter, r *http.Request) {
+	j := r.Context().Value(jobKey).(*job.Job)
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"job-%d-report.json\"", j.ID))
+		if err := json.NewEncoder(w).Encode(j.Issues); err != nil {
+			logger.Warnf("error writing job report: %v", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"job-%d-report.csv\"", j.ID))
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"level", "time", "stage", "scene_id", "path", "message"})
+	for _, i := range j.Issues {
+		sceneID := ""
+		if i.SceneID != 0 {
+			sceneID = strconv.Itoa(i.SceneID)
+		}
+
+		_ = cw.Write([]string{
+			string(i.Level),
+			i.Time.Format(time.RFC3339),
+			i.Stage,
+			sceneID,
+			i.Path,
+			i.Message,
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Warnf("error writing job report: %v", err)
+	}
+}
+
+func JobCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jobID, err := strconv.Atoi(chi.URLParam(r, "jobId"))
+		if err != nil {
+			http.Error(w, http.StatusText(404), 404)
+			return
+		}
+
+		j, err := manager.GetInstance().FindJob(r.Context(), jobID)
+		if err != nil || j == nil {
+			http.Error(w, http.StatusText(404), 404)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), jobKey, j)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
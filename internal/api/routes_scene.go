@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -29,12 +30,13 @@ func (rs sceneRoutes) Routes() chi.Router {
 		r.Use(SceneCtx)
 
 		// streaming endpoints
-		r.Get("/stream", rs.StreamDirect)
-		r.Get("/stream.mkv", rs.StreamMKV)
-		r.Get("/stream.webm", rs.StreamWebM)
-		r.Get("/stream.m3u8", rs.StreamHLS)
-		r.Get("/stream.ts", rs.StreamTS)
-		r.Get("/stream.mp4", rs.StreamMp4)
+		rs.streamRoutes(r)
+
+		// streaming endpoints for the individual files of the scene
+		r.Route("/file/{fileId}", func(r chi.Router) {
+			r.Use(SceneFileCtx)
+			rs.streamRoutes(r)
+		})
 
 		r.Get("/screenshot", rs.Screenshot)
 		r.Get("/preview", rs.Preview)
@@ -54,6 +56,20 @@ func (rs sceneRoutes) Routes() chi.Router {
 	return r
 }
 
+func (rs sceneRoutes) streamRoutes(r chi.Router) {
+	r.Get("/stream", rs.StreamDirect)
+	r.Get("/stream.mkv", rs.StreamMKV)
+	r.Get("/stream.webm", rs.StreamWebM)
+	r.Get("/stream.m3u8", rs.StreamHLS)
+	r.Get("/stream.ts", rs.StreamTS)
+	r.Get("/hls/{resolution}/index.m3u8", rs.StreamHLSMedia)
+	r.Get("/hls/{resolution}/{segment:[0-9]+}.ts", rs.StreamHLSSegment)
+	r.Get("/stream.mpd", rs.StreamDASH)
+	r.Get("/dash/{representation}/init.mp4", rs.StreamDASHInit)
+	r.Get("/dash/{representation}/{segment:[0-9]+}.m4s", rs.StreamDASHSegment)
+	r.Get("/stream.mp4", rs.StreamMp4)
+}
+
 // region Handlers
 
 func (rs sceneRoutes) StreamDirect(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +82,12 @@ func (rs sceneRoutes) StreamDirect(w http.ResponseWriter, r *http.Request) {
 }
 
 func (rs sceneRoutes) StreamMKV(w http.ResponseWriter, r *http.Request) {
+	// transcode profiles set their own codecs
+	if r.URL.Query().Get("profile") != "" {
+		rs.streamTranscode(w, r, ffmpeg.StreamFormatMKVAudio)
+		return
+	}
+
 	// only allow mkv streaming if the scene container is an mkv already
 	scene := r.Context().Value(sceneKey).(*models.Scene)
 
@@ -93,31 +115,223 @@ func (rs sceneRoutes) StreamMp4(w http.ResponseWriter, r *http.Request) {
 	rs.streamTranscode(w, r, ffmpeg.StreamFormatH264)
 }
 
+// StreamHLS serves the HLS master playlist, which lists a rendition for each
+// resolution the scene may be streamed at.
 func (rs sceneRoutes) StreamHLS(w http.ResponseWriter, r *http.Request) {
 	scene := r.Context().Value(sceneKey).(*models.Scene)
 
-	ffprobe := manager.GetInstance().FFProbe
-	videoFile, err := ffprobe.NewVideoFile(scene.Path)
+	logger.Debug("Returning HLS master playlist")
+
+	maxStreamingTranscodeSize := config.GetInstance().GetMaxStreamingTranscodeSize()
+
+	var str strings.Builder
+	manager.WriteHLSMasterPlaylist(scene, maxStreamingTranscodeSize, func(res models.StreamingResolutionEnum) string {
+		return withRawQuery("hls/"+res.String()+"/index.m3u8", r)
+	}, &str)
+
+	rs.serveHLSPlaylist(w, r, str.String())
+}
+
+// getHLSResolution returns the resolution of the HLS rendition requested, or
+// false if the rendition is not offered for the scene.
+func getHLSResolution(r *http.Request, scene *models.Scene) (models.StreamingResolutionEnum, bool) {
+	res := models.StreamingResolutionEnum(chi.URLParam(r, "resolution"))
+
+	maxStreamingTranscodeSize := config.GetInstance().GetMaxStreamingTranscodeSize()
+	for _, v := range manager.GetHLSResolutions(scene, maxStreamingTranscodeSize) {
+		if v == res {
+			return res, true
+		}
+	}
+
+	return res, false
+}
+
+// StreamHLSMedia serves the HLS media playlist of a single rendition.
+func (rs sceneRoutes) StreamHLSMedia(w http.ResponseWriter, r *http.Request) {
+	scene := r.Context().Value(sceneKey).(*models.Scene)
+
+	if _, ok := getHLSResolution(r, scene); !ok {
+		http.Error(w, "invalid resolution", http.StatusNotFound)
+		return
+	}
+
+	duration, err := getSceneDuration(scene)
 	if err != nil {
 		logger.Errorf("[stream] error reading video file: %v", err)
 		return
 	}
 
-	logger.Debug("Returning HLS playlist")
+	logger.Debug("Returning HLS media playlist")
 
-	// getting the playlist manifest only
-	w.Header().Set("Content-Type", ffmpeg.MimeHLS)
 	var str strings.Builder
+	ffmpeg.WriteHLSMediaPlaylist(duration, func(index int) string {
+		return withRawQuery(strconv.Itoa(index)+".ts", r)
+	}, &str)
+
+	rs.serveHLSPlaylist(w, r, str.String())
+}
+
+// StreamHLSSegment serves a single segment of a HLS rendition, waiting for it
+// to be transcoded if necessary.
+func (rs sceneRoutes) StreamHLSSegment(w http.ResponseWriter, r *http.Request) {
+	scene := r.Context().Value(sceneKey).(*models.Scene)
+
+	res, ok := getHLSResolution(r, scene)
+	if !ok {
+		http.Error(w, "invalid resolution", http.StatusNotFound)
+		return
+	}
+
+	index, err := strconv.Atoi(chi.URLParam(r, "segment"))
+	if err != nil {
+		http.Error(w, "invalid segment", http.StatusBadRequest)
+		return
+	}
+
+	segmentPath, err := manager.GetInstance().HLSStreamManager.GetSegment(r.Context(), scene, res, index)
+	rs.serveSegment(w, r, segmentPath, ffmpeg.MimeMpegts, err)
+}
+
+// StreamDASH serves the DASH manifest, which lists a video representation for
+// each resolution the scene may be streamed at, and an audio representation.
+func (rs sceneRoutes) StreamDASH(w http.ResponseWriter, r *http.Request) {
+	scene := r.Context().Value(sceneKey).(*models.Scene)
+
+	duration, err := getSceneDuration(scene)
+	if err != nil {
+		logger.Errorf("[stream] error reading video file: %v", err)
+		return
+	}
+
+	logger.Debug("Returning DASH manifest")
+
+	maxStreamingTranscodeSize := config.GetInstance().GetMaxStreamingTranscodeSize()
+	initURL := withRawQuery("dash/$RepresentationID$/init.mp4", r)
+	mediaURL := withRawQuery("dash/$RepresentationID$/$Number$.m4s", r)
+
+	var buf bytes.Buffer
+	if err := manager.WriteDASHManifest(scene, duration, maxStreamingTranscodeSize, initURL, mediaURL, &buf); err != nil {
+		logger.Errorf("[stream] error writing DASH manifest: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ffmpeg.MimeDASH)
+	if n, err := w.Write(buf.Bytes()); err != nil {
+		logger.Warnf("[stream] error writing stream (wrote %v bytes): %v", n, err)
+	}
+}
+
+// getDASHRepresentation returns the DASH representation requested, or false
+// if the representation is not offered for the scene.
+func getDASHRepresentation(r *http.Request, scene *models.Scene) (string, bool) {
+	representation := chi.URLParam(r, "representation")
+	if representation == manager.DASHAudioRepresentation {
+		return representation, true
+	}
 
-	ffmpeg.WriteHLSPlaylist(videoFile.Duration, r.URL.String(), &str)
+	maxStreamingTranscodeSize := config.GetInstance().GetMaxStreamingTranscodeSize()
+	for _, v := range manager.GetHLSResolutions(scene, maxStreamingTranscodeSize) {
+		if v.String() == representation {
+			return representation, true
+		}
+	}
+
+	return representation, false
+}
+
+func dashSegmentMimeType(representation string) string {
+	if representation == manager.DASHAudioRepresentation {
+		return ffmpeg.MimeMp4Audio
+	}
+
+	return ffmpeg.MimeMp4
+}
+
+// StreamDASHInit serves the initialization segment of a DASH representation.
+func (rs sceneRoutes) StreamDASHInit(w http.ResponseWriter, r *http.Request) {
+	scene := r.Context().Value(sceneKey).(*models.Scene)
+
+	representation, ok := getDASHRepresentation(r, scene)
+	if !ok {
+		http.Error(w, "invalid representation", http.StatusNotFound)
+		return
+	}
+
+	initPath, err := manager.GetInstance().HLSStreamManager.GetDASHInitSegment(r.Context(), scene, representation)
+	rs.serveSegment(w, r, initPath, dashSegmentMimeType(representation), err)
+}
+
+// StreamDASHSegment serves a single segment of a DASH representation, waiting
+// for it to be transcoded if necessary.
+func (rs sceneRoutes) StreamDASHSegment(w http.ResponseWriter, r *http.Request) {
+	scene := r.Context().Value(sceneKey).(*models.Scene)
+
+	representation, ok := getDASHRepresentation(r, scene)
+	if !ok {
+		http.Error(w, "invalid representation", http.StatusNotFound)
+		return
+	}
+
+	index, err := strconv.Atoi(chi.URLParam(r, "segment"))
+	if err != nil {
+		http.Error(w, "invalid segment", http.StatusBadRequest)
+		return
+	}
+
+	segmentPath, err := manager.GetInstance().HLSStreamManager.GetDASHSegment(r.Context(), scene, representation, index)
+	rs.serveSegment(w, r, segmentPath, dashSegmentMimeType(representation), err)
+}
+
+func (rs sceneRoutes) serveSegment(w http.ResponseWriter, r *http.Request, segmentPath string, mimeType string, err error) {
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			logger.Errorf("[stream] error getting segment: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", mimeType)
+	http.ServeFile(w, r, segmentPath)
+}
+
+// getSceneDuration returns the duration of the scene, reading it from the
+// file if it is not set.
+func getSceneDuration(scene *models.Scene) (float64, error) {
+	if scene.Duration.Valid {
+		return scene.Duration.Float64, nil
+	}
+
+	ffprobe := manager.GetInstance().FFProbe
+	videoFile, err := ffprobe.NewVideoFile(scene.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	return videoFile.Duration, nil
+}
+
+func withRawQuery(url string, r *http.Request) string {
+	if r.URL.RawQuery == "" {
+		return url
+	}
+
+	return url + "?" + r.URL.RawQuery
+}
+
+func (rs sceneRoutes) serveHLSPlaylist(w http.ResponseWriter, r *http.Request, playlist string) {
+	// getting the playlist manifest only
+	w.Header().Set("Content-Type", ffmpeg.MimeHLS)
 
 	requestByteRange := createByteRange(r.Header.Get("Range"))
 	if requestByteRange.RawString != "" {
 		logger.Debugf("Requested range: %s", requestByteRange.RawString)
 	}
 
-	ret := requestByteRange.apply([]byte(str.String()))
-	rangeStr := requestByteRange.toHeaderValue(int64(str.Len()))
+	ret := requestByteRange.apply([]byte(playlist))
+	rangeStr := requestByteRange.toHeaderValue(int64(len(playlist)))
 	w.Header().Set("Content-Range", rangeStr)
 
 	if n, err := w.Write(ret); err != nil {
@@ -141,6 +355,21 @@ func (rs sceneRoutes) streamTranscode(w http.ResponseWriter, r *http.Request, st
 	startTime := r.Form.Get("start")
 	ss, _ := strconv.ParseFloat(startTime, 64)
 	requestedSize := r.Form.Get("resolution")
+	maxTranscodeSize := config.GetInstance().GetMaxStreamingTranscodeSize().GetMaxResolution()
+
+	// a transcode profile overrides the codecs of the stream format
+	if profileName := r.Form.Get("profile"); profileName != "" {
+		profile := config.GetInstance().GetTranscodeProfile(profileName)
+		if profile == nil {
+			http.Error(w, "transcode profile not found", http.StatusBadRequest)
+			return
+		}
+
+		streamFormat = profile.StreamFormat()
+		if profile.MaxTranscodeSize != 0 {
+			maxTranscodeSize = profile.MaxTranscodeSize
+		}
+	}
 
 	audioCodec := ffmpeg.MissingUnsupported
 	if scene.AudioCodec.Valid {
@@ -156,13 +385,17 @@ func (rs sceneRoutes) streamTranscode(w http.ResponseWriter, r *http.Request, st
 		VideoHeight: int(scene.Height.Int64),
 
 		StartTime:        ss,
-		MaxTranscodeSize: config.GetInstance().GetMaxStreamingTranscodeSize().GetMaxResolution(),
+		MaxTranscodeSize: maxTranscodeSize,
 	}
 
 	if requestedSize != "" {
 		options.MaxTranscodeSize = models.StreamingResolutionEnum(requestedSize).GetMaxResolution()
 	}
 
+	rs.serveTranscodeStream(w, r, scene, options)
+}
+
+func (rs sceneRoutes) serveTranscodeStream(w http.ResponseWriter, r *http.Request, scene *models.Scene, options ffmpeg.TranscodeStreamOptions) {
 	encoder := manager.GetInstance().FFMPEG
 
 	lm := manager.GetInstance().ReadLockManager
@@ -262,8 +495,12 @@ func (rs sceneRoutes) ChapterVtt(w http.ResponseWriter, r *http.Request) {
 	vttLines := []string{"WEBVTT", ""}
 	for i, marker := range sceneMarkers {
 		vttLines = append(vttLines, strconv.Itoa(i+1))
-		time := utils.GetVTTTime(marker.Seconds)
-		vttLines = append(vttLines, time+" --> "+time)
+		startTime := utils.GetVTTTime(marker.Seconds)
+		endTime := startTime
+		if marker.EndSeconds.Valid {
+			endTime = utils.GetVTTTime(marker.EndSeconds.Float64)
+		}
+		vttLines = append(vttLines, startTime+" --> "+endTime)
 		vttLines = append(vttLines, rs.getChapterVttTitle(r.Context(), marker))
 		vttLines = append(vttLines, "")
 	}
@@ -360,9 +597,39 @@ func (rs sceneRoutes) SceneMarkerStream(w http.ResponseWriter, r *http.Request)
 	}
 
 	filepath := manager.GetInstance().Paths.SceneMarkers.GetVideoPreviewPath(scene.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm()), int(sceneMarker.Seconds))
+
+	// clip the marker range from the scene if the preview has not been generated
+	exists, _ := fsutil.FileExists(filepath)
+	if !exists && sceneMarker.EndSeconds.Valid {
+		rs.streamMarkerRange(w, r, scene, sceneMarker)
+		return
+	}
+
 	http.ServeFile(w, r, filepath)
 }
 
+func (rs sceneRoutes) streamMarkerRange(w http.ResponseWriter, r *http.Request, scene *models.Scene, sceneMarker *models.SceneMarker) {
+	audioCodec := ffmpeg.MissingUnsupported
+	if scene.AudioCodec.Valid {
+		audioCodec = ffmpeg.ProbeAudioCodec(scene.AudioCodec.String)
+	}
+
+	options := ffmpeg.TranscodeStreamOptions{
+		Input:     scene.Path,
+		Codec:     ffmpeg.StreamFormatH264,
+		VideoOnly: audioCodec == ffmpeg.MissingUnsupported,
+
+		VideoWidth:  int(scene.Width.Int64),
+		VideoHeight: int(scene.Height.Int64),
+
+		StartTime:        sceneMarker.Seconds,
+		Duration:         sceneMarker.Duration(),
+		MaxTranscodeSize: config.GetInstance().GetMaxStreamingTranscodeSize().GetMaxResolution(),
+	}
+
+	rs.serveTranscodeStream(w, r, scene, options)
+}
+
 func (rs sceneRoutes) SceneMarkerPreview(w http.ResponseWriter, r *http.Request) {
 	scene := r.Context().Value(sceneKey).(*models.Scene)
 	sceneMarkerID, _ := strconv.Atoi(chi.URLParam(r, "sceneMarkerId"))
@@ -465,3 +732,37 @@ func SceneCtx(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// SceneFileCtx replaces the scene in the request context with a copy that
+// refers to the file given by the fileId URL parameter. It must be used after
+// SceneCtx.
+func SceneFileCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scene := r.Context().Value(sceneKey).(*models.Scene)
+		fileID, _ := strconv.Atoi(chi.URLParam(r, "fileId"))
+
+		var file *models.SceneFile
+		readTxnErr := manager.GetInstance().TxnManager.WithReadTxn(r.Context(), func(repo models.ReaderRepository) error {
+			var err error
+			file, err = repo.Scene().FindFile(fileID)
+			return err
+		})
+		if readTxnErr != nil {
+			// FindFile returns no error if the file does not exist
+			logger.Errorf("error finding scene file %d: %v", fileID, readTxnErr)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if file == nil || file.SceneID != scene.ID {
+			http.Error(w, http.StatusText(404), 404)
+			return
+		}
+
+		fileScene := *scene
+		fileScene.SetSceneFile(*file)
+
+		ctx := context.WithValue(r.Context(), sceneKey, &fileScene)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
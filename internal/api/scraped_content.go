@@ -75,6 +75,29 @@ func marshalScrapedGalleries(content []models.ScrapedContent) ([]*models.Scraped
 	return ret, nil
 }
 
+// marshalScrapedImages converts ScrapedContent into ScrapedImage. If
+// conversion fails, an error is returned.
+func marshalScrapedImages(content []models.ScrapedContent) ([]*models.ScrapedImage, error) {
+	var ret []*models.ScrapedImage
+	for _, c := range content {
+		if c == nil {
+			// graphql schema requires images to be non-nil
+			continue
+		}
+
+		switch i := c.(type) {
+		case *models.ScrapedImage:
+			ret = append(ret, i)
+		case models.ScrapedImage:
+			ret = append(ret, &i)
+		default:
+			return nil, fmt.Errorf("%w: cannot turn ScrapedContent into ScrapedImage", models.ErrConversion)
+		}
+	}
+
+	return ret, nil
+}
+
 // marshalScrapedMovies converts ScrapedContent into ScrapedMovie. If conversion
 // fails, an error is returned.
 func marshalScrapedMovies(content []models.ScrapedContent) ([]*models.ScrapedMovie, error) {
@@ -128,6 +151,16 @@ func marshalScrapedGallery(content models.ScrapedContent) (*models.ScrapedGaller
 	return g[0], nil
 }
 
+// marshalScrapedImage will marshal a single scraped image
+func marshalScrapedImage(content models.ScrapedContent) (*models.ScrapedImage, error) {
+	i, err := marshalScrapedImages([]models.ScrapedContent{content})
+	if err != nil {
+		return nil, err
+	}
+
+	return i[0], nil
+}
+
 // marshalScrapedMovie will marshal a single scraped movie
 func marshalScrapedMovie(content models.ScrapedContent) (*models.ScrapedMovie, error) {
 	m, err := marshalScrapedMovies([]models.ScrapedContent{content})
@@ -100,6 +100,7 @@ func Start() error {
 
 	gqlSrv.SetQueryCache(gqlLru.New(1000))
 	gqlSrv.Use(gqlExtension.Introspection{})
+	gqlSrv.AroundFields(authorizeField)
 
 	gqlHandlerFunc := func(w http.ResponseWriter, r *http.Request) {
 		gqlSrv.ServeHTTP(w, r)
@@ -110,7 +111,6 @@ func Start() error {
 	manager.GetInstance().PluginCache.RegisterGQLHandler(visitedPluginHandler(http.HandlerFunc(gqlHandlerFunc)))
 
 	r.HandleFunc("/graphql", gqlHandlerFunc)
-	r.HandleFunc("/playground", gqlPlayground.Handler("GraphQL playground", "/graphql"))
 
 	// session handlers
 	r.Post(loginEndPoint, handleLogin(loginUIBox))
@@ -118,25 +118,40 @@ func Start() error {
 
 	r.Get(loginEndPoint, getLoginHandler(loginUIBox))
 
-	r.Mount("/performer", performerRoutes{
-		txnManager: txnManager,
-	}.Routes())
-	r.Mount("/scene", sceneRoutes{
-		txnManager: txnManager,
-	}.Routes())
-	r.Mount("/image", imageRoutes{
-		txnManager: txnManager,
-	}.Routes())
-	r.Mount("/studio", studioRoutes{
-		txnManager: txnManager,
-	}.Routes())
-	r.Mount("/movie", movieRoutes{
-		txnManager: txnManager,
-	}.Routes())
-	r.Mount("/tag", tagRoutes{
-		txnManager: txnManager,
-	}.Routes())
-	r.Mount("/downloads", downloadsRoutes{}.Routes())
+	// content routes are available to all users. Content hidden by the
+	// restriction profile of the user is not found by the repositories.
+	r.Group(func(r chi.Router) {
+		r.Use(requireRole(models.UserRoleViewer))
+
+		r.Mount("/performer", performerRoutes{
+			txnManager: txnManager,
+		}.Routes())
+		r.Mount("/scene", sceneRoutes{
+			txnManager: txnManager,
+		}.Routes())
+		r.Mount("/image", imageRoutes{
+			txnManager: txnManager,
+		}.Routes())
+		r.Mount("/studio", studioRoutes{
+			txnManager: txnManager,
+		}.Routes())
+		r.Mount("/movie", movieRoutes{
+			txnManager: txnManager,
+		}.Routes())
+		r.Mount("/tag", tagRoutes{
+			txnManager: txnManager,
+		}.Routes())
+	})
+
+	// routes exposing system data, backups and exports are restricted to
+	// admins, in the same way as the corresponding graphql fields
+	r.Group(func(r chi.Router) {
+		r.Use(requireRole(models.UserRoleAdmin))
+
+		r.HandleFunc("/playground", gqlPlayground.Handler("GraphQL playground", "/graphql"))
+		r.Mount("/downloads", downloadsRoutes{}.Routes())
+		r.Mount("/job", jobRoutes{}.Routes())
+	})
 
 	r.HandleFunc("/css", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/css")
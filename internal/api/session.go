@@ -8,7 +8,6 @@ import (
 	"net/http"
 
 	"github.com/stashapp/stash/internal/manager"
-	"github.com/stashapp/stash/internal/manager/config"
 	"github.com/stashapp/stash/pkg/session"
 )
 
@@ -44,7 +43,13 @@ func redirectToLogin(loginUIBox embed.FS, w http.ResponseWriter, returnURL strin
 
 func getLoginHandler(loginUIBox embed.FS) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !config.GetInstance().HasCredentials() {
+		authRequired, err := authenticationRequired(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		if !authRequired {
 			http.Redirect(w, r, "/", http.StatusFound)
 			return
 		}
@@ -27,6 +27,14 @@ func (b SceneURLBuilder) GetStreamURL() string {
 	return fmt.Sprintf("%s/scene/%s/stream%s", b.BaseURL, b.SceneID, apiKeyParam)
 }
 
+func (b SceneURLBuilder) GetFileStreamURL(fileID int) string {
+	var apiKeyParam string
+	if b.APIKey != "" {
+		apiKeyParam = fmt.Sprintf("?apikey=%s", b.APIKey)
+	}
+	return fmt.Sprintf("%s/scene/%s/file/%d/stream%s", b.BaseURL, b.SceneID, fileID, apiKeyParam)
+}
+
 func (b SceneURLBuilder) GetStreamPreviewURL() string {
 	return b.BaseURL + "/scene/" + b.SceneID + "/preview"
 }
@@ -51,7 +51,10 @@ func runTests(m *testing.M) int {
 	f.Close()
 	databaseFile := f.Name()
 	if err := database.Initialize(databaseFile); err != nil {
-		panic(fmt.Sprintf("Could not initialize database: %s", err.Error()))
+		// usually caused by running the tests without the sqlite_fts5 tag
+		fmt.Fprintf(os.Stderr, "Could not initialize database: %s\n", err.Error())
+		os.Remove(databaseFile)
+		return 1
 	}
 
 	// defer close and delete the database
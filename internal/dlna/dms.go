@@ -252,6 +252,7 @@ type Server struct {
 	txnManager         models.TransactionManager
 	sceneServer        sceneServer
 	ipWhitelistManager *ipWhitelistManager
+	playRecorder       *playRecorder
 }
 
 // UPnP SOAP service.
@@ -571,6 +572,10 @@ func (me *Server) initMux(mux *http.ServeMux) {
 			return
 		}
 
+		if me.playRecorder != nil {
+			me.playRecorder.record(r, scene.ID)
+		}
+
 		me.sceneServer.StreamSceneDirect(scene, w, r)
 	})
 	mux.HandleFunc(rootDescPath, func(w http.ResponseWriter, r *http.Request) {
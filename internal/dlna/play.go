@@ -0,0 +1,90 @@
+package dlna
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// playRecordInterval is the minimum time between plays of the same scene
+// recorded for a client.
+const playRecordInterval = 5 * time.Minute
+
+// playRecorder records plays of scenes streamed to DLNA renderers. Renderers
+// make several requests for a resource while playing and seeking, so only
+// requests for the start of the file are counted, and repeated requests from
+// the same client within playRecordInterval are ignored.
+type playRecorder struct {
+	txnManager models.TransactionManager
+
+	mutex  sync.Mutex
+	recent map[string]time.Time
+}
+
+func newPlayRecorder(txnManager models.TransactionManager) *playRecorder {
+	return &playRecorder{
+		txnManager: txnManager,
+		recent:     make(map[string]time.Time),
+	}
+}
+
+func isStartOfFileRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+
+	rangeHeader := r.Header.Get("Range")
+	return rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+}
+
+// shouldRecord returns true if a play of the scene should be recorded for
+// the client, and marks the play as recorded.
+func (p *playRecorder) shouldRecord(client string, sceneID int) bool {
+	now := time.Now()
+	key := client + "/" + strconv.Itoa(sceneID)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// purge expired entries
+	for k, t := range p.recent {
+		if now.Sub(t) >= playRecordInterval {
+			delete(p.recent, k)
+		}
+	}
+
+	if _, found := p.recent[key]; found {
+		return false
+	}
+
+	p.recent[key] = now
+	return true
+}
+
+func (p *playRecorder) record(r *http.Request, sceneID int) {
+	if !isStartOfFileRequest(r) {
+		return
+	}
+
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+
+	if !p.shouldRecord(client, sceneID) {
+		return
+	}
+
+	if err := p.txnManager.WithTxn(r.Context(), func(repo models.Repository) error {
+		_, err := repo.Scene().AddPlay(sceneID, nil, time.Now())
+		return err
+	}); err != nil {
+		logger.Warnf("[dlna] error recording play of scene %d: %v", sceneID, err)
+	}
+}
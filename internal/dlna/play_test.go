@@ -0,0 +1,43 @@
+package dlna
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStartOfFileRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		rng    string
+		want   bool
+	}{
+		{"no range", http.MethodGet, "", true},
+		{"range from start", http.MethodGet, "bytes=0-", true},
+		{"partial range from start", http.MethodGet, "bytes=0-1023", true},
+		{"range from offset", http.MethodGet, "bytes=1024-", false},
+		{"head request", http.MethodHead, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest(tt.method, "/res?scene=1", nil)
+			if tt.rng != "" {
+				r.Header.Set("Range", tt.rng)
+			}
+
+			assert.Equal(t, tt.want, isStartOfFileRequest(r))
+		})
+	}
+}
+
+func TestPlayRecorderShouldRecord(t *testing.T) {
+	p := newPlayRecorder(nil)
+
+	assert.True(t, p.shouldRecord("client", 1))
+	assert.False(t, p.shouldRecord("client", 1))
+	assert.True(t, p.shouldRecord("client", 2))
+	assert.True(t, p.shouldRecord("other", 1))
+}
@@ -0,0 +1,36 @@
+package dlna
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// restrictedTxnManager applies the configured restriction profile to the
+// transactions used to serve DLNA clients.
+type restrictedTxnManager struct {
+	models.TransactionManager
+	config Config
+}
+
+func (m *restrictedTxnManager) restrict(ctx context.Context) (context.Context, error) {
+	return models.WithRestrictionProfile(ctx, m.TransactionManager, m.config.GetDLNARestrictionProfileID())
+}
+
+func (m *restrictedTxnManager) WithTxn(ctx context.Context, fn func(r models.Repository) error) error {
+	ctx, err := m.restrict(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.TransactionManager.WithTxn(ctx, fn)
+}
+
+func (m *restrictedTxnManager) WithReadTxn(ctx context.Context, fn func(r models.ReaderRepository) error) error {
+	ctx, err := m.restrict(ctx)
+	if err != nil {
+		return err
+	}
+
+	return m.TransactionManager.WithReadTxn(ctx, fn)
+}
@@ -31,6 +31,7 @@ type Config interface {
 	GetDLNAInterfaces() []string
 	GetDLNAServerName() string
 	GetDLNADefaultIPWhitelist() []string
+	GetDLNARestrictionProfileID() int
 }
 
 type Service struct {
@@ -108,6 +109,7 @@ func (s *Service) init() error {
 		txnManager:         s.txnManager,
 		sceneServer:        s.sceneServer,
 		ipWhitelistManager: s.ipWhitelistMgr,
+		playRecorder:       newPlayRecorder(s.txnManager),
 		Interfaces:         interfaces,
 		HTTPConn: func() net.Listener {
 			conn, err := net.Listen("tcp", dmsConfig.Http)
@@ -169,7 +171,7 @@ func (s *Service) init() error {
 // NewService initialises and returns a new DLNA service.
 func NewService(txnManager models.TransactionManager, cfg Config, sceneServer sceneServer) *Service {
 	ret := &Service{
-		txnManager:  txnManager,
+		txnManager:  &restrictedTxnManager{TransactionManager: txnManager, config: cfg},
 		sceneServer: sceneServer,
 		config:      cfg,
 		ipWhitelistMgr: &ipWhitelistManager{
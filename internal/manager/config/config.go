@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"sync"
@@ -42,6 +43,14 @@ const (
 	Exclude      = "exclude"
 	ImageExclude = "image_exclude"
 
+	// WatchStashPaths is the config key used to determine if the stash paths
+	// are watched for changes, which are then scanned or cleaned.
+	WatchStashPaths = "watch_stash_paths"
+	// WatchDebounce is the number of seconds a changed file must be left
+	// unchanged before it is scanned.
+	WatchDebounce        = "watch_debounce"
+	watchDebounceDefault = 30
+
 	VideoExtensions            = "video_extensions"
 	ImageExtensions            = "image_extensions"
 	GalleryExtensions          = "gallery_extensions"
@@ -58,9 +67,19 @@ const (
 	MaxTranscodeSize          = "max_transcode_size"
 	MaxStreamingTranscodeSize = "max_streaming_transcode_size"
 
+	// TranscodeProfiles is the config key for the named transcode profiles.
+	TranscodeProfiles = "transcode_profiles"
+	// GeneratedTranscodeProfile is the config key for the name of the
+	// transcode profile used for generated transcodes.
+	GeneratedTranscodeProfile = "generated_transcode_profile"
+
 	ParallelTasks        = "parallel_tasks"
 	parallelTasksDefault = 1
 
+	// JobLaneConcurrency is the config key of the map of job lanes to the
+	// number of jobs that may run concurrently in the lane.
+	JobLaneConcurrency = "job_lane_concurrency"
+
 	PreviewPreset = "preview_preset"
 
 	PreviewAudio        = "preview_audio"
@@ -101,6 +120,8 @@ const (
 	ScraperCertCheck          = "scraper_cert_check"
 	ScraperCDPPath            = "scraper_cdp_path"
 	ScraperExcludeTagPatterns = "scraper_exclude_tag_patterns"
+	ScraperCacheTTL           = "scraper_cache_ttl"
+	ScraperCredentials        = "scraper_credentials"
 
 	// stash-box options
 	StashBoxes = "stash_boxes"
@@ -174,10 +195,11 @@ const (
 	securityTripwireAccessedFromPublicInternetDefault = ""
 
 	// DLNA options
-	DLNAServerName         = "dlna.server_name"
-	DLNADefaultEnabled     = "dlna.default_enabled"
-	DLNADefaultIPWhitelist = "dlna.default_whitelist"
-	DLNAInterfaces         = "dlna.interfaces"
+	DLNAServerName           = "dlna.server_name"
+	DLNADefaultEnabled       = "dlna.default_enabled"
+	DLNADefaultIPWhitelist   = "dlna.default_whitelist"
+	DLNAInterfaces           = "dlna.interfaces"
+	DLNARestrictionProfileID = "dlna.restriction_profile_id"
 
 	// Logging options
 	LogFile          = "logFile"
@@ -527,6 +549,23 @@ func (i *Instance) GetImageExcludes() []string {
 	return i.getStringSlice(ImageExclude)
 }
 
+// GetWatchStashPaths returns true if the stash paths should be watched for
+// changes.
+func (i *Instance) GetWatchStashPaths() bool {
+	return i.getBool(WatchStashPaths)
+}
+
+// GetWatchDebounce returns the number of seconds a changed file must be left
+// unchanged before it is scanned.
+func (i *Instance) GetWatchDebounce() int {
+	ret := i.getInt(WatchDebounce)
+	if ret <= 0 {
+		ret = watchDebounceDefault
+	}
+
+	return ret
+}
+
 func (i *Instance) GetVideoExtensions() []string {
 	ret := i.getStringSlice(VideoExtensions)
 	if ret == nil {
@@ -609,6 +648,23 @@ func (i *Instance) GetScraperExcludeTagPatterns() []string {
 	return i.getStringSlice(ScraperExcludeTagPatterns)
 }
 
+// GetScraperCacheTTL returns the number of seconds for which scraper
+// responses and results are cached. Caching is disabled if zero.
+func (i *Instance) GetScraperCacheTTL() int {
+	return i.getInt(ScraperCacheTTL)
+}
+
+// GetScraperCredentials returns the credentials which scraper
+// configurations reference to log in to APIs.
+func (i *Instance) GetScraperCredentials() []*models.ScraperCredential {
+	var ret []*models.ScraperCredential
+	if err := i.unmarshalKey(ScraperCredentials, &ret); err != nil {
+		logger.Warnf("error in unmarshalkey: %v", err)
+	}
+
+	return ret
+}
+
 func (i *Instance) GetStashBoxes() models.StashBoxes {
 	var boxes models.StashBoxes
 	if err := i.unmarshalKey(StashBoxes, &boxes); err != nil {
@@ -671,6 +727,22 @@ func (i *Instance) GetParallelTasks() int {
 	return i.getInt(ParallelTasks)
 }
 
+// GetJobLaneConcurrency returns the number of jobs that may run concurrently
+// in each of the configured job lanes, keyed by the lowercase lane name.
+func (i *Instance) GetJobLaneConcurrency() map[string]int {
+	ret := make(map[string]int)
+	for lane, v := range i.getStringMapString(JobLaneConcurrency) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			continue
+		}
+
+		ret[strings.ToLower(lane)] = n
+	}
+
+	return ret
+}
+
 func (i *Instance) GetParallelTasksWithAutoDetection() int {
 	parallelTasks := i.getInt(ParallelTasks)
 	if parallelTasks <= 0 {
@@ -1164,6 +1236,12 @@ func (i *Instance) GetDLNAInterfaces() []string {
 	return i.getStringSlice(DLNAInterfaces)
 }
 
+// GetDLNARestrictionProfileID returns the id of the restriction profile
+// applied to the content served over DLNA. Returns 0 if none is set.
+func (i *Instance) GetDLNARestrictionProfileID() int {
+	return i.getInt(DLNARestrictionProfileID)
+}
+
 // GetLogFile returns the filename of the file to output logs to.
 // An empty string means that file logging will be disabled.
 func (i *Instance) GetLogFile() string {
@@ -39,6 +39,8 @@ func TestConcurrentConfigAccess(t *testing.T) {
 				i.GetDefaultScrapersPath()
 				i.Set(Exclude, i.GetExcludes())
 				i.Set(ImageExclude, i.GetImageExcludes())
+				i.Set(WatchStashPaths, i.GetWatchStashPaths())
+				i.Set(WatchDebounce, i.GetWatchDebounce())
 				i.Set(VideoExtensions, i.GetVideoExtensions())
 				i.Set(ImageExtensions, i.GetImageExtensions())
 				i.Set(GalleryExtensions, i.GetGalleryExtensions())
@@ -49,6 +51,8 @@ func TestConcurrentConfigAccess(t *testing.T) {
 				i.Set(ScraperUserAgent, i.GetScraperUserAgent())
 				i.Set(ScraperCDPPath, i.GetScraperCDPPath())
 				i.Set(ScraperCertCheck, i.GetScraperCertCheck())
+				i.Set(ScraperCacheTTL, i.GetScraperCacheTTL())
+				i.Set(ScraperCredentials, i.GetScraperCredentials())
 				i.Set(ScraperExcludeTagPatterns, i.GetScraperExcludeTagPatterns())
 				i.Set(StashBoxes, i.GetStashBoxes())
 				i.GetDefaultPluginsPath()
@@ -59,6 +63,7 @@ func TestConcurrentConfigAccess(t *testing.T) {
 				i.Set(PreviewSegmentDuration, i.GetPreviewSegmentDuration())
 				i.Set(ParallelTasks, i.GetParallelTasks())
 				i.Set(ParallelTasks, i.GetParallelTasksWithAutoDetection())
+				i.Set(JobLaneConcurrency, i.GetJobLaneConcurrency())
 				i.Set(PreviewAudio, i.GetPreviewAudio())
 				i.Set(PreviewSegments, i.GetPreviewSegments())
 				i.Set(PreviewExcludeStart, i.GetPreviewExcludeStart())
@@ -66,6 +71,8 @@ func TestConcurrentConfigAccess(t *testing.T) {
 				i.Set(PreviewPreset, i.GetPreviewPreset())
 				i.Set(MaxTranscodeSize, i.GetMaxTranscodeSize())
 				i.Set(MaxStreamingTranscodeSize, i.GetMaxStreamingTranscodeSize())
+				i.Set(TranscodeProfiles, i.GetTranscodeProfiles())
+				i.Set(GeneratedTranscodeProfile, i.GetGeneratedTranscodeProfile())
 				i.Set(ApiKey, i.GetAPIKey())
 				i.Set(Username, i.GetUsername())
 				i.Set(Password, i.GetPasswordHash())
@@ -91,6 +98,7 @@ func TestConcurrentConfigAccess(t *testing.T) {
 				i.Set(DLNADefaultEnabled, i.GetDLNADefaultEnabled())
 				i.Set(DLNADefaultIPWhitelist, i.GetDLNADefaultIPWhitelist())
 				i.Set(DLNAInterfaces, i.GetDLNAInterfaces())
+				i.Set(DLNARestrictionProfileID, i.GetDLNARestrictionProfileID())
 				i.Set(LogFile, i.GetLogFile())
 				i.Set(LogOut, i.GetLogOut())
 				i.Set(LogLevel, i.GetLogLevel())
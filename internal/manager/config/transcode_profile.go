@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// GetTranscodeProfiles returns the configured transcode profiles.
+func (i *Instance) GetTranscodeProfiles() models.TranscodeProfiles {
+	var ret models.TranscodeProfiles
+	if err := i.unmarshalKey(TranscodeProfiles, &ret); err != nil {
+		logger.Warnf("error in unmarshalkey: %v", err)
+	}
+
+	return ret
+}
+
+// GetGeneratedTranscodeProfile returns the name of the transcode profile
+// used for generated transcodes. Returns an empty string if the default
+// settings should be used.
+func (i *Instance) GetGeneratedTranscodeProfile() string {
+	return i.getString(GeneratedTranscodeProfile)
+}
+
+// GetTranscodeProfile returns the transcode profile with the provided name,
+// or nil if it does not exist.
+func (i *Instance) GetTranscodeProfile(name string) *ffmpeg.TranscodeProfile {
+	p := i.GetTranscodeProfiles().Find(name)
+	if p == nil {
+		return nil
+	}
+
+	ret := toFFMpegTranscodeProfile(p)
+	return &ret
+}
+
+func toFFMpegTranscodeProfile(p *models.TranscodeProfile) ffmpeg.TranscodeProfile {
+	return ffmpeg.TranscodeProfile{
+		Name:             p.Name,
+		Container:        ffmpeg.TranscodeContainer(p.Container),
+		VideoCodec:       ffmpeg.VideoCodec(p.VideoCodec),
+		Preset:           p.Preset,
+		CRF:              p.CRF,
+		VideoBitrate:     p.VideoBitrate,
+		AudioCodec:       ffmpeg.AudioCodec(p.AudioCodec),
+		AudioBitrate:     p.AudioBitrate,
+		MaxTranscodeSize: p.MaxHeight,
+	}
+}
+
+// ValidateTranscodeProfiles returns an error if any of the profiles are
+// invalid, if profile names are not unique, or if generatedProfile is not
+// the name of an mp4 profile.
+func (i *Instance) ValidateTranscodeProfiles(profiles models.TranscodeProfiles, generatedProfile string) error {
+	names := make(map[string]bool)
+	for _, p := range profiles {
+		if err := toFFMpegTranscodeProfile(p).Validate(); err != nil {
+			return err
+		}
+
+		if names[p.Name] {
+			return fmt.Errorf("duplicate transcode profile name %q", p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	if generatedProfile != "" {
+		p := profiles.Find(generatedProfile)
+		if p == nil {
+			return fmt.Errorf("transcode profile %q not found", generatedProfile)
+		}
+
+		// generated transcodes are served as mp4 files
+		if ffmpeg.TranscodeContainer(p.Container) != ffmpeg.TranscodeContainerMP4 {
+			return fmt.Errorf("transcode profile %q: generated transcodes require the mp4 container", generatedProfile)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func TestValidateTranscodeProfiles(t *testing.T) {
+	mobile := &models.TranscodeProfile{
+		Name:         "mobile",
+		Container:    "mp4",
+		VideoCodec:   "libx264",
+		VideoBitrate: "1M",
+		MaxHeight:    480,
+	}
+	lan := &models.TranscodeProfile{
+		Name:       "lan",
+		Container:  "webm",
+		VideoCodec: "libvpx-vp9",
+		CRF:        20,
+	}
+
+	tests := []struct {
+		name             string
+		profiles         models.TranscodeProfiles
+		generatedProfile string
+		wantErr          bool
+	}{
+		{"valid", models.TranscodeProfiles{mobile, lan}, "mobile", false},
+		{"no generated profile", models.TranscodeProfiles{mobile, lan}, "", false},
+		{"duplicate name", models.TranscodeProfiles{mobile, mobile}, "", true},
+		{"missing generated profile", models.TranscodeProfiles{mobile}, "lan", true},
+		{"generated profile not mp4", models.TranscodeProfiles{mobile, lan}, "lan", true},
+		{"blank name", models.TranscodeProfiles{{Container: "mp4", VideoCodec: "libx264"}}, "", true},
+		{"invalid container", models.TranscodeProfiles{{Name: "x", Container: "avi", VideoCodec: "libx264"}}, "", true},
+		{"blank codec", models.TranscodeProfiles{{Name: "x", Container: "mp4"}}, "", true},
+	}
+
+	i := GetInstance()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := i.ValidateTranscodeProfiles(tt.profiles, tt.generatedProfile); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTranscodeProfiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetTranscodeProfile(t *testing.T) {
+	i := GetInstance()
+	i.Set(TranscodeProfiles, models.TranscodeProfiles{
+		{
+			Name:       "mobile",
+			Container:  "mp4",
+			VideoCodec: "libx264",
+			Preset:     "veryfast",
+			CRF:        28,
+			MaxHeight:  480,
+		},
+	})
+	defer i.Set(TranscodeProfiles, nil)
+
+	p := i.GetTranscodeProfile("mobile")
+	if p == nil {
+		t.Fatal("GetTranscodeProfile() returned nil")
+	}
+
+	if p.VideoCodec != "libx264" || p.Preset != "veryfast" || p.CRF != 28 || p.MaxTranscodeSize != 480 {
+		t.Errorf("GetTranscodeProfile() = %+v", p)
+	}
+
+	if i.GetTranscodeProfile("lan") != nil {
+		t.Error("GetTranscodeProfile() returned profile for unknown name")
+	}
+}
@@ -0,0 +1,644 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/ffmpeg"
+	"github.com/stashapp/stash/pkg/fsutil"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	// hlsSessionIdleTimeout is the time after which a HLS session that has
+	// not been accessed is stopped and its segments removed.
+	hlsSessionIdleTimeout = 2 * time.Minute
+	hlsReapInterval       = 30 * time.Second
+
+	// hlsSegmentTimeout is the maximum time to wait for a segment to be
+	// generated.
+	hlsSegmentTimeout  = 30 * time.Second
+	hlsSegmentPollTime = 250 * time.Millisecond
+
+	// hlsMaxSegmentGap is the maximum number of segments that a request may
+	// be ahead of the running transcode before the transcode is restarted
+	// from the requested segment.
+	hlsMaxSegmentGap = 3
+)
+
+// DASHAudioRepresentation is the ID of the audio representation of DASH
+// streams. Video representations are identified by their resolution.
+const DASHAudioRepresentation = "audio"
+
+// hlsResolutions are the resolutions that may be offered as renditions of a
+// HLS stream, ordered from highest to lowest.
+var hlsResolutions = []models.StreamingResolutionEnum{
+	models.StreamingResolutionEnumFourK,
+	models.StreamingResolutionEnumFullHd,
+	models.StreamingResolutionEnumStandardHd,
+	models.StreamingResolutionEnumStandard,
+	models.StreamingResolutionEnumLow,
+}
+
+// hlsBandwidth is the estimated peak bit rate of each rendition.
+var hlsBandwidth = map[models.StreamingResolutionEnum]int{
+	models.StreamingResolutionEnumFourK:      16000000,
+	models.StreamingResolutionEnumFullHd:     5000000,
+	models.StreamingResolutionEnumStandardHd: 2800000,
+	models.StreamingResolutionEnumStandard:   1200000,
+	models.StreamingResolutionEnumLow:        400000,
+}
+
+// GetHLSResolutions returns the resolutions of the renditions offered for the
+// HLS stream of the scene. Resolutions larger than the scene or
+// maxStreamingTranscodeSize are not included. If no resolution applies, then
+// the original resolution is returned.
+func GetHLSResolutions(scene *models.Scene, maxStreamingTranscodeSize models.StreamingResolutionEnum) []models.StreamingResolutionEnum {
+	var ret []models.StreamingResolutionEnum
+	for _, res := range hlsResolutions {
+		if includeSceneStreamPath(scene, res, maxStreamingTranscodeSize) {
+			ret = append(ret, res)
+		}
+	}
+
+	if len(ret) == 0 {
+		ret = append(ret, models.StreamingResolutionEnumOriginal)
+	}
+
+	return ret
+}
+
+func getHLSRendition(scene *models.Scene, res models.StreamingResolutionEnum, url string) ffmpeg.HLSRendition {
+	width := int(scene.Width.Int64)
+	height := int(scene.Height.Int64)
+
+	maxSize := res.GetMaxResolution()
+	minSize := width
+	if height < minSize {
+		minSize = height
+	}
+
+	if maxSize != 0 && minSize > maxSize {
+		// scale the smaller dimension to maxSize, keeping the other even
+		scale := func(v int) int {
+			return (v*maxSize/minSize + 1) / 2 * 2
+		}
+
+		if width > height {
+			width = scale(width)
+			height = maxSize
+		} else {
+			height = scale(height)
+			width = maxSize
+		}
+	}
+
+	bandwidth, found := hlsBandwidth[res]
+	if !found {
+		bandwidth = int(scene.Bitrate.Int64)
+	}
+	if bandwidth == 0 {
+		bandwidth = hlsBandwidth[models.StreamingResolutionEnumLow]
+	}
+
+	return ffmpeg.HLSRendition{
+		Width:     width,
+		Height:    height,
+		Bandwidth: bandwidth,
+		URL:       url,
+	}
+}
+
+// WriteHLSMasterPlaylist writes the HLS master playlist of the scene to w.
+// mediaPlaylistURL returns the URL of the media playlist of the rendition
+// with the provided resolution.
+func WriteHLSMasterPlaylist(scene *models.Scene, maxStreamingTranscodeSize models.StreamingResolutionEnum, mediaPlaylistURL func(res models.StreamingResolutionEnum) string, w io.Writer) {
+	var renditions []ffmpeg.HLSRendition
+	for _, res := range GetHLSResolutions(scene, maxStreamingTranscodeSize) {
+		renditions = append(renditions, getHLSRendition(scene, res, mediaPlaylistURL(res)))
+	}
+
+	ffmpeg.WriteHLSMasterPlaylist(renditions, w)
+}
+
+// WriteDASHManifest writes the DASH manifest of the scene to w, with a video
+// representation for each resolution returned by GetHLSResolutions. initURL
+// and mediaURL are the segment URL templates.
+func WriteDASHManifest(scene *models.Scene, duration float64, maxStreamingTranscodeSize models.StreamingResolutionEnum, initURL, mediaURL string, w io.Writer) error {
+	options := ffmpeg.DASHManifestOptions{
+		Duration: duration,
+		InitURL:  initURL,
+		MediaURL: mediaURL,
+	}
+
+	for _, res := range GetHLSResolutions(scene, maxStreamingTranscodeSize) {
+		r := getHLSRendition(scene, res, "")
+		options.Video = append(options.Video, ffmpeg.DASHRepresentation{
+			ID:        res.String(),
+			Width:     r.Width,
+			Height:    r.Height,
+			Bandwidth: r.Bandwidth,
+			FrameRate: scene.Framerate.Float64,
+		})
+	}
+
+	if scene.AudioCodec.Valid && ffmpeg.ProbeAudioCodec(scene.AudioCodec.String) != ffmpeg.MissingUnsupported {
+		options.AudioID = DASHAudioRepresentation
+	}
+
+	return ffmpeg.WriteDASHManifest(options, w)
+}
+
+// HLSStreamManager manages segmented stream sessions. A session exists for
+// each combination of video file and HLS rendition or DASH representation
+// being streamed. Each session runs
+// at most one ffmpeg process, which writes segments into a cache directory
+// from which they are served. Sessions that have not been accessed for a
+// while are stopped and their segments removed.
+type HLSStreamManager struct {
+	sessions map[string]*hlsSession
+	mutex    sync.Mutex
+}
+
+// NewHLSStreamManager creates a new HLSStreamManager, which will periodically
+// clean up idle sessions.
+func NewHLSStreamManager() *HLSStreamManager {
+	ret := &HLSStreamManager{
+		sessions: make(map[string]*hlsSession),
+	}
+
+	go ret.reapIdleSessions()
+
+	return ret
+}
+
+// hlsSessionSpec describes the output of a session.
+type hlsSessionSpec struct {
+	// name distinguishes sessions of the same file and resolution
+	name        string
+	resolution  models.StreamingResolutionEnum
+	segmentType ffmpeg.HLSSegmentType
+	videoOnly   bool
+	audioOnly   bool
+}
+
+func (s hlsSessionSpec) key(hash string) string {
+	return hash + "_" + s.name + "_" + s.resolution.String()
+}
+
+func hlsSpec(res models.StreamingResolutionEnum) hlsSessionSpec {
+	return hlsSessionSpec{
+		name:        "hls",
+		resolution:  res,
+		segmentType: ffmpeg.HLSSegmentTypeMpegTS,
+	}
+}
+
+// dashSpec returns the session spec for the DASH representation with the
+// provided ID. Video and audio are served as separate representations.
+func dashSpec(representation string) hlsSessionSpec {
+	if representation == DASHAudioRepresentation {
+		return hlsSessionSpec{
+			name:        "dash_audio",
+			segmentType: ffmpeg.HLSSegmentTypeFMP4,
+			audioOnly:   true,
+		}
+	}
+
+	return hlsSessionSpec{
+		name:        "dash",
+		resolution:  models.StreamingResolutionEnum(representation),
+		segmentType: ffmpeg.HLSSegmentTypeFMP4,
+		videoOnly:   true,
+	}
+}
+
+func (m *HLSStreamManager) getSession(scene *models.Scene, spec hlsSessionSpec) (*hlsSession, error) {
+	hash := scene.GetHash(config.GetInstance().GetVideoFileNamingAlgorithm())
+	if hash == "" {
+		return nil, errors.New("scene does not have a hash")
+	}
+
+	key := spec.key(hash)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if s, found := m.sessions[key]; found {
+		return s, nil
+	}
+
+	audioCodec := ffmpeg.MissingUnsupported
+	if scene.AudioCodec.Valid {
+		audioCodec = ffmpeg.ProbeAudioCodec(scene.AudioCodec.String)
+	}
+
+	dir := instance.Paths.Generated.GetHLSPath(key)
+	if err := fsutil.EnsureDir(dir); err != nil {
+		return nil, fmt.Errorf("creating HLS directory: %w", err)
+	}
+
+	s := &hlsSession{
+		hash:         hash,
+		dir:          dir,
+		segmentCount: ffmpeg.HLSSegmentCount(scene.Duration.Float64),
+		options: ffmpeg.HLSSegmentOptions{
+			Input:            scene.Path,
+			OutputDir:        dir,
+			SegmentType:      spec.segmentType,
+			MaxTranscodeSize: spec.resolution.GetMaxResolution(),
+			VideoWidth:       int(scene.Width.Int64),
+			VideoHeight:      int(scene.Height.Int64),
+			VideoOnly:        spec.videoOnly || audioCodec == ffmpeg.MissingUnsupported,
+			AudioOnly:        spec.audioOnly,
+		},
+		lastAccess: time.Now(),
+	}
+
+	m.sessions[key] = s
+
+	logger.Debugf("[hls] started session %s", key)
+
+	return s, nil
+}
+
+// GetSegment returns the path of the segment with the provided index of the
+// HLS stream of the scene at the provided resolution, starting or restarting
+// the transcode as needed. It waits until the segment has been completely
+// written, the context is cancelled, or the timeout is reached.
+func (m *HLSStreamManager) GetSegment(ctx context.Context, scene *models.Scene, res models.StreamingResolutionEnum, index int) (string, error) {
+	return m.getSegment(ctx, scene, hlsSpec(res), index)
+}
+
+// GetDASHSegment returns the path of the fragmented MP4 segment with the
+// provided index of the DASH representation of the scene. representation is
+// either a streaming resolution or DASHAudioRepresentation.
+func (m *HLSStreamManager) GetDASHSegment(ctx context.Context, scene *models.Scene, representation string, index int) (string, error) {
+	return m.getSegment(ctx, scene, dashSpec(representation), index)
+}
+
+// GetDASHInitSegment returns the path of the initialization segment of the
+// DASH representation of the scene.
+func (m *HLSStreamManager) GetDASHInitSegment(ctx context.Context, scene *models.Scene, representation string) (string, error) {
+	s, err := m.getSession(scene, dashSpec(representation))
+	if err != nil {
+		return "", err
+	}
+
+	return s.waitForInit(ctx)
+}
+
+func (m *HLSStreamManager) getSegment(ctx context.Context, scene *models.Scene, spec hlsSessionSpec, index int) (string, error) {
+	s, err := m.getSession(scene, spec)
+	if err != nil {
+		return "", err
+	}
+
+	if s.segmentCount > 0 && (index < 0 || index >= s.segmentCount) {
+		return "", fmt.Errorf("segment %d out of range", index)
+	}
+
+	return s.waitForSegment(ctx, index)
+}
+
+// Stop stops all sessions for the video file with the provided hash, and
+// removes their segments.
+func (m *HLSStreamManager) Stop(hash string) {
+	m.stopSessions(func(s *hlsSession) bool {
+		return s.hash == hash
+	})
+}
+
+func (m *HLSStreamManager) stopSessions(filter func(s *hlsSession) bool) {
+	var toStop []*hlsSession
+
+	m.mutex.Lock()
+	for key, s := range m.sessions {
+		if filter(s) {
+			toStop = append(toStop, s)
+			delete(m.sessions, key)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, s := range toStop {
+		s.close()
+	}
+}
+
+func (m *HLSStreamManager) reapIdleSessions() {
+	for range time.Tick(hlsReapInterval) {
+		m.stopSessions(func(s *hlsSession) bool {
+			return s.idleSince() > hlsSessionIdleTimeout
+		})
+	}
+}
+
+type hlsSession struct {
+	hash         string
+	dir          string
+	segmentCount int
+	options      ffmpeg.HLSSegmentOptions
+
+	mutex      sync.Mutex
+	lastAccess time.Time
+	transcode  *hlsTranscode
+	closed     bool
+	// initPath is the path of a complete initialization segment, for
+	// fragmented MP4 sessions
+	initPath string
+}
+
+// hlsTranscode is a running ffmpeg process writing segments from
+// startSegment onwards.
+type hlsTranscode struct {
+	startSegment int
+	lockCtx      *fsutil.LockContext
+	done         chan struct{}
+	err          error
+	// completed is true if the transcode wrote all segments to the end of
+	// the input
+	completed bool
+}
+
+func (t *hlsTranscode) finished() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *hlsTranscode) stop() {
+	t.lockCtx.Cancel()
+
+	select {
+	case <-t.done:
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func (s *hlsSession) idleSince() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return time.Since(s.lastAccess)
+}
+
+func (s *hlsSession) segmentPath(index int) string {
+	return ffmpeg.HLSSegmentPath(s.dir, index, s.options.SegmentType)
+}
+
+func (s *hlsSession) segmentExists(index int) bool {
+	exists, _ := fsutil.FileExists(s.segmentPath(index))
+	return exists
+}
+
+// lastSegment returns the index of the last segment written by the
+// transcode, or -1 if no segment has been written yet.
+func (s *hlsSession) lastSegment(t *hlsTranscode) int {
+	ret := t.startSegment - 1
+	for s.segmentExists(ret + 1) {
+		ret++
+	}
+
+	return ret
+}
+
+// segmentReady returns true if the segment has been completely written. A
+// segment is complete once the next segment has been started, or the
+// transcode that wrote it has finished successfully.
+func (s *hlsSession) segmentReady(index int) bool {
+	if !s.segmentExists(index) {
+		return false
+	}
+
+	if s.segmentExists(index + 1) {
+		return true
+	}
+
+	t := s.transcode
+	return t != nil && t.finished() && t.completed && index >= t.startSegment
+}
+
+// covers returns true if the running transcode will write the segment
+// shortly.
+func (s *hlsSession) covers(index int) bool {
+	t := s.transcode
+	if t == nil || t.finished() || index < t.startSegment {
+		return false
+	}
+
+	return index <= s.lastSegment(t)+hlsMaxSegmentGap
+}
+
+func (s *hlsSession) waitForSegment(ctx context.Context, index int) (string, error) {
+	segmentPath := s.segmentPath(index)
+	timeout := time.After(hlsSegmentTimeout)
+
+	for {
+		s.mutex.Lock()
+		s.lastAccess = time.Now()
+
+		if s.closed {
+			s.mutex.Unlock()
+			return "", errors.New("stream session was stopped")
+		}
+
+		if s.segmentReady(index) {
+			s.mutex.Unlock()
+			return segmentPath, nil
+		}
+
+		t := s.transcode
+		if t != nil && t.finished() && t.err != nil && t.startSegment == index {
+			// the transcode failed to produce the requested segment
+			err := t.err
+			s.transcode = nil
+			s.mutex.Unlock()
+			return "", err
+		}
+
+		if !s.covers(index) {
+			if err := s.restart(index); err != nil {
+				s.mutex.Unlock()
+				return "", err
+			}
+		}
+		s.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-timeout:
+			return "", fmt.Errorf("timed out waiting for segment %d", index)
+		case <-time.After(hlsSegmentPollTime):
+		}
+	}
+}
+
+// updateInit records the initialization segment of the running transcode
+// once it is complete. The initialization segment is complete once the
+// first media segment has been started. The session mutex must be held.
+func (s *hlsSession) updateInit() {
+	t := s.transcode
+	if s.initPath != "" || t == nil {
+		return
+	}
+
+	initPath := ffmpeg.HLSInitSegmentPath(s.dir, t.startSegment)
+	if exists, _ := fsutil.FileExists(initPath); exists && s.segmentExists(t.startSegment) {
+		s.initPath = initPath
+	}
+}
+
+func (s *hlsSession) waitForInit(ctx context.Context) (string, error) {
+	timeout := time.After(hlsSegmentTimeout)
+
+	for {
+		s.mutex.Lock()
+		s.lastAccess = time.Now()
+
+		if s.closed {
+			s.mutex.Unlock()
+			return "", errors.New("stream session was stopped")
+		}
+
+		s.updateInit()
+		if s.initPath != "" {
+			s.mutex.Unlock()
+			return s.initPath, nil
+		}
+
+		t := s.transcode
+		if t != nil && t.finished() && t.err != nil {
+			err := t.err
+			s.transcode = nil
+			s.mutex.Unlock()
+			return "", err
+		}
+
+		if t == nil || t.finished() {
+			// start from the beginning, since that is most likely to be
+			// requested next
+			if err := s.restart(0); err != nil {
+				s.mutex.Unlock()
+				return "", err
+			}
+		}
+		s.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-timeout:
+			return "", errors.New("timed out waiting for initialization segment")
+		case <-time.After(hlsSegmentPollTime):
+		}
+	}
+}
+
+// stopTranscode stops the running transcode and removes the segment it was
+// writing. The session mutex must be held.
+func (s *hlsSession) stopTranscode() {
+	t := s.transcode
+	if t == nil {
+		return
+	}
+
+	// keep the initialization segment if it is complete
+	s.updateInit()
+
+	s.transcode = nil
+
+	if t.finished() {
+		return
+	}
+
+	last := s.lastSegment(t)
+	t.stop()
+
+	// the last segment may be incomplete
+	if last >= t.startSegment {
+		if err := os.Remove(s.segmentPath(last)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf("[hls] error removing incomplete segment: %v", err)
+		}
+	}
+}
+
+// restart stops the running transcode and starts a new one from the segment
+// with the provided index. The session mutex must be held.
+func (s *hlsSession) restart(index int) error {
+	s.stopTranscode()
+
+	options := s.options
+	options.StartSegment = index
+	args := options.Args()
+
+	logger.Debugf("[hls] starting transcode of %s from segment %d", options.Input, index)
+
+	// use a read lock on the input so that the transcode is killed along
+	// with the other running streams of the file
+	lockCtx := instance.ReadLockManager.ReadLock(context.Background(), options.Input)
+
+	cmd := instance.FFMPEG.Command(lockCtx, args)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		lockCtx.Cancel()
+		return fmt.Errorf("error starting ffmpeg: %w", err)
+	}
+
+	t := &hlsTranscode{
+		startSegment: index,
+		lockCtx:      lockCtx,
+		done:         make(chan struct{}),
+	}
+
+	go func() {
+		err := cmd.Wait()
+
+		// cancelled transcodes are not errors
+		cancelled := lockCtx.Err() != nil
+		if err != nil && !cancelled {
+			t.err = fmt.Errorf("error running ffmpeg command <%s>: %w: %s", strings.Join(args, " "), err, stderr.String())
+			logger.Errorf("[hls] %v", t.err)
+		}
+		t.completed = err == nil && !cancelled
+
+		// release the read lock
+		lockCtx.Cancel()
+		close(t.done)
+	}()
+
+	s.transcode = t
+
+	return nil
+}
+
+// close stops the running transcode and removes the session directory.
+func (s *hlsSession) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	s.stopTranscode()
+
+	if err := fsutil.RemoveDir(s.dir); err != nil {
+		logger.Warnf("[hls] error removing session directory %s: %v", s.dir, err)
+	}
+
+	logger.Debugf("[hls] stopped session %s", s.dir)
+}
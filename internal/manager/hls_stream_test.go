@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"bytes"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func makeHLSScene(width, height int64) *models.Scene {
+	return &models.Scene{
+		Width:  sql.NullInt64{Int64: width, Valid: true},
+		Height: sql.NullInt64{Int64: height, Valid: true},
+	}
+}
+
+func TestGetHLSResolutions(t *testing.T) {
+	tests := []struct {
+		name    string
+		scene   *models.Scene
+		maxSize models.StreamingResolutionEnum
+		want    []models.StreamingResolutionEnum
+	}{
+		{
+			"1080p original",
+			makeHLSScene(1920, 1080),
+			models.StreamingResolutionEnumOriginal,
+			[]models.StreamingResolutionEnum{
+				models.StreamingResolutionEnumFullHd,
+				models.StreamingResolutionEnumStandardHd,
+				models.StreamingResolutionEnumStandard,
+				models.StreamingResolutionEnumLow,
+			},
+		},
+		{
+			"1080p capped",
+			makeHLSScene(1920, 1080),
+			models.StreamingResolutionEnumStandard,
+			[]models.StreamingResolutionEnum{
+				models.StreamingResolutionEnumStandard,
+				models.StreamingResolutionEnumLow,
+			},
+		},
+		{
+			"tiny",
+			makeHLSScene(160, 120),
+			models.StreamingResolutionEnumOriginal,
+			[]models.StreamingResolutionEnum{
+				models.StreamingResolutionEnumOriginal,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetHLSResolutions(tt.scene, tt.maxSize); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetHLSResolutions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetHLSRendition(t *testing.T) {
+	tests := []struct {
+		name       string
+		scene      *models.Scene
+		res        models.StreamingResolutionEnum
+		wantWidth  int
+		wantHeight int
+	}{
+		{"landscape", makeHLSScene(1920, 1080), models.StreamingResolutionEnumStandardHd, 1280, 720},
+		{"portrait", makeHLSScene(1080, 1920), models.StreamingResolutionEnumStandardHd, 720, 1280},
+		{"smaller", makeHLSScene(640, 480), models.StreamingResolutionEnumStandardHd, 640, 480},
+		{"original", makeHLSScene(160, 120), models.StreamingResolutionEnumOriginal, 160, 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getHLSRendition(tt.scene, tt.res, "")
+			if got.Width != tt.wantWidth || got.Height != tt.wantHeight {
+				t.Errorf("getHLSRendition() = %dx%d, want %dx%d", got.Width, got.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if got.Bandwidth == 0 {
+				t.Errorf("getHLSRendition() bandwidth = 0")
+			}
+		})
+	}
+}
+
+func TestWriteDASHManifest(t *testing.T) {
+	scene := makeHLSScene(1280, 720)
+	scene.AudioCodec = sql.NullString{String: "aac", Valid: true}
+
+	var buf bytes.Buffer
+	if err := WriteDASHManifest(scene, 95, models.StreamingResolutionEnumOriginal, "init", "media", &buf); err != nil {
+		t.Fatalf("WriteDASHManifest() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		`mediaPresentationDuration="PT95.000S"`,
+		`<Representation id="STANDARD_HD" bandwidth="2800000" width="1280" height="720">`,
+		`<Representation id="LOW"`,
+		`<Representation id="audio"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteDASHManifest() missing %q in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// stages of the issues added to jobs
+const (
+	issueStageScan               = "scan"
+	issueStageThumbnail          = "thumbnail"
+	issueStageScreenshot         = "screenshot"
+	issueStagePreview            = "preview"
+	issueStageImagePreview       = "image_preview"
+	issueStageSprite             = "sprite"
+	issueStagePhash              = "phash"
+	issueStageMarker             = "marker"
+	issueStageTranscode          = "transcode"
+	issueStageInteractiveHeatmap = "interactive_heatmap"
+	issueStageIdentify           = "identify"
+	issueStageMerge              = "merge"
+)
+
+// addSceneError adds an error processing the scene to the running job.
+func addSceneError(ctx context.Context, stage string, s *models.Scene, err error) {
+	job.AddIssue(ctx, job.Issue{
+		Level:   job.IssueLevelError,
+		Stage:   stage,
+		SceneID: s.ID,
+		Path:    s.Path,
+		Message: err.Error(),
+	})
+}
+
+// addFileError adds an error processing the file to the running job.
+func addFileError(ctx context.Context, stage string, path string, err error) {
+	job.AddIssue(ctx, job.Issue{
+		Level:   job.IssueLevelError,
+		Stage:   stage,
+		Path:    path,
+		Message: err.Error(),
+	})
+}
+
+// failedItems returns the unique scene ids and paths of the error issues of
+// the job.
+func failedItems(j job.Job) (sceneIDs []string, paths []string) {
+	seenScenes := make(map[int]bool)
+	seenPaths := make(map[string]bool)
+	for _, i := range j.Issues {
+		if i.Level != job.IssueLevelError {
+			continue
+		}
+
+		if i.SceneID != 0 && !seenScenes[i.SceneID] {
+			seenScenes[i.SceneID] = true
+			sceneIDs = append(sceneIDs, strconv.Itoa(i.SceneID))
+		}
+		if i.Path != "" && !seenPaths[i.Path] {
+			seenPaths[i.Path] = true
+			paths = append(paths, i.Path)
+		}
+	}
+
+	return
+}
+
+var errNoFailedItems = errors.New("job has no failed items")
+
+// retryInput returns the input of the job limited to the items that failed.
+func retryInput(j job.Job) (json.RawMessage, error) {
+	sceneIDs, paths := failedItems(j)
+
+	var input interface{}
+	switch j.Type {
+	case scanJobType:
+		var i models.ScanMetadataInput
+		if err := json.Unmarshal(j.Input, &i); err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, errNoFailedItems
+		}
+		i.Paths = paths
+		input = i
+	case generateJobType:
+		var i models.GenerateMetadataInput
+		if err := json.Unmarshal(j.Input, &i); err != nil {
+			return nil, err
+		}
+		if len(sceneIDs) == 0 {
+			return nil, errNoFailedItems
+		}
+		i.SceneIDs = sceneIDs
+		i.MarkerIDs = nil
+		input = i
+	case identifyJobType:
+		var i models.IdentifyMetadataInput
+		if err := json.Unmarshal(j.Input, &i); err != nil {
+			return nil, err
+		}
+		if len(sceneIDs) == 0 {
+			return nil, errNoFailedItems
+		}
+		i.SceneIDs = sceneIDs
+		i.Paths = nil
+		input = i
+	default:
+		return nil, fmt.Errorf("retrying the failed items of job %d is not supported", j.ID)
+	}
+
+	return json.Marshal(input)
+}
+
+// RetryJobFailures queues a new job to process the items that failed in the
+// job with the provided id, using the input of the original job.
+func (s *Manager) RetryJobFailures(ctx context.Context, id int) (int, error) {
+	j, err := s.FindJob(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if j == nil {
+		return 0, fmt.Errorf("job with id %d not found", id)
+	}
+
+	input, err := retryInput(*j)
+	if err != nil {
+		return 0, err
+	}
+
+	retry := *j
+	retry.Input = input
+	retry.Description = fmt.Sprintf("Retrying failed items of job %d: %s", j.ID, j.Description)
+
+	return s.JobManager.Requeue(context.Background(), retry)
+}
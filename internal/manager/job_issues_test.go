@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryInput(t *testing.T) {
+	issues := []job.Issue{
+		{Level: job.IssueLevelError, SceneID: 1, Path: "/a.mp4", Message: "failed"},
+		{Level: job.IssueLevelError, SceneID: 1, Path: "/a.mp4", Message: "failed again"},
+		{Level: job.IssueLevelWarning, SceneID: 2, Path: "/b.mp4", Message: "warning"},
+		{Level: job.IssueLevelError, Path: "/c.jpg", Message: "failed"},
+	}
+
+	marshal := func(v interface{}) json.RawMessage {
+		ret, _ := json.Marshal(v)
+		return ret
+	}
+
+	t.Run("scan", func(t *testing.T) {
+		j := job.Job{
+			Type:   scanJobType,
+			Input:  marshal(models.ScanMetadataInput{Paths: []string{"/"}}),
+			Issues: issues,
+		}
+
+		data, err := retryInput(j)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var got models.ScanMetadataInput
+		_ = json.Unmarshal(data, &got)
+		assert.Equal(t, []string{"/a.mp4", "/c.jpg"}, got.Paths)
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		previews := true
+		j := job.Job{
+			Type: generateJobType,
+			Input: marshal(models.GenerateMetadataInput{
+				Previews:  &previews,
+				MarkerIDs: []string{"5"},
+			}),
+			Issues: issues,
+		}
+
+		data, err := retryInput(j)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var got models.GenerateMetadataInput
+		_ = json.Unmarshal(data, &got)
+		assert.Equal(t, []string{"1"}, got.SceneIDs)
+		assert.Nil(t, got.MarkerIDs)
+		if assert.NotNil(t, got.Previews) {
+			assert.True(t, *got.Previews)
+		}
+	})
+
+	t.Run("no failures", func(t *testing.T) {
+		j := job.Job{
+			Type:   generateJobType,
+			Input:  marshal(models.GenerateMetadataInput{}),
+			Issues: issues[2:3],
+		}
+
+		_, err := retryInput(j)
+		assert.True(t, errors.Is(err, errNoFailedItems))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		j := job.Job{
+			Type:   cleanJobType,
+			Issues: issues,
+		}
+
+		_, err := retryInput(j)
+		assert.Error(t, err)
+	})
+}
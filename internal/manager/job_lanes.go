@@ -0,0 +1,44 @@
+package manager
+
+import (
+	"strings"
+
+	"github.com/stashapp/stash/pkg/job"
+)
+
+// scanning and cleaning are kept in the same lane so that they don't
+// modify the same files concurrently
+func (j *ScanJob) JobLane() job.Lane {
+	return job.LaneIO
+}
+
+func (j *cleanJob) JobLane() job.Lane {
+	return job.LaneIO
+}
+
+func (j *autoTagJob) JobLane() job.Lane {
+	return job.LaneIO
+}
+
+func (j *resolveDuplicatesJob) JobLane() job.Lane {
+	return job.LaneIO
+}
+
+func (j *GenerateJob) JobLane() job.Lane {
+	return job.LaneCPU
+}
+
+func (j *IdentifyJob) JobLane() job.Lane {
+	return job.LaneNetwork
+}
+
+// RefreshJobLanes sets the number of jobs that may run concurrently in each
+// job lane from the configuration. Call this when the configuration changes.
+func (s *Manager) RefreshJobLanes() {
+	concurrency := s.Config.GetJobLaneConcurrency()
+	for _, lane := range job.AllLanes {
+		// lanes that are not configured run one job at a time
+		n := concurrency[strings.ToLower(string(lane))]
+		s.JobManager.SetLaneConcurrency(lane, n)
+	}
+}
@@ -0,0 +1,302 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/database"
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// job types of the requeueable jobs
+const (
+	scanJobType     = "scan"
+	autoTagJobType  = "auto_tag"
+	generateJobType = "generate"
+	cleanJobType    = "clean"
+	identifyJobType = "identify"
+
+	resolveDuplicatesJobType = "resolve_duplicates"
+)
+
+func (j *ScanJob) JobType() string {
+	return scanJobType
+}
+
+func (j *ScanJob) JobInput() interface{} {
+	return j.input
+}
+
+func (j *autoTagJob) JobType() string {
+	return autoTagJobType
+}
+
+func (j *autoTagJob) JobInput() interface{} {
+	return j.input
+}
+
+func (j *GenerateJob) JobType() string {
+	return generateJobType
+}
+
+func (j *GenerateJob) JobInput() interface{} {
+	return j.input
+}
+
+func (j *cleanJob) JobType() string {
+	return cleanJobType
+}
+
+func (j *cleanJob) JobInput() interface{} {
+	return j.input
+}
+
+func (j *IdentifyJob) JobType() string {
+	return identifyJobType
+}
+
+func (j *IdentifyJob) JobInput() interface{} {
+	return j.input
+}
+
+func (j *resolveDuplicatesJob) JobType() string {
+	return resolveDuplicatesJobType
+}
+
+func (j *resolveDuplicatesJob) JobInput() interface{} {
+	return j.input
+}
+
+// createJobExec recreates a requeueable job from its type and input.
+func (s *Manager) createJobExec(jobType string, input json.RawMessage) (job.JobExec, error) {
+	switch jobType {
+	case scanJobType:
+		var i models.ScanMetadataInput
+		if err := json.Unmarshal(input, &i); err != nil {
+			return nil, err
+		}
+		return s.newScanJob(i), nil
+	case autoTagJobType:
+		var i models.AutoTagMetadataInput
+		if err := json.Unmarshal(input, &i); err != nil {
+			return nil, err
+		}
+		return s.newAutoTagJob(i), nil
+	case generateJobType:
+		var i models.GenerateMetadataInput
+		if err := json.Unmarshal(input, &i); err != nil {
+			return nil, err
+		}
+		return s.newGenerateJob(i), nil
+	case cleanJobType:
+		var i models.CleanMetadataInput
+		if err := json.Unmarshal(input, &i); err != nil {
+			return nil, err
+		}
+		return s.newCleanJob(i), nil
+	case identifyJobType:
+		var i models.IdentifyMetadataInput
+		if err := json.Unmarshal(input, &i); err != nil {
+			return nil, err
+		}
+		return CreateIdentifyJob(i), nil
+	case resolveDuplicatesJobType:
+		var i models.ResolveDuplicatesMetadataInput
+		if err := json.Unmarshal(input, &i); err != nil {
+			return nil, err
+		}
+		return s.newResolveDuplicatesJob(i), nil
+	}
+
+	return nil, fmt.Errorf("unknown job type %q", jobType)
+}
+
+// jobStore stores the job history in the database.
+type jobStore struct {
+	txnManager models.TransactionManager
+}
+
+func (s jobStore) Save(j job.Job) error {
+	// the database is unavailable while migrating
+	if err := database.Ready(); err != nil {
+		return err
+	}
+
+	return s.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		return r.JobRecord().Save(JobToRecord(j))
+	})
+}
+
+func (s jobStore) FindUnfinished() ([]job.Job, error) {
+	var ret []job.Job
+	if err := s.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		records, err := r.JobRecord().FindUnfinished()
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			ret = append(ret, JobFromRecord(record))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (s jobStore) MaxID() (ret int, err error) {
+	err = s.txnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		ret, err = r.JobRecord().MaxID()
+		return err
+	})
+
+	return ret, err
+}
+
+func (s jobStore) Prune(keep int) error {
+	// the database is unavailable while migrating
+	if err := database.Ready(); err != nil {
+		return err
+	}
+
+	return s.txnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		return r.JobRecord().Prune(keep)
+	})
+}
+
+// JobToRecord converts a job to its stored representation.
+func JobToRecord(j job.Job) models.JobRecord {
+	ret := models.JobRecord{
+		ID:          j.ID,
+		Status:      models.JobStatus(j.Status),
+		Description: j.Description,
+		AddTime:     models.SQLiteTimestamp{Timestamp: j.AddTime},
+		Lane:        sql.NullString{String: string(j.Lane), Valid: j.Lane != ""},
+		Priority:    j.Priority,
+	}
+
+	if j.IsRequeueable() {
+		ret.Type = sql.NullString{String: j.Type, Valid: true}
+		ret.Input = sql.NullString{String: string(j.Input), Valid: true}
+	}
+	if j.Progress != job.ProgressIndefinite {
+		ret.Progress = sql.NullFloat64{Float64: j.Progress, Valid: true}
+	}
+	if j.Error != nil {
+		ret.Error = sql.NullString{String: *j.Error, Valid: true}
+	}
+	if len(j.Issues) > 0 {
+		issues, err := json.Marshal(j.Issues)
+		if err != nil {
+			logger.Warnf("error encoding issues of job %d: %v", j.ID, err)
+		} else {
+			ret.Issues = sql.NullString{String: string(issues), Valid: true}
+		}
+		ret.IssueCount = j.IssueCount
+	}
+	if j.StartTime != nil {
+		ret.StartTime = models.NullSQLiteTimestamp{Timestamp: *j.StartTime, Valid: true}
+	}
+	if j.EndTime != nil {
+		ret.EndTime = models.NullSQLiteTimestamp{Timestamp: *j.EndTime, Valid: true}
+	}
+
+	return ret
+}
+
+// JobFromRecord converts a stored job to a job.
+func JobFromRecord(r *models.JobRecord) job.Job {
+	ret := job.Job{
+		ID:          r.ID,
+		Status:      job.Status(r.Status),
+		Description: r.Description,
+		Progress:    job.ProgressIndefinite,
+		AddTime:     r.AddTime.Timestamp,
+		Type:        r.Type.String,
+		IssueCount:  r.IssueCount,
+		Lane:        job.LaneDefault,
+		Priority:    r.Priority,
+	}
+
+	if r.Lane.Valid {
+		ret.Lane = job.Lane(r.Lane.String)
+	}
+
+	if r.Input.Valid {
+		ret.Input = json.RawMessage(r.Input.String)
+	}
+	if r.Progress.Valid {
+		ret.Progress = r.Progress.Float64
+	}
+	if r.Error.Valid {
+		ret.Error = &r.Error.String
+	}
+	if r.Issues.Valid {
+		if err := json.Unmarshal([]byte(r.Issues.String), &ret.Issues); err != nil {
+			logger.Warnf("error decoding issues of job %d: %v", r.ID, err)
+		}
+	}
+	if r.StartTime.Valid {
+		ret.StartTime = &r.StartTime.Timestamp
+	}
+	if r.EndTime.Valid {
+		ret.EndTime = &r.EndTime.Timestamp
+	}
+
+	return ret
+}
+
+// restoreJobs persists the job queue and requeues the jobs interrupted by
+// the last shutdown.
+func (s *Manager) restoreJobs() {
+	store := jobStore{txnManager: s.TxnManager}
+	if err := s.JobManager.Restore(store, s.createJobExec); err != nil {
+		logger.Errorf("error restoring job queue: %v", err)
+	}
+}
+
+// FindJob returns the job with the provided id from the job queue or the job
+// history. Returns nil if the job does not exist.
+func (s *Manager) FindJob(ctx context.Context, id int) (*job.Job, error) {
+	if j := s.JobManager.GetJob(id); j != nil {
+		return j, nil
+	}
+
+	var record *models.JobRecord
+	if err := s.TxnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		var err error
+		record, err = r.JobRecord().Find(id)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if record == nil {
+		return nil, nil
+	}
+
+	ret := JobFromRecord(record)
+	return &ret, nil
+}
+
+// RequeueJob queues a new job with the input of the job with the provided
+// id, which may be in the job history.
+func (s *Manager) RequeueJob(ctx context.Context, id int) (int, error) {
+	j, err := s.FindJob(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if j == nil {
+		return 0, fmt.Errorf("job with id %d not found", id)
+	}
+
+	return s.JobManager.Requeue(context.Background(), *j)
+}
@@ -53,9 +53,17 @@ type Manager struct {
 
 	DLNAService *dlna.Service
 
+	HLSStreamManager *HLSStreamManager
+
 	TxnManager models.TransactionManager
 
+	Scheduler *Scheduler
+
 	scanSubs *subscriptionManager
+
+	fileWatcher         *fileWatcher
+	fileWatcherSettings *fileWatcherSettings
+	fileWatcherMutex    sync.Mutex
 }
 
 var instance *Manager
@@ -95,12 +103,16 @@ func initialize() error {
 		DownloadStore:   NewDownloadStore(),
 		PluginCache:     plugin.NewCache(cfg),
 
+		HLSStreamManager: NewHLSStreamManager(),
+
 		TxnManager: sqlite.NewTransactionManager(),
 
 		scanSubs: &subscriptionManager{},
 	}
 
 	instance.JobManager = initJobManager()
+	instance.Scheduler = newScheduler(instance)
+	instance.Scheduler.start()
 
 	sceneServer := SceneServer{
 		TXNManager: instance.TxnManager,
@@ -129,7 +141,7 @@ func initialize() error {
 
 		// create temporary session store - this will be re-initialised
 		// after config is complete
-		instance.SessionStore = session.NewStore(cfg)
+		instance.SessionStore = session.NewStore(cfg, userFinder{txnManager: instance.TxnManager})
 
 		logger.Warnf("config file %snot found. Assuming new system...", cfgFile)
 	}
@@ -151,6 +163,13 @@ func initialize() error {
 func initJobManager() *job.Manager {
 	ret := job.NewManager()
 
+	// jobs are not restricted to the content visible to the user who
+	// started them, and do not change the data of the user
+	ret.SetContextFunc(func(ctx context.Context) context.Context {
+		ctx = models.SetContentRestriction(ctx, nil)
+		return session.SetCurrentUser(ctx, nil)
+	})
+
 	// desktop notifications
 	ctx := context.Background()
 	c := ret.Subscribe(context.Background())
@@ -261,7 +280,7 @@ func (s *Manager) PostInit(ctx context.Context) error {
 
 	s.Paths = paths.NewPaths(s.Config.GetGeneratedPath())
 	s.RefreshConfig()
-	s.SessionStore = session.NewStore(s.Config)
+	s.SessionStore = session.NewStore(s.Config, userFinder{txnManager: s.TxnManager})
 	s.PluginCache.RegisterSessionStore(s.SessionStore)
 
 	if err := s.PluginCache.LoadPlugins(); err != nil {
@@ -283,6 +302,9 @@ func (s *Manager) PostInit(ctx context.Context) error {
 			if err := fsutil.EmptyDir(instance.Paths.Generated.Tmp); err != nil {
 				logger.Warnf("could not empty Tmp directory: %v", err)
 			}
+			if err := fsutil.RemoveDir(instance.Paths.Generated.HLS); err != nil {
+				logger.Warnf("could not remove HLS directory: %v", err)
+			}
 		}, deleteTimeout, func(done chan struct{}) {
 			logger.Info("Please wait. Deleting temporary files...") // print
 			<-done                                                  // and wait for deletion
@@ -347,6 +369,9 @@ func (s *Manager) RefreshConfig() {
 			logger.Warnf("could not create directory for Interactive Heatmaps: %v", err)
 		}
 	}
+
+	s.RefreshFileWatcher()
+	s.RefreshJobLanes()
 }
 
 // RefreshScraperCache refreshes the scraper cache. Call this when scraper
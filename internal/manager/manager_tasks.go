@@ -67,13 +67,15 @@ func (s *Manager) Scan(ctx context.Context, input models.ScanMetadataInput) (int
 		return 0, err
 	}
 
-	scanJob := ScanJob{
+	return s.JobManager.Add(ctx, "Scanning...", s.newScanJob(input)), nil
+}
+
+func (s *Manager) newScanJob(input models.ScanMetadataInput) *ScanJob {
+	return &ScanJob{
 		txnManager:    s.TxnManager,
 		input:         input,
 		subscriptions: s.scanSubs,
 	}
-
-	return s.JobManager.Add(ctx, "Scanning...", &scanJob), nil
 }
 
 func (s *Manager) Import(ctx context.Context) (int, error) {
@@ -139,12 +141,14 @@ func (s *Manager) Generate(ctx context.Context, input models.GenerateMetadataInp
 		logger.Warnf("could not generate temporary directory: %v", err)
 	}
 
-	j := &GenerateJob{
+	return s.JobManager.Add(ctx, "Generating...", s.newGenerateJob(input)), nil
+}
+
+func (s *Manager) newGenerateJob(input models.GenerateMetadataInput) *GenerateJob {
+	return &GenerateJob{
 		txnManager: s.TxnManager,
 		input:      input,
 	}
-
-	return s.JobManager.Add(ctx, "Generating...", j), nil
 }
 
 func (s *Manager) GenerateDefaultScreenshot(ctx context.Context, sceneId string) int {
@@ -190,26 +194,47 @@ func (s *Manager) generateScreenshot(ctx context.Context, sceneId string, at *fl
 		logger.Infof("Generate screenshot finished")
 	})
 
-	return s.JobManager.Add(ctx, fmt.Sprintf("Generating screenshot for scene id %s", sceneId), j)
+	return s.JobManager.Add(ctx, fmt.Sprintf("Generating screenshot for scene id %s", sceneId), job.WithLane(j, job.LaneCPU))
 }
 
 func (s *Manager) AutoTag(ctx context.Context, input models.AutoTagMetadataInput) int {
-	j := autoTagJob{
+	return s.JobManager.Add(ctx, "Auto-tagging...", s.newAutoTagJob(input))
+}
+
+func (s *Manager) newAutoTagJob(input models.AutoTagMetadataInput) *autoTagJob {
+	return &autoTagJob{
 		txnManager: s.TxnManager,
 		input:      input,
 	}
-
-	return s.JobManager.Add(ctx, "Auto-tagging...", &j)
 }
 
 func (s *Manager) Clean(ctx context.Context, input models.CleanMetadataInput) int {
-	j := cleanJob{
+	return s.JobManager.Add(ctx, "Cleaning...", s.newCleanJob(input))
+}
+
+func (s *Manager) newCleanJob(input models.CleanMetadataInput) *cleanJob {
+	return &cleanJob{
 		txnManager: s.TxnManager,
 		input:      input,
 		scanSubs:   s.scanSubs,
 	}
+}
 
-	return s.JobManager.Add(ctx, "Cleaning...", &j)
+// ResolveDuplicates queues a job merging each group of duplicate scenes into
+// the scene chosen by the rules of the input.
+func (s *Manager) ResolveDuplicates(ctx context.Context, input models.ResolveDuplicatesMetadataInput) (int, error) {
+	if len(input.Rules) == 0 {
+		return 0, errors.New("at least one rule is required")
+	}
+
+	return s.JobManager.Add(ctx, "Resolving duplicate scenes...", s.newResolveDuplicatesJob(input)), nil
+}
+
+func (s *Manager) newResolveDuplicatesJob(input models.ResolveDuplicatesMetadataInput) *resolveDuplicatesJob {
+	return &resolveDuplicatesJob{
+		txnManager: s.TxnManager,
+		input:      input,
+	}
 }
 
 func (s *Manager) MigrateHash(ctx context.Context) int {
@@ -369,5 +394,5 @@ func (s *Manager) StashBoxBatchPerformerTag(ctx context.Context, input models.St
 		}
 	})
 
-	return s.JobManager.Add(ctx, "Batch stash-box performer tag...", j)
+	return s.JobManager.Add(ctx, "Batch stash-box performer tag...", job.WithLane(j, job.LaneNetwork))
 }
@@ -47,6 +47,9 @@ func KillRunningStreams(scene *models.Scene, fileNamingAlgo models.HashAlgorithm
 
 	transcodePath := GetInstance().Paths.Scene.GetTranscodePath(sceneHash)
 	instance.ReadLockManager.Cancel(transcodePath)
+
+	// stop HLS sessions and remove their cached segments
+	instance.HLSStreamManager.Stop(sceneHash)
 }
 
 type SceneServer struct {
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/stashapp/stash/internal/manager/config"
 	"github.com/stashapp/stash/pkg/ffmpeg"
@@ -66,10 +67,12 @@ func GetSceneStreamPaths(scene *models.Scene, directStreamURL string, maxStreami
 	var ret []*models.SceneStreamEndpoint
 	mimeWebm := ffmpeg.MimeWebm
 	mimeHLS := ffmpeg.MimeHLS
+	mimeDASH := ffmpeg.MimeDASH
 	mimeMp4 := ffmpeg.MimeMp4
 
 	labelWebm := "webm"
 	labelHLS := "HLS"
+	labelDASH := "DASH"
 
 	// direct stream should only apply when the audio codec is supported
 	audioCodec := ffmpeg.MissingUnsupported
@@ -166,9 +169,41 @@ func GetSceneStreamPaths(scene *models.Scene, directStreamURL string, maxStreami
 	}
 	ret = append(ret, &hls)
 
+	dash := models.SceneStreamEndpoint{
+		URL:      directStreamURL + ".mpd",
+		MimeType: &mimeDASH,
+		Label:    &labelDASH,
+	}
+	ret = append(ret, &dash)
+
+	ret = append(ret, getTranscodeProfileStreamPaths(directStreamURL)...)
+
 	return ret, nil
 }
 
+// getTranscodeProfileStreamPaths returns a stream endpoint for each
+// configured transcode profile.
+func getTranscodeProfileStreamPaths(directStreamURL string) []*models.SceneStreamEndpoint {
+	var ret []*models.SceneStreamEndpoint
+	for _, p := range config.GetInstance().GetTranscodeProfiles() {
+		container := ffmpeg.TranscodeContainer(p.Container)
+		mimeType := container.MimeType()
+		if container == ffmpeg.TranscodeContainerMkv {
+			// set mkv to mp4 to trick the client, since many clients won't try mkv
+			mimeType = ffmpeg.MimeMp4
+		}
+
+		label := p.Name
+		ret = append(ret, &models.SceneStreamEndpoint{
+			URL:      fmt.Sprintf("%s.%s?profile=%s", directStreamURL, container, url.QueryEscape(p.Name)),
+			MimeType: &mimeType,
+			Label:    &label,
+		})
+	}
+
+	return ret
+}
+
 // HasTranscode returns true if a transcoded video exists for the provided
 // scene. It will check using the OSHash of the scene first, then fall back
 // to the checksum.
@@ -0,0 +1,330 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/database"
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const (
+	schedulerInterval = 30 * time.Second
+
+	// minScheduleInterval is the minimum number of seconds between the runs
+	// of an interval schedule.
+	minScheduleInterval = 60
+)
+
+var ErrScheduleRunning = errors.New("schedule is already running")
+
+// ValidateSchedule returns an error if the schedule is not runnable.
+func ValidateSchedule(s models.Schedule) error {
+	if !s.Task.IsValid() {
+		return fmt.Errorf("invalid task %q", s.Task)
+	}
+
+	switch {
+	case s.Cron.Valid && s.Interval.Valid:
+		return errors.New("only one of cron and interval may be set")
+	case s.Cron.Valid:
+		if _, err := job.ParseCron(s.Cron.String); err != nil {
+			return err
+		}
+	case s.Interval.Valid:
+		if s.Interval.Int64 < minScheduleInterval {
+			return fmt.Errorf("interval must be at least %d seconds", minScheduleInterval)
+		}
+	default:
+		return errors.New("one of cron or interval must be set")
+	}
+
+	if s.Task == models.ScheduledTaskPlugin && (s.PluginID.String == "" || s.PluginTaskName.String == "") {
+		return errors.New("plugin_id and plugin_task_name are required for plugin schedules")
+	}
+
+	return nil
+}
+
+// GetNextScheduleRun returns the time the schedule is next due to run.
+// Returns nil if the schedule is disabled or will never run.
+func GetNextScheduleRun(s *models.Schedule) *time.Time {
+	if !s.Enabled {
+		return nil
+	}
+
+	base := s.CreatedAt.Timestamp
+	if s.LastRunAt.Valid {
+		base = s.LastRunAt.Timestamp
+	}
+
+	var ret time.Time
+	switch {
+	case s.Cron.Valid:
+		cron, err := job.ParseCron(s.Cron.String)
+		if err != nil {
+			return nil
+		}
+		ret = cron.Next(base.Local())
+	case s.Interval.Valid && s.Interval.Int64 > 0:
+		ret = base.Add(time.Duration(s.Interval.Int64) * time.Second)
+	}
+
+	if ret.IsZero() {
+		return nil
+	}
+
+	return &ret
+}
+
+// Scheduler runs the enabled schedules when they are due. A schedule is
+// not run again until its previous job has been removed from the job queue.
+type Scheduler struct {
+	manager *Manager
+
+	mutex   sync.Mutex
+	running map[int]bool
+}
+
+func newScheduler(manager *Manager) *Scheduler {
+	return &Scheduler{
+		manager: manager,
+		running: make(map[int]bool),
+	}
+}
+
+func (s *Scheduler) start() {
+	go func() {
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			s.runDue(now)
+		}
+	}()
+}
+
+func (s *Scheduler) runDue(now time.Time) {
+	// the database is not available until setup and migration is complete
+	if database.Ready() != nil {
+		return
+	}
+
+	var schedules []*models.Schedule
+	if err := s.manager.TxnManager.WithReadTxn(context.TODO(), func(r models.ReaderRepository) error {
+		var err error
+		schedules, err = r.Schedule().All()
+		return err
+	}); err != nil {
+		logger.Errorf("error getting schedules: %v", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		next := GetNextScheduleRun(schedule)
+		if next == nil || next.After(now) {
+			continue
+		}
+
+		logger.Infof("Running schedule %q", schedule.Name)
+		if _, err := s.run(schedule); err != nil && !errors.Is(err, ErrScheduleRunning) {
+			logger.Errorf("error running schedule %q: %v", schedule.Name, err)
+		}
+	}
+}
+
+// Run runs the schedule with the provided id immediately, returning the id
+// of the queued job.
+func (s *Scheduler) Run(ctx context.Context, id int) (int, error) {
+	var schedule *models.Schedule
+	if err := s.manager.TxnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		var err error
+		schedule, err = r.Schedule().Find(id)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	if schedule == nil {
+		return 0, fmt.Errorf("schedule with id %d not found", id)
+	}
+
+	return s.run(schedule)
+}
+
+func (s *Scheduler) setRunning(id int, running bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if running && s.running[id] {
+		return false
+	}
+
+	if running {
+		s.running[id] = true
+	} else {
+		delete(s.running, id)
+	}
+
+	return true
+}
+
+func (s *Scheduler) run(schedule *models.Schedule) (int, error) {
+	if !s.setRunning(schedule.ID, true) {
+		return 0, ErrScheduleRunning
+	}
+
+	// subscribe before queuing so that the job is not removed unseen
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := s.manager.JobManager.Subscribe(ctx)
+
+	jobID, err := s.queueTask(schedule)
+
+	s.recordRun(schedule.ID, jobID, err)
+
+	if err != nil {
+		cancel()
+		s.setRunning(schedule.ID, false)
+		return 0, err
+	}
+
+	go func() {
+		defer s.setRunning(schedule.ID, false)
+		defer cancel()
+
+		for j := range sub.RemovedJob {
+			if j.ID != jobID {
+				continue
+			}
+
+			result := models.ScheduleRunResultFinished
+			switch j.Status {
+			case job.StatusCancelled:
+				result = models.ScheduleRunResultCancelled
+			case job.StatusFailed:
+				result = models.ScheduleRunResultFailed
+			}
+
+			s.recordResult(schedule.ID, result, j.Error)
+			return
+		}
+	}()
+
+	return jobID, nil
+}
+
+// recordRun sets the last run of the schedule, setting the result to failed
+// if the task could not be queued.
+func (s *Scheduler) recordRun(id int, jobID int, runErr error) {
+	s.updateSchedule(id, func(schedule *models.Schedule) {
+		schedule.LastRunAt = models.NullSQLiteTimestamp{Timestamp: time.Now(), Valid: true}
+		schedule.LastJobID = sql.NullInt64{Int64: int64(jobID), Valid: runErr == nil}
+		schedule.LastResult = sql.NullString{}
+		schedule.LastError = sql.NullString{}
+
+		if runErr != nil {
+			schedule.LastResult = sql.NullString{String: models.ScheduleRunResultFailed.String(), Valid: true}
+			schedule.LastError = sql.NullString{String: runErr.Error(), Valid: true}
+		}
+	})
+}
+
+func (s *Scheduler) recordResult(id int, result models.ScheduleRunResult, jobErr *string) {
+	s.updateSchedule(id, func(schedule *models.Schedule) {
+		schedule.LastResult = sql.NullString{String: result.String(), Valid: true}
+		if jobErr != nil {
+			schedule.LastError = sql.NullString{String: *jobErr, Valid: true}
+		}
+	})
+}
+
+func (s *Scheduler) updateSchedule(id int, fn func(schedule *models.Schedule)) {
+	if err := s.manager.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+		qb := r.Schedule()
+		schedule, err := qb.Find(id)
+		if err != nil {
+			return err
+		}
+
+		// schedule may have been destroyed while running
+		if schedule == nil {
+			return nil
+		}
+
+		fn(schedule)
+		_, err = qb.Update(*schedule)
+		return err
+	}); err != nil {
+		logger.Errorf("error updating schedule: %v", err)
+	}
+}
+
+// queueTask queues the task of the schedule using the default task settings.
+func (s *Scheduler) queueTask(schedule *models.Schedule) (int, error) {
+	m := s.manager
+	cfg := m.Config
+
+	// tasks must not be cancelled with the caller
+	ctx := context.Background()
+
+	switch schedule.Task {
+	case models.ScheduledTaskScan:
+		return m.Scan(ctx, getDefaultScanInput(nil))
+	case models.ScheduledTaskAutoTag:
+		input := models.AutoTagMetadataInput{}
+		if err := convertTaskOptions(cfg.GetDefaultAutoTagSettings(), &input); err != nil {
+			return 0, err
+		}
+		return m.AutoTag(ctx, input), nil
+	case models.ScheduledTaskGenerate:
+		input := models.GenerateMetadataInput{}
+		if err := convertTaskOptions(cfg.GetDefaultGenerateSettings(), &input); err != nil {
+			return 0, err
+		}
+		return m.Generate(ctx, input)
+	case models.ScheduledTaskIDEntify:
+		d := cfg.GetDefaultIdentifySettings()
+		if d == nil || len(d.Sources) == 0 {
+			return 0, errors.New("default identify settings have no sources")
+		}
+
+		input := models.IdentifyMetadataInput{}
+		if err := convertTaskOptions(d, &input); err != nil {
+			return 0, err
+		}
+		return m.JobManager.Add(ctx, "Identifying...", CreateIdentifyJob(input)), nil
+	case models.ScheduledTaskClean:
+		return m.Clean(ctx, models.CleanMetadataInput{}), nil
+	case models.ScheduledTaskBackup:
+		j := job.MakeJobExec(func(ctx context.Context, progress *job.Progress) {
+			if err := database.Backup(database.DB, database.DatabaseBackupPath()); err != nil {
+				logger.Errorf("error backing up database: %v", err)
+				progress.SetError(err)
+			}
+		})
+		return m.JobManager.Add(ctx, "Backing up database...", j), nil
+	case models.ScheduledTaskPlugin:
+		return m.RunPluginTask(ctx, schedule.PluginID.String, schedule.PluginTaskName.String, nil), nil
+	}
+
+	return 0, fmt.Errorf("invalid task %q", schedule.Task)
+}
+
+// convertTaskOptions converts the default task settings to the task input.
+// The settings and input types have matching fields.
+func convertTaskOptions(options interface{}, input interface{}) error {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	// null settings leave the input unchanged
+	return json.Unmarshal(data, input)
+}
@@ -0,0 +1,94 @@
+package manager
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	cron := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	interval := func(i int64) sql.NullInt64 { return sql.NullInt64{Int64: i, Valid: true} }
+
+	tests := []struct {
+		name     string
+		schedule models.Schedule
+		wantErr  bool
+	}{
+		{"cron", models.Schedule{Task: models.ScheduledTaskScan, Cron: cron("@daily")}, false},
+		{"interval", models.Schedule{Task: models.ScheduledTaskClean, Interval: interval(3600)}, false},
+		{"neither", models.Schedule{Task: models.ScheduledTaskScan}, true},
+		{"both", models.Schedule{Task: models.ScheduledTaskScan, Cron: cron("@daily"), Interval: interval(3600)}, true},
+		{"invalid cron", models.Schedule{Task: models.ScheduledTaskScan, Cron: cron("* *")}, true},
+		{"short interval", models.Schedule{Task: models.ScheduledTaskScan, Interval: interval(10)}, true},
+		{"invalid task", models.Schedule{Task: "INVALID", Cron: cron("@daily")}, true},
+		{"plugin without task", models.Schedule{Task: models.ScheduledTaskPlugin, Cron: cron("@daily"), PluginID: cron("plugin")}, true},
+		{"plugin", models.Schedule{Task: models.ScheduledTaskPlugin, Cron: cron("@daily"), PluginID: cron("plugin"), PluginTaskName: cron("task")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSchedule(tt.schedule); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetNextScheduleRun(t *testing.T) {
+	created := time.Date(2022, time.March, 15, 10, 30, 0, 0, time.Local)
+	lastRun := time.Date(2022, time.March, 16, 3, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		schedule models.Schedule
+		want     *time.Time
+	}{
+		{
+			"disabled",
+			models.Schedule{Cron: sql.NullString{String: "@daily", Valid: true}},
+			nil,
+		},
+		{
+			"interval never run",
+			models.Schedule{Enabled: true, Interval: sql.NullInt64{Int64: 3600, Valid: true}},
+			timePtr(created.Add(time.Hour)),
+		},
+		{
+			"interval after last run",
+			models.Schedule{
+				Enabled:   true,
+				Interval:  sql.NullInt64{Int64: 3600, Valid: true},
+				LastRunAt: models.NullSQLiteTimestamp{Timestamp: lastRun, Valid: true},
+			},
+			timePtr(lastRun.Add(time.Hour)),
+		},
+		{
+			"cron after last run",
+			models.Schedule{
+				Enabled:   true,
+				Cron:      sql.NullString{String: "0 3 * * *", Valid: true},
+				LastRunAt: models.NullSQLiteTimestamp{Timestamp: lastRun, Valid: true},
+			},
+			timePtr(lastRun.AddDate(0, 0, 1)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.schedule.CreatedAt = models.SQLiteTimestamp{Timestamp: created}
+			got := GetNextScheduleRun(&tt.schedule)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || !got.Equal(*tt.want):
+				t.Errorf("GetNextScheduleRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
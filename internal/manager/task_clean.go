@@ -100,6 +100,7 @@ func (j *cleanJob) processScenes(ctx context.Context, progress *job.Progress, qb
 	findFilter.Sort = &sort
 
 	var toDelete []int
+	var filesToDelete []int
 
 	more := true
 	for more {
@@ -114,9 +115,28 @@ func (j *cleanJob) processScenes(ctx context.Context, progress *job.Progress, qb
 
 		for _, scene := range scenes {
 			progress.ExecuteTask(fmt.Sprintf("Assessing scene %s for clean", scene.Path), func() {
-				if j.shouldCleanScene(scene) {
+				files, err := qb.GetFiles(scene.ID)
+				if err != nil {
+					logger.Errorf("Error getting files for scene %s: %v", scene.Path, err)
+					progress.Increment()
+					return
+				}
+
+				var missing []int
+				for _, f := range files {
+					if j.shouldCleanSceneFile(f.Path) {
+						missing = append(missing, f.ID)
+					}
+				}
+
+				switch {
+				case len(missing) == len(files):
 					toDelete = append(toDelete, scene.ID)
-				} else {
+				case len(missing) > 0:
+					// only remove the missing files, keeping the scene
+					filesToDelete = append(filesToDelete, missing...)
+					progress.Increment()
+				default:
 					// increment progress, no further processing
 					progress.Increment()
 				}
@@ -137,6 +157,18 @@ func (j *cleanJob) processScenes(ctx context.Context, progress *job.Progress, qb
 
 	fileNamingAlgorithm := instance.Config.GetVideoFileNamingAlgorithm()
 
+	if !j.input.DryRun && len(filesToDelete) > 0 {
+		progress.ExecuteTask(fmt.Sprintf("Cleaning %d scene files", len(filesToDelete)), func() {
+			for _, fileID := range filesToDelete {
+				if job.IsCancelled(ctx) {
+					return
+				}
+
+				j.deleteSceneFile(ctx, fileNamingAlgorithm, fileID)
+			}
+		})
+	}
+
 	if !j.input.DryRun && len(toDelete) > 0 {
 		progress.ExecuteTask(fmt.Sprintf("Cleaning %d scenes", len(toDelete)), func() {
 			for _, sceneID := range toDelete {
@@ -293,25 +325,25 @@ func (j *cleanJob) shouldClean(path string) bool {
 	return false
 }
 
-func (j *cleanJob) shouldCleanScene(s *models.Scene) bool {
-	if j.shouldClean(s.Path) {
+func (j *cleanJob) shouldCleanSceneFile(path string) bool {
+	if j.shouldClean(path) {
 		return true
 	}
 
-	stash := getStashFromPath(s.Path)
+	stash := getStashFromPath(path)
 	if stash.ExcludeVideo {
-		logger.Infof("File in stash library that excludes video. Marking to clean: \"%s\"", s.Path)
+		logger.Infof("File in stash library that excludes video. Marking to clean: \"%s\"", path)
 		return true
 	}
 
 	config := config.GetInstance()
-	if !fsutil.MatchExtension(s.Path, config.GetVideoExtensions()) {
-		logger.Infof("File extension does not match video extensions. Marking to clean: \"%s\"", s.Path)
+	if !fsutil.MatchExtension(path, config.GetVideoExtensions()) {
+		logger.Infof("File extension does not match video extensions. Marking to clean: \"%s\"", path)
 		return true
 	}
 
-	if matchFile(s.Path, config.GetExcludes()) {
-		logger.Infof("File matched regex. Marking to clean: \"%s\"", s.Path)
+	if matchFile(path, config.GetExcludes()) {
+		logger.Infof("File matched regex. Marking to clean: \"%s\"", path)
 		return true
 	}
 
@@ -428,6 +460,52 @@ func (j *cleanJob) deleteScene(ctx context.Context, fileNamingAlgorithm models.H
 	}, nil)
 }
 
+func (j *cleanJob) deleteSceneFile(ctx context.Context, fileNamingAlgorithm models.HashAlgorithm, fileID int) {
+	var f *models.SceneFile
+	var oldHash, newHash string
+	if err := j.txnManager.WithTxn(ctx, func(repo models.Repository) error {
+		qb := repo.Scene()
+
+		var err error
+		f, err = qb.FindFile(fileID)
+		if err != nil {
+			return err
+		}
+
+		if f == nil {
+			return fmt.Errorf("scene file not found: %d", fileID)
+		}
+
+		s, err := qb.Find(f.SceneID)
+		if err != nil {
+			return err
+		}
+		oldHash = s.GetHash(fileNamingAlgorithm)
+
+		if err := qb.DestroyFile(fileID); err != nil {
+			return err
+		}
+
+		s, err = qb.Find(f.SceneID)
+		if err != nil {
+			return err
+		}
+		newHash = s.GetHash(fileNamingAlgorithm)
+
+		return nil
+	}); err != nil {
+		logger.Errorf("Error deleting scene file from database: %s", err.Error())
+		return
+	}
+
+	// the generated files belong to the new primary file
+	if oldHash != newHash {
+		scene.MigrateHash(GetInstance().Paths, oldHash, newHash)
+	}
+
+	GetInstance().PluginCache.ExecutePostHooks(ctx, f.SceneID, plugin.SceneUpdatePost, nil, nil)
+}
+
 func (j *cleanJob) deleteGallery(ctx context.Context, galleryID int) {
 	var g *models.Gallery
 
@@ -431,6 +431,12 @@ func exportScene(wg *sync.WaitGroup, jobChan <-chan *models.Scene, repo models.R
 			continue
 		}
 
+		newSceneJSON.Files, err = scene.GetSceneFilesJSON(sceneReader, s)
+		if err != nil {
+			logger.Errorf("[scenes] <%s> error getting scene files JSON: %s", sceneHash, err.Error())
+			continue
+		}
+
 		if t.includeDependencies {
 			if s.StudioID.Valid {
 				t.studios.IDs = intslice.IntAppendUnique(t.studios.IDs, int(s.StudioID.Int64))
@@ -8,6 +8,7 @@ import (
 
 	"github.com/remeh/sizedwaitgroup"
 	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/image"
 	"github.com/stashapp/stash/pkg/job"
 	"github.com/stashapp/stash/pkg/logger"
 	"github.com/stashapp/stash/pkg/models"
@@ -34,6 +35,7 @@ type totalsGenerate struct {
 	markers                  int64
 	transcodes               int64
 	phashes                  int64
+	imagePhashes             int64
 	interactiveHeatmapSpeeds int64
 
 	tasks int
@@ -80,6 +82,10 @@ func (j *GenerateJob) Execute(ctx context.Context, progress *job.Progress) {
 			qb := r.Scene()
 			if len(j.input.SceneIDs) == 0 && len(j.input.MarkerIDs) == 0 {
 				totals = j.queueTasks(ctx, g, queue)
+
+				if utils.IsTrue(j.input.ImagePhashes) {
+					j.queueImagePhashTasks(ctx, queue, &totals)
+				}
 			} else {
 				if len(j.input.SceneIDs) > 0 {
 					scenes, err = qb.FindMany(sceneIDs)
@@ -105,7 +111,7 @@ func (j *GenerateJob) Execute(ctx context.Context, progress *job.Progress) {
 			return
 		}
 
-		logger.Infof("Generating %d sprites %d previews %d image previews %d markers %d transcodes %d phashes %d heatmaps & speeds", totals.sprites, totals.previews, totals.imagePreviews, totals.markers, totals.transcodes, totals.phashes, totals.interactiveHeatmapSpeeds)
+		logger.Infof("Generating %d sprites %d previews %d image previews %d markers %d transcodes %d phashes %d image phashes %d heatmaps & speeds", totals.sprites, totals.previews, totals.imagePreviews, totals.markers, totals.transcodes, totals.phashes, totals.imagePhashes, totals.interactiveHeatmapSpeeds)
 
 		progress.SetTotal(int(totals.tasks))
 	}()
@@ -194,6 +200,55 @@ func (j *GenerateJob) queueTasks(ctx context.Context, g *generate.Generator, que
 	return totals
 }
 
+func (j *GenerateJob) queueImagePhashTasks(ctx context.Context, queue chan<- Task, totals *totalsGenerate) {
+	const batchSize = 1000
+
+	findFilter := models.BatchFindFilter(batchSize)
+
+	if err := j.txnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		for more := true; more; {
+			if job.IsCancelled(ctx) {
+				return context.Canceled
+			}
+
+			images, err := image.Query(r.Image(), nil, findFilter)
+			if err != nil {
+				return err
+			}
+
+			for _, i := range images {
+				if job.IsCancelled(ctx) {
+					return context.Canceled
+				}
+
+				task := &GenerateImagePhashTask{
+					Image:      *i,
+					Overwrite:  j.overwrite,
+					txnManager: j.txnManager,
+				}
+
+				if task.shouldGenerate() {
+					totals.imagePhashes++
+					totals.tasks++
+					queue <- task
+				}
+			}
+
+			if len(images) != batchSize {
+				more = false
+			} else {
+				*findFilter.Page++
+			}
+		}
+
+		return nil
+	}); err != nil {
+		if !errors.Is(err, context.Canceled) {
+			logger.Errorf("Error encountered queuing images: %s", err.Error())
+		}
+	}
+}
+
 func getGeneratePreviewOptions(optionsInput models.GeneratePreviewOptionsInput) generate.PreviewOptions {
 	config := config.GetInstance()
 
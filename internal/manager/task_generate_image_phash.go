@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/hash/imagephash"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+type GenerateImagePhashTask struct {
+	Image      models.Image
+	Overwrite  bool
+	txnManager models.TransactionManager
+}
+
+func (t *GenerateImagePhashTask) GetDescription() string {
+	return fmt.Sprintf("Generating phash for %s", t.Image.Path)
+}
+
+func (t *GenerateImagePhashTask) Start(ctx context.Context) {
+	if !t.shouldGenerate() {
+		return
+	}
+
+	hash, err := imagephash.Generate(&t.Image)
+	if err != nil {
+		logger.Errorf("error generating phash for image %s: %s", t.Image.Path, err.Error())
+		addFileError(ctx, issueStagePhash, t.Image.Path, err)
+		return
+	}
+
+	if err := t.txnManager.WithTxn(ctx, func(r models.Repository) error {
+		hashValue := sql.NullInt64{Int64: int64(*hash), Valid: true}
+		imagePartial := models.ImagePartial{
+			ID:    t.Image.ID,
+			Phash: &hashValue,
+		}
+		_, err := r.Image().Update(imagePartial)
+		return err
+	}); err != nil {
+		logger.Error(err.Error())
+		addFileError(ctx, issueStagePhash, t.Image.Path, err)
+	}
+}
+
+func (t *GenerateImagePhashTask) shouldGenerate() bool {
+	return t.Overwrite || !t.Image.Phash.Valid
+}
@@ -37,6 +37,7 @@ func (t *GenerateInteractiveHeatmapSpeedTask) Start(ctx context.Context) {
 
 	if err != nil {
 		logger.Errorf("error generating heatmap: %s", err.Error())
+		addSceneError(ctx, issueStageInteractiveHeatmap, &t.Scene, err)
 		return
 	}
 
@@ -53,6 +54,7 @@ func (t *GenerateInteractiveHeatmapSpeedTask) Start(ctx context.Context) {
 		return err
 	}); err != nil {
 		logger.Error(err.Error())
+		addSceneError(ctx, issueStageInteractiveHeatmap, &t.Scene, err)
 		return
 	}
 
@@ -66,6 +68,7 @@ func (t *GenerateInteractiveHeatmapSpeedTask) Start(ctx context.Context) {
 		return err
 	}); err != nil {
 		logger.Error(err.Error())
+		addSceneError(ctx, issueStageInteractiveHeatmap, &t.Scene, err)
 	}
 
 }
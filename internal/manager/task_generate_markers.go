@@ -60,10 +60,11 @@ func (t *GenerateMarkersTask) Start(ctx context.Context) {
 		videoFile, err := ffprobe.NewVideoFile(t.Scene.Path)
 		if err != nil {
 			logger.Errorf("error reading video file: %s", err.Error())
+			addSceneError(ctx, issueStageMarker, scene, err)
 			return
 		}
 
-		t.generateMarker(videoFile, scene, t.Marker)
+		t.generateMarker(ctx, videoFile, scene, t.Marker)
 	}
 }
 
@@ -86,6 +87,7 @@ func (t *GenerateMarkersTask) generateSceneMarkers(ctx context.Context) {
 	videoFile, err := ffprobe.NewVideoFile(t.Scene.Path)
 	if err != nil {
 		logger.Errorf("error reading video file: %s", err.Error())
+		addSceneError(ctx, issueStageMarker, t.Scene, err)
 		return
 	}
 
@@ -101,24 +103,27 @@ func (t *GenerateMarkersTask) generateSceneMarkers(ctx context.Context) {
 		index := i + 1
 		logger.Progressf("[generator] <%s> scene marker %d of %d", sceneHash, index, len(sceneMarkers))
 
-		t.generateMarker(videoFile, t.Scene, sceneMarker)
+		t.generateMarker(ctx, videoFile, t.Scene, sceneMarker)
 	}
 }
 
-func (t *GenerateMarkersTask) generateMarker(videoFile *ffmpeg.VideoFile, scene *models.Scene, sceneMarker *models.SceneMarker) {
+func (t *GenerateMarkersTask) generateMarker(ctx context.Context, videoFile *ffmpeg.VideoFile, scene *models.Scene, sceneMarker *models.SceneMarker) {
 	sceneHash := t.Scene.GetHash(t.fileNamingAlgorithm)
 	seconds := int(sceneMarker.Seconds)
+	duration := sceneMarker.Duration()
 
 	g := t.generator
 
-	if err := g.MarkerPreviewVideo(context.TODO(), videoFile.Path, sceneHash, seconds, instance.Config.GetPreviewAudio()); err != nil {
+	if err := g.MarkerPreviewVideo(context.TODO(), videoFile.Path, sceneHash, seconds, duration, instance.Config.GetPreviewAudio()); err != nil {
 		logger.Errorf("[generator] failed to generate marker video: %v", err)
+		addSceneError(ctx, issueStageMarker, scene, err)
 		logErrorOutput(err)
 	}
 
 	if t.ImagePreview {
-		if err := g.SceneMarkerWebp(context.TODO(), videoFile.Path, sceneHash, seconds); err != nil {
+		if err := g.SceneMarkerWebp(context.TODO(), videoFile.Path, sceneHash, seconds, duration); err != nil {
 			logger.Errorf("[generator] failed to generate marker image: %v", err)
+			addSceneError(ctx, issueStageMarker, scene, err)
 			logErrorOutput(err)
 		}
 	}
@@ -126,6 +131,7 @@ func (t *GenerateMarkersTask) generateMarker(videoFile *ffmpeg.VideoFile, scene
 	if t.Screenshot {
 		if err := g.SceneMarkerScreenshot(context.TODO(), videoFile.Path, sceneHash, seconds, videoFile.Width); err != nil {
 			logger.Errorf("[generator] failed to generate marker screenshot: %v", err)
+			addSceneError(ctx, issueStageMarker, scene, err)
 			logErrorOutput(err)
 		}
 	}
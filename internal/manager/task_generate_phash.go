@@ -30,12 +30,14 @@ func (t *GeneratePhashTask) Start(ctx context.Context) {
 	videoFile, err := ffprobe.NewVideoFile(t.Scene.Path)
 	if err != nil {
 		logger.Errorf("error reading video file: %s", err.Error())
+		addSceneError(ctx, issueStagePhash, &t.Scene, err)
 		return
 	}
 
 	hash, err := videophash.Generate(instance.FFMPEG, videoFile)
 	if err != nil {
 		logger.Errorf("error generating phash: %s", err.Error())
+		addSceneError(ctx, issueStagePhash, &t.Scene, err)
 		logErrorOutput(err)
 		return
 	}
@@ -51,6 +53,7 @@ func (t *GeneratePhashTask) Start(ctx context.Context) {
 		return err
 	}); err != nil {
 		logger.Error(err.Error())
+		addSceneError(ctx, issueStagePhash, &t.Scene, err)
 	}
 }
 
@@ -35,6 +35,7 @@ func (t *GeneratePreviewTask) Start(ctx context.Context) {
 	videoFile, err := ffprobe.NewVideoFile(t.Scene.Path)
 	if err != nil {
 		logger.Errorf("error reading video file: %v", err)
+		addSceneError(ctx, issueStagePreview, &t.Scene, err)
 		return
 	}
 
@@ -42,6 +43,7 @@ func (t *GeneratePreviewTask) Start(ctx context.Context) {
 
 	if err := t.generateVideo(videoChecksum, videoFile.Duration); err != nil {
 		logger.Errorf("error generating preview: %v", err)
+		addSceneError(ctx, issueStagePreview, &t.Scene, err)
 		logErrorOutput(err)
 		return
 	}
@@ -49,6 +51,7 @@ func (t *GeneratePreviewTask) Start(ctx context.Context) {
 	if t.ImagePreview {
 		if err := t.generateWebp(videoChecksum); err != nil {
 			logger.Errorf("error generating preview webp: %v", err)
+			addSceneError(ctx, issueStageImagePreview, &t.Scene, err)
 			logErrorOutput(err)
 		}
 	}
@@ -27,6 +27,7 @@ func (t *GenerateScreenshotTask) Start(ctx context.Context) {
 
 	if err != nil {
 		logger.Error(err.Error())
+		addSceneError(ctx, issueStageScreenshot, &t.Scene, err)
 		return
 	}
 
@@ -57,6 +58,7 @@ func (t *GenerateScreenshotTask) Start(ctx context.Context) {
 		At: &at,
 	}); err != nil {
 		logger.Errorf("Error generating screenshot: %v", err)
+		addSceneError(ctx, issueStageScreenshot, &t.Scene, err)
 		logErrorOutput(err)
 		return
 	}
@@ -64,6 +66,7 @@ func (t *GenerateScreenshotTask) Start(ctx context.Context) {
 	f, err := os.Open(normalPath)
 	if err != nil {
 		logger.Errorf("Error reading screenshot: %s", err.Error())
+		addSceneError(ctx, issueStageScreenshot, &t.Scene, err)
 		return
 	}
 	defer f.Close()
@@ -71,6 +74,7 @@ func (t *GenerateScreenshotTask) Start(ctx context.Context) {
 	coverImageData, err := io.ReadAll(f)
 	if err != nil {
 		logger.Errorf("Error reading screenshot: %s", err.Error())
+		addSceneError(ctx, issueStageScreenshot, &t.Scene, err)
 		return
 	}
 
@@ -100,5 +104,6 @@ func (t *GenerateScreenshotTask) Start(ctx context.Context) {
 		return nil
 	}); err != nil {
 		logger.Error(err.Error())
+		addSceneError(ctx, issueStageScreenshot, &t.Scene, err)
 	}
 }
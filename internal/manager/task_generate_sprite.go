@@ -28,6 +28,7 @@ func (t *GenerateSpriteTask) Start(ctx context.Context) {
 	videoFile, err := ffprobe.NewVideoFile(t.Scene.Path)
 	if err != nil {
 		logger.Errorf("error reading video file: %s", err.Error())
+		addSceneError(ctx, issueStageSprite, &t.Scene, err)
 		return
 	}
 
@@ -38,12 +39,14 @@ func (t *GenerateSpriteTask) Start(ctx context.Context) {
 
 	if err != nil {
 		logger.Errorf("error creating sprite generator: %s", err.Error())
+		addSceneError(ctx, issueStageSprite, &t.Scene, err)
 		return
 	}
 	generator.Overwrite = t.Overwrite
 
 	if err := generator.Generate(); err != nil {
 		logger.Errorf("error generating sprite: %s", err.Error())
+		addSceneError(ctx, issueStageSprite, &t.Scene, err)
 		logErrorOutput(err)
 		return
 	}
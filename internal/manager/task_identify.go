@@ -46,6 +46,7 @@ func (j *IdentifyJob) Execute(ctx context.Context, progress *job.Progress) {
 	sources, err := j.getSources()
 	if err != nil {
 		logger.Error(err)
+		progress.SetError(err)
 		return
 	}
 
@@ -84,6 +85,7 @@ func (j *IdentifyJob) Execute(ctx context.Context, progress *job.Progress) {
 		return nil
 	}); err != nil {
 		logger.Errorf("Error encountered while identifying scenes: %v", err)
+		progress.SetError(err)
 	}
 }
 
@@ -146,6 +148,7 @@ func (j *IdentifyJob) identifyScene(ctx context.Context, s *models.Scene, source
 
 	if taskError != nil {
 		logger.Errorf("Error encountered identifying %s: %v", s.Path, taskError)
+		addSceneError(ctx, issueStageIdentify, s, taskError)
 	}
 
 	j.progress.Increment()
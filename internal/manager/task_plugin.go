@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/stashapp/stash/pkg/job"
@@ -15,12 +16,14 @@ func (s *Manager) RunPluginTask(ctx context.Context, pluginID string, taskName s
 		task, err := s.PluginCache.CreateTask(ctx, pluginID, taskName, args, pluginProgress)
 		if err != nil {
 			logger.Errorf("Error creating plugin task: %s", err.Error())
+			progress.SetError(err)
 			return
 		}
 
 		err = task.Start()
 		if err != nil {
 			logger.Errorf("Error running plugin task: %s", err.Error())
+			progress.SetError(err)
 			return
 		}
 
@@ -35,6 +38,7 @@ func (s *Manager) RunPluginTask(ctx context.Context, pluginID string, taskName s
 			} else {
 				if output.Error != nil {
 					logger.Errorf("Plugin returned error: %s", *output.Error)
+					progress.SetError(errors.New(*output.Error))
 				} else if output.Output != nil {
 					logger.Debugf("Plugin returned: %v", output.Output)
 				}
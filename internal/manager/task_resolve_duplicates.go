@@ -0,0 +1,123 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/file"
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/plugin"
+	"github.com/stashapp/stash/pkg/scene"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// resolveDuplicatesJob merges each group of duplicate scenes into the scene
+// of the group chosen by the rules of the input.
+type resolveDuplicatesJob struct {
+	txnManager models.TransactionManager
+	input      models.ResolveDuplicatesMetadataInput
+}
+
+func (j *resolveDuplicatesJob) Execute(ctx context.Context, progress *job.Progress) {
+	logger.Infof("Starting resolution of duplicate scenes")
+	if utils.IsTrue(j.input.DryRun) {
+		logger.Infof("Running in Dry Mode")
+	}
+
+	options := models.DuplicateOptions{
+		DurationDiff: -1,
+	}
+	if j.input.Distance != nil {
+		options.Distance = *j.input.Distance
+	}
+	if j.input.DurationDiff != nil {
+		options.DurationDiff = *j.input.DurationDiff
+	}
+
+	var groups [][]*models.Scene
+	if err := j.txnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		var err error
+		groups, err = r.Scene().FindDuplicates(options)
+		return err
+	}); err != nil {
+		logger.Errorf("error finding duplicate scenes: %v", err)
+		progress.SetError(err)
+		return
+	}
+
+	progress.SetTotal(len(groups))
+
+	fileNamingAlgo := config.GetInstance().GetVideoFileNamingAlgorithm()
+	for _, group := range groups {
+		if job.IsCancelled(ctx) {
+			logger.Info("Stopping due to user request")
+			return
+		}
+
+		dest := scene.ChooseMergeDestination(group, j.input.Rules)
+		progress.ExecuteTask(fmt.Sprintf("Merging duplicates of %s", dest.Path), func() {
+			j.resolveGroup(ctx, dest, group, fileNamingAlgo)
+		})
+		progress.Increment()
+	}
+
+	logger.Info("Finished resolving duplicate scenes")
+}
+
+func (j *resolveDuplicatesJob) resolveGroup(ctx context.Context, dest *models.Scene, group []*models.Scene, fileNamingAlgo models.HashAlgorithm) {
+	var sources []*models.Scene
+	var sourceIDs []string
+	var sourcePaths []string
+	for _, s := range group {
+		if s.ID != dest.ID {
+			sources = append(sources, s)
+			sourceIDs = append(sourceIDs, strconv.Itoa(s.ID))
+			sourcePaths = append(sourcePaths, s.Path)
+		}
+	}
+
+	if utils.IsTrue(j.input.DryRun) {
+		logger.Infof("Would merge %s into %s", strings.Join(sourcePaths, ", "), dest.Path)
+		return
+	}
+
+	fileDeleter := &scene.FileDeleter{
+		Deleter:        *file.NewDeleter(),
+		FileNamingAlgo: fileNamingAlgo,
+		Paths:          instance.Paths,
+	}
+
+	options := scene.MergeOptions{
+		DeleteFiles: utils.IsTrue(j.input.DeleteFiles),
+	}
+
+	if err := j.txnManager.WithTxn(ctx, func(r models.Repository) error {
+		for _, s := range sources {
+			// kill any running encoders
+			KillRunningStreams(s, fileNamingAlgo)
+		}
+
+		_, err := scene.Merge(dest, sources, r, fileDeleter, options)
+		return err
+	}); err != nil {
+		fileDeleter.Rollback()
+		logger.Errorf("error merging duplicates of %s: %v", dest.Path, err)
+		addSceneError(ctx, issueStageMerge, dest, err)
+		return
+	}
+
+	// perform the post-commit actions
+	fileDeleter.Commit()
+
+	logger.Infof("Merged %s into %s", strings.Join(sourcePaths, ", "), dest.Path)
+	instance.PluginCache.ExecutePostHooks(ctx, dest.ID, plugin.SceneMergePost, models.ScenesMergeInput{
+		Source:      sourceIDs,
+		Destination: strconv.Itoa(dest.ID),
+		DeleteFiles: j.input.DeleteFiles,
+	}, nil)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"time"
 
 	"github.com/remeh/sizedwaitgroup"
@@ -349,14 +350,65 @@ func (t *ScanTask) Start(ctx context.Context) {
 	iwg.Wait()
 }
 
-func walkFilesToScan(s *models.StashConfig, f filepath.WalkFunc) error {
+// scanFilter determines which files in the stash paths are scanned.
+type scanFilter struct {
+	vidExt          []string
+	imgExt          []string
+	gExt            []string
+	capExt          []string
+	excludeVidRegex []*regexp.Regexp
+	excludeImgRegex []*regexp.Regexp
+	generatedPath   string
+}
+
+func newScanFilter() *scanFilter {
 	config := config.GetInstance()
-	vidExt := config.GetVideoExtensions()
-	imgExt := config.GetImageExtensions()
-	gExt := config.GetGalleryExtensions()
-	capExt := scene.CaptionExts
-	excludeVidRegex := generateRegexps(config.GetExcludes())
-	excludeImgRegex := generateRegexps(config.GetImageExcludes())
+	return &scanFilter{
+		vidExt:          config.GetVideoExtensions(),
+		imgExt:          config.GetImageExtensions(),
+		gExt:            config.GetGalleryExtensions(),
+		capExt:          scene.CaptionExts,
+		excludeVidRegex: generateRegexps(config.GetExcludes()),
+		excludeImgRegex: generateRegexps(config.GetImageExcludes()),
+		generatedPath:   config.GetGeneratedPath(),
+	}
+}
+
+// skipDir returns true if the directory at path in stash s should not be
+// scanned.
+func (f *scanFilter) skipDir(s *models.StashConfig, path string) bool {
+	// #1102 - ignore files in generated path
+	if fsutil.IsPathInDir(f.generatedPath, path) {
+		return true
+	}
+
+	// shortcut: skip the directory entirely if it matches both exclusion patterns
+	// add a trailing separator so that it correctly matches against patterns like path/.*
+	pathExcludeTest := path + string(filepath.Separator)
+	return (s.ExcludeVideo || matchFileRegex(pathExcludeTest, f.excludeVidRegex)) && (s.ExcludeImage || matchFileRegex(pathExcludeTest, f.excludeImgRegex))
+}
+
+// matches returns true if the file at path in stash s should be scanned.
+func (f *scanFilter) matches(s *models.StashConfig, path string) bool {
+	if fsutil.IsPathInDir(f.generatedPath, path) {
+		return false
+	}
+
+	if !s.ExcludeVideo && fsutil.MatchExtension(path, f.vidExt) && !matchFileRegex(path, f.excludeVidRegex) {
+		return true
+	}
+
+	if !s.ExcludeImage {
+		if (fsutil.MatchExtension(path, f.imgExt) || fsutil.MatchExtension(path, f.gExt)) && !matchFileRegex(path, f.excludeImgRegex) {
+			return true
+		}
+	}
+
+	return fsutil.MatchExtension(path, f.capExt)
+}
+
+func walkFilesToScan(s *models.StashConfig, f filepath.WalkFunc) error {
+	filter := newScanFilter()
 
 	// don't scan zip images directly
 	if file.IsZipPath(s.Path) {
@@ -364,8 +416,6 @@ func walkFilesToScan(s *models.StashConfig, f filepath.WalkFunc) error {
 		return nil
 	}
 
-	generatedPath := config.GetGeneratedPath()
-
 	return fsutil.SymWalk(s.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			logger.Warnf("error scanning %s: %s", path, err.Error())
@@ -373,32 +423,14 @@ func walkFilesToScan(s *models.StashConfig, f filepath.WalkFunc) error {
 		}
 
 		if info.IsDir() {
-			// #1102 - ignore files in generated path
-			if fsutil.IsPathInDir(generatedPath, path) {
-				return filepath.SkipDir
-			}
-
-			// shortcut: skip the directory entirely if it matches both exclusion patterns
-			// add a trailing separator so that it correctly matches against patterns like path/.*
-			pathExcludeTest := path + string(filepath.Separator)
-			if (s.ExcludeVideo || matchFileRegex(pathExcludeTest, excludeVidRegex)) && (s.ExcludeImage || matchFileRegex(pathExcludeTest, excludeImgRegex)) {
+			if filter.skipDir(s, path) {
 				return filepath.SkipDir
 			}
 
 			return nil
 		}
 
-		if !s.ExcludeVideo && fsutil.MatchExtension(path, vidExt) && !matchFileRegex(path, excludeVidRegex) {
-			return f(path, info, err)
-		}
-
-		if !s.ExcludeImage {
-			if (fsutil.MatchExtension(path, imgExt) || fsutil.MatchExtension(path, gExt)) && !matchFileRegex(path, excludeImgRegex) {
-				return f(path, info, err)
-			}
-		}
-
-		if fsutil.MatchExtension(path, capExt) {
+		if filter.matches(s, path) {
 			return f(path, info, err)
 		}
 
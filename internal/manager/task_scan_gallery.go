@@ -35,6 +35,7 @@ func (t *ScanTask) scanGallery(ctx context.Context) {
 		return err
 	}); err != nil {
 		logger.Error(err.Error())
+		addFileError(ctx, issueStageScan, t.file.Path(), err)
 		return
 	}
 
@@ -54,6 +55,7 @@ func (t *ScanTask) scanGallery(ctx context.Context) {
 		g, scanImages, err = scanner.ScanExisting(ctx, g, t.file)
 		if err != nil {
 			logger.Error(err.Error())
+			addFileError(ctx, issueStageScan, t.file.Path(), err)
 			return
 		}
 
@@ -63,6 +65,7 @@ func (t *ScanTask) scanGallery(ctx context.Context) {
 		g, scanImages, err = scanner.ScanNew(ctx, t.file)
 		if err != nil {
 			logger.Error(err.Error())
+			addFileError(ctx, issueStageScan, t.file.Path(), err)
 		}
 	}
 
@@ -164,6 +167,7 @@ func (t *ScanTask) regenerateZipImages(ctx context.Context, zipGallery *models.G
 	}
 
 	for _, img := range images {
-		t.generateThumbnail(img)
+		t.generateThumbnail(ctx, img)
+		t.generateImagePhash(ctx, img)
 	}
 }
@@ -29,6 +29,7 @@ func (t *ScanTask) scanImage(ctx context.Context) {
 		return err
 	}); err != nil {
 		logger.Error(err.Error())
+		addFileError(ctx, issueStageScan, t.file.Path(), err)
 		return
 	}
 
@@ -46,12 +47,14 @@ func (t *ScanTask) scanImage(ctx context.Context) {
 		i, err = scanner.ScanExisting(ctx, i, t.file)
 		if err != nil {
 			logger.Error(err.Error())
+			addFileError(ctx, issueStageScan, t.file.Path(), err)
 			return
 		}
 	} else {
 		i, err = scanner.ScanNew(ctx, t.file)
 		if err != nil {
 			logger.Error(err.Error())
+			addFileError(ctx, issueStageScan, t.file.Path(), err)
 			return
 		}
 
@@ -62,6 +65,7 @@ func (t *ScanTask) scanImage(ctx context.Context) {
 					return gallery.AddImage(r.Gallery(), t.zipGallery.ID, i.ID)
 				}); err != nil {
 					logger.Error(err.Error())
+					addFileError(ctx, issueStageScan, t.file.Path(), err)
 					return
 				}
 			} else if config.GetInstance().GetCreateGalleriesFromFolders() {
@@ -75,6 +79,7 @@ func (t *ScanTask) scanImage(ctx context.Context) {
 					return err
 				}); err != nil {
 					logger.Error(err.Error())
+					addFileError(ctx, issueStageScan, t.file.Path(), err)
 					return
 				}
 
@@ -86,7 +91,8 @@ func (t *ScanTask) scanImage(ctx context.Context) {
 	}
 
 	if i != nil {
-		t.generateThumbnail(i)
+		t.generateThumbnail(ctx, i)
+		t.generateImagePhash(ctx, i)
 	}
 }
 
@@ -134,7 +140,19 @@ func (t *ScanTask) associateImageWithFolderGallery(imageID int, qb models.Galler
 	return
 }
 
-func (t *ScanTask) generateThumbnail(i *models.Image) {
+func (t *ScanTask) generateImagePhash(ctx context.Context, i *models.Image) {
+	if !t.GeneratePhash {
+		return
+	}
+
+	task := GenerateImagePhashTask{
+		Image:      *i,
+		txnManager: t.TxnManager,
+	}
+	task.Start(ctx)
+}
+
+func (t *ScanTask) generateThumbnail(ctx context.Context, i *models.Image) {
 	if !t.GenerateThumbnails {
 		return
 	}
@@ -148,6 +166,7 @@ func (t *ScanTask) generateThumbnail(i *models.Image) {
 	config, _, err := image.DecodeSourceImage(i)
 	if err != nil {
 		logger.Errorf("error reading image %s: %s", i.Path, err.Error())
+		addFileError(ctx, issueStageThumbnail, i.Path, err)
 		return
 	}
 
@@ -164,6 +183,7 @@ func (t *ScanTask) generateThumbnail(i *models.Image) {
 				if errors.As(err, &exitErr) {
 					logger.Errorf("stderr: %s", string(exitErr.Stderr))
 				}
+				addFileError(ctx, issueStageThumbnail, i.Path, err)
 			}
 			return
 		}
@@ -171,6 +191,7 @@ func (t *ScanTask) generateThumbnail(i *models.Image) {
 		err = fsutil.WriteFile(thumbPath, data)
 		if err != nil {
 			logger.Errorf("error writing thumbnail for image %s: %s", i.Path, err)
+			addFileError(ctx, issueStageThumbnail, i.Path, err)
 		}
 	}
 }
@@ -28,18 +28,20 @@ func (ss *sceneScreenshotter) GenerateThumbnail(ctx context.Context, probeResult
 func (t *ScanTask) scanScene(ctx context.Context) *models.Scene {
 	logError := func(err error) *models.Scene {
 		logger.Error(err.Error())
+		addFileError(ctx, issueStageScan, t.file.Path(), err)
 		return nil
 	}
 
 	var retScene *models.Scene
-	var s *models.Scene
+	var f *models.SceneFile
 
 	if err := t.TxnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
 		var err error
-		s, err = r.Scene().FindByPath(t.file.Path())
+		f, err = r.Scene().FindFileByPath(t.file.Path())
 		return err
 	}); err != nil {
 		logger.Error(err.Error())
+		addFileError(ctx, issueStageScan, t.file.Path(), err)
 		return nil
 	}
 
@@ -64,8 +66,8 @@ func (t *ScanTask) scanScene(ctx context.Context) *models.Scene {
 		UseFileMetadata:  t.UseFileMetadata,
 	}
 
-	if s != nil {
-		if err := scanner.ScanExisting(ctx, s, t.file); err != nil {
+	if f != nil {
+		if err := scanner.ScanExisting(ctx, f, t.file); err != nil {
 			return logError(err)
 		}
 
@@ -39,6 +39,7 @@ func (t *GenerateTranscodeTask) Start(ctc context.Context) {
 	container, err = GetSceneFileContainer(&t.Scene)
 	if err != nil {
 		logger.Errorf("[transcode] error getting scene container: %s", err.Error())
+		addSceneError(ctc, issueStageTranscode, &t.Scene, err)
 		return
 	}
 
@@ -57,20 +58,36 @@ func (t *GenerateTranscodeTask) Start(ctc context.Context) {
 	videoFile, err := ffprobe.NewVideoFile(t.Scene.Path)
 	if err != nil {
 		logger.Errorf("[transcode] error reading video file: %s", err.Error())
+		addSceneError(ctc, issueStageTranscode, &t.Scene, err)
 		return
 	}
 
 	sceneHash := t.Scene.GetHash(t.fileNamingAlgorithm)
-	transcodeSize := config.GetInstance().GetMaxTranscodeSize()
+	transcodeSize := config.GetInstance().GetMaxTranscodeSize().GetMaxResolution()
+
+	var profile *ffmpeg.TranscodeProfile
+	if profileName := config.GetInstance().GetGeneratedTranscodeProfile(); profileName != "" {
+		profile = config.GetInstance().GetTranscodeProfile(profileName)
+		if profile == nil {
+			logger.Errorf("[transcode] transcode profile %q not found", profileName)
+			return
+		}
+
+		if profile.MaxTranscodeSize != 0 {
+			transcodeSize = profile.MaxTranscodeSize
+		}
+	}
 
-	w, h := videoFile.TranscodeScale(transcodeSize.GetMaxResolution())
+	w, h := videoFile.TranscodeScale(transcodeSize)
 
 	options := generate.TranscodeOptions{
-		Width:  w,
-		Height: h,
+		Width:   w,
+		Height:  h,
+		Profile: profile,
 	}
 
-	if videoCodec == ffmpeg.H264 { // for non supported h264 files stream copy the video part
+	// a transcode profile always re-encodes the video using its settings
+	if videoCodec == ffmpeg.H264 && profile == nil { // for non supported h264 files stream copy the video part
 		if audioCodec == ffmpeg.MissingUnsupported {
 			err = t.g.TranscodeCopyVideo(context.TODO(), videoFile.Path, sceneHash, options)
 		} else {
@@ -87,6 +104,7 @@ func (t *GenerateTranscodeTask) Start(ctc context.Context) {
 
 	if err != nil {
 		logger.Errorf("[transcode] error generating transcode: %v", err)
+		addSceneError(ctc, issueStageTranscode, &t.Scene, err)
 		return
 	}
 }
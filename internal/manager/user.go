@@ -0,0 +1,40 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// userFinder finds stored users for the session store.
+type userFinder struct {
+	txnManager models.TransactionManager
+}
+
+func (f userFinder) FindUser(ctx context.Context, username string) (ret *models.User, err error) {
+	err = f.txnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		ret, err = r.User().FindByUsername(username)
+		return err
+	})
+
+	return ret, err
+}
+
+func (f userFinder) HasUsers(ctx context.Context) (ret bool, err error) {
+	err = f.txnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		count, err := r.User().Count()
+		ret = count > 0
+		return err
+	})
+
+	return ret, err
+}
+
+func (f userFinder) FindUserByAPIKey(ctx context.Context, apiKey string) (ret *models.User, err error) {
+	err = f.txnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		ret, err = r.User().FindByAPIKey(apiKey)
+		return err
+	})
+
+	return ret, err
+}
@@ -0,0 +1,438 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/stashapp/stash/internal/manager/config"
+	"github.com/stashapp/stash/pkg/fsutil"
+	"github.com/stashapp/stash/pkg/job"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const watchFlushInterval = time.Second
+
+// watchQueue holds the changed paths until they have been left unchanged
+// for the debounce duration.
+type watchQueue struct {
+	mutex    sync.Mutex
+	debounce time.Duration
+	pending  map[string]time.Time
+}
+
+func newWatchQueue(debounce time.Duration) *watchQueue {
+	return &watchQueue{
+		debounce: debounce,
+		pending:  make(map[string]time.Time),
+	}
+}
+
+// add adds path to the queue, resetting its debounce if already queued.
+func (q *watchQueue) add(path string, t time.Time) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pending[path] = t
+}
+
+// pop removes and returns the paths that have not changed for the debounce
+// duration before now.
+func (q *watchQueue) pop(now time.Time) []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var ret []string
+	for path, t := range q.pending {
+		if now.Sub(t) >= q.debounce {
+			ret = append(ret, path)
+			delete(q.pending, path)
+		}
+	}
+
+	return ret
+}
+
+// collapsePaths returns paths without the paths within one of dirs, which
+// are processed with the directory.
+func collapsePaths(paths []string, dirs []string) []string {
+	var ret []string
+	for _, p := range paths {
+		inDir := false
+		for _, d := range dirs {
+			if p != d && fsutil.IsPathInDir(d, p) {
+				inDir = true
+				break
+			}
+		}
+
+		if !inDir {
+			ret = append(ret, p)
+		}
+	}
+
+	return ret
+}
+
+// fileWatcher watches the stash paths for changes. Changed files are
+// queued for scanning and removed files for cleaning once they have been
+// left unchanged for the configured debounce duration.
+type fileWatcher struct {
+	manager *Manager
+	watcher *fsnotify.Watcher
+	filter  *scanFilter
+	queue   *watchQueue
+
+	// dirs holds the watched directories
+	dirs      map[string]bool
+	dirsMutex sync.Mutex
+
+	done chan struct{}
+}
+
+// errWatcherStopped stops walking the stash paths when the watcher is
+// stopped.
+var errWatcherStopped = errors.New("file watcher stopped")
+
+// fileWatcherSettings holds the configuration used by the file watcher, so
+// that the watcher is only restarted when it changes.
+type fileWatcherSettings struct {
+	enabled       bool
+	debounce      int
+	stashes       []models.StashConfig
+	vidExt        []string
+	imgExt        []string
+	gExt          []string
+	excludes      []string
+	imageExcludes []string
+	generatedPath string
+}
+
+func getFileWatcherSettings(c *config.Instance) fileWatcherSettings {
+	ret := fileWatcherSettings{
+		enabled:       c.GetWatchStashPaths(),
+		debounce:      c.GetWatchDebounce(),
+		vidExt:        c.GetVideoExtensions(),
+		imgExt:        c.GetImageExtensions(),
+		gExt:          c.GetGalleryExtensions(),
+		excludes:      c.GetExcludes(),
+		imageExcludes: c.GetImageExcludes(),
+		generatedPath: c.GetGeneratedPath(),
+	}
+
+	for _, s := range c.GetStashPaths() {
+		ret.stashes = append(ret.stashes, *s)
+	}
+
+	return ret
+}
+
+func newFileWatcher(manager *Manager) (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	debounce := time.Duration(config.GetInstance().GetWatchDebounce()) * time.Second
+
+	return &fileWatcher{
+		manager: manager,
+		watcher: watcher,
+		filter:  newScanFilter(),
+		queue:   newWatchQueue(debounce),
+		dirs:    make(map[string]bool),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// start watches the stash paths in the background, since walking large
+// libraries takes a while.
+func (w *fileWatcher) start() {
+	stashes := config.GetInstance().GetStashPaths()
+
+	go func() {
+		for _, s := range stashes {
+			if w.isStopped() {
+				return
+			}
+
+			w.watchDir(s, s.Path)
+		}
+
+		logger.Debug("Finished adding stash paths to the file watcher")
+	}()
+
+	go w.run()
+}
+
+func (w *fileWatcher) isStopped() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *fileWatcher) stop() {
+	close(w.done)
+	if err := w.watcher.Close(); err != nil {
+		logger.Warnf("error closing file watcher: %v", err)
+	}
+}
+
+// watchDir watches dir and its subdirectories, excluding the directories
+// that are not scanned.
+func (w *fileWatcher) watchDir(s *models.StashConfig, dir string) {
+	err := fsutil.SymWalk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logger.Warnf("error watching %s: %v", path, err)
+			return nil
+		}
+
+		if w.isStopped() {
+			return errWatcherStopped
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if w.filter.skipDir(s, path) {
+			return filepath.SkipDir
+		}
+
+		if err := w.watcher.Add(path); err != nil {
+			// usually caused by the inotify watch limit
+			logger.Warnf("could not watch %s: %v", path, err)
+			return nil
+		}
+
+		w.dirsMutex.Lock()
+		w.dirs[path] = true
+		w.dirsMutex.Unlock()
+
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errWatcherStopped) {
+		logger.Warnf("error watching %s: %v", dir, err)
+	}
+}
+
+func (w *fileWatcher) isWatchedDir(path string) bool {
+	w.dirsMutex.Lock()
+	defer w.dirsMutex.Unlock()
+
+	return w.dirs[path]
+}
+
+func (w *fileWatcher) unwatchDir(path string) {
+	w.dirsMutex.Lock()
+	defer w.dirsMutex.Unlock()
+
+	for d := range w.dirs {
+		if fsutil.IsPathInDir(path, d) {
+			// the watch is usually removed with the directory
+			_ = w.watcher.Remove(d)
+			delete(w.dirs, d)
+		}
+	}
+}
+
+func (w *fileWatcher) run() {
+	ticker := time.NewTicker(watchFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(event)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warnf("file watcher error: %v", err)
+		case now := <-ticker.C:
+			w.flush(now)
+		}
+	}
+}
+
+func (w *fileWatcher) handleEvent(event fsnotify.Event) {
+	// permission changes don't affect the scanned data
+	if event.Op == fsnotify.Chmod {
+		return
+	}
+
+	path := event.Name
+
+	// watch new directories immediately so that changes within them are
+	// not missed
+	if event.Op&fsnotify.Create != 0 {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			s := getStashFromDirPath(path)
+			if s == nil || w.filter.skipDir(s, path) {
+				return
+			}
+
+			w.watchDir(s, path)
+		}
+	}
+
+	w.queue.add(path, time.Now())
+}
+
+// flush queues jobs for the paths that have been left unchanged for the
+// debounce duration.
+func (w *fileWatcher) flush(now time.Time) {
+	var scanPaths []string
+	var scanDirs []string
+	var cleanPaths []string
+
+	for _, path := range w.queue.pop(now) {
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && info.IsDir():
+			scanPaths = append(scanPaths, path)
+			scanDirs = append(scanDirs, path)
+		case err == nil:
+			if s := getStashFromPath(path); s != nil && w.filter.matches(s, path) {
+				scanPaths = append(scanPaths, path)
+			}
+		case errors.Is(err, os.ErrNotExist):
+			if w.isWatchedDir(path) {
+				w.unwatchDir(path)
+				cleanPaths = append(cleanPaths, path)
+			} else if s := getStashFromPath(path); s != nil && w.filter.matches(s, path) {
+				// the clean job cleans directories, and only removes the
+				// missing files
+				cleanPaths = append(cleanPaths, filepath.Dir(path))
+			}
+		default:
+			logger.Warnf("error reading changed file %s: %v", path, err)
+		}
+	}
+
+	scanPaths = collapsePaths(scanPaths, scanDirs)
+	cleanPaths = collapsePaths(uniquePaths(cleanPaths), cleanPaths)
+
+	if len(scanPaths) == 0 && len(cleanPaths) == 0 {
+		return
+	}
+
+	logger.Infof("Scanning %d changed paths and cleaning %d removed paths", len(scanPaths), len(cleanPaths))
+	if _, err := w.manager.scanAndClean(context.Background(), scanPaths, cleanPaths); err != nil {
+		logger.Warnf("could not scan changed paths: %v", err)
+	}
+}
+
+func uniquePaths(paths []string) []string {
+	seen := make(map[string]bool)
+	var ret []string
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			ret = append(ret, p)
+		}
+	}
+
+	return ret
+}
+
+// getDefaultScanInput returns the input used to scan paths using the default
+// scan settings. All stash paths are scanned if paths is empty.
+func getDefaultScanInput(paths []string) models.ScanMetadataInput {
+	ret := models.ScanMetadataInput{
+		Paths: paths,
+	}
+
+	if d := config.GetInstance().GetDefaultScanSettings(); d != nil {
+		ret.UseFileMetadata = &d.UseFileMetadata
+		ret.StripFileExtension = &d.StripFileExtension
+		ret.ScanGeneratePreviews = &d.ScanGeneratePreviews
+		ret.ScanGenerateImagePreviews = &d.ScanGenerateImagePreviews
+		ret.ScanGenerateSprites = &d.ScanGenerateSprites
+		ret.ScanGeneratePhashes = &d.ScanGeneratePhashes
+		ret.ScanGenerateThumbnails = &d.ScanGenerateThumbnails
+	}
+
+	return ret
+}
+
+// scanAndClean queues a job scanning scanPaths and then cleaning cleanPaths.
+// The scan runs first so that moved files are found by their hash and keep
+// their metadata, rather than being removed by the clean before the scan
+// reaches their new path.
+func (s *Manager) scanAndClean(ctx context.Context, scanPaths []string, cleanPaths []string) (int, error) {
+	if len(scanPaths) > 0 {
+		if err := s.validateFFMPEG(); err != nil {
+			return 0, err
+		}
+	}
+
+	scanJob := s.newScanJob(getDefaultScanInput(scanPaths))
+	cleanJob := s.newCleanJob(models.CleanMetadataInput{
+		Paths: cleanPaths,
+	})
+
+	j := job.MakeJobExec(func(ctx context.Context, progress *job.Progress) {
+		if len(scanPaths) > 0 {
+			scanJob.Execute(ctx, progress)
+		}
+
+		if len(cleanPaths) > 0 && !job.IsCancelled(ctx) {
+			progress.SetProcessed(0)
+			cleanJob.Execute(ctx, progress)
+		}
+	})
+
+	return s.JobManager.Add(ctx, "Scanning changed paths...", job.WithLane(j, job.LaneIO)), nil
+}
+
+// RefreshFileWatcher starts, restarts or stops watching the stash paths for
+// changes as configured. The watcher is left running if the stash paths and
+// the watch configuration have not changed since it was started.
+func (s *Manager) RefreshFileWatcher() {
+	s.fileWatcherMutex.Lock()
+	defer s.fileWatcherMutex.Unlock()
+
+	settings := getFileWatcherSettings(s.Config)
+	if s.fileWatcherSettings != nil && reflect.DeepEqual(*s.fileWatcherSettings, settings) {
+		return
+	}
+	s.fileWatcherSettings = &settings
+
+	if s.fileWatcher != nil {
+		s.fileWatcher.stop()
+		s.fileWatcher = nil
+	}
+
+	if !s.Config.GetWatchStashPaths() {
+		return
+	}
+
+	w, err := newFileWatcher(s)
+	if err != nil {
+		logger.Errorf("could not start file watcher: %v", err)
+		// try again on the next refresh
+		s.fileWatcherSettings = nil
+		return
+	}
+
+	logger.Info("Watching stash paths for changes")
+	w.start()
+	s.fileWatcher = w
+}
@@ -0,0 +1,54 @@
+package manager
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestWatchQueuePop(t *testing.T) {
+	const debounce = 10 * time.Second
+	start := time.Now()
+
+	q := newWatchQueue(debounce)
+	q.add("a", start)
+	q.add("b", start)
+
+	// changing b again resets its debounce
+	q.add("b", start.Add(5*time.Second))
+
+	if got := q.pop(start.Add(5 * time.Second)); len(got) != 0 {
+		t.Errorf("pop() before debounce = %v, want none", got)
+	}
+
+	if got := q.pop(start.Add(debounce)); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("pop() after debounce = %v, want [a]", got)
+	}
+
+	if got := q.pop(start.Add(debounce)); len(got) != 0 {
+		t.Errorf("pop() after popping = %v, want none", got)
+	}
+
+	if got := q.pop(start.Add(2 * debounce)); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("pop() after second debounce = %v, want [b]", got)
+	}
+}
+
+func TestCollapsePaths(t *testing.T) {
+	dir := filepath.Join("stash", "new")
+	inDir := filepath.Join(dir, "file.mp4")
+	similar := filepath.Join("stash", "new file.mp4")
+	other := filepath.Join("stash", "other", "file.mp4")
+
+	got := collapsePaths([]string{inDir, dir, similar, other}, []string{dir})
+	sort.Strings(got)
+
+	want := []string{dir, similar, other}
+	sort.Strings(want)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collapsePaths() = %v, want %v", got, want)
+	}
+}
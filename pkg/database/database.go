@@ -23,7 +23,7 @@ import (
 var DB *sqlx.DB
 var WriteMu sync.Mutex
 var dbPath string
-var appSchemaVersion uint = 31
+var appSchemaVersion uint = 42
 var databaseSchemaVersion uint
 
 //go:embed migrations/*.sql
@@ -37,6 +37,10 @@ var (
 	// ErrDatabaseNotInitialized indicates that the database is not
 	// initialized, usually due to an incomplete configuration.
 	ErrDatabaseNotInitialized = errors.New("database not initialized")
+
+	// ErrFTS5Unavailable indicates that the sqlite library was built
+	// without the FTS5 extension used by the full-text search tables.
+	ErrFTS5Unavailable = errors.New("sqlite FTS5 extension is not available: stash must be built with the sqlite_fts5 build tag")
 )
 
 const sqlite3Driver = "sqlite3ex"
@@ -62,6 +66,11 @@ func init() {
 func Initialize(databasePath string) error {
 	dbPath = databasePath
 
+	// fail early rather than when migrating or searching
+	if err := checkFTS5(); err != nil {
+		return err
+	}
+
 	if err := getDatabaseSchemaVersion(); err != nil {
 		return fmt.Errorf("error getting database schema version: %v", err)
 	}
@@ -95,6 +104,23 @@ func Initialize(databasePath string) error {
 	return nil
 }
 
+// checkFTS5 returns ErrFTS5Unavailable if the full-text search tables cannot
+// be created.
+func checkFTS5() error {
+	db, err := sqlx.Connect(sqlite3Driver, ":memory:")
+	if err != nil {
+		return fmt.Errorf("open in-memory database failed: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE VIRTUAL TABLE fts5_check USING fts5(value)"); err != nil {
+		logger.Debugf("error creating FTS5 table: %v", err)
+		return ErrFTS5Unavailable
+	}
+
+	return nil
+}
+
 func Close() error {
 	WriteMu.Lock()
 	defer WriteMu.Unlock()
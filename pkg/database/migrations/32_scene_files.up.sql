@@ -0,0 +1,71 @@
+CREATE TABLE `scene_files` (
+  `id` integer not null primary key autoincrement,
+  `scene_id` integer not null,
+  `is_primary` boolean not null default '0',
+  `path` varchar(510) not null,
+  `checksum` varchar(255),
+  `oshash` varchar(255),
+  `size` varchar(255),
+  `duration` float,
+  `video_codec` varchar(255),
+  `format` varchar(255),
+  `audio_codec` varchar(255),
+  `width` tinyint,
+  `height` tinyint,
+  `framerate` float,
+  `bitrate` integer,
+  `file_mod_time` datetime,
+  `interactive` boolean not null default '0',
+  `created_at` datetime not null,
+  `updated_at` datetime not null,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE,
+  CHECK (`checksum` is not null or `oshash` is not null)
+);
+
+CREATE UNIQUE INDEX `scene_files_path_unique` on `scene_files` (`path`);
+CREATE INDEX `index_scene_files_on_scene_id` on `scene_files` (`scene_id`);
+CREATE INDEX `index_scene_files_on_checksum` on `scene_files` (`checksum`);
+CREATE INDEX `index_scene_files_on_oshash` on `scene_files` (`oshash`);
+
+-- the existing scene file becomes the primary file of each scene
+INSERT INTO `scene_files`
+  (
+    `scene_id`,
+    `is_primary`,
+    `path`,
+    `checksum`,
+    `oshash`,
+    `size`,
+    `duration`,
+    `video_codec`,
+    `format`,
+    `audio_codec`,
+    `width`,
+    `height`,
+    `framerate`,
+    `bitrate`,
+    `file_mod_time`,
+    `interactive`,
+    `created_at`,
+    `updated_at`
+  )
+  SELECT
+    `id`,
+    1,
+    `path`,
+    `checksum`,
+    `oshash`,
+    `size`,
+    `duration`,
+    `video_codec`,
+    `format`,
+    `audio_codec`,
+    `width`,
+    `height`,
+    `framerate`,
+    `bitrate`,
+    `file_mod_time`,
+    `interactive`,
+    `created_at`,
+    `updated_at`
+  FROM `scenes`;
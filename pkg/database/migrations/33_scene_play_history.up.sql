@@ -0,0 +1,12 @@
+ALTER TABLE `scenes` ADD COLUMN `resume_time` float not null default 0;
+
+CREATE TABLE `scenes_play_history` (
+  `id` integer not null primary key autoincrement,
+  `scene_id` integer not null,
+  `played_at` datetime not null,
+  `play_duration` float not null default 0,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_scenes_play_history_on_scene_id` on `scenes_play_history` (`scene_id`);
+CREATE INDEX `index_scenes_play_history_on_played_at` on `scenes_play_history` (`played_at`);
@@ -0,0 +1,30 @@
+CREATE TABLE `users` (
+  `id` integer not null primary key autoincrement,
+  `username` varchar(255) not null,
+  `password` varchar(255) not null,
+  `role` varchar(255) not null,
+  `api_key` varchar(255),
+  `created_at` datetime not null,
+  `updated_at` datetime not null
+);
+
+CREATE UNIQUE INDEX `index_users_on_username` on `users` (`username`);
+CREATE UNIQUE INDEX `index_users_on_api_key` on `users` (`api_key`);
+
+CREATE TABLE `scenes_users` (
+  `scene_id` integer not null,
+  `user_id` integer not null,
+  `rating` tinyint,
+  `o_counter` tinyint not null default 0,
+  `resume_time` float not null default 0,
+  foreign key(`scene_id`) references `scenes`(`id`) on delete CASCADE,
+  foreign key(`user_id`) references `users`(`id`) on delete CASCADE,
+  PRIMARY KEY(`scene_id`, `user_id`)
+);
+
+CREATE INDEX `index_scenes_users_on_user_id` on `scenes_users` (`user_id`);
+
+-- plays without a user belong to the configured credentials
+ALTER TABLE `scenes_play_history` ADD COLUMN `user_id` integer REFERENCES `users`(`id`) ON DELETE CASCADE;
+
+CREATE INDEX `index_scenes_play_history_on_user_id` on `scenes_play_history` (`user_id`);
@@ -0,0 +1,18 @@
+CREATE TABLE `schedules` (
+  `id` integer not null primary key autoincrement,
+  `name` varchar(255) not null,
+  `task` varchar(255) not null,
+  `cron` varchar(255),
+  `interval` integer,
+  `plugin_id` varchar(255),
+  `plugin_task_name` varchar(255),
+  `enabled` boolean not null default '1',
+  `last_run_at` datetime,
+  `last_job_id` integer,
+  `last_result` varchar(255),
+  `last_error` text,
+  `created_at` datetime not null,
+  `updated_at` datetime not null
+);
+
+CREATE UNIQUE INDEX `index_schedules_on_name` on `schedules` (`name`);
@@ -0,0 +1,14 @@
+CREATE TABLE `jobs` (
+  `id` integer not null primary key,
+  `status` varchar(255) not null,
+  `description` text not null,
+  `type` varchar(255),
+  `input` text,
+  `progress` real,
+  `error` text,
+  `add_time` datetime not null,
+  `start_time` datetime,
+  `end_time` datetime
+);
+
+CREATE INDEX `index_jobs_on_status` on `jobs` (`status`);
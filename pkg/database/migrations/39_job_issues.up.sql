@@ -0,0 +1,2 @@
+ALTER TABLE `jobs` ADD COLUMN `issues` text;
+ALTER TABLE `jobs` ADD COLUMN `issue_count` integer not null default 0;
@@ -0,0 +1,2 @@
+ALTER TABLE `jobs` ADD COLUMN `lane` varchar(255);
+ALTER TABLE `jobs` ADD COLUMN `priority` integer not null default 0;
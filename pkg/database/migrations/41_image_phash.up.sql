@@ -0,0 +1,2 @@
+ALTER TABLE `images` ADD COLUMN `phash` blob;
+CREATE INDEX `index_images_on_phash` on `images` (`phash`);
@@ -0,0 +1,82 @@
+-- full-text search tables, keyed by the id of the object
+CREATE VIRTUAL TABLE `scenes_fts` USING fts5(
+  `title`, `details`, `path`, `oshash`, `checksum`, `markers`,
+  tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE `scene_markers_fts` USING fts5(
+  `title`, `scene_title`,
+  tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE `images_fts` USING fts5(
+  `title`, `path`, `checksum`,
+  tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE `galleries_fts` USING fts5(
+  `title`, `path`, `checksum`,
+  tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE `performers_fts` USING fts5(
+  `name`, `aliases`,
+  tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE `studios_fts` USING fts5(
+  `name`, `aliases`,
+  tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE `tags_fts` USING fts5(
+  `name`, `aliases`,
+  tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'
+);
+
+CREATE VIRTUAL TABLE `movies_fts` USING fts5(
+  `name`, `aliases`,
+  tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3'
+);
+
+-- rank matches in titles and names above matches in other columns
+INSERT INTO `scenes_fts` (`scenes_fts`, `rank`) VALUES ('rank', 'bm25(10.0, 1.0, 2.0, 1.0, 1.0, 2.0)');
+INSERT INTO `scene_markers_fts` (`scene_markers_fts`, `rank`) VALUES ('rank', 'bm25(10.0, 1.0)');
+INSERT INTO `images_fts` (`images_fts`, `rank`) VALUES ('rank', 'bm25(10.0, 2.0, 1.0)');
+INSERT INTO `galleries_fts` (`galleries_fts`, `rank`) VALUES ('rank', 'bm25(10.0, 2.0, 1.0)');
+INSERT INTO `performers_fts` (`performers_fts`, `rank`) VALUES ('rank', 'bm25(10.0, 5.0)');
+INSERT INTO `studios_fts` (`studios_fts`, `rank`) VALUES ('rank', 'bm25(10.0, 5.0)');
+INSERT INTO `tags_fts` (`tags_fts`, `rank`) VALUES ('rank', 'bm25(10.0, 5.0)');
+INSERT INTO `movies_fts` (`movies_fts`, `rank`) VALUES ('rank', 'bm25(10.0, 5.0)');
+
+INSERT INTO `scenes_fts` (`rowid`, `title`, `details`, `path`, `oshash`, `checksum`, `markers`)
+SELECT `scenes`.`id`, `scenes`.`title`, `scenes`.`details`, `scenes`.`path`, `scenes`.`oshash`, `scenes`.`checksum`,
+  (SELECT GROUP_CONCAT(`scene_markers`.`title`, ' ') FROM `scene_markers` WHERE `scene_markers`.`scene_id` = `scenes`.`id`)
+FROM `scenes`;
+
+INSERT INTO `scene_markers_fts` (`rowid`, `title`, `scene_title`)
+SELECT `scene_markers`.`id`, `scene_markers`.`title`,
+  (SELECT `scenes`.`title` FROM `scenes` WHERE `scenes`.`id` = `scene_markers`.`scene_id`)
+FROM `scene_markers`;
+
+INSERT INTO `images_fts` (`rowid`, `title`, `path`, `checksum`)
+SELECT `id`, `title`, `path`, `checksum` FROM `images`;
+
+INSERT INTO `galleries_fts` (`rowid`, `title`, `path`, `checksum`)
+SELECT `id`, `title`, `path`, `checksum` FROM `galleries`;
+
+INSERT INTO `performers_fts` (`rowid`, `name`, `aliases`)
+SELECT `id`, `name`, `aliases` FROM `performers`;
+
+INSERT INTO `studios_fts` (`rowid`, `name`, `aliases`)
+SELECT `studios`.`id`, `studios`.`name`,
+  (SELECT GROUP_CONCAT(`studio_aliases`.`alias`, ' ') FROM `studio_aliases` WHERE `studio_aliases`.`studio_id` = `studios`.`id`)
+FROM `studios`;
+
+INSERT INTO `tags_fts` (`rowid`, `name`, `aliases`)
+SELECT `tags`.`id`, `tags`.`name`,
+  (SELECT GROUP_CONCAT(`tag_aliases`.`alias`, ' ') FROM `tag_aliases` WHERE `tag_aliases`.`tag_id` = `tags`.`id`)
+FROM `tags`;
+
+INSERT INTO `movies_fts` (`rowid`, `name`, `aliases`)
+SELECT `id`, `name`, `aliases` FROM `movies`;
@@ -0,0 +1,207 @@
+package ffmpeg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// dashVideoCodecsPrefix is the codecs string prefix for H.264 High
+	// profile video produced by the segment transcoder. The level is
+	// appended as a hex byte.
+	dashVideoCodecsPrefix = "avc1.6400"
+	// dashAudioCodecs is the codecs string for AAC-LC audio.
+	dashAudioCodecs = "mp4a.40.2"
+
+	dashAudioBandwidth = 128000
+
+	dashDefaultFrameRate = 30
+)
+
+// h264Level is a H.264 level with its maximum macroblock processing rate
+// and frame size.
+type h264Level struct {
+	level     int
+	maxMBPS   int
+	maxFrames int
+}
+
+// h264Levels are the H.264 levels from 3.0, as level_idc values.
+var h264Levels = []h264Level{
+	{30, 40500, 1620},
+	{31, 108000, 3600},
+	{32, 216000, 5120},
+	{40, 245760, 8192},
+	{42, 522240, 8704},
+	{50, 589824, 22080},
+	{51, 983040, 36864},
+	{52, 2073600, 36864},
+	{60, 4177920, 139264},
+	{61, 8355840, 139264},
+	{62, 16711680, 139264},
+}
+
+// dashVideoCodecs returns the codecs string of the H.264 video with the
+// provided dimensions and frame rate, using the lowest level which
+// supports it.
+func dashVideoCodecs(width, height int, frameRate float64) string {
+	if frameRate <= 0 {
+		frameRate = dashDefaultFrameRate
+	}
+
+	// dimensions in 16x16 macroblocks
+	frameSize := ((width + 15) / 16) * ((height + 15) / 16)
+	mbps := int(math.Ceil(float64(frameSize) * frameRate))
+
+	level := h264Levels[len(h264Levels)-1].level
+	for _, l := range h264Levels {
+		if frameSize <= l.maxFrames && mbps <= l.maxMBPS {
+			level = l.level
+			break
+		}
+	}
+
+	return fmt.Sprintf("%s%02x", dashVideoCodecsPrefix, level)
+}
+
+// DASHRepresentation represents a single video representation of a DASH
+// stream.
+type DASHRepresentation struct {
+	// ID is the identifier of the representation. It is substituted for
+	// $RepresentationID$ in the segment URL templates.
+	ID        string
+	Width     int
+	Height    int
+	Bandwidth int
+	// FrameRate is used to determine the H.264 level of the
+	// representation. 30 is assumed if it is zero.
+	FrameRate float64
+}
+
+// DASHManifestOptions represents options for writing a DASH manifest.
+type DASHManifestOptions struct {
+	Duration float64
+
+	Video []DASHRepresentation
+	// AudioID is the identifier of the audio representation. The manifest
+	// has no audio if it is empty.
+	AudioID string
+
+	// InitURL and MediaURL are the URL templates of the initialization and
+	// media segments. They may contain the $RepresentationID$ and $Number$
+	// identifiers.
+	InitURL  string
+	MediaURL string
+}
+
+type mpdSegmentTemplate struct {
+	Timescale      int    `xml:"timescale,attr"`
+	Duration       int    `xml:"duration,attr"`
+	StartNumber    int    `xml:"startNumber,attr"`
+	Initialization string `xml:"initialization,attr"`
+	Media          string `xml:"media,attr"`
+}
+
+type mpdRepresentation struct {
+	ID        string `xml:"id,attr"`
+	Bandwidth int    `xml:"bandwidth,attr"`
+	Codecs    string `xml:"codecs,attr,omitempty"`
+	Width     int    `xml:"width,attr,omitempty"`
+	Height    int    `xml:"height,attr,omitempty"`
+}
+
+type mpdAdaptationSet struct {
+	ContentType      string              `xml:"contentType,attr"`
+	MimeType         string              `xml:"mimeType,attr"`
+	Codecs           string              `xml:"codecs,attr,omitempty"`
+	SegmentAlignment bool                `xml:"segmentAlignment,attr"`
+	SegmentTemplate  mpdSegmentTemplate  `xml:"SegmentTemplate"`
+	Representations  []mpdRepresentation `xml:"Representation"`
+}
+
+type mpdPeriod struct {
+	ID             string             `xml:"id,attr"`
+	Start          string             `xml:"start,attr"`
+	AdaptationSets []mpdAdaptationSet `xml:"AdaptationSet"`
+}
+
+type mpd struct {
+	XMLName                   xml.Name  `xml:"urn:mpeg:dash:schema:mpd:2011 MPD"`
+	Type                      string    `xml:"type,attr"`
+	Profiles                  string    `xml:"profiles,attr"`
+	MinBufferTime             string    `xml:"minBufferTime,attr"`
+	MediaPresentationDuration string    `xml:"mediaPresentationDuration,attr"`
+	Period                    mpdPeriod `xml:"Period"`
+}
+
+func dashDuration(seconds float64) string {
+	return fmt.Sprintf("PT%.3fS", seconds)
+}
+
+// WriteDASHManifest writes a static DASH manifest to w. The segments are
+// expected to have been produced using HLSSegmentOptions with the
+// HLSSegmentTypeFMP4 segment type.
+func WriteDASHManifest(options DASHManifestOptions, w io.Writer) error {
+	segmentTemplate := mpdSegmentTemplate{
+		Timescale:      1000,
+		Duration:       int(hlsSegmentLength * 1000),
+		StartNumber:    0,
+		Initialization: options.InitURL,
+		Media:          options.MediaURL,
+	}
+
+	video := mpdAdaptationSet{
+		ContentType:      "video",
+		MimeType:         MimeMp4,
+		SegmentAlignment: true,
+		SegmentTemplate:  segmentTemplate,
+	}
+
+	for _, r := range options.Video {
+		video.Representations = append(video.Representations, mpdRepresentation{
+			ID:        r.ID,
+			Bandwidth: r.Bandwidth,
+			Codecs:    dashVideoCodecs(r.Width, r.Height, r.FrameRate),
+			Width:     r.Width,
+			Height:    r.Height,
+		})
+	}
+
+	m := mpd{
+		Type:                      "static",
+		Profiles:                  "urn:mpeg:dash:profile:isoff-live:2011",
+		MinBufferTime:             dashDuration(hlsSegmentLength),
+		MediaPresentationDuration: dashDuration(options.Duration),
+		Period: mpdPeriod{
+			ID:             "0",
+			Start:          dashDuration(0),
+			AdaptationSets: []mpdAdaptationSet{video},
+		},
+	}
+
+	if options.AudioID != "" {
+		m.Period.AdaptationSets = append(m.Period.AdaptationSets, mpdAdaptationSet{
+			ContentType:      "audio",
+			MimeType:         MimeMp4Audio,
+			Codecs:           dashAudioCodecs,
+			SegmentAlignment: true,
+			SegmentTemplate:  segmentTemplate,
+			Representations: []mpdRepresentation{
+				{
+					ID:        options.AudioID,
+					Bandwidth: dashAudioBandwidth,
+				},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(m)
+}
@@ -3,38 +3,200 @@ package ffmpeg
 import (
 	"fmt"
 	"io"
-	"strings"
+	"math"
+	"path/filepath"
 )
 
 const hlsSegmentLength = 10.0
 
-// WriteHLSPlaylist writes a HLS playlist to w using baseUrl as the base URL for TS streams.
-func WriteHLSPlaylist(duration float64, baseUrl string, w io.Writer) {
+// HLSRendition represents a single rendition of an adaptive HLS stream.
+type HLSRendition struct {
+	// Width and Height are the dimensions of the rendition.
+	Width  int
+	Height int
+	// Bandwidth is the peak bit rate of the rendition, in bits per second.
+	Bandwidth int
+	// URL is the URL of the media playlist of the rendition.
+	URL string
+}
+
+// WriteHLSMasterPlaylist writes a HLS master playlist to w, listing the
+// provided renditions.
+func WriteHLSMasterPlaylist(renditions []HLSRendition, w io.Writer) {
+	fmt.Fprint(w, "#EXTM3U\n")
+	fmt.Fprint(w, "#EXT-X-VERSION:3\n")
+
+	for _, r := range renditions {
+		fmt.Fprintf(w, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n", r.Bandwidth, r.Width, r.Height)
+		fmt.Fprintf(w, "%s\n", r.URL)
+	}
+}
+
+// HLSSegmentCount returns the number of segments in a HLS stream of a video
+// with the provided duration.
+func HLSSegmentCount(duration float64) int {
+	return int(math.Ceil(duration / hlsSegmentLength))
+}
+
+// WriteHLSMediaPlaylist writes a HLS VOD media playlist to w for a video of
+// the provided duration. segmentURL returns the URL of the segment with the
+// provided index.
+func WriteHLSMediaPlaylist(duration float64, segmentURL func(index int) string, w io.Writer) {
 	fmt.Fprint(w, "#EXTM3U\n")
 	fmt.Fprint(w, "#EXT-X-VERSION:3\n")
 	fmt.Fprint(w, "#EXT-X-MEDIA-SEQUENCE:0\n")
-	fmt.Fprint(w, "#EXT-X-ALLOW-CACHE:YES\n")
 	fmt.Fprintf(w, "#EXT-X-TARGETDURATION:%d\n", int(hlsSegmentLength))
 	fmt.Fprint(w, "#EXT-X-PLAYLIST-TYPE:VOD\n")
 
 	leftover := duration
-	upTo := 0.0
-
-	i := strings.LastIndex(baseUrl, ".m3u8")
-	tsURL := baseUrl[0:i] + ".ts"
-
-	for leftover > 0 {
+	for i := 0; leftover > 0; i++ {
 		thisLength := hlsSegmentLength
 		if leftover < thisLength {
 			thisLength = leftover
 		}
 
-		fmt.Fprintf(w, "#EXTINF: %f,\n", thisLength)
-		fmt.Fprintf(w, "%s?start=%f\n", tsURL, upTo)
+		fmt.Fprintf(w, "#EXTINF:%f,\n", thisLength)
+		fmt.Fprintf(w, "%s\n", segmentURL(i))
 
 		leftover -= thisLength
-		upTo += thisLength
 	}
 
 	fmt.Fprint(w, "#EXT-X-ENDLIST\n")
 }
+
+// HLSSegmentType is the container format of HLS segments.
+type HLSSegmentType string
+
+const (
+	// HLSSegmentTypeMpegTS produces MPEG-TS segments.
+	HLSSegmentTypeMpegTS HLSSegmentType = "mpegts"
+	// HLSSegmentTypeFMP4 produces fragmented MP4 segments, preceded by an
+	// initialization segment. These may also be used for DASH streams.
+	HLSSegmentTypeFMP4 HLSSegmentType = "fmp4"
+)
+
+func (t HLSSegmentType) extension() string {
+	if t == HLSSegmentTypeFMP4 {
+		return "m4s"
+	}
+
+	return "ts"
+}
+
+// HLSSegmentOptions represents options for transcoding a video file into
+// HLS segments.
+type HLSSegmentOptions struct {
+	Input string
+	// OutputDir is the directory to write the segments to. Segments are
+	// named using their index, for example 12.ts.
+	OutputDir string
+	// SegmentType is the container format of the segments. Defaults to
+	// MPEG-TS.
+	SegmentType HLSSegmentType
+	// StartSegment is the index of the first segment to generate.
+	StartSegment int
+	// MaxTranscodeSize is the maximum size of the smaller dimension of the
+	// output video. Zero means the original size.
+	MaxTranscodeSize int
+
+	// original video dimensions
+	VideoWidth  int
+	VideoHeight int
+
+	// VideoOnly removes the audio from the output
+	VideoOnly bool
+	// AudioOnly removes the video from the output
+	AudioOnly bool
+}
+
+// HLSSegmentPath returns the path of the segment of the provided type with
+// the provided index in dir.
+func HLSSegmentPath(dir string, index int, segmentType HLSSegmentType) string {
+	return filepath.Join(dir, fmt.Sprintf("%d.%s", index, segmentType.extension()))
+}
+
+// HLSInitSegmentPath returns the path of the initialization segment written
+// to dir by a fragmented MP4 transcode starting from startSegment.
+func HLSInitSegmentPath(dir string, startSegment int) string {
+	return filepath.Join(dir, hlsInitSegmentName(startSegment))
+}
+
+func hlsInitSegmentName(startSegment int) string {
+	return fmt.Sprintf("init_%d.mp4", startSegment)
+}
+
+// Args returns the ffmpeg arguments to transcode the input into HLS
+// segments, starting from StartSegment and continuing to the end of the
+// input.
+func (o HLSSegmentOptions) Args() Args {
+	startTime := float64(o.StartSegment) * hlsSegmentLength
+
+	var args Args
+	args = append(args, "-hide_banner")
+	args = args.LogLevel(LogLevelError)
+
+	if startTime > 0 {
+		args = args.Seek(startTime)
+	}
+
+	args = args.Input(o.Input)
+
+	if o.VideoOnly {
+		args = args.SkipAudio()
+	}
+
+	if o.AudioOnly {
+		args = append(args, "-vn")
+	} else {
+		args = args.VideoCodec(VideoCodecLibX264)
+
+		var videoFilter VideoFilter
+		videoFilter = videoFilter.ScaleMax(o.VideoWidth, o.VideoHeight, o.MaxTranscodeSize)
+		args = args.VideoFilter(videoFilter)
+
+		args = append(args,
+			"-pix_fmt", "yuv420p",
+			"-preset", "veryfast",
+			"-crf", "25",
+			// keyframes must align with the segment boundaries so that segments
+			// produced by different processes can be joined
+			"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", int(hlsSegmentLength)),
+			"-sc_threshold", "0",
+		)
+	}
+
+	if !o.VideoOnly {
+		args = append(args,
+			"-c:a", "aac",
+			// this is needed for 5-channel ac3 files
+			"-ac", "2",
+		)
+	}
+
+	segmentType := o.SegmentType
+	if segmentType == "" {
+		segmentType = HLSSegmentTypeMpegTS
+	}
+
+	args = append(args,
+		// keep the timestamps continuous with the previous segments
+		"-output_ts_offset", fmt.Sprint(startTime),
+		"-f", "hls",
+		"-hls_time", fmt.Sprint(hlsSegmentLength),
+		"-hls_list_size", "0",
+		"-hls_segment_type", string(segmentType),
+		"-start_number", fmt.Sprint(o.StartSegment),
+		"-hls_segment_filename", filepath.Join(o.OutputDir, "%d."+segmentType.extension()),
+	)
+
+	if segmentType == HLSSegmentTypeFMP4 {
+		// the initialization segment is written relative to the output
+		// directory. Each transcode writes its own so that one being
+		// served is never overwritten.
+		args = append(args, "-hls_fmp4_init_filename", hlsInitSegmentName(o.StartSegment))
+	}
+
+	args = args.Output(filepath.Join(o.OutputDir, "stream.m3u8"))
+
+	return args
+}
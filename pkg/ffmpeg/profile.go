@@ -0,0 +1,153 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TranscodeContainer is the output container of a transcode profile.
+type TranscodeContainer string
+
+const (
+	TranscodeContainerMP4  TranscodeContainer = "mp4"
+	TranscodeContainerWebm TranscodeContainer = "webm"
+	TranscodeContainerMkv  TranscodeContainer = "mkv"
+)
+
+// IsValid returns true if the container is supported for transcoding.
+func (c TranscodeContainer) IsValid() bool {
+	switch c {
+	case TranscodeContainerMP4, TranscodeContainerWebm, TranscodeContainerMkv:
+		return true
+	}
+
+	return false
+}
+
+func (c TranscodeContainer) format() Format {
+	switch c {
+	case TranscodeContainerWebm:
+		return FormatWebm
+	case TranscodeContainerMkv:
+		return FormatMatroska
+	default:
+		return FormatMP4
+	}
+}
+
+// MimeType returns the mime type of the container.
+func (c TranscodeContainer) MimeType() string {
+	switch c {
+	case TranscodeContainerWebm:
+		return MimeWebm
+	case TranscodeContainerMkv:
+		return MimeMkv
+	default:
+		return MimeMp4
+	}
+}
+
+// TranscodeProfile is a named set of encoder settings used for live
+// transcoding and generated transcodes. The video codec may be any ffmpeg
+// encoder, including hardware encoders.
+type TranscodeProfile struct {
+	Name      string
+	Container TranscodeContainer
+
+	VideoCodec VideoCodec
+	// Preset is the encoder preset. Not set if empty.
+	Preset string
+	// CRF is the constant rate factor. Ignored if VideoBitrate is set.
+	CRF int
+	// VideoBitrate is the target video bitrate, for example 2M.
+	VideoBitrate string
+
+	// AudioCodec is the audio encoder. Defaults to the container default if
+	// empty.
+	AudioCodec   AudioCodec
+	AudioBitrate string
+
+	// MaxTranscodeSize is the maximum size of the smaller dimension of the
+	// output video. Zero means the original size.
+	MaxTranscodeSize int
+}
+
+// Validate returns an error if the profile is invalid.
+func (p TranscodeProfile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("transcode profile name cannot be blank")
+	}
+	if !p.Container.IsValid() {
+		return fmt.Errorf("transcode profile %q: invalid container %q", p.Name, p.Container)
+	}
+	if p.VideoCodec == "" {
+		return fmt.Errorf("transcode profile %q: video codec cannot be blank", p.Name)
+	}
+	if p.CRF < 0 {
+		return fmt.Errorf("transcode profile %q: crf must not be negative", p.Name)
+	}
+	if p.MaxTranscodeSize < 0 {
+		return fmt.Errorf("transcode profile %q: max height must not be negative", p.Name)
+	}
+
+	return nil
+}
+
+// VideoArgs returns the ffmpeg arguments for the video encoder settings,
+// excluding the codec.
+func (p TranscodeProfile) VideoArgs() Args {
+	var args Args
+
+	// hardware encoders use their own pixel formats
+	if strings.HasPrefix(string(p.VideoCodec), "lib") {
+		args = append(args, "-pix_fmt", "yuv420p")
+	}
+
+	if p.Preset != "" {
+		args = append(args, "-preset", p.Preset)
+	}
+
+	switch {
+	case p.VideoBitrate != "":
+		args = append(args, "-b:v", p.VideoBitrate)
+	case p.CRF > 0:
+		args = append(args, "-crf", fmt.Sprint(p.CRF))
+		if p.VideoCodec == VideoCodecVP9 {
+			// constant quality mode for vp9
+			args = append(args, "-b:v", "0")
+		}
+	}
+
+	return args
+}
+
+// AudioArgs returns the ffmpeg arguments for the audio encoder settings,
+// excluding the codec.
+func (p TranscodeProfile) AudioArgs() Args {
+	var args Args
+	if p.AudioBitrate != "" {
+		args = args.AudioBitrate(p.AudioBitrate)
+	}
+
+	return args
+}
+
+// StreamFormat returns the StreamFormat used to live transcode using the
+// profile.
+func (p TranscodeProfile) StreamFormat() StreamFormat {
+	var extraArgs []string
+	if p.Container == TranscodeContainerMP4 {
+		extraArgs = append(extraArgs, "-movflags", "frag_keyframe+empty_moov")
+	}
+
+	extraArgs = append(extraArgs, p.VideoArgs()...)
+	extraArgs = append(extraArgs, p.AudioCodec.Args()...)
+	extraArgs = append(extraArgs, p.AudioArgs()...)
+
+	return StreamFormat{
+		MimeType:  p.Container.MimeType(),
+		codec:     p.VideoCodec,
+		format:    p.Container.format(),
+		extraArgs: extraArgs,
+	}
+}
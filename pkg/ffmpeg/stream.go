@@ -11,11 +11,13 @@ import (
 )
 
 const (
-	MimeWebm   string = "video/webm"
-	MimeMkv    string = "video/x-matroska"
-	MimeMp4    string = "video/mp4"
-	MimeHLS    string = "application/vnd.apple.mpegurl"
-	MimeMpegts string = "video/MP2T"
+	MimeWebm     string = "video/webm"
+	MimeMkv      string = "video/x-matroska"
+	MimeMp4      string = "video/mp4"
+	MimeMp4Audio string = "audio/mp4"
+	MimeHLS      string = "application/vnd.apple.mpegurl"
+	MimeDASH     string = "application/dash+xml"
+	MimeMpegts   string = "video/MP2T"
 )
 
 // Stream represents an ongoing transcoded stream.
@@ -134,6 +136,10 @@ type TranscodeStreamOptions struct {
 	StartTime        float64
 	MaxTranscodeSize int
 
+	// Duration limits the length of the stream if greater than zero. It is
+	// ignored for HLS streams, which use a fixed segment length.
+	Duration float64
+
 	// original video dimensions
 	VideoWidth  int
 	VideoHeight int
@@ -156,6 +162,8 @@ func (o TranscodeStreamOptions) getStreamArgs() Args {
 	if o.Codec.hls {
 		// we only serve a fixed segment length
 		args = args.Duration(hlsSegmentLength)
+	} else if o.Duration > 0 {
+		args = args.Duration(o.Duration)
 	}
 
 	args = args.Input(o.Input)
@@ -0,0 +1,29 @@
+package imagephash
+
+import (
+	"fmt"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/corona10/goimagehash"
+
+	"github.com/stashapp/stash/pkg/image"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// Generate returns the perceptual hash of the image. Images in zip files are
+// read from the zip file.
+func Generate(i *models.Image) (*uint64, error) {
+	img, err := image.GetSourceImage(i)
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	hash, err := goimagehash.PerceptionHash(img)
+	if err != nil {
+		return nil, fmt.Errorf("computing phash: %w", err)
+	}
+	hashValue := hash.GetHash()
+	return &hashValue, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/models/json"
 	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/utils"
 )
 
 // ToBasicJSON converts a image object into its JSON object equivalent. It
@@ -24,6 +25,10 @@ func ToBasicJSON(image *models.Image) *jsonschema.Image {
 		newImageJSON.Rating = int(image.Rating.Int64)
 	}
 
+	if image.Phash.Valid {
+		newImageJSON.Phash = utils.PhashToString(image.Phash.Int64)
+	}
+
 	newImageJSON.Organized = image.Organized
 	newImageJSON.OCounter = image.OCounter
 
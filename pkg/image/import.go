@@ -3,6 +3,7 @@ package image
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/stashapp/stash/pkg/models"
@@ -61,6 +62,10 @@ func (i *Importer) imageJSONToImage(imageJSON jsonschema.Image) models.Image {
 	if imageJSON.Rating != 0 {
 		newImage.Rating = sql.NullInt64{Int64: int64(imageJSON.Rating), Valid: true}
 	}
+	if imageJSON.Phash != "" {
+		hash, err := strconv.ParseUint(imageJSON.Phash, 16, 64)
+		newImage.Phash = sql.NullInt64{Int64: int64(hash), Valid: err == nil}
+	}
 
 	newImage.Organized = imageJSON.Organized
 	newImage.OCounter = imageJSON.OCounter
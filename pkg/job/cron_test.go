@@ -0,0 +1,62 @@
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected error", expr)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	from := time.Date(2022, time.March, 15, 10, 30, 20, 0, time.UTC) // a tuesday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2022, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2022, time.March, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2022, time.March, 16, 3, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2022, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2022, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2022, time.March, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2022, time.March, 20, 0, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2022, time.April, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 12 * 1,6 *", time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2022, time.March, 15, 13, 0, 0, 0, time.UTC)},
+		// either day field matches if both are restricted
+		{"0 0 20 * 4", time.Date(2022, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
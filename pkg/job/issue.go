@@ -0,0 +1,40 @@
+package job
+
+import (
+	"context"
+	"time"
+)
+
+// maxJobIssues is the maximum number of issues kept for a job. Further
+// issues are only counted.
+const maxJobIssues = 1000
+
+// IssueLevel is the severity of an Issue.
+type IssueLevel string
+
+const (
+	IssueLevelWarning IssueLevel = "WARNING"
+	IssueLevelError   IssueLevel = "ERROR"
+)
+
+// Issue is a warning or error affecting a single item processed by a job.
+type Issue struct {
+	Level IssueLevel `json:"level"`
+	Time  time.Time  `json:"time"`
+	// Stage is the operation that failed, for example "preview"
+	Stage string `json:"stage,omitempty"`
+	// SceneID and Path identify the affected item where applicable
+	SceneID int    `json:"scene_id,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+type progressCtxKey struct{}
+
+// AddIssue adds an issue to the job executing with ctx. Has no effect if ctx
+// is not the context of a job.
+func AddIssue(ctx context.Context, i Issue) {
+	if p, ok := ctx.Value(progressCtxKey{}).(*Progress); ok {
+		p.AddIssue(i)
+	}
+}
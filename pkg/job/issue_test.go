@@ -0,0 +1,52 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddIssue(t *testing.T) {
+	store := newTestStore()
+	m := NewManager()
+	if err := m.Restore(store, nil); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	const issues = maxJobIssues + 5
+	jobID := m.Add(context.Background(), "job with issues", MakeJobExec(func(ctx context.Context, progress *Progress) {
+		for i := 0; i < issues; i++ {
+			AddIssue(ctx, Issue{
+				Level:   IssueLevelError,
+				Stage:   "test",
+				SceneID: i + 1,
+				Message: "failed",
+			})
+		}
+	}))
+
+	time.Sleep(sleepTime)
+
+	assert := assert.New(t)
+	j := m.GetJob(jobID)
+	if assert.NotNil(j) {
+		// issues do not fail the job
+		assert.Equal(StatusFinished, j.Status)
+		assert.Equal(issues, j.IssueCount)
+		assert.Len(j.Issues, maxJobIssues)
+		assert.Equal(1, j.Issues[0].SceneID)
+		assert.False(j.Issues[0].Time.IsZero())
+	}
+
+	saved, found := store.get(jobID)
+	if assert.True(found) {
+		assert.Equal(issues, saved.IssueCount)
+	}
+}
+
+func TestAddIssueNoJob(t *testing.T) {
+	// must not panic outside of a job
+	AddIssue(context.Background(), Issue{Message: "ignored"})
+}
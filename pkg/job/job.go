@@ -2,6 +2,7 @@ package job
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 )
 
@@ -32,6 +33,9 @@ type Status string
 const (
 	// StatusReady means that the Job is not yet started.
 	StatusReady Status = "READY"
+	// StatusPaused means that the Job is not yet started, and will not be
+	// started until it is resumed.
+	StatusPaused Status = "PAUSED"
 	// StatusRunning means that the job is currently running.
 	StatusRunning Status = "RUNNING"
 	// StatusStopping means that the job is cancelled but is still running.
@@ -40,8 +44,15 @@ const (
 	StatusFinished Status = "FINISHED"
 	// StatusCancelled means that the job was cancelled and is now stopped.
 	StatusCancelled Status = "CANCELLED"
+	// StatusFailed means that the job stopped with an error.
+	StatusFailed Status = "FAILED"
 )
 
+// isDone returns true if the job has stopped and will not be run again.
+func (s Status) isDone() bool {
+	return s == StatusFinished || s == StatusCancelled || s == StatusFailed
+}
+
 // Job represents the status of a queued or running job.
 type Job struct {
 	ID     int
@@ -54,10 +65,26 @@ type Job struct {
 	StartTime *time.Time
 	EndTime   *time.Time
 	AddTime   time.Time
+	// Error is the error that caused the job to fail
+	Error *string
+	// Issues are the warnings and errors affecting individual items.
+	// IssueCount includes the issues that were not kept.
+	Issues     []Issue
+	IssueCount int
+	// Lane is the lane the job runs in. Queued jobs with a higher Priority
+	// are started before the other queued jobs in the same lane.
+	Lane     Lane
+	Priority int
+	// Type and Input are set for Requeueable jobs. Input is the JSON encoded
+	// input options of the job.
+	Type  string
+	Input json.RawMessage
 
 	outerCtx   context.Context
 	exec       JobExec
 	cancelFunc context.CancelFunc
+	// laneSlot is true while the job is counted as running in its lane
+	laneSlot bool
 }
 
 // TimeElapsed returns the total time elapsed for the job.
@@ -73,8 +100,14 @@ func (j *Job) TimeElapsed() time.Duration {
 	return end.Sub(*j.StartTime)
 }
 
+// IsRequeueable returns true if the job can be recreated from its type and
+// input.
+func (j *Job) IsRequeueable() bool {
+	return j.Type != ""
+}
+
 func (j *Job) cancel() {
-	if j.Status == StatusReady {
+	if j.Status == StatusReady || j.Status == StatusPaused {
 		j.Status = StatusCancelled
 	} else if j.Status == StatusRunning {
 		j.Status = StatusStopping
@@ -0,0 +1,72 @@
+package job
+
+// Lane is the category of a job. Jobs in different lanes run concurrently,
+// so that long running jobs using one resource do not block jobs using
+// another. The number of jobs that may run concurrently in a lane is set
+// using Manager.SetLaneConcurrency.
+type Lane string
+
+const (
+	// LaneDefault is the lane of jobs that don't declare a lane.
+	LaneDefault Lane = "DEFAULT"
+	// LaneIO is the lane of jobs that mostly read files, such as scanning.
+	LaneIO Lane = "IO"
+	// LaneCPU is the lane of jobs that mostly use the CPU, such as
+	// generating previews.
+	LaneCPU Lane = "CPU"
+	// LaneNetwork is the lane of jobs that mostly wait on remote servers,
+	// such as identifying scenes.
+	LaneNetwork Lane = "NETWORK"
+)
+
+// AllLanes contains all of the job lanes.
+var AllLanes = []Lane{LaneDefault, LaneIO, LaneCPU, LaneNetwork}
+
+// IsValid returns true if the lane is one of AllLanes.
+func (l Lane) IsValid() bool {
+	for _, v := range AllLanes {
+		if l == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaultLaneConcurrency is the number of jobs that may run concurrently in
+// a lane unless set otherwise.
+const defaultLaneConcurrency = 1
+
+// LaneExec is implemented by JobExecs that run in a lane other than the
+// default lane.
+type LaneExec interface {
+	JobExec
+	// JobLane returns the lane of the job.
+	JobLane() Lane
+}
+
+type laneExec struct {
+	JobExec
+	lane Lane
+}
+
+func (e *laneExec) JobLane() Lane {
+	return e.lane
+}
+
+// WithLane returns a JobExec that executes e in the provided lane. The
+// returned JobExec is not Requeueable.
+func WithLane(e JobExec, lane Lane) JobExec {
+	return &laneExec{
+		JobExec: e,
+		lane:    lane,
+	}
+}
+
+func getLane(e JobExec) Lane {
+	if l, ok := e.(LaneExec); ok && l.JobLane().IsValid() {
+		return l.JobLane()
+	}
+
+	return LaneDefault
+}
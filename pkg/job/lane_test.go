@@ -0,0 +1,152 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func isStarted(e *testExec) bool {
+	select {
+	case <-e.started:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestLanes(t *testing.T) {
+	m := NewManager()
+
+	io1 := newTestExec(make(chan struct{}))
+	io2 := newTestExec(make(chan struct{}))
+	network := newTestExec(make(chan struct{}))
+
+	io1ID := m.Add(context.Background(), "io 1", WithLane(io1, LaneIO))
+	m.Add(context.Background(), "io 2", WithLane(io2, LaneIO))
+	m.Add(context.Background(), "network", WithLane(network, LaneNetwork))
+
+	time.Sleep(sleepTime)
+
+	assert := assert.New(t)
+
+	// jobs in different lanes run side by side
+	assert.True(isStarted(io1))
+	assert.False(isStarted(io2))
+	assert.True(isStarted(network))
+
+	j := m.GetJob(io1ID)
+	assert.Equal(LaneIO, j.Lane)
+
+	// increasing the concurrency starts the waiting job
+	m.SetLaneConcurrency(LaneIO, 2)
+	time.Sleep(sleepTime)
+	assert.True(isStarted(io2))
+
+	close(io1.finish)
+	close(io2.finish)
+	close(network.finish)
+	time.Sleep(sleepTime)
+
+	assert.Len(m.GetQueue(), 0)
+}
+
+func TestPriority(t *testing.T) {
+	m := NewManager()
+
+	exec1 := newTestExec(make(chan struct{}))
+	exec2 := newTestExec(make(chan struct{}))
+	exec3 := newTestExec(make(chan struct{}))
+
+	m.Add(context.Background(), "running", exec1)
+	m.Add(context.Background(), "low", exec2)
+	job3ID := m.Add(context.Background(), "high", exec3)
+
+	assert := assert.New(t)
+	assert.True(m.SetJobPriority(job3ID, 1))
+
+	time.Sleep(sleepTime)
+	close(exec1.finish)
+	time.Sleep(sleepTime)
+
+	// the higher priority job is started first
+	assert.False(isStarted(exec2))
+	assert.True(isStarted(exec3))
+
+	close(exec3.finish)
+	time.Sleep(sleepTime)
+	assert.True(isStarted(exec2))
+	close(exec2.finish)
+}
+
+func TestPauseResume(t *testing.T) {
+	m := NewManager()
+
+	exec1 := newTestExec(make(chan struct{}))
+	exec2 := newTestExec(make(chan struct{}))
+	exec3 := newTestExec(make(chan struct{}))
+
+	job1ID := m.Add(context.Background(), "running", exec1)
+	job2ID := m.Add(context.Background(), "paused", exec2)
+	m.Add(context.Background(), "next", exec3)
+
+	time.Sleep(sleepTime)
+
+	assert := assert.New(t)
+
+	// running jobs cannot be paused
+	assert.False(m.PauseJob(job1ID))
+	assert.True(m.PauseJob(job2ID))
+	assert.Equal(StatusPaused, m.GetJob(job2ID).Status)
+
+	close(exec1.finish)
+	time.Sleep(sleepTime)
+
+	// the paused job is skipped
+	assert.False(isStarted(exec2))
+	assert.True(isStarted(exec3))
+
+	close(exec3.finish)
+	time.Sleep(sleepTime)
+	assert.False(isStarted(exec2))
+
+	assert.True(m.ResumeJob(job2ID))
+	assert.False(m.ResumeJob(job2ID))
+	time.Sleep(sleepTime)
+	assert.True(isStarted(exec2))
+	close(exec2.finish)
+}
+
+func TestMoveJob(t *testing.T) {
+	m := NewManager()
+
+	exec1 := newTestExec(make(chan struct{}))
+	exec2 := newTestExec(make(chan struct{}))
+	exec3 := newTestExec(make(chan struct{}))
+
+	job1ID := m.Add(context.Background(), "running", exec1)
+	job2ID := m.Add(context.Background(), "second", exec2)
+	job3ID := m.Add(context.Background(), "third", exec3)
+
+	assert := assert.New(t)
+	assert.True(m.MoveJob(job3ID, 1))
+	assert.False(m.MoveJob(100, 0))
+
+	var ids []int
+	for _, j := range m.GetQueue() {
+		ids = append(ids, j.ID)
+	}
+	assert.Equal([]int{job1ID, job3ID, job2ID}, ids)
+
+	time.Sleep(sleepTime)
+	close(exec1.finish)
+	time.Sleep(sleepTime)
+
+	assert.False(isStarted(exec2))
+	assert.True(isStarted(exec3))
+
+	close(exec3.finish)
+	close(exec2.finish)
+}
@@ -2,36 +2,63 @@ package job
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
 )
 
 const maxGraveyardSize = 10
 const defaultThrottleLimit = 100 * time.Millisecond
 
-// Manager maintains a queue of jobs. Jobs are executed one at a time.
+// persistThrottleLimit is the minimum time between saving the progress of a
+// job to the store.
+const persistThrottleLimit = 5 * time.Second
+
+// errInterrupted is the error set on stored jobs that were interrupted and
+// could not be requeued.
+var errInterrupted = errors.New("interrupted by restart")
+
+// Manager maintains a queue of jobs. Jobs are executed in their lanes, which
+// each run one job at a time unless set otherwise by SetLaneConcurrency.
 type Manager struct {
 	queue     []*Job
 	graveyard []*Job
 
-	mutex    sync.Mutex
-	notEmpty *sync.Cond
-	stop     chan struct{}
+	mutex sync.Mutex
+	// changed is signalled when a queued job may be able to start
+	changed *sync.Cond
+	stop    chan struct{}
+
+	// concurrency is the maximum number of running jobs of each lane
+	concurrency map[Lane]int
+	running     map[Lane]int
 
 	lastID int
 
 	subscriptions       []*ManagerSubscription
 	updateThrottleLimit time.Duration
+
+	persister *persister
+	factory   Factory
+
+	// contextFunc is applied to the contexts of the jobs before they run
+	contextFunc func(ctx context.Context) context.Context
 }
 
 // NewManager initialises and returns a new Manager.
 func NewManager() *Manager {
 	ret := &Manager{
 		stop:                make(chan struct{}),
+		concurrency:         make(map[Lane]int),
+		running:             make(map[Lane]int),
 		updateThrottleLimit: defaultThrottleLimit,
 	}
 
-	ret.notEmpty = sync.NewCond(&ret.mutex)
+	ret.changed = sync.NewCond(&ret.mutex)
 
 	go ret.dispatcher()
 
@@ -42,59 +69,253 @@ func NewManager() *Manager {
 // more Jobs will be processed.
 func (m *Manager) Stop() {
 	m.CancelAll()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	close(m.stop)
+	m.changed.Broadcast()
 }
 
-// Add queues a job.
-func (m *Manager) Add(ctx context.Context, description string, e JobExec) int {
+// SetLaneConcurrency sets the number of jobs that may run concurrently in
+// the lane. Running jobs are not stopped if the number is reduced.
+func (m *Manager) SetLaneConcurrency(lane Lane, n int) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	t := time.Now()
-
-	j := Job{
-		ID:          m.nextID(),
-		Status:      StatusReady,
-		Description: description,
-		AddTime:     t,
-		exec:        e,
-		outerCtx:    ctx,
+	if n < 1 {
+		n = 1
 	}
 
-	m.queue = append(m.queue, &j)
+	m.concurrency[lane] = n
+	m.changed.Broadcast()
+}
+
+// SetContextFunc sets a function which is applied to the context of each job
+// before it runs. It is used to remove values of the context the job was
+// added with which should not apply to the job.
+func (m *Manager) SetContextFunc(fn func(ctx context.Context) context.Context) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.contextFunc = fn
+}
 
-	if len(m.queue) == 1 {
-		// notify that there is now a job in the queue
-		m.notEmpty.Broadcast()
+func (m *Manager) getLaneConcurrency(lane Lane) int {
+	// assumes lock held
+	if n, ok := m.concurrency[lane]; ok {
+		return n
 	}
 
-	m.notifyNewJob(&j)
+	return defaultLaneConcurrency
+}
+
+// Add queues a job.
+func (m *Manager) Add(ctx context.Context, description string, e JobExec) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	j := m.newJob(ctx, description, e)
+	m.queueJob(j)
 
 	return j.ID
 }
 
 // Start adds a job and starts it immediately, concurrently with any other
-// jobs.
+// jobs. The job is not counted as running in its lane.
 func (m *Manager) Start(ctx context.Context, description string, e JobExec) int {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	t := time.Now()
+	j := m.newJob(ctx, description, e)
+
+	m.queue = append(m.queue, j)
+	m.persist(j)
 
-	j := Job{
+	m.dispatch(ctx, j)
+
+	return j.ID
+}
+
+func (m *Manager) newJob(ctx context.Context, description string, e JobExec) *Job {
+	// assumes lock held
+	j := &Job{
 		ID:          m.nextID(),
 		Status:      StatusReady,
 		Description: description,
-		AddTime:     t,
+		AddTime:     time.Now(),
+		Lane:        getLane(e),
 		exec:        e,
 		outerCtx:    ctx,
 	}
 
-	m.queue = append(m.queue, &j)
+	if r, ok := e.(Requeueable); ok {
+		input, err := json.Marshal(r.JobInput())
+		if err != nil {
+			logger.Warnf("error encoding input of job %q: %v", description, err)
+		} else {
+			j.Type = r.JobType()
+			j.Input = input
+		}
+	}
 
-	m.dispatch(ctx, &j)
+	return j
+}
 
-	return j.ID
+func (m *Manager) queueJob(j *Job) {
+	// assumes lock held
+	m.queue = append(m.queue, j)
+
+	// notify that there is a new job that may be started
+	m.changed.Broadcast()
+
+	m.persist(j)
+	m.notifyNewJob(j)
+}
+
+func (m *Manager) persist(j *Job) {
+	// assumes lock held
+	if m.persister != nil {
+		m.persister.add(*j)
+	}
+}
+
+// Restore sets the store used to persist jobs, then requeues the stored jobs
+// that were interrupted by a restart using factory. Interrupted jobs that
+// cannot be recreated are marked as failed.
+func (m *Manager) Restore(store Store, factory Factory) error {
+	maxID, err := store.MaxID()
+	if err != nil {
+		return err
+	}
+
+	unfinished, err := store.FindUnfinished()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Prune(maxStoredJobs); err != nil {
+		logger.Warnf("error pruning job history: %v", err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// jobs added before the store was first set are numbered from 1, and
+	// would replace the stored jobs with the same ids
+	if m.persister == nil {
+		m.persister = newPersister(store)
+		m.renumberFrom(maxID)
+	}
+	m.factory = factory
+
+	if maxID > m.lastID {
+		m.lastID = maxID
+	}
+
+	for _, j := range m.queue {
+		m.persist(j)
+	}
+
+	for i := range unfinished {
+		j := unfinished[i]
+
+		// jobs in the queue are not interrupted
+		if _, existing := m.getJob(m.queue, j.ID); existing != nil {
+			continue
+		}
+
+		var e JobExec
+		if j.IsRequeueable() {
+			e, err = factory(j.Type, j.Input)
+			if err != nil {
+				logger.Warnf("could not requeue job %d: %v", j.ID, err)
+			}
+		}
+
+		if e == nil {
+			errStr := errInterrupted.Error()
+			t := time.Now()
+			j.Status = StatusFailed
+			j.Error = &errStr
+			j.EndTime = &t
+			m.persist(&j)
+			continue
+		}
+
+		logger.Infof("Requeuing interrupted job: %s", j.Description)
+		if j.Status != StatusPaused {
+			j.Status = StatusReady
+		}
+		j.Lane = getLane(e)
+		j.Progress = 0
+		j.Details = nil
+		j.Error = nil
+		j.Issues = nil
+		j.IssueCount = 0
+		j.StartTime = nil
+		j.EndTime = nil
+		j.exec = e
+		j.outerCtx = context.Background()
+
+		m.queueJob(&j)
+	}
+
+	return nil
+}
+
+// Requeue queues a new job with the type, input and description of j, which
+// may be a stored job that is no longer in the queue. Requires the factory
+// set by Restore.
+func (m *Manager) Requeue(ctx context.Context, j Job) (int, error) {
+	m.mutex.Lock()
+	factory := m.factory
+	m.mutex.Unlock()
+
+	if factory == nil || !j.IsRequeueable() {
+		return 0, fmt.Errorf("job %d cannot be requeued", j.ID)
+	}
+
+	e, err := factory(j.Type, j.Input)
+	if err != nil {
+		return 0, fmt.Errorf("recreating job %d: %w", j.ID, err)
+	}
+
+	return m.Add(ctx, j.Description, e), nil
+}
+
+// renumberFrom gives the jobs in the queue and graveyard new ids following
+// maxID, notifying the subscriptions that the jobs with the old ids were
+// removed.
+func (m *Manager) renumberFrom(maxID int) {
+	// assumes lock held
+	if maxID == 0 {
+		return
+	}
+
+	if maxID > m.lastID {
+		m.lastID = maxID
+	}
+
+	renumber := func(j *Job) {
+		for _, s := range m.subscriptions {
+			// don't block if channel is full
+			select {
+			case s.removedJob <- *j:
+			default:
+			}
+		}
+
+		j.ID = m.nextID()
+		m.notifyNewJob(j)
+	}
+
+	for _, j := range m.queue {
+		renumber(j)
+	}
+	for _, j := range m.graveyard {
+		renumber(j)
+	}
 }
 
 func (m *Manager) notifyNewJob(j *Job) {
@@ -113,47 +334,51 @@ func (m *Manager) nextID() int {
 	return m.lastID
 }
 
-func (m *Manager) getReadyJob() *Job {
+// getNextJob returns the ready job that should be started next, or nil if
+// none of the ready jobs can be started because their lanes are full. Jobs
+// with a higher priority are started first, then in queue order.
+func (m *Manager) getNextJob() *Job {
 	// assumes lock held
+	var ret *Job
 	for _, j := range m.queue {
-		if j.Status == StatusReady {
-			return j
+		if j.Status != StatusReady || m.running[j.Lane] >= m.getLaneConcurrency(j.Lane) {
+			continue
+		}
+
+		if ret == nil || j.Priority > ret.Priority {
+			ret = j
 		}
 	}
 
-	return nil
+	return ret
 }
 
 func (m *Manager) dispatcher() {
 	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
 	for {
 		// wait until we have something to process
-		j := m.getReadyJob()
+		j := m.getNextJob()
 
 		for j == nil {
-			m.notEmpty.Wait()
+			m.changed.Wait()
 
 			// it's possible that we have been stopped - check here
 			select {
 			case <-m.stop:
-				m.mutex.Unlock()
 				return
 			default:
 				// keep going
-				j = m.getReadyJob()
+				j = m.getNextJob()
 			}
 		}
 
-		done := m.dispatch(j.outerCtx, j)
-
-		// unlock the mutex and wait for the job to finish
-		m.mutex.Unlock()
-		<-done
-		m.mutex.Lock()
+		// the lane slot is released when the job finishes
+		m.running[j.Lane]++
+		j.laneSlot = true
 
-		// remove the job from the queue
-		m.removeJob(j)
+		m.dispatch(j.outerCtx, j)
 
 		// process next job
 	}
@@ -169,41 +394,58 @@ func (m *Manager) newProgress(j *Job) *Progress {
 	}
 }
 
-func (m *Manager) dispatch(ctx context.Context, j *Job) (done chan struct{}) {
+func (m *Manager) dispatch(ctx context.Context, j *Job) {
 	// assumes lock held
 	t := time.Now()
 	j.StartTime = &t
 	j.Status = StatusRunning
 
+	if m.contextFunc != nil {
+		ctx = m.contextFunc(ctx)
+	}
+
 	ctx, cancelFunc := context.WithCancel(valueOnlyContext{ctx})
 	j.cancelFunc = cancelFunc
 
-	done = make(chan struct{})
+	// allow issues to be added from the tasks of the job
+	progress := m.newProgress(j)
+	ctx = context.WithValue(ctx, progressCtxKey{}, progress)
+
 	go func() {
-		progress := m.newProgress(j)
 		j.exec.Execute(ctx, progress)
 
 		m.onJobFinish(j)
-
-		close(done)
 	}()
 
+	m.persist(j)
 	m.notifyJobUpdate(j)
-
-	return
 }
 
 func (m *Manager) onJobFinish(job *Job) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if job.Status == StatusStopping {
+	switch {
+	case job.Status == StatusStopping:
 		job.Status = StatusCancelled
-	} else {
+	case job.Error != nil:
+		job.Status = StatusFailed
+	default:
 		job.Status = StatusFinished
 	}
 	t := time.Now()
 	job.EndTime = &t
+
+	if job.laneSlot {
+		m.running[job.Lane]--
+		job.laneSlot = false
+
+		// another job in the lane may now be started
+		m.changed.Broadcast()
+	}
+
+	// remove the job from the queue
+	m.removeJob(job)
 }
 
 func (m *Manager) removeJob(job *Job) {
@@ -217,6 +459,7 @@ func (m *Manager) removeJob(job *Job) {
 	job.Details = nil
 
 	m.queue = append(m.queue[:index], m.queue[index+1:]...)
+	m.persist(job)
 
 	m.graveyard = append(m.graveyard, job)
 	if len(m.graveyard) > maxGraveyardSize {
@@ -261,6 +504,8 @@ func (m *Manager) CancelJob(id int) {
 		if j.Status == StatusCancelled {
 			// remove from the queue
 			m.removeJob(j)
+		} else {
+			m.persist(j)
 		}
 	}
 }
@@ -278,10 +523,96 @@ func (m *Manager) CancelAll() {
 		if j.Status == StatusCancelled {
 			// add to graveyard
 			m.removeJob(j)
+		} else {
+			m.persist(j)
 		}
 	}
 }
 
+// PauseJob prevents the queued job with the provided id from being started
+// until it is resumed. Returns false if the job is not waiting to start.
+func (m *Manager) PauseJob(id int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, j := m.getJob(m.queue, id)
+	if j == nil || j.Status != StatusReady {
+		return false
+	}
+
+	j.Status = StatusPaused
+	m.persist(j)
+	m.notifyJobUpdate(j)
+
+	return true
+}
+
+// ResumeJob allows the paused job with the provided id to be started.
+// Returns false if the job is not paused.
+func (m *Manager) ResumeJob(id int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, j := m.getJob(m.queue, id)
+	if j == nil || j.Status != StatusPaused {
+		return false
+	}
+
+	j.Status = StatusReady
+	m.persist(j)
+	m.notifyJobUpdate(j)
+	m.changed.Broadcast()
+
+	return true
+}
+
+// SetJobPriority sets the priority of the queued job with the provided id.
+// Returns false if the job is not in the queue.
+func (m *Manager) SetJobPriority(id int, priority int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, j := m.getJob(m.queue, id)
+	if j == nil {
+		return false
+	}
+
+	j.Priority = priority
+	m.persist(j)
+	m.notifyJobUpdate(j)
+	m.changed.Broadcast()
+
+	return true
+}
+
+// MoveJob moves the job with the provided id to position in the queue,
+// which determines the order that jobs with the same priority are started.
+// Returns false if the job is not in the queue.
+func (m *Manager) MoveJob(id int, position int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	index, j := m.getJob(m.queue, id)
+	if j == nil {
+		return false
+	}
+
+	if position < 0 {
+		position = 0
+	}
+	if position >= len(m.queue) {
+		position = len(m.queue) - 1
+	}
+
+	m.queue = append(m.queue[:index], m.queue[index+1:]...)
+	m.queue = append(m.queue[:position], append([]*Job{j}, m.queue[position:]...)...)
+
+	m.notifyJobUpdate(j)
+	m.changed.Broadcast()
+
+	return true
+}
+
 // GetJob returns a copy of the Job for the provided id. Returns nil if the job
 // does not exist.
 func (m *Manager) GetJob(id int) *Job {
@@ -343,9 +674,9 @@ func (m *Manager) Subscribe(ctx context.Context) *ManagerSubscription {
 }
 
 func (m *Manager) notifyJobUpdate(j *Job) {
-	// don't update if job is finished or cancelled - these are handled
-	// by removeJob
-	if j.Status == StatusCancelled || j.Status == StatusFinished {
+	// don't update if job is finished, failed or cancelled - these are
+	// handled by removeJob
+	if j.Status == StatusCancelled || j.Status == StatusFinished || j.Status == StatusFailed {
 		return
 	}
 
@@ -363,6 +694,7 @@ type updater struct {
 	m           *Manager
 	job         *Job
 	lastUpdate  time.Time
+	lastPersist time.Time
 	updateTimer *time.Timer
 }
 
@@ -373,6 +705,24 @@ func (u *updater) notifyUpdate() {
 	u.updateTimer = nil
 }
 
+func (u *updater) addIssue(i Issue) {
+	u.m.mutex.Lock()
+	defer u.m.mutex.Unlock()
+
+	u.job.IssueCount++
+	if len(u.job.Issues) < maxJobIssues {
+		u.job.Issues = append(u.job.Issues, i)
+	}
+}
+
+func (u *updater) setError(err error) {
+	u.m.mutex.Lock()
+	defer u.m.mutex.Unlock()
+
+	errStr := err.Error()
+	u.job.Error = &errStr
+}
+
 func (u *updater) updateProgress(progress float64, details []string) {
 	u.m.mutex.Lock()
 	defer u.m.mutex.Unlock()
@@ -380,6 +730,11 @@ func (u *updater) updateProgress(progress float64, details []string) {
 	u.job.Progress = progress
 	u.job.Details = details
 
+	if time.Since(u.lastPersist) >= persistThrottleLimit {
+		u.m.persist(u.job)
+		u.lastPersist = time.Now()
+	}
+
 	if time.Since(u.lastUpdate) < u.m.updateThrottleLimit {
 		if u.updateTimer == nil {
 			u.updateTimer = time.AfterFunc(u.m.updateThrottleLimit-time.Since(u.lastUpdate), func() {
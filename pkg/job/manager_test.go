@@ -124,6 +124,34 @@ func TestAdd(t *testing.T) {
 	assert.NotNil(j2.StartTime)
 }
 
+type contextTestKey struct{}
+
+type contextTestExec struct {
+	value chan interface{}
+}
+
+func (e contextTestExec) Execute(ctx context.Context, p *Progress) {
+	e.value <- ctx.Value(contextTestKey{})
+}
+
+func TestSetContextFunc(t *testing.T) {
+	m := NewManager()
+	m.SetContextFunc(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextTestKey{}, nil)
+	})
+
+	ctx := context.WithValue(context.Background(), contextTestKey{}, "user")
+	e := contextTestExec{value: make(chan interface{}, 1)}
+	m.Add(ctx, "test job", e)
+
+	select {
+	case v := <-e.value:
+		assert.Nil(t, v)
+	case <-time.After(time.Second):
+		t.Error("job did not run")
+	}
+}
+
 func TestCancel(t *testing.T) {
 	m := NewManager()
 
@@ -1,6 +1,9 @@
 package job
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 // ProgressIndefinite is the special percent value to indicate that the
 // percent progress is not known.
@@ -141,6 +144,22 @@ func (p *Progress) removeTask(t *task) {
 	}
 }
 
+// AddIssue adds a warning or error affecting a single item to the job. The
+// time of the issue is set if not already set.
+func (p *Progress) AddIssue(i Issue) {
+	if i.Time.IsZero() {
+		i.Time = time.Now()
+	}
+
+	p.updater.addIssue(i)
+}
+
+// SetError sets the error that caused the job to fail. The job status is set
+// to failed when the job returns, unless it was cancelled.
+func (p *Progress) SetError(err error) {
+	p.updater.setError(err)
+}
+
 // ExecuteTask executes a task as part of a job. The description is used to
 // populate the Details slice in the parent Job.
 func (p *Progress) ExecuteTask(description string, fn func()) {
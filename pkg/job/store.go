@@ -0,0 +1,120 @@
+package job
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/stashapp/stash/pkg/logger"
+)
+
+// maxStoredJobs is the number of jobs kept in the job history.
+const maxStoredJobs = 1000
+
+// Store persists the jobs of a Manager, so that the job history is kept and
+// interrupted jobs can be requeued after a restart.
+type Store interface {
+	// Save creates or updates the stored job.
+	Save(j Job) error
+	// FindUnfinished returns the stored jobs that are ready, paused, running
+	// or stopping, in the order they were added.
+	FindUnfinished() ([]Job, error)
+	// MaxID returns the highest stored job id, or 0 if there are no stored
+	// jobs.
+	MaxID() (int, error)
+	// Prune deletes the oldest stored jobs that are done, keeping the most
+	// recent keep jobs.
+	Prune(keep int) error
+}
+
+// Requeueable is implemented by JobExecs that can be recreated from their
+// type and input by a Factory.
+type Requeueable interface {
+	JobExec
+	// JobType returns the type passed to the Factory to recreate the job.
+	JobType() string
+	// JobInput returns the input options of the job. The options are
+	// stored as JSON.
+	JobInput() interface{}
+}
+
+// Factory recreates the JobExec of a job from its type and JSON encoded
+// input options.
+type Factory func(jobType string, input json.RawMessage) (JobExec, error)
+
+// persister saves jobs to the store in the background, so that jobs are not
+// blocked by database writes. Only the latest state of each job is saved.
+type persister struct {
+	store Store
+
+	mutex   sync.Mutex
+	pending map[int]Job
+	order   []int
+	signal  chan struct{}
+}
+
+func newPersister(store Store) *persister {
+	ret := &persister{
+		store:   store,
+		pending: make(map[int]Job),
+		signal:  make(chan struct{}, 1),
+	}
+
+	go ret.run()
+
+	return ret
+}
+
+func (p *persister) add(j Job) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, found := p.pending[j.ID]; !found {
+		p.order = append(p.order, j.ID)
+	}
+	p.pending[j.ID] = j
+
+	// don't block if already signalled
+	select {
+	case p.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (p *persister) take() []Job {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	ret := make([]Job, len(p.order))
+	for i, id := range p.order {
+		ret[i] = p.pending[id]
+	}
+
+	p.pending = make(map[int]Job)
+	p.order = nil
+
+	return ret
+}
+
+func (p *persister) run() {
+	for range p.signal {
+		prune := false
+		for _, j := range p.take() {
+			if err := p.store.Save(j); err != nil {
+				logger.Warnf("error saving job %d: %v", j.ID, err)
+			}
+
+			prune = prune || j.Status.isDone()
+		}
+
+		// the history only grows when jobs finish
+		if prune {
+			p.prune()
+		}
+	}
+}
+
+func (p *persister) prune() {
+	if err := p.store.Prune(maxStoredJobs); err != nil {
+		logger.Warnf("error pruning job history: %v", err)
+	}
+}
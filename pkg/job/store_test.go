@@ -0,0 +1,210 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStore struct {
+	mutex      sync.Mutex
+	saved      map[int]Job
+	unfinished []Job
+	maxID      int
+}
+
+func newTestStore() *testStore {
+	return &testStore{
+		saved: make(map[int]Job),
+	}
+}
+
+func (s *testStore) Save(j Job) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.saved[j.ID] = j
+	return nil
+}
+
+func (s *testStore) FindUnfinished() ([]Job, error) {
+	return s.unfinished, nil
+}
+
+func (s *testStore) MaxID() (int, error) {
+	return s.maxID, nil
+}
+
+func (s *testStore) Prune(keep int) error {
+	return nil
+}
+
+func (s *testStore) get(id int) (Job, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j, found := s.saved[id]
+	return j, found
+}
+
+type testRequeueableExec struct {
+	*testExec
+	input string
+}
+
+func (e *testRequeueableExec) JobType() string {
+	return "test"
+}
+
+func (e *testRequeueableExec) JobInput() interface{} {
+	return e.input
+}
+
+func TestRestore(t *testing.T) {
+	const requeueInput = "input"
+	encodedInput, _ := json.Marshal(requeueInput)
+
+	store := newTestStore()
+	store.maxID = 10
+	store.unfinished = []Job{
+		{ID: 9, Status: StatusRunning, Description: "requeueable", Type: "test", Input: encodedInput},
+		{ID: 10, Status: StatusReady, Description: "not requeueable"},
+	}
+
+	var recreated []string
+	factory := func(jobType string, input json.RawMessage) (JobExec, error) {
+		var i string
+		if err := json.Unmarshal(input, &i); err != nil {
+			return nil, err
+		}
+		recreated = append(recreated, i)
+		return &testRequeueableExec{testExec: newTestExec(make(chan struct{})), input: i}, nil
+	}
+
+	m := NewManager()
+	if err := m.Restore(store, factory); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	assert := assert.New(t)
+	assert.Equal([]string{requeueInput}, recreated)
+
+	// interrupted requeueable job is requeued with the same id
+	time.Sleep(sleepTime)
+	j := m.GetJob(9)
+	if assert.NotNil(j) {
+		assert.Equal(StatusRunning, j.Status)
+	}
+
+	// other interrupted jobs are failed
+	saved, found := store.get(10)
+	if assert.True(found) {
+		assert.Equal(StatusFailed, saved.Status)
+		assert.NotNil(saved.Error)
+	}
+
+	// new job ids follow the stored ids
+	jobID := m.Add(context.Background(), "new job", newTestExec(nil))
+	assert.Equal(11, jobID)
+
+	time.Sleep(sleepTime)
+	saved, found = store.get(jobID)
+	if assert.True(found) {
+		assert.Equal("new job", saved.Description)
+	}
+}
+
+func TestRestoreRenumbersAddedJobs(t *testing.T) {
+	store := newTestStore()
+	store.maxID = 10
+
+	m := NewManager()
+
+	// jobs added before the store is set must not replace the stored jobs
+	exec := newTestExec(make(chan struct{}))
+	jobID := m.Add(context.Background(), "added before restore", exec)
+
+	assert := assert.New(t)
+	assert.Equal(1, jobID)
+
+	if err := m.Restore(store, nil); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	assert.Nil(m.GetJob(jobID))
+	j := m.GetJob(11)
+	if assert.NotNil(j) {
+		assert.Equal("added before restore", j.Description)
+	}
+
+	time.Sleep(sleepTime)
+	_, found := store.get(jobID)
+	assert.False(found)
+	_, found = store.get(11)
+	assert.True(found)
+
+	assert.Equal(12, m.Add(context.Background(), "new job", newTestExec(nil)))
+
+	close(exec.finish)
+}
+
+func TestRequeue(t *testing.T) {
+	m := NewManager()
+	if err := m.Restore(newTestStore(), func(jobType string, input json.RawMessage) (JobExec, error) {
+		return &testRequeueableExec{testExec: newTestExec(nil)}, nil
+	}); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	exec := &testRequeueableExec{testExec: newTestExec(nil), input: "input"}
+	jobID := m.Add(context.Background(), "requeueable", exec)
+	time.Sleep(sleepTime)
+
+	j := m.GetJob(jobID)
+	assert := assert.New(t)
+	if !assert.NotNil(j) {
+		return
+	}
+	assert.True(j.IsRequeueable())
+
+	newID, err := m.Requeue(context.Background(), *j)
+	assert.Nil(err)
+	assert.NotEqual(jobID, newID)
+
+	_, err = m.Requeue(context.Background(), Job{ID: 100})
+	assert.NotNil(err)
+}
+
+func TestSetError(t *testing.T) {
+	store := newTestStore()
+	m := NewManager()
+	if err := m.Restore(store, nil); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	jobErr := errors.New("test error")
+	jobID := m.Add(context.Background(), "failing job", MakeJobExec(func(ctx context.Context, progress *Progress) {
+		progress.SetError(jobErr)
+	}))
+
+	time.Sleep(sleepTime)
+
+	assert := assert.New(t)
+	j := m.GetJob(jobID)
+	if assert.NotNil(j) {
+		assert.Equal(StatusFailed, j.Status)
+		if assert.NotNil(j.Error) {
+			assert.Equal(jobErr.Error(), *j.Error)
+		}
+	}
+
+	saved, found := store.get(jobID)
+	if assert.True(found) {
+		assert.Equal(StatusFailed, saved.Status)
+	}
+}
@@ -0,0 +1,288 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// FilterQueryOperator is the comparison operator of a filter query term.
+type FilterQueryOperator string
+
+const (
+	FilterQueryOperatorNone         FilterQueryOperator = ""
+	FilterQueryOperatorEquals       FilterQueryOperator = "="
+	FilterQueryOperatorGreater      FilterQueryOperator = ">"
+	FilterQueryOperatorGreaterEqual FilterQueryOperator = ">="
+	FilterQueryOperatorLess         FilterQueryOperator = "<"
+	FilterQueryOperatorLessEqual    FilterQueryOperator = "<="
+	FilterQueryOperatorRegex        FilterQueryOperator = "~"
+)
+
+// filterQueryOperators are ordered so that the longest operators are
+// matched first.
+var filterQueryOperators = []FilterQueryOperator{
+	FilterQueryOperatorGreaterEqual,
+	FilterQueryOperatorLessEqual,
+	FilterQueryOperatorEquals,
+	FilterQueryOperatorGreater,
+	FilterQueryOperatorLess,
+	FilterQueryOperatorRegex,
+}
+
+const (
+	filterQueryFieldSeparator = ':'
+	filterQueryValueSeparator = ','
+	filterQueryEscapeChar     = '\\'
+)
+
+// FilterQueryValue is a value of a filter query term.
+type FilterQueryValue struct {
+	Value string
+	// Quoted is true if the value was quoted. Quoted values are never
+	// treated as keywords such as none.
+	Quoted bool
+}
+
+// FilterQueryTerm is a field criterion of a filter query, such as
+// -tag:"a","b".
+type FilterQueryTerm struct {
+	Field    string
+	Negated  bool
+	Operator FilterQueryOperator
+	Values   []FilterQueryValue
+	// Position is the position of the term in the query, starting at 1.
+	Position int
+}
+
+// FilterQuery is a parsed textual filter query.
+type FilterQuery struct {
+	Terms []FilterQueryTerm
+	// Text is the free text of the query which is not part of a term. It
+	// is used as the q value of the find filter.
+	Text string
+}
+
+// FilterQueryError is returned for filter queries which cannot be parsed
+// or applied.
+type FilterQueryError struct {
+	// Position is the position of the error in the query, starting at 1.
+	Position int
+	Message  string
+}
+
+func (e *FilterQueryError) Error() string {
+	return fmt.Sprintf("filter query: position %d: %s", e.Position, e.Message)
+}
+
+func filterQueryErrorf(position int, format string, args ...interface{}) error {
+	return &FilterQueryError{
+		Position: position,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+type filterQueryParser struct {
+	s   []rune
+	pos int
+}
+
+func (p *filterQueryParser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *filterQueryParser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *filterQueryParser) skipSpace() {
+	for !p.done() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// fieldEnd returns the position of the field separator if the query has a
+// field name at pos, or -1 otherwise. Field names start with a letter and
+// consist of letters, digits and underscores.
+func (p *filterQueryParser) fieldEnd(pos int) int {
+	if pos >= len(p.s) || !unicode.IsLetter(p.s[pos]) {
+		return -1
+	}
+
+	for i := pos; i < len(p.s); i++ {
+		r := p.s[i]
+		switch {
+		case r == filterQueryFieldSeparator:
+			return i
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			continue
+		default:
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// readText reads a word of free text, including any quoted phrases.
+func (p *filterQueryParser) readText() (string, error) {
+	start := p.pos
+	quoteStart := -1
+	for ; !p.done(); p.pos++ {
+		r := p.peek()
+		if r == phraseChar {
+			if quoteStart == -1 {
+				quoteStart = p.pos
+			} else {
+				quoteStart = -1
+			}
+			continue
+		}
+
+		if quoteStart == -1 && unicode.IsSpace(r) {
+			break
+		}
+	}
+
+	if quoteStart != -1 {
+		return "", filterQueryErrorf(quoteStart+1, "unterminated quoted phrase")
+	}
+
+	return string(p.s[start:p.pos]), nil
+}
+
+func (p *filterQueryParser) readQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+
+	var b strings.Builder
+	for ; !p.done(); p.pos++ {
+		r := p.peek()
+		switch r {
+		case phraseChar:
+			p.pos++
+			return b.String(), nil
+		case filterQueryEscapeChar:
+			if p.pos+1 < len(p.s) {
+				p.pos++
+				r = p.peek()
+			}
+		}
+		b.WriteRune(r)
+	}
+
+	return "", filterQueryErrorf(start+1, "unterminated quoted value")
+}
+
+func (p *filterQueryParser) readBare() string {
+	start := p.pos
+	for !p.done() {
+		r := p.peek()
+		if unicode.IsSpace(r) || r == filterQueryValueSeparator || r == phraseChar {
+			break
+		}
+		p.pos++
+	}
+
+	return string(p.s[start:p.pos])
+}
+
+func (p *filterQueryParser) readTerm(negated bool, fieldEnd int) (*FilterQueryTerm, error) {
+	ret := &FilterQueryTerm{
+		Field:    strings.ToLower(string(p.s[p.pos:fieldEnd])),
+		Negated:  negated,
+		Position: p.pos + 1,
+	}
+	if negated {
+		ret.Position--
+	}
+
+	p.pos = fieldEnd + 1
+
+	rest := string(p.s[p.pos:])
+	for _, op := range filterQueryOperators {
+		if strings.HasPrefix(rest, string(op)) {
+			ret.Operator = op
+			p.pos += len([]rune(op))
+			break
+		}
+	}
+
+	for {
+		valuePos := p.pos + 1
+		var v FilterQueryValue
+		if p.peek() == phraseChar {
+			value, err := p.readQuoted()
+			if err != nil {
+				return nil, err
+			}
+			v = FilterQueryValue{Value: value, Quoted: true}
+		} else {
+			v = FilterQueryValue{Value: p.readBare()}
+			if v.Value == "" {
+				return nil, filterQueryErrorf(valuePos, "missing value for field %q", ret.Field)
+			}
+		}
+
+		ret.Values = append(ret.Values, v)
+
+		if p.peek() != filterQueryValueSeparator {
+			break
+		}
+		p.pos++
+	}
+
+	if !p.done() && !unicode.IsSpace(p.peek()) {
+		return nil, filterQueryErrorf(p.pos+1, "unexpected %q after value of field %q", p.peek(), ret.Field)
+	}
+
+	return ret, nil
+}
+
+// ParseFilterQuery parses a textual filter query.
+//
+// A query consists of terms of the form field:value, separated by spaces.
+// Values containing spaces must be quoted, with quotes and backslashes in
+// quoted values escaped by a backslash. Multiple values are separated by
+// commas. The value may be preceded by one of the operators =, >, >=, <,
+// <= and ~. A term is negated by a leading -.
+//
+// Words which are not terms are returned as the free text of the query.
+// Free text containing a colon must be quoted.
+func ParseFilterQuery(s string) (*FilterQuery, error) {
+	p := &filterQueryParser{s: []rune(s)}
+	ret := &FilterQuery{}
+
+	var text []string
+	for p.skipSpace(); !p.done(); p.skipSpace() {
+		negated := p.peek() == notPrefix
+		fieldStart := p.pos
+		if negated {
+			fieldStart++
+		}
+
+		if fieldEnd := p.fieldEnd(fieldStart); fieldEnd != -1 {
+			p.pos = fieldStart
+			term, err := p.readTerm(negated, fieldEnd)
+			if err != nil {
+				return nil, err
+			}
+
+			ret.Terms = append(ret.Terms, *term)
+			continue
+		}
+
+		word, err := p.readText()
+		if err != nil {
+			return nil, err
+		}
+		text = append(text, word)
+	}
+
+	ret.Text = strings.Join(text, " ")
+
+	return ret, nil
+}
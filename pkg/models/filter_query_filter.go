@@ -0,0 +1,611 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	filterQueryNone       = "none"
+	filterQueryIDPrefix   = "#"
+	filterQueryRangeSep   = ".."
+	filterQueryDuration   = "duration"
+	filterQueryStructTag  = "json"
+	filterQueryParents    = "parents"
+	filterQueryChildren   = "children"
+	filterQueryDeprecated = "tag_id"
+)
+
+// filterQueryAliases maps alternative field names to the names of the
+// fields of the filter types.
+var filterQueryAliases = map[string]string{
+	"tag":           "tags",
+	"performer":     "performers",
+	"studio":        "studios",
+	"movie":         "movies",
+	"gallery":       "galleries",
+	"parent":        filterQueryParents,
+	"child":         filterQueryChildren,
+	"performer_tag": "performer_tags",
+	"scene_tag":     "scene_tags",
+	"favorite":      "filter_favorites",
+}
+
+// filterQueryObjectTypes are the types of the objects referenced by the
+// values of multi-value fields.
+var filterQueryObjectTypes = map[string]SearchResultType{
+	"tags":           SearchResultTypeTag,
+	"performer_tags": SearchResultTypeTag,
+	"scene_tags":     SearchResultTypeTag,
+	"performers":     SearchResultTypePerformer,
+	"studios":        SearchResultTypeStudio,
+	"movies":         SearchResultTypeMovie,
+	"galleries":      SearchResultTypeGallery,
+}
+
+// filterQueryHierarchyTypes are the types of the objects referenced by the
+// parents and children fields of each filter type.
+var filterQueryHierarchyTypes = map[reflect.Type]SearchResultType{
+	reflect.TypeOf(StudioFilterType{}): SearchResultTypeStudio,
+	reflect.TypeOf(TagFilterType{}):    SearchResultTypeTag,
+}
+
+// filterQueryResolutions maps the display names of resolutions to their
+// values.
+var filterQueryResolutions = map[string]ResolutionEnum{
+	"144p":  ResolutionEnumVeryLow,
+	"240p":  ResolutionEnumLow,
+	"360p":  ResolutionEnumR360p,
+	"480p":  ResolutionEnumStandard,
+	"540p":  ResolutionEnumWebHd,
+	"720p":  ResolutionEnumStandardHd,
+	"1080p": ResolutionEnumFullHd,
+	"1440p": ResolutionEnumQuadHd,
+	"1920p": ResolutionEnumVrHd,
+	"4k":    ResolutionEnumFourK,
+	"5k":    ResolutionEnumFiveK,
+	"6k":    ResolutionEnumSixK,
+	"8k":    ResolutionEnumEightK,
+}
+
+// FilterQueryResolver resolves the names used in filter queries.
+type FilterQueryResolver interface {
+	// ResolveName returns the id of the object of type t with the provided
+	// name, ignoring case. Returns ErrNotFound if there is no such object.
+	ResolveName(t SearchResultType, name string) (int, error)
+}
+
+type repositoryFilterQueryResolver struct {
+	repo ReaderRepository
+}
+
+// NewFilterQueryResolver returns a FilterQueryResolver which finds tags,
+// performers, studios and movies by name in the repository.
+func NewFilterQueryResolver(repo ReaderRepository) FilterQueryResolver {
+	return &repositoryFilterQueryResolver{
+		repo: repo,
+	}
+}
+
+func (r *repositoryFilterQueryResolver) ResolveName(t SearchResultType, name string) (int, error) {
+	switch t {
+	case SearchResultTypeTag:
+		tag, err := r.repo.Tag().FindByName(name, true)
+		if err != nil || tag == nil {
+			return 0, notFoundIfNil(err)
+		}
+		return tag.ID, nil
+	case SearchResultTypePerformer:
+		performers, err := r.repo.Performer().FindByNames([]string{name}, true)
+		if err != nil || len(performers) == 0 {
+			return 0, notFoundIfNil(err)
+		}
+		return performers[0].ID, nil
+	case SearchResultTypeStudio:
+		studio, err := r.repo.Studio().FindByName(name, true)
+		if err != nil || studio == nil {
+			return 0, notFoundIfNil(err)
+		}
+		return studio.ID, nil
+	case SearchResultTypeMovie:
+		movie, err := r.repo.Movie().FindByName(name, true)
+		if err != nil || movie == nil {
+			return 0, notFoundIfNil(err)
+		}
+		return movie.ID, nil
+	}
+
+	return 0, fmt.Errorf("cannot find %s objects by name", t)
+}
+
+func notFoundIfNil(err error) error {
+	if err == nil {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Apply sets the criteria of the terms of the query in filter, which must
+// be a pointer to one of the filter types such as SceneFilterType. The
+// criteria already set in filter cannot be set by the query.
+//
+// Fields are the fields of the filter type, or their singular form for
+// multi-value fields. Values are interpreted as follows:
+//   - string fields include the value, equal it with = or match the
+//     regular expression with ~.
+//   - number fields equal the value, are compared to it with >, >=, <
+//     and <=, or are between the bounds of a range such as 1..3. Durations
+//     are given in seconds or with units such as 1h30m.
+//   - multi-value fields include any of the values, or all of them with =.
+//     Objects are referenced by name or by id, such as #12. Repeating the
+//     field includes all of the values.
+//   - the value none matches objects with no value for the field.
+//
+// Negated terms match the objects which the term would not match.
+func (q *FilterQuery) Apply(filter interface{}, r FilterQueryResolver) error {
+	v := reflect.ValueOf(filter)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("invalid filter type %T", filter)
+	}
+
+	a := &filterQueryApplier{
+		filter:   v.Elem(),
+		resolver: r,
+		fields:   make(map[string]reflect.Value),
+		set:      make(map[string]bool),
+	}
+
+	filterType := a.filter.Type()
+	for i := 0; i < filterType.NumField(); i++ {
+		name := strings.Split(filterType.Field(i).Tag.Get(filterQueryStructTag), ",")[0]
+		// uppercase fields are sub-filters
+		if name == "" || name == filterQueryDeprecated || strings.ToUpper(name) == name {
+			continue
+		}
+		a.fields[name] = a.filter.Field(i)
+	}
+
+	for _, t := range q.Terms {
+		if err := a.apply(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type filterQueryApplier struct {
+	filter   reflect.Value
+	resolver FilterQueryResolver
+	fields   map[string]reflect.Value
+	// set are the fields which were set by the query
+	set map[string]bool
+}
+
+func (a *filterQueryApplier) apply(t FilterQueryTerm) error {
+	name := t.Field
+	if alias, found := filterQueryAliases[name]; found {
+		name = alias
+	}
+
+	field, found := a.fields[name]
+	if !found {
+		return filterQueryErrorf(t.Position, "unknown field %q", t.Field)
+	}
+
+	repeated := a.set[name]
+	if !repeated && !field.IsNil() {
+		return filterQueryErrorf(t.Position, "field %q is already set by the filter", t.Field)
+	}
+
+	var err error
+	switch ptr := field.Addr().Interface().(type) {
+	case **MultiCriterionInput:
+		var c *MultiCriterionInput
+		if *ptr != nil {
+			c = *ptr
+		} else {
+			c = &MultiCriterionInput{}
+		}
+		err = a.setMulti(t, name, repeated, &c.Modifier, &c.Value)
+		*ptr = c
+	case **HierarchicalMultiCriterionInput:
+		var c *HierarchicalMultiCriterionInput
+		if *ptr != nil {
+			c = *ptr
+		} else {
+			c = &HierarchicalMultiCriterionInput{}
+		}
+		err = a.setMulti(t, name, repeated, &c.Modifier, &c.Value)
+		*ptr = c
+	default:
+		if repeated {
+			return filterQueryErrorf(t.Position, "field %q is specified more than once", t.Field)
+		}
+		err = a.setSingle(t, name, ptr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	a.set[name] = true
+	return nil
+}
+
+func (a *filterQueryApplier) setSingle(t FilterQueryTerm, name string, ptr interface{}) error {
+	if len(t.Values) > 1 {
+		return filterQueryErrorf(t.Position, "field %q takes a single value", t.Field)
+	}
+	v := t.Values[0]
+
+	var err error
+	switch ptr := ptr.(type) {
+	case **StringCriterionInput:
+		*ptr, err = filterQueryStringCriterion(t, v)
+	case **IntCriterionInput:
+		*ptr, err = filterQueryIntCriterion(t, v, name == filterQueryDuration)
+	case **TimestampCriterionInput:
+		*ptr, err = filterQueryTimestampCriterion(t, v)
+	case **ResolutionCriterionInput:
+		*ptr, err = filterQueryResolutionCriterion(t, v)
+	case **GenderCriterionInput:
+		*ptr, err = filterQueryGenderCriterion(t, v)
+	case **bool:
+		*ptr, err = filterQueryBool(t, v)
+	case **string:
+		if t.Negated || t.Operator != FilterQueryOperatorNone {
+			return filterQueryErrorf(t.Position, "field %q cannot be negated or compared", t.Field)
+		}
+		value := v.Value
+		*ptr = &value
+	default:
+		return filterQueryErrorf(t.Position, "field %q is not supported by filter queries", t.Field)
+	}
+
+	return err
+}
+
+func (t FilterQueryTerm) unsupportedOperator() error {
+	return filterQueryErrorf(t.Position, "operator %q is not supported by field %q", t.Operator, t.Field)
+}
+
+func (v FilterQueryValue) isNone() bool {
+	return !v.Quoted && strings.EqualFold(v.Value, filterQueryNone)
+}
+
+// nullModifier returns the modifier of terms with the value none.
+func (t FilterQueryTerm) nullModifier() (CriterionModifier, error) {
+	if t.Operator != FilterQueryOperatorNone {
+		return "", t.unsupportedOperator()
+	}
+
+	if t.Negated {
+		return CriterionModifierNotNull, nil
+	}
+	return CriterionModifierIsNull, nil
+}
+
+// modifier returns the modifier for the term, or its negated modifier if
+// the term is negated.
+func (t FilterQueryTerm) modifier(m, negated CriterionModifier) CriterionModifier {
+	if t.Negated {
+		return negated
+	}
+	return m
+}
+
+func filterQueryStringCriterion(t FilterQueryTerm, v FilterQueryValue) (*StringCriterionInput, error) {
+	ret := &StringCriterionInput{
+		Value: v.Value,
+	}
+
+	if v.isNone() {
+		var err error
+		ret.Value = ""
+		ret.Modifier, err = t.nullModifier()
+		return ret, err
+	}
+
+	switch t.Operator {
+	case FilterQueryOperatorNone:
+		ret.Modifier = t.modifier(CriterionModifierIncludes, CriterionModifierExcludes)
+	case FilterQueryOperatorEquals:
+		ret.Modifier = t.modifier(CriterionModifierEquals, CriterionModifierNotEquals)
+	case FilterQueryOperatorRegex:
+		ret.Modifier = t.modifier(CriterionModifierMatchesRegex, CriterionModifierNotMatchesRegex)
+	default:
+		return nil, t.unsupportedOperator()
+	}
+
+	return ret, nil
+}
+
+func filterQueryInt(t FilterQueryTerm, s string, duration bool) (int, error) {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i, nil
+	}
+
+	if duration {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, filterQueryErrorf(t.Position, "invalid duration %q for field %q", s, t.Field)
+		}
+		return int(d.Seconds()), nil
+	}
+
+	return 0, filterQueryErrorf(t.Position, "invalid number %q for field %q", s, t.Field)
+}
+
+func filterQueryIntCriterion(t FilterQueryTerm, v FilterQueryValue, duration bool) (*IntCriterionInput, error) {
+	ret := &IntCriterionInput{}
+
+	if v.isNone() {
+		var err error
+		ret.Modifier, err = t.nullModifier()
+		return ret, err
+	}
+
+	if bounds := strings.SplitN(v.Value, filterQueryRangeSep, 2); len(bounds) == 2 {
+		if t.Operator != FilterQueryOperatorNone {
+			return nil, t.unsupportedOperator()
+		}
+
+		lower, err := filterQueryInt(t, bounds[0], duration)
+		if err != nil {
+			return nil, err
+		}
+		upper, err := filterQueryInt(t, bounds[1], duration)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Value = lower
+		ret.Value2 = &upper
+		ret.Modifier = t.modifier(CriterionModifierBetween, CriterionModifierNotBetween)
+		return ret, nil
+	}
+
+	value, err := filterQueryInt(t, v.Value, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	// there are no inclusive comparison modifiers, so inclusive comparisons
+	// use the exclusive comparison of the adjacent value
+	ret.Value = value
+	switch t.Operator {
+	case FilterQueryOperatorNone, FilterQueryOperatorEquals:
+		ret.Modifier = t.modifier(CriterionModifierEquals, CriterionModifierNotEquals)
+	case FilterQueryOperatorGreater:
+		if t.Negated {
+			ret.Value = value + 1
+		}
+		ret.Modifier = t.modifier(CriterionModifierGreaterThan, CriterionModifierLessThan)
+	case FilterQueryOperatorGreaterEqual:
+		if !t.Negated {
+			ret.Value = value - 1
+		}
+		ret.Modifier = t.modifier(CriterionModifierGreaterThan, CriterionModifierLessThan)
+	case FilterQueryOperatorLess:
+		if t.Negated {
+			ret.Value = value - 1
+		}
+		ret.Modifier = t.modifier(CriterionModifierLessThan, CriterionModifierGreaterThan)
+	case FilterQueryOperatorLessEqual:
+		if !t.Negated {
+			ret.Value = value + 1
+		}
+		ret.Modifier = t.modifier(CriterionModifierLessThan, CriterionModifierGreaterThan)
+	default:
+		return nil, t.unsupportedOperator()
+	}
+
+	return ret, nil
+}
+
+func filterQueryTimestampCriterion(t FilterQueryTerm, v FilterQueryValue) (*TimestampCriterionInput, error) {
+	ret := &TimestampCriterionInput{
+		Value: v.Value,
+	}
+
+	if v.isNone() {
+		var err error
+		ret.Value = ""
+		ret.Modifier, err = t.nullModifier()
+		return ret, err
+	}
+
+	if bounds := strings.SplitN(v.Value, filterQueryRangeSep, 2); len(bounds) == 2 {
+		if t.Operator != FilterQueryOperatorNone {
+			return nil, t.unsupportedOperator()
+		}
+
+		ret.Value = bounds[0]
+		ret.Value2 = &bounds[1]
+		ret.Modifier = t.modifier(CriterionModifierBetween, CriterionModifierNotBetween)
+		return ret, nil
+	}
+
+	// timestamps are only compared exclusively, so comparisons cannot be
+	// negated
+	switch {
+	case t.Operator == FilterQueryOperatorNone || t.Operator == FilterQueryOperatorEquals:
+		ret.Modifier = t.modifier(CriterionModifierEquals, CriterionModifierNotEquals)
+	case t.Operator == FilterQueryOperatorGreater && !t.Negated:
+		ret.Modifier = CriterionModifierGreaterThan
+	case t.Operator == FilterQueryOperatorLess && !t.Negated:
+		ret.Modifier = CriterionModifierLessThan
+	default:
+		return nil, t.unsupportedOperator()
+	}
+
+	return ret, nil
+}
+
+func filterQueryResolutionCriterion(t FilterQueryTerm, v FilterQueryValue) (*ResolutionCriterionInput, error) {
+	value, found := filterQueryResolutions[strings.ToLower(v.Value)]
+	if !found {
+		value = ResolutionEnum(strings.ToUpper(v.Value))
+	}
+	if !value.IsValid() {
+		return nil, filterQueryErrorf(t.Position, "invalid resolution %q for field %q", v.Value, t.Field)
+	}
+
+	ret := &ResolutionCriterionInput{
+		Value: value,
+	}
+
+	switch {
+	case t.Operator == FilterQueryOperatorNone || t.Operator == FilterQueryOperatorEquals:
+		ret.Modifier = t.modifier(CriterionModifierEquals, CriterionModifierNotEquals)
+	case t.Operator == FilterQueryOperatorGreater && !t.Negated:
+		ret.Modifier = CriterionModifierGreaterThan
+	case t.Operator == FilterQueryOperatorLess && !t.Negated:
+		ret.Modifier = CriterionModifierLessThan
+	default:
+		return nil, t.unsupportedOperator()
+	}
+
+	return ret, nil
+}
+
+func filterQueryGenderCriterion(t FilterQueryTerm, v FilterQueryValue) (*GenderCriterionInput, error) {
+	ret := &GenderCriterionInput{}
+
+	if v.isNone() {
+		var err error
+		ret.Modifier, err = t.nullModifier()
+		return ret, err
+	}
+
+	value := GenderEnum(strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToUpper(v.Value)))
+	if !value.IsValid() {
+		return nil, filterQueryErrorf(t.Position, "invalid gender %q for field %q", v.Value, t.Field)
+	}
+	ret.Value = &value
+
+	if t.Operator != FilterQueryOperatorNone && t.Operator != FilterQueryOperatorEquals {
+		return nil, t.unsupportedOperator()
+	}
+	ret.Modifier = t.modifier(CriterionModifierEquals, CriterionModifierNotEquals)
+
+	return ret, nil
+}
+
+func filterQueryBool(t FilterQueryTerm, v FilterQueryValue) (*bool, error) {
+	if t.Operator != FilterQueryOperatorNone {
+		return nil, t.unsupportedOperator()
+	}
+
+	var ret bool
+	switch strings.ToLower(v.Value) {
+	case "true", "yes":
+		ret = true
+	case "false", "no":
+		ret = false
+	default:
+		return nil, filterQueryErrorf(t.Position, "invalid boolean %q for field %q", v.Value, t.Field)
+	}
+
+	if t.Negated {
+		ret = !ret
+	}
+
+	return &ret, nil
+}
+
+func (a *filterQueryApplier) objectType(name string) SearchResultType {
+	if name == filterQueryParents || name == filterQueryChildren {
+		return filterQueryHierarchyTypes[a.filter.Type()]
+	}
+	return filterQueryObjectTypes[name]
+}
+
+func (a *filterQueryApplier) resolveIDs(t FilterQueryTerm, name string) ([]string, error) {
+	objectType := a.objectType(name)
+
+	var ret []string
+	for _, v := range t.Values {
+		if !v.Quoted && strings.HasPrefix(v.Value, filterQueryIDPrefix) {
+			id := strings.TrimPrefix(v.Value, filterQueryIDPrefix)
+			if _, err := strconv.Atoi(id); err != nil {
+				return nil, filterQueryErrorf(t.Position, "invalid id %q for field %q", v.Value, t.Field)
+			}
+			ret = append(ret, id)
+			continue
+		}
+
+		if objectType == SearchResultTypeGallery || objectType == "" {
+			return nil, filterQueryErrorf(t.Position, "field %q only accepts ids such as #1", t.Field)
+		}
+
+		id, err := a.resolver.ResolveName(objectType, v.Value)
+		if errors.Is(err, ErrNotFound) {
+			return nil, filterQueryErrorf(t.Position, "%s %q not found", strings.ToLower(string(objectType)), v.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, strconv.Itoa(id))
+	}
+
+	return ret, nil
+}
+
+// setMulti sets the modifier and values of a multi-value criterion. Terms
+// of fields which were already set by the query are combined with the
+// existing criterion where possible.
+func (a *filterQueryApplier) setMulti(t FilterQueryTerm, name string, repeated bool, modifier *CriterionModifier, values *[]string) error {
+	if t.Values[0].isNone() {
+		if repeated {
+			return filterQueryErrorf(t.Position, "field %q is specified more than once", t.Field)
+		}
+		if len(t.Values) > 1 {
+			return filterQueryErrorf(t.Position, "field %q takes a single value with none", t.Field)
+		}
+
+		m, err := t.nullModifier()
+		*modifier = m
+		return err
+	}
+
+	var m CriterionModifier
+	switch {
+	case t.Negated && t.Operator == FilterQueryOperatorNone:
+		m = CriterionModifierExcludes
+	case t.Operator == FilterQueryOperatorNone && len(t.Values) == 1:
+		// a single value is both included and included by all
+		m = CriterionModifierIncludesAll
+	case t.Operator == FilterQueryOperatorNone:
+		m = CriterionModifierIncludes
+	case t.Operator == FilterQueryOperatorEquals && !t.Negated:
+		m = CriterionModifierIncludesAll
+	default:
+		return t.unsupportedOperator()
+	}
+
+	ids, err := a.resolveIDs(t, name)
+	if err != nil {
+		return err
+	}
+
+	if repeated {
+		switch {
+		case *modifier == CriterionModifierExcludes && m == CriterionModifierExcludes,
+			*modifier == CriterionModifierIncludesAll && m == CriterionModifierIncludesAll:
+		case *modifier == CriterionModifierExcludes || m == CriterionModifierExcludes:
+			return filterQueryErrorf(t.Position, "field %q cannot be both included and excluded", t.Field)
+		default:
+			return filterQueryErrorf(t.Position, "field %q with alternative values cannot be specified more than once", t.Field)
+		}
+	}
+
+	*modifier = m
+	*values = append(*values, ids...)
+	return nil
+}
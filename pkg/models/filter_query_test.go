@@ -0,0 +1,297 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFilterQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want *FilterQuery
+	}{
+		{
+			"empty",
+			"  ",
+			&FilterQuery{},
+		},
+		{
+			"text",
+			`foo -bar "a b" | c`,
+			&FilterQuery{
+				Text: `foo -bar "a b" | c`,
+			},
+		},
+		{
+			"terms",
+			`tag:"blow job" rating:>=4 -performer:"Y" duration:<10m`,
+			&FilterQuery{
+				Terms: []FilterQueryTerm{
+					{Field: "tag", Values: []FilterQueryValue{{"blow job", true}}, Position: 1},
+					{Field: "rating", Operator: FilterQueryOperatorGreaterEqual, Values: []FilterQueryValue{{"4", false}}, Position: 16},
+					{Field: "performer", Negated: true, Values: []FilterQueryValue{{"Y", true}}, Position: 27},
+					{Field: "duration", Operator: FilterQueryOperatorLess, Values: []FilterQueryValue{{"10m", false}}, Position: 42},
+				},
+			},
+		},
+		{
+			"mixed",
+			`beach Tag:a,"b \"c\"",#3 sunset`,
+			&FilterQuery{
+				Terms: []FilterQueryTerm{
+					{Field: "tag", Values: []FilterQueryValue{{"a", false}, {`b "c"`, true}, {"#3", false}}, Position: 7},
+				},
+				Text: "beach sunset",
+			},
+		},
+		{
+			"operators",
+			`title:~^a path:=b`,
+			&FilterQuery{
+				Terms: []FilterQueryTerm{
+					{Field: "title", Operator: FilterQueryOperatorRegex, Values: []FilterQueryValue{{"^a", false}}, Position: 1},
+					{Field: "path", Operator: FilterQueryOperatorEquals, Values: []FilterQueryValue{{"b", false}}, Position: 11},
+				},
+			},
+		},
+		{
+			"non-field colons",
+			`12:30 "a:b" -:c`,
+			&FilterQuery{
+				Text: `12:30 "a:b" -:c`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilterQuery(tt.s)
+			if err != nil {
+				t.Errorf("ParseFilterQuery() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilterQuery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterQueryErrors(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{`tag:"a`, `filter query: position 5: unterminated quoted value`},
+		{`a "b c`, `filter query: position 3: unterminated quoted phrase`},
+		{`rating: 4`, `filter query: position 8: missing value for field "rating"`},
+		{`tag:a, b`, `filter query: position 7: missing value for field "tag"`},
+		{`tag:"a"b`, `filter query: position 8: unexpected 'b' after value of field "tag"`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseFilterQuery(tt.s)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("ParseFilterQuery(%q) error = %v, want %s", tt.s, err, tt.want)
+		}
+	}
+}
+
+type testFilterQueryResolver map[SearchResultType]map[string]int
+
+func (r testFilterQueryResolver) ResolveName(t SearchResultType, name string) (int, error) {
+	id, found := r[t][strings.ToLower(name)]
+	if !found {
+		return 0, ErrNotFound
+	}
+	return id, nil
+}
+
+var filterQueryTestResolver = testFilterQueryResolver{
+	SearchResultTypeTag: {
+		"a": 1,
+		"b": 2,
+	},
+	SearchResultTypePerformer: {
+		"y": 3,
+	},
+	SearchResultTypeStudio: {
+		"x": 4,
+	},
+}
+
+func applyTestFilterQuery(s string, filter interface{}) error {
+	q, err := ParseFilterQuery(s)
+	if err != nil {
+		return err
+	}
+
+	return q.Apply(filter, filterQueryTestResolver)
+}
+
+func TestFilterQueryApply(t *testing.T) {
+	intPtr := func(i int) *int {
+		return &i
+	}
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
+	strPtr := func(s string) *string {
+		return &s
+	}
+	female := GenderEnumFemale
+
+	tests := []struct {
+		name   string
+		s      string
+		filter interface{}
+		want   interface{}
+	}{
+		{
+			"scene",
+			`tag:"A" rating:>=4 studio:"X" -performer:"Y" duration:<10m`,
+			&SceneFilterType{},
+			&SceneFilterType{
+				Tags:       &HierarchicalMultiCriterionInput{Value: []string{"1"}, Modifier: CriterionModifierIncludesAll},
+				Rating:     &IntCriterionInput{Value: 3, Modifier: CriterionModifierGreaterThan},
+				Studios:    &HierarchicalMultiCriterionInput{Value: []string{"4"}, Modifier: CriterionModifierIncludesAll},
+				Performers: &MultiCriterionInput{Value: []string{"3"}, Modifier: CriterionModifierExcludes},
+				Duration:   &IntCriterionInput{Value: 600, Modifier: CriterionModifierLessThan},
+			},
+		},
+		{
+			"repeated multi-value fields",
+			`tag:a tag:=b,#7 -performer:y -performer:#8`,
+			&ImageFilterType{},
+			&ImageFilterType{
+				Tags:       &HierarchicalMultiCriterionInput{Value: []string{"1", "2", "7"}, Modifier: CriterionModifierIncludesAll},
+				Performers: &MultiCriterionInput{Value: []string{"3", "8"}, Modifier: CriterionModifierExcludes},
+			},
+		},
+		{
+			"strings",
+			`name:a -details:b url:=c -aliases:~d country:none -ethnicity:none`,
+			&PerformerFilterType{},
+			&PerformerFilterType{
+				Name:      &StringCriterionInput{Value: "a", Modifier: CriterionModifierIncludes},
+				Details:   &StringCriterionInput{Value: "b", Modifier: CriterionModifierExcludes},
+				URL:       &StringCriterionInput{Value: "c", Modifier: CriterionModifierEquals},
+				Aliases:   &StringCriterionInput{Value: "d", Modifier: CriterionModifierNotMatchesRegex},
+				Country:   &StringCriterionInput{Modifier: CriterionModifierIsNull},
+				Ethnicity: &StringCriterionInput{Modifier: CriterionModifierNotNull},
+			},
+		},
+		{
+			"numbers",
+			`rating:3 -scene_count:2 image_count:1..4 -gallery_count:<=2 tag_count:<2 -birth_year:>1990 age:none`,
+			&PerformerFilterType{},
+			&PerformerFilterType{
+				Rating:       &IntCriterionInput{Value: 3, Modifier: CriterionModifierEquals},
+				SceneCount:   &IntCriterionInput{Value: 2, Modifier: CriterionModifierNotEquals},
+				ImageCount:   &IntCriterionInput{Value: 1, Value2: intPtr(4), Modifier: CriterionModifierBetween},
+				GalleryCount: &IntCriterionInput{Value: 2, Modifier: CriterionModifierGreaterThan},
+				TagCount:     &IntCriterionInput{Value: 2, Modifier: CriterionModifierLessThan},
+				BirthYear:    &IntCriterionInput{Value: 1991, Modifier: CriterionModifierLessThan},
+				Age:          &IntCriterionInput{Modifier: CriterionModifierIsNull},
+			},
+		},
+		{
+			"other types",
+			`favorite:yes gender:Female is_missing:image`,
+			&PerformerFilterType{},
+			&PerformerFilterType{
+				FilterFavorites: boolPtr(true),
+				Gender:          &GenderCriterionInput{Value: &female, Modifier: CriterionModifierEquals},
+				IsMissing:       strPtr("image"),
+			},
+		},
+		{
+			"scene types",
+			`-organized:true resolution:>720p last_played_at:2022-01-01..2022-02-01 duration:1h30m..2h -tag:none`,
+			&SceneFilterType{},
+			&SceneFilterType{
+				Organized:    boolPtr(false),
+				Resolution:   &ResolutionCriterionInput{Value: ResolutionEnumStandardHd, Modifier: CriterionModifierGreaterThan},
+				LastPlayedAt: &TimestampCriterionInput{Value: "2022-01-01", Value2: strPtr("2022-02-01"), Modifier: CriterionModifierBetween},
+				Duration:     &IntCriterionInput{Value: 5400, Value2: intPtr(7200), Modifier: CriterionModifierBetween},
+				Tags:         &HierarchicalMultiCriterionInput{Modifier: CriterionModifierNotNull},
+			},
+		},
+		{
+			"parents",
+			`parent:x`,
+			&StudioFilterType{},
+			&StudioFilterType{
+				Parents: &MultiCriterionInput{Value: []string{"4"}, Modifier: CriterionModifierIncludesAll},
+			},
+		},
+		{
+			"tag parents",
+			`parent:a,b`,
+			&TagFilterType{},
+			&TagFilterType{
+				Parents: &HierarchicalMultiCriterionInput{Value: []string{"1", "2"}, Modifier: CriterionModifierIncludes},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := applyTestFilterQuery(tt.s, tt.filter); err != nil {
+				t.Errorf("Apply() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(tt.filter, tt.want) {
+				t.Errorf("Apply() = %+v, want %+v", tt.filter, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterQueryApplyErrors(t *testing.T) {
+	tests := []struct {
+		s      string
+		filter interface{}
+		want   string
+	}{
+		{`foo:a`, &SceneFilterType{}, `filter query: position 1: unknown field "foo"`},
+		{`and:a`, &SceneFilterType{}, `filter query: position 1: unknown field "and"`},
+		{`x -tag:c`, &SceneFilterType{}, `filter query: position 3: tag "c" not found`},
+		{`rating:high`, &SceneFilterType{}, `filter query: position 1: invalid number "high" for field "rating"`},
+		{`duration:>1y`, &MovieFilterType{}, `filter query: position 1: invalid duration "1y" for field "duration"`},
+		{`title:>a`, &SceneFilterType{}, `filter query: position 1: operator ">" is not supported by field "title"`},
+		{`title:a,b`, &SceneFilterType{}, `filter query: position 1: field "title" takes a single value`},
+		{`rating:1 rating:2`, &SceneFilterType{}, `filter query: position 10: field "rating" is specified more than once`},
+		{`tag:a -tag:b`, &SceneFilterType{}, `filter query: position 7: field "tag" cannot be both included and excluded`},
+		{`tag:a,b tag:a`, &SceneFilterType{}, `filter query: position 9: field "tag" with alternative values cannot be specified more than once`},
+		{`gallery:a`, &ImageFilterType{}, `filter query: position 1: field "gallery" only accepts ids such as #1`},
+		{`duplicated:true`, &SceneFilterType{}, `filter query: position 1: field "duplicated" is not supported by filter queries`},
+		{`organized:maybe`, &SceneFilterType{}, `filter query: position 1: invalid boolean "maybe" for field "organized"`},
+		{`resolution:huge`, &SceneFilterType{}, `filter query: position 1: invalid resolution "huge" for field "resolution"`},
+		{`-is_missing:cover`, &SceneFilterType{}, `filter query: position 1: field "is_missing" cannot be negated or compared`},
+	}
+
+	for _, tt := range tests {
+		err := applyTestFilterQuery(tt.s, tt.filter)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Apply(%q) error = %v, want %s", tt.s, err, tt.want)
+		}
+	}
+
+	// criteria set by the filter cannot be set by the query
+	filter := &SceneFilterType{
+		Rating: &IntCriterionInput{Value: 1, Modifier: CriterionModifierEquals},
+	}
+	err := applyTestFilterQuery(`rating:2`, filter)
+	var queryErr *FilterQueryError
+	if !errors.As(err, &queryErr) || queryErr.Message != `field "rating" is already set by the filter` {
+		t.Errorf("Apply() error = %v", err)
+	}
+
+	if err := applyTestFilterQuery(`rating:2`, SceneFilterType{}); err == nil {
+		t.Errorf("Apply() with non-pointer filter should return an error")
+	}
+}
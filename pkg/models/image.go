@@ -45,6 +45,10 @@ type ImageReader interface {
 	FindByGalleryID(galleryID int) ([]*Image, error)
 	CountByGalleryID(galleryID int) (int, error)
 	FindByPath(path string) (*Image, error)
+	// FindDuplicates returns the groups of images with phashes within the
+	// distance of the options of each other. Groups are ordered by the
+	// total size of their files.
+	FindDuplicates(options DuplicateOptions) ([][]*Image, error)
 	// FindByPerformerID(performerID int) ([]*Image, error)
 	// CountByPerformerID(performerID int) (int, error)
 	// FindByStudioID(studioID int) ([]*Image, error)
@@ -0,0 +1,25 @@
+package models
+
+type JobRecordReader interface {
+	Find(id int) (*JobRecord, error)
+	// FindUnfinished returns the jobs that are not finished, failed or
+	// cancelled, in the order they were added.
+	FindUnfinished() ([]*JobRecord, error)
+	// Query returns the jobs with one of the provided statuses, or all jobs
+	// if statuses is empty. Jobs are sorted by most recently added by default.
+	Query(statuses []JobStatus, findFilter *FindFilterType) ([]*JobRecord, int, error)
+	MaxID() (int, error)
+}
+
+type JobRecordWriter interface {
+	// Save creates the job or replaces the existing job with the same id.
+	Save(job JobRecord) error
+	// Prune deletes the oldest finished, failed and cancelled jobs, keeping
+	// the most recent keep jobs.
+	Prune(keep int) error
+}
+
+type JobRecordReaderWriter interface {
+	JobRecordReader
+	JobRecordWriter
+}
@@ -18,6 +18,7 @@ type ImageFile struct {
 type Image struct {
 	Title      string        `json:"title,omitempty"`
 	Checksum   string        `json:"checksum,omitempty"`
+	Phash      string        `json:"phash,omitempty"`
 	Studio     string        `json:"studio,omitempty"`
 	Rating     int           `json:"rating,omitempty"`
 	Organized  bool          `json:"organized,omitempty"`
@@ -12,6 +12,7 @@ import (
 type SceneMarker struct {
 	Title      string        `json:"title,omitempty"`
 	Seconds    string        `json:"seconds,omitempty"`
+	EndSeconds string        `json:"end_seconds,omitempty"`
 	PrimaryTag string        `json:"primary_tag,omitempty"`
 	Tags       []string      `json:"tags,omitempty"`
 	CreatedAt  json.JSONTime `json:"created_at,omitempty"`
@@ -19,6 +20,11 @@ type SceneMarker struct {
 }
 
 type SceneFile struct {
+	// Path, Checksum and OSHash are only set for the additional files of a
+	// scene. The primary file is identified by the scene mapping.
+	Path       string        `json:"path,omitempty"`
+	Checksum   string        `json:"checksum,omitempty"`
+	OSHash     string        `json:"oshash,omitempty"`
 	ModTime    json.JSONTime `json:"mod_time,omitempty"`
 	Size       string        `json:"size"`
 	Duration   string        `json:"duration"`
@@ -54,6 +60,7 @@ type Scene struct {
 	Tags       []string         `json:"tags,omitempty"`
 	Markers    []SceneMarker    `json:"markers,omitempty"`
 	File       *SceneFile       `json:"file,omitempty"`
+	Files      []SceneFile      `json:"files,omitempty"`
 	Cover      string           `json:"cover,omitempty"`
 	CreatedAt  json.JSONTime    `json:"created_at,omitempty"`
 	UpdatedAt  json.JSONTime    `json:"updated_at,omitempty"`
@@ -227,6 +227,29 @@ func (_m *ImageReaderWriter) FindByPath(path string) (*models.Image, error) {
 	return r0, r1
 }
 
+// FindDuplicates provides a mock function with given fields: options
+func (_m *ImageReaderWriter) FindDuplicates(options models.DuplicateOptions) ([][]*models.Image, error) {
+	ret := _m.Called(options)
+
+	var r0 [][]*models.Image
+	if rf, ok := ret.Get(0).(func(models.DuplicateOptions) [][]*models.Image); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]*models.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.DuplicateOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindMany provides a mock function with given fields: ids
 func (_m *ImageReaderWriter) FindMany(ids []int) ([]*models.Image, error) {
 	ret := _m.Called(ids)
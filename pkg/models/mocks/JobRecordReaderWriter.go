@@ -0,0 +1,138 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/stashapp/stash/pkg/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// JobRecordReaderWriter is an autogenerated mock type for the JobRecordReaderWriter type
+type JobRecordReaderWriter struct {
+	mock.Mock
+}
+
+// Find provides a mock function with given fields: id
+func (_m *JobRecordReaderWriter) Find(id int) (*models.JobRecord, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.JobRecord
+	if rf, ok := ret.Get(0).(func(int) *models.JobRecord); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.JobRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindUnfinished provides a mock function with given fields:
+func (_m *JobRecordReaderWriter) FindUnfinished() ([]*models.JobRecord, error) {
+	ret := _m.Called()
+
+	var r0 []*models.JobRecord
+	if rf, ok := ret.Get(0).(func() []*models.JobRecord); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.JobRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MaxID provides a mock function with given fields:
+func (_m *JobRecordReaderWriter) MaxID() (int, error) {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Prune provides a mock function with given fields: keep
+func (_m *JobRecordReaderWriter) Prune(keep int) error {
+	ret := _m.Called(keep)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(keep)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Query provides a mock function with given fields: statuses, findFilter
+func (_m *JobRecordReaderWriter) Query(statuses []models.JobStatus, findFilter *models.FindFilterType) ([]*models.JobRecord, int, error) {
+	ret := _m.Called(statuses, findFilter)
+
+	var r0 []*models.JobRecord
+	if rf, ok := ret.Get(0).(func([]models.JobStatus, *models.FindFilterType) []*models.JobRecord); ok {
+		r0 = rf(statuses, findFilter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.JobRecord)
+		}
+	}
+
+	var r1 int
+	if rf, ok := ret.Get(1).(func([]models.JobStatus, *models.FindFilterType) int); ok {
+		r1 = rf(statuses, findFilter)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func([]models.JobStatus, *models.FindFilterType) error); ok {
+		r2 = rf(statuses, findFilter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Save provides a mock function with given fields: job
+func (_m *JobRecordReaderWriter) Save(job models.JobRecord) error {
+	ret := _m.Called(job)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.JobRecord) error); ok {
+		r0 = rf(job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
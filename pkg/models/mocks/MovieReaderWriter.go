@@ -333,6 +333,20 @@ func (_m *MovieReaderWriter) GetFrontImage(movieID int) ([]byte, error) {
 	return r0, r1
 }
 
+// Merge provides a mock function with given fields: source, destination
+func (_m *MovieReaderWriter) Merge(source []int, destination int) error {
+	ret := _m.Called(source, destination)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]int, int) error); ok {
+		r0 = rf(source, destination)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Query provides a mock function with given fields: movieFilter, findFilter
 func (_m *MovieReaderWriter) Query(movieFilter *models.MovieFilterType, findFilter *models.FindFilterType) ([]*models.Movie, int, error) {
 	ret := _m.Called(movieFilter, findFilter)
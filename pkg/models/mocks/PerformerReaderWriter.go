@@ -404,6 +404,20 @@ func (_m *PerformerReaderWriter) GetTagIDs(performerID int) ([]int, error) {
 	return r0, r1
 }
 
+// Merge provides a mock function with given fields: source, destination
+func (_m *PerformerReaderWriter) Merge(source []int, destination int) error {
+	ret := _m.Called(source, destination)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]int, int) error); ok {
+		r0 = rf(source, destination)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Query provides a mock function with given fields: performerFilter, findFilter
 func (_m *PerformerReaderWriter) Query(performerFilter *models.PerformerFilterType, findFilter *models.FindFilterType) ([]*models.Performer, int, error) {
 	ret := _m.Called(performerFilter, findFilter)
@@ -0,0 +1,276 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/stashapp/stash/pkg/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RestrictionProfileReaderWriter is an autogenerated mock type for the RestrictionProfileReaderWriter type
+type RestrictionProfileReaderWriter struct {
+	mock.Mock
+}
+
+// All provides a mock function with given fields:
+func (_m *RestrictionProfileReaderWriter) All() ([]*models.RestrictionProfile, error) {
+	ret := _m.Called()
+
+	var r0 []*models.RestrictionProfile
+	if rf, ok := ret.Get(0).(func() []*models.RestrictionProfile); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.RestrictionProfile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: newProfile
+func (_m *RestrictionProfileReaderWriter) Create(newProfile models.RestrictionProfile) (*models.RestrictionProfile, error) {
+	ret := _m.Called(newProfile)
+
+	var r0 *models.RestrictionProfile
+	if rf, ok := ret.Get(0).(func(models.RestrictionProfile) *models.RestrictionProfile); ok {
+		r0 = rf(newProfile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RestrictionProfile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.RestrictionProfile) error); ok {
+		r1 = rf(newProfile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Destroy provides a mock function with given fields: id
+func (_m *RestrictionProfileReaderWriter) Destroy(id int) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Find provides a mock function with given fields: id
+func (_m *RestrictionProfileReaderWriter) Find(id int) (*models.RestrictionProfile, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.RestrictionProfile
+	if rf, ok := ret.Get(0).(func(int) *models.RestrictionProfile); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RestrictionProfile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByName provides a mock function with given fields: name
+func (_m *RestrictionProfileReaderWriter) FindByName(name string) (*models.RestrictionProfile, error) {
+	ret := _m.Called(name)
+
+	var r0 *models.RestrictionProfile
+	if rf, ok := ret.Get(0).(func(string) *models.RestrictionProfile); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RestrictionProfile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetContentRestriction provides a mock function with given fields: id
+func (_m *RestrictionProfileReaderWriter) GetContentRestriction(id int) (*models.ContentRestriction, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.ContentRestriction
+	if rf, ok := ret.Get(0).(func(int) *models.ContentRestriction); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ContentRestriction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPerformerIDs provides a mock function with given fields: id
+func (_m *RestrictionProfileReaderWriter) GetPerformerIDs(id int) ([]int, error) {
+	ret := _m.Called(id)
+
+	var r0 []int
+	if rf, ok := ret.Get(0).(func(int) []int); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStudioIDs provides a mock function with given fields: id
+func (_m *RestrictionProfileReaderWriter) GetStudioIDs(id int) ([]int, error) {
+	ret := _m.Called(id)
+
+	var r0 []int
+	if rf, ok := ret.Get(0).(func(int) []int); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTagIDs provides a mock function with given fields: id
+func (_m *RestrictionProfileReaderWriter) GetTagIDs(id int) ([]int, error) {
+	ret := _m.Called(id)
+
+	var r0 []int
+	if rf, ok := ret.Get(0).(func(int) []int); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: updatedProfile
+func (_m *RestrictionProfileReaderWriter) Update(updatedProfile models.RestrictionProfile) (*models.RestrictionProfile, error) {
+	ret := _m.Called(updatedProfile)
+
+	var r0 *models.RestrictionProfile
+	if rf, ok := ret.Get(0).(func(models.RestrictionProfile) *models.RestrictionProfile); ok {
+		r0 = rf(updatedProfile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RestrictionProfile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.RestrictionProfile) error); ok {
+		r1 = rf(updatedProfile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePerformers provides a mock function with given fields: id, performerIDs
+func (_m *RestrictionProfileReaderWriter) UpdatePerformers(id int, performerIDs []int) error {
+	ret := _m.Called(id, performerIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []int) error); ok {
+		r0 = rf(id, performerIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStudios provides a mock function with given fields: id, studioIDs
+func (_m *RestrictionProfileReaderWriter) UpdateStudios(id int, studioIDs []int) error {
+	ret := _m.Called(id, studioIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []int) error); ok {
+		r0 = rf(id, studioIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateTags provides a mock function with given fields: id, tagIDs
+func (_m *RestrictionProfileReaderWriter) UpdateTags(id int, tagIDs []int) error {
+	ret := _m.Called(id, tagIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, []int) error); ok {
+		r0 = rf(id, tagIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
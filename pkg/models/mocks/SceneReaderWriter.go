@@ -5,6 +5,8 @@ package mocks
 import (
 	models "github.com/stashapp/stash/pkg/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // SceneReaderWriter is an autogenerated mock type for the SceneReaderWriter type
@@ -12,6 +14,27 @@ type SceneReaderWriter struct {
 	mock.Mock
 }
 
+// AddPlay provides a mock function with given fields: id, userID, playedAt
+func (_m *SceneReaderWriter) AddPlay(id int, userID *int, playedAt time.Time) (int, error) {
+	ret := _m.Called(id, userID, playedAt)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int, *int, time.Time) int); ok {
+		r0 = rf(id, userID, playedAt)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, *int, time.Time) error); ok {
+		r1 = rf(id, userID, playedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // All provides a mock function with given fields:
 func (_m *SceneReaderWriter) All() ([]*models.Scene, error) {
 	ret := _m.Called()
@@ -205,6 +228,29 @@ func (_m *SceneReaderWriter) Create(newScene models.Scene) (*models.Scene, error
 	return r0, r1
 }
 
+// CreateFile provides a mock function with given fields: newFile
+func (_m *SceneReaderWriter) CreateFile(newFile models.SceneFile) (*models.SceneFile, error) {
+	ret := _m.Called(newFile)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(models.SceneFile) *models.SceneFile); ok {
+		r0 = rf(newFile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.SceneFile) error); ok {
+		r1 = rf(newFile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DecrementOCounter provides a mock function with given fields: id
 func (_m *SceneReaderWriter) DecrementOCounter(id int) (int, error) {
 	ret := _m.Called(id)
@@ -254,6 +300,20 @@ func (_m *SceneReaderWriter) DestroyCover(sceneID int) error {
 	return r0
 }
 
+// DestroyFile provides a mock function with given fields: id
+func (_m *SceneReaderWriter) DestroyFile(id int) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Duration provides a mock function with given fields:
 func (_m *SceneReaderWriter) Duration() (float64, error) {
 	ret := _m.Called()
@@ -436,22 +496,68 @@ func (_m *SceneReaderWriter) FindByPerformerID(performerID int) ([]*models.Scene
 	return r0, r1
 }
 
-// FindDuplicates provides a mock function with given fields: distance
-func (_m *SceneReaderWriter) FindDuplicates(distance int) ([][]*models.Scene, error) {
-	ret := _m.Called(distance)
+// FindDuplicates provides a mock function with given fields: options
+func (_m *SceneReaderWriter) FindDuplicates(options models.DuplicateOptions) ([][]*models.Scene, error) {
+	ret := _m.Called(options)
 
 	var r0 [][]*models.Scene
-	if rf, ok := ret.Get(0).(func(int) [][]*models.Scene); ok {
-		r0 = rf(distance)
+	if rf, ok := ret.Get(0).(func(models.DuplicateOptions) [][]*models.Scene); ok {
+		r0 = rf(options)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([][]*models.Scene)
 		}
 	}
 
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.DuplicateOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindFile provides a mock function with given fields: id
+func (_m *SceneReaderWriter) FindFile(id int) (*models.SceneFile, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(int) *models.SceneFile); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
 	var r1 error
 	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(distance)
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindFileByPath provides a mock function with given fields: path
+func (_m *SceneReaderWriter) FindFileByPath(path string) (*models.SceneFile, error) {
+	ret := _m.Called(path)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(string) *models.SceneFile); ok {
+		r0 = rf(path)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(path)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -528,6 +634,29 @@ func (_m *SceneReaderWriter) GetCover(sceneID int) ([]byte, error) {
 	return r0, r1
 }
 
+// GetFiles provides a mock function with given fields: sceneID
+func (_m *SceneReaderWriter) GetFiles(sceneID int) ([]*models.SceneFile, error) {
+	ret := _m.Called(sceneID)
+
+	var r0 []*models.SceneFile
+	if rf, ok := ret.Get(0).(func(int) []*models.SceneFile); ok {
+		r0 = rf(sceneID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(sceneID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetGalleryIDs provides a mock function with given fields: sceneID
 func (_m *SceneReaderWriter) GetGalleryIDs(sceneID int) ([]int, error) {
 	ret := _m.Called(sceneID)
@@ -597,6 +726,29 @@ func (_m *SceneReaderWriter) GetPerformerIDs(sceneID int) ([]int, error) {
 	return r0, r1
 }
 
+// GetPlayHistory provides a mock function with given fields: sceneID, userID
+func (_m *SceneReaderWriter) GetPlayHistory(sceneID int, userID *int) ([]*models.ScenePlay, error) {
+	ret := _m.Called(sceneID, userID)
+
+	var r0 []*models.ScenePlay
+	if rf, ok := ret.Get(0).(func(int, *int) []*models.ScenePlay); ok {
+		r0 = rf(sceneID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ScenePlay)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, *int) error); ok {
+		r1 = rf(sceneID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStashIDs provides a mock function with given fields: sceneID
 func (_m *SceneReaderWriter) GetStashIDs(sceneID int) ([]*models.StashID, error) {
 	ret := _m.Called(sceneID)
@@ -643,6 +795,29 @@ func (_m *SceneReaderWriter) GetTagIDs(sceneID int) ([]int, error) {
 	return r0, r1
 }
 
+// GetUserData provides a mock function with given fields: sceneID, userID
+func (_m *SceneReaderWriter) GetUserData(sceneID int, userID int) (*models.SceneUserData, error) {
+	ret := _m.Called(sceneID, userID)
+
+	var r0 *models.SceneUserData
+	if rf, ok := ret.Get(0).(func(int, int) *models.SceneUserData); ok {
+		r0 = rf(sceneID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneUserData)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(sceneID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // IncrementOCounter provides a mock function with given fields: id
 func (_m *SceneReaderWriter) IncrementOCounter(id int) (int, error) {
 	ret := _m.Called(id)
@@ -664,6 +839,20 @@ func (_m *SceneReaderWriter) IncrementOCounter(id int) (int, error) {
 	return r0, r1
 }
 
+// Merge provides a mock function with given fields: source, destination
+func (_m *SceneReaderWriter) Merge(source []int, destination int) error {
+	ret := _m.Called(source, destination)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]int, int) error); ok {
+		r0 = rf(source, destination)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Query provides a mock function with given fields: options
 func (_m *SceneReaderWriter) Query(options models.SceneQueryOptions) (*models.SceneQueryResult, error) {
 	ret := _m.Called(options)
@@ -708,6 +897,34 @@ func (_m *SceneReaderWriter) ResetOCounter(id int) (int, error) {
 	return r0, r1
 }
 
+// SaveActivity provides a mock function with given fields: id, userID, resumeTime, playDuration
+func (_m *SceneReaderWriter) SaveActivity(id int, userID *int, resumeTime *float64, playDuration *float64) error {
+	ret := _m.Called(id, userID, resumeTime, playDuration)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, *int, *float64, *float64) error); ok {
+		r0 = rf(id, userID, resumeTime, playDuration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPrimaryFile provides a mock function with given fields: sceneID, fileID
+func (_m *SceneReaderWriter) SetPrimaryFile(sceneID int, fileID int) error {
+	ret := _m.Called(sceneID, fileID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int) error); ok {
+		r0 = rf(sceneID, fileID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Size provides a mock function with given fields:
 func (_m *SceneReaderWriter) Size() (float64, error) {
 	ret := _m.Called()
@@ -780,6 +997,29 @@ func (_m *SceneReaderWriter) UpdateCover(sceneID int, cover []byte) error {
 	return r0
 }
 
+// UpdateFile provides a mock function with given fields: updatedFile
+func (_m *SceneReaderWriter) UpdateFile(updatedFile models.SceneFile) (*models.SceneFile, error) {
+	ret := _m.Called(updatedFile)
+
+	var r0 *models.SceneFile
+	if rf, ok := ret.Get(0).(func(models.SceneFile) *models.SceneFile); ok {
+		r0 = rf(updatedFile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SceneFile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.SceneFile) error); ok {
+		r1 = rf(updatedFile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateFileModTime provides a mock function with given fields: id, modTime
 func (_m *SceneReaderWriter) UpdateFileModTime(id int, modTime models.NullSQLiteTimestamp) error {
 	ret := _m.Called(id, modTime)
@@ -887,6 +1127,20 @@ func (_m *SceneReaderWriter) UpdateTags(sceneID int, tagIDs []int) error {
 	return r0
 }
 
+// UpdateUserData provides a mock function with given fields: data
+func (_m *SceneReaderWriter) UpdateUserData(data models.SceneUserData) error {
+	ret := _m.Called(data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.SceneUserData) error); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Wall provides a mock function with given fields: q
 func (_m *SceneReaderWriter) Wall(q *string) ([]*models.Scene, error) {
 	ret := _m.Called(q)
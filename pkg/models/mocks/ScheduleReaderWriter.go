@@ -0,0 +1,142 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/stashapp/stash/pkg/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ScheduleReaderWriter is an autogenerated mock type for the ScheduleReaderWriter type
+type ScheduleReaderWriter struct {
+	mock.Mock
+}
+
+// All provides a mock function with given fields:
+func (_m *ScheduleReaderWriter) All() ([]*models.Schedule, error) {
+	ret := _m.Called()
+
+	var r0 []*models.Schedule
+	if rf, ok := ret.Get(0).(func() []*models.Schedule); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Schedule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: newSchedule
+func (_m *ScheduleReaderWriter) Create(newSchedule models.Schedule) (*models.Schedule, error) {
+	ret := _m.Called(newSchedule)
+
+	var r0 *models.Schedule
+	if rf, ok := ret.Get(0).(func(models.Schedule) *models.Schedule); ok {
+		r0 = rf(newSchedule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Schedule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Schedule) error); ok {
+		r1 = rf(newSchedule)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Destroy provides a mock function with given fields: id
+func (_m *ScheduleReaderWriter) Destroy(id int) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Find provides a mock function with given fields: id
+func (_m *ScheduleReaderWriter) Find(id int) (*models.Schedule, error) {
+	ret := _m.Called(id)
+
+	var r0 *models.Schedule
+	if rf, ok := ret.Get(0).(func(int) *models.Schedule); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Schedule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByName provides a mock function with given fields: name
+func (_m *ScheduleReaderWriter) FindByName(name string) (*models.Schedule, error) {
+	ret := _m.Called(name)
+
+	var r0 *models.Schedule
+	if rf, ok := ret.Get(0).(func(string) *models.Schedule); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Schedule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: updatedSchedule
+func (_m *ScheduleReaderWriter) Update(updatedSchedule models.Schedule) (*models.Schedule, error) {
+	ret := _m.Called(updatedSchedule)
+
+	var r0 *models.Schedule
+	if rf, ok := ret.Get(0).(func(models.Schedule) *models.Schedule); ok {
+		r0 = rf(updatedSchedule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Schedule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Schedule) error); ok {
+		r1 = rf(updatedSchedule)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,36 @@
+// Code generated by mockery v2.10.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "github.com/stashapp/stash/pkg/models"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SearchReader is an autogenerated mock type for the SearchReader type
+type SearchReader struct {
+	mock.Mock
+}
+
+// Search provides a mock function with given fields: q, types, limit
+func (_m *SearchReader) Search(q string, types []models.SearchResultType, limit int) ([]*models.SearchMatch, error) {
+	ret := _m.Called(q, types, limit)
+
+	var r0 []*models.SearchMatch
+	if rf, ok := ret.Get(0).(func(string, []models.SearchResultType, int) []*models.SearchMatch); ok {
+		r0 = rf(q, types, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SearchMatch)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []models.SearchResultType, int) error); ok {
+		r1 = rf(q, types, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -17,6 +17,11 @@ type TransactionManager struct {
 	studio      *StudioReaderWriter
 	tag         *TagReaderWriter
 	savedFilter *SavedFilterReaderWriter
+	user        *UserReaderWriter
+	restriction *RestrictionProfileReaderWriter
+	schedule    *ScheduleReaderWriter
+	jobRecord   *JobRecordReaderWriter
+	search      *SearchReader
 }
 
 func NewTransactionManager() *TransactionManager {
@@ -31,6 +36,11 @@ func NewTransactionManager() *TransactionManager {
 		studio:      &StudioReaderWriter{},
 		tag:         &TagReaderWriter{},
 		savedFilter: &SavedFilterReaderWriter{},
+		user:        &UserReaderWriter{},
+		restriction: &RestrictionProfileReaderWriter{},
+		schedule:    &ScheduleReaderWriter{},
+		jobRecord:   &JobRecordReaderWriter{},
+		search:      &SearchReader{},
 	}
 }
 
@@ -78,6 +88,26 @@ func (t *TransactionManager) SavedFilterMock() *SavedFilterReaderWriter {
 	return t.savedFilter
 }
 
+func (t *TransactionManager) UserMock() *UserReaderWriter {
+	return t.user
+}
+
+func (t *TransactionManager) RestrictionProfileMock() *RestrictionProfileReaderWriter {
+	return t.restriction
+}
+
+func (t *TransactionManager) ScheduleMock() *ScheduleReaderWriter {
+	return t.schedule
+}
+
+func (t *TransactionManager) JobRecordMock() *JobRecordReaderWriter {
+	return t.jobRecord
+}
+
+func (t *TransactionManager) SearchMock() *SearchReader {
+	return t.search
+}
+
 func (t *TransactionManager) Gallery() models.GalleryReaderWriter {
 	return t.GalleryMock()
 }
@@ -118,6 +148,22 @@ func (t *TransactionManager) SavedFilter() models.SavedFilterReaderWriter {
 	return t.SavedFilterMock()
 }
 
+func (t *TransactionManager) User() models.UserReaderWriter {
+	return t.UserMock()
+}
+
+func (t *TransactionManager) RestrictionProfile() models.RestrictionProfileReaderWriter {
+	return t.RestrictionProfileMock()
+}
+
+func (t *TransactionManager) Schedule() models.ScheduleReaderWriter {
+	return t.ScheduleMock()
+}
+
+func (t *TransactionManager) JobRecord() models.JobRecordReaderWriter {
+	return t.JobRecordMock()
+}
+
 type ReadTransaction struct {
 	*TransactionManager
 }
@@ -165,3 +211,23 @@ func (r *ReadTransaction) Tag() models.TagReader {
 func (r *ReadTransaction) SavedFilter() models.SavedFilterReader {
 	return r.SavedFilterMock()
 }
+
+func (r *ReadTransaction) User() models.UserReader {
+	return r.UserMock()
+}
+
+func (r *ReadTransaction) RestrictionProfile() models.RestrictionProfileReader {
+	return r.RestrictionProfileMock()
+}
+
+func (r *ReadTransaction) Schedule() models.ScheduleReader {
+	return r.ScheduleMock()
+}
+
+func (r *ReadTransaction) JobRecord() models.JobRecordReader {
+	return r.JobRecordMock()
+}
+
+func (r *ReadTransaction) Search() models.SearchReader {
+	return r.SearchMock()
+}
@@ -19,6 +19,7 @@ type Image struct {
 	Size        sql.NullInt64       `db:"size" json:"size"`
 	Width       sql.NullInt64       `db:"width" json:"width"`
 	Height      sql.NullInt64       `db:"height" json:"height"`
+	Phash       sql.NullInt64       `db:"phash,omitempty" json:"phash"`
 	StudioID    sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
 	FileModTime NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
 	CreatedAt   SQLiteTimestamp     `db:"created_at" json:"created_at"`
@@ -37,6 +38,7 @@ type ImagePartial struct {
 	Size        *sql.NullInt64       `db:"size" json:"size"`
 	Width       *sql.NullInt64       `db:"width" json:"width"`
 	Height      *sql.NullInt64       `db:"height" json:"height"`
+	Phash       *sql.NullInt64       `db:"phash,omitempty" json:"phash"`
 	StudioID    *sql.NullInt64       `db:"studio_id,omitempty" json:"studio_id"`
 	FileModTime *NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
 	CreatedAt   *SQLiteTimestamp     `db:"created_at" json:"created_at"`
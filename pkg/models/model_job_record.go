@@ -0,0 +1,34 @@
+package models
+
+import "database/sql"
+
+// JobRecord is a job stored in the job history.
+type JobRecord struct {
+	ID          int       `db:"id" json:"id"`
+	Status      JobStatus `db:"status" json:"status"`
+	Description string    `db:"description" json:"description"`
+	// Type and Input are used to recreate requeueable jobs. Input is the
+	// JSON encoded input options of the job.
+	Type      sql.NullString      `db:"type" json:"type"`
+	Input     sql.NullString      `db:"input" json:"input"`
+	Progress  sql.NullFloat64     `db:"progress" json:"progress"`
+	Error     sql.NullString      `db:"error" json:"error"`
+	AddTime   SQLiteTimestamp     `db:"add_time" json:"add_time"`
+	StartTime NullSQLiteTimestamp `db:"start_time" json:"start_time"`
+	EndTime   NullSQLiteTimestamp `db:"end_time" json:"end_time"`
+	// Issues is the JSON encoded list of issues affecting individual items
+	Issues     sql.NullString `db:"issues" json:"issues"`
+	IssueCount int            `db:"issue_count" json:"issue_count"`
+	Lane       sql.NullString `db:"lane" json:"lane"`
+	Priority   int            `db:"priority" json:"priority"`
+}
+
+type JobRecords []*JobRecord
+
+func (j *JobRecords) Append(o interface{}) {
+	*j = append(*j, o.(*JobRecord))
+}
+
+func (j *JobRecords) New() interface{} {
+	return &JobRecord{}
+}
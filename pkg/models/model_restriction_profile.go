@@ -0,0 +1,21 @@
+package models
+
+// RestrictionProfile is a named set of excluded tags, studios and performers.
+// Content with an excluded tag, or any child of an excluded tag, studio or
+// performer is hidden from users bound to the profile.
+type RestrictionProfile struct {
+	ID        int             `db:"id" json:"id"`
+	Name      string          `db:"name" json:"name"`
+	CreatedAt SQLiteTimestamp `db:"created_at" json:"created_at"`
+	UpdatedAt SQLiteTimestamp `db:"updated_at" json:"updated_at"`
+}
+
+type RestrictionProfiles []*RestrictionProfile
+
+func (p *RestrictionProfiles) Append(o interface{}) {
+	*p = append(*p, o.(*RestrictionProfile))
+}
+
+func (p *RestrictionProfiles) New() interface{} {
+	return &RestrictionProfile{}
+}
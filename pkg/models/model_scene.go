@@ -36,6 +36,7 @@ type Scene struct {
 	UpdatedAt        SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
 	Interactive      bool                `db:"interactive" json:"interactive"`
 	InteractiveSpeed sql.NullInt64       `db:"interactive_speed" json:"interactive_speed"`
+	ResumeTime       float64             `db:"resume_time" json:"resume_time"`
 }
 
 func (s *Scene) File() File {
@@ -120,6 +121,7 @@ type ScenePartial struct {
 	UpdatedAt        *SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
 	Interactive      *bool                `db:"interactive" json:"interactive"`
 	InteractiveSpeed *sql.NullInt64       `db:"interactive_speed" json:"interactive_speed"`
+	ResumeTime       *float64             `db:"resume_time" json:"resume_time"`
 }
 
 // UpdateInput constructs a SceneUpdateInput using the populated fields in the ScenePartial object.
@@ -145,6 +147,15 @@ func (s ScenePartial) UpdateInput() SceneUpdateInput {
 	}
 }
 
+// HasFileFields returns true if any of the fields that mirror the primary
+// file of the scene are set.
+func (s ScenePartial) HasFileFields() bool {
+	return s.Path != nil || s.Checksum != nil || s.OSHash != nil || s.Size != nil ||
+		s.Duration != nil || s.VideoCodec != nil || s.Format != nil || s.AudioCodec != nil ||
+		s.Width != nil || s.Height != nil || s.Framerate != nil || s.Bitrate != nil ||
+		s.FileModTime != nil || s.Interactive != nil
+}
+
 func (s *ScenePartial) SetFile(f File) {
 	path := f.Path
 	s.Path = &path
@@ -231,3 +242,22 @@ type SceneCaption struct {
 func (c SceneCaption) Path(scenePath string) string {
 	return filepath.Join(filepath.Dir(scenePath), c.Filename)
 }
+
+// ScenePlay records a single play of a scene.
+type ScenePlay struct {
+	ID           int             `db:"id" json:"id"`
+	SceneID      int             `db:"scene_id" json:"scene_id"`
+	UserID       sql.NullInt64   `db:"user_id" json:"user_id"`
+	PlayedAt     SQLiteTimestamp `db:"played_at" json:"played_at"`
+	PlayDuration float64         `db:"play_duration" json:"play_duration"`
+}
+
+type ScenePlays []*ScenePlay
+
+func (s *ScenePlays) Append(o interface{}) {
+	*s = append(*s, o.(*ScenePlay))
+}
+
+func (s *ScenePlays) New() interface{} {
+	return &ScenePlay{}
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql"
+	"path/filepath"
+)
+
+// SceneFile stores the metadata for a single video file of a scene. A scene
+// may own several files, such as different encodes of the same content, one
+// of which is the primary file. The file fields of Scene mirror the primary
+// file so that filtering and sorting continue to operate on the scenes table.
+type SceneFile struct {
+	ID          int                 `db:"id" json:"id"`
+	SceneID     int                 `db:"scene_id" json:"scene_id"`
+	Primary     bool                `db:"is_primary" json:"primary"`
+	Path        string              `db:"path" json:"path"`
+	Checksum    sql.NullString      `db:"checksum" json:"checksum"`
+	OSHash      sql.NullString      `db:"oshash" json:"oshash"`
+	Size        sql.NullString      `db:"size" json:"size"`
+	Duration    sql.NullFloat64     `db:"duration" json:"duration"`
+	VideoCodec  sql.NullString      `db:"video_codec" json:"video_codec"`
+	Format      sql.NullString      `db:"format" json:"format_name"`
+	AudioCodec  sql.NullString      `db:"audio_codec" json:"audio_codec"`
+	Width       sql.NullInt64       `db:"width" json:"width"`
+	Height      sql.NullInt64       `db:"height" json:"height"`
+	Framerate   sql.NullFloat64     `db:"framerate" json:"framerate"`
+	Bitrate     sql.NullInt64       `db:"bitrate" json:"bitrate"`
+	FileModTime NullSQLiteTimestamp `db:"file_mod_time" json:"file_mod_time"`
+	Interactive bool                `db:"interactive" json:"interactive"`
+	CreatedAt   SQLiteTimestamp     `db:"created_at" json:"created_at"`
+	UpdatedAt   SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
+}
+
+// File returns the hash and modification details of the scene file.
+func (f *SceneFile) File() File {
+	ret := File{
+		Path:     f.Path,
+		Checksum: f.Checksum.String,
+		OSHash:   f.OSHash.String,
+		Size:     f.Size.String,
+	}
+
+	if f.FileModTime.Valid {
+		ret.FileModTime = f.FileModTime.Timestamp
+	}
+
+	return ret
+}
+
+// SetFile sets the hash and modification details of the scene file.
+func (f *SceneFile) SetFile(file File) {
+	f.Path = file.Path
+	f.Checksum = sql.NullString{String: file.Checksum, Valid: file.Checksum != ""}
+	f.OSHash = sql.NullString{String: file.OSHash, Valid: file.OSHash != ""}
+	f.Size = sql.NullString{String: file.Size, Valid: file.Size != ""}
+	f.FileModTime = NullSQLiteTimestamp{
+		Timestamp: file.FileModTime,
+		Valid:     !file.FileModTime.IsZero(),
+	}
+}
+
+// GetHash returns the hash of the file, based on the hash algorithm provided.
+func (f SceneFile) GetHash(hashAlgorithm HashAlgorithm) string {
+	return f.File().GetHash(hashAlgorithm)
+}
+
+// Basename returns the base filename of the file.
+func (f SceneFile) Basename() string {
+	return filepath.Base(f.Path)
+}
+
+// SceneFile returns a SceneFile populated from the file fields of the scene.
+// The returned file does not have an ID.
+func (s Scene) SceneFile() SceneFile {
+	return SceneFile{
+		SceneID:     s.ID,
+		Primary:     true,
+		Path:        s.Path,
+		Checksum:    s.Checksum,
+		OSHash:      s.OSHash,
+		Size:        s.Size,
+		Duration:    s.Duration,
+		VideoCodec:  s.VideoCodec,
+		Format:      s.Format,
+		AudioCodec:  s.AudioCodec,
+		Width:       s.Width,
+		Height:      s.Height,
+		Framerate:   s.Framerate,
+		Bitrate:     s.Bitrate,
+		FileModTime: s.FileModTime,
+		Interactive: s.Interactive,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+// SetSceneFile sets the file fields of the scene to those of the provided
+// file.
+func (s *Scene) SetSceneFile(f SceneFile) {
+	s.Path = f.Path
+	s.Checksum = f.Checksum
+	s.OSHash = f.OSHash
+	s.Size = f.Size
+	s.Duration = f.Duration
+	s.VideoCodec = f.VideoCodec
+	s.Format = f.Format
+	s.AudioCodec = f.AudioCodec
+	s.Width = f.Width
+	s.Height = f.Height
+	s.Framerate = f.Framerate
+	s.Bitrate = f.Bitrate
+	s.FileModTime = f.FileModTime
+	s.Interactive = f.Interactive
+}
+
+type SceneFiles []*SceneFile
+
+func (s *SceneFiles) Append(o interface{}) {
+	*s = append(*s, o.(*SceneFile))
+}
+
+func (s *SceneFiles) New() interface{} {
+	return &SceneFile{}
+}
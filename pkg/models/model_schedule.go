@@ -0,0 +1,37 @@
+package models
+
+import "database/sql"
+
+// Schedule runs a task on a cron expression or an interval, using the
+// default settings of the task.
+type Schedule struct {
+	ID   int           `db:"id" json:"id"`
+	Name string        `db:"name" json:"name"`
+	Task ScheduledTask `db:"task" json:"task"`
+	// Cron is the cron expression of the schedule. Either Cron or Interval
+	// is set.
+	Cron sql.NullString `db:"cron" json:"cron"`
+	// Interval is the number of seconds between runs.
+	Interval sql.NullInt64 `db:"interval" json:"interval"`
+	// PluginID and PluginTaskName identify the plugin task run by plugin
+	// schedules.
+	PluginID       sql.NullString      `db:"plugin_id" json:"plugin_id"`
+	PluginTaskName sql.NullString      `db:"plugin_task_name" json:"plugin_task_name"`
+	Enabled        bool                `db:"enabled" json:"enabled"`
+	LastRunAt      NullSQLiteTimestamp `db:"last_run_at" json:"last_run_at"`
+	LastJobID      sql.NullInt64       `db:"last_job_id" json:"last_job_id"`
+	LastResult     sql.NullString      `db:"last_result" json:"last_result"`
+	LastError      sql.NullString      `db:"last_error" json:"last_error"`
+	CreatedAt      SQLiteTimestamp     `db:"created_at" json:"created_at"`
+	UpdatedAt      SQLiteTimestamp     `db:"updated_at" json:"updated_at"`
+}
+
+type Schedules []*Schedule
+
+func (s *Schedules) Append(o interface{}) {
+	*s = append(*s, o.(*Schedule))
+}
+
+func (s *Schedules) New() interface{} {
+	return &Schedule{}
+}
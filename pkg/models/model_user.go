@@ -0,0 +1,50 @@
+package models
+
+import "database/sql"
+
+// User is a user account stored in the database. Users are in addition to
+// the account configured using the username and password settings, which
+// always has the admin role.
+type User struct {
+	ID       int    `db:"id" json:"id"`
+	Username string `db:"username" json:"username"`
+	// Password is the bcrypt hash of the user's password.
+	Password string         `db:"password" json:"password"`
+	Role     UserRole       `db:"role" json:"role"`
+	APIKey   sql.NullString `db:"api_key" json:"api_key"`
+	// RestrictionProfileID is the restriction profile applied to the user.
+	RestrictionProfileID sql.NullInt64   `db:"restriction_profile_id" json:"restriction_profile_id"`
+	CreatedAt            SQLiteTimestamp `db:"created_at" json:"created_at"`
+	UpdatedAt            SQLiteTimestamp `db:"updated_at" json:"updated_at"`
+}
+
+type Users []*User
+
+func (u *Users) Append(o interface{}) {
+	*u = append(*u, o.(*User))
+}
+
+func (u *Users) New() interface{} {
+	return &User{}
+}
+
+var userRoleRank = map[UserRole]int{
+	UserRoleViewer: 1,
+	UserRoleEditor: 2,
+	UserRoleAdmin:  3,
+}
+
+// HasRole returns true if r grants at least the permissions of required.
+func (r UserRole) HasRole(required UserRole) bool {
+	return userRoleRank[r] >= userRoleRank[required]
+}
+
+// SceneUserData is the rating, o-counter and resume time of a scene for a
+// single user.
+type SceneUserData struct {
+	SceneID    int           `db:"scene_id" json:"scene_id"`
+	UserID     int           `db:"user_id" json:"user_id"`
+	Rating     sql.NullInt64 `db:"rating" json:"rating"`
+	OCounter   int           `db:"o_counter" json:"o_counter"`
+	ResumeTime float64       `db:"resume_time" json:"resume_time"`
+}
@@ -24,6 +24,9 @@ type MovieWriter interface {
 	Destroy(id int) error
 	UpdateImages(movieID int, frontImage []byte, backImage []byte) error
 	DestroyImages(movieID int) error
+	// Merge moves the scenes of the source movies to the destination movie,
+	// adding their names to its aliases, then destroys the source movies.
+	Merge(source []int, destination int) error
 }
 
 type MovieReaderWriter interface {
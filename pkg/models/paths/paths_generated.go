@@ -21,6 +21,7 @@ type generatedPaths struct {
 	Downloads          string
 	Tmp                string
 	InteractiveHeatmap string
+	HLS                string
 }
 
 func newGeneratedPaths(path string) *generatedPaths {
@@ -33,9 +34,16 @@ func newGeneratedPaths(path string) *generatedPaths {
 	gp.Downloads = filepath.Join(path, "download_stage")
 	gp.Tmp = filepath.Join(path, "tmp")
 	gp.InteractiveHeatmap = filepath.Join(path, "interactive_heatmaps")
+	gp.HLS = filepath.Join(path, "hls")
 	return &gp
 }
 
+// GetHLSPath returns the directory used to cache the segments of the HLS
+// stream session with the provided key.
+func (gp *generatedPaths) GetHLSPath(key string) string {
+	return filepath.Join(gp.HLS, key)
+}
+
 func (gp *generatedPaths) GetTmpPath(fileName string) string {
 	return filepath.Join(gp.Tmp, fileName)
 }
@@ -31,6 +31,10 @@ type PerformerWriter interface {
 	DestroyImage(performerID int) error
 	UpdateStashIDs(performerID int, stashIDs []StashID) error
 	UpdateTags(performerID int, tagIDs []int) error
+	// Merge moves the relationships and stash ids of the source performers
+	// to the destination performer, adding their names to its aliases, then
+	// destroys the source performers.
+	Merge(source []int, destination int) error
 }
 
 type PerformerReaderWriter interface {
@@ -9,3 +9,17 @@ type QueryResult struct {
 	IDs   []int
 	Count int
 }
+
+// DuplicateOptions are the options used to find groups of objects with
+// similar phashes.
+type DuplicateOptions struct {
+	// Distance is the maximum hamming distance between the phashes of
+	// duplicates.
+	Distance int
+	// DurationDiff is the maximum difference in seconds between the
+	// durations of duplicates. Negative values disable the check. Only
+	// applies to scenes.
+	DurationDiff float64
+	// FindFilter pages the groups. All groups are returned if nil.
+	FindFilter *FindFilterType
+}
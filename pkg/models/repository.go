@@ -11,6 +11,10 @@ type Repository interface {
 	Studio() StudioReaderWriter
 	Tag() TagReaderWriter
 	SavedFilter() SavedFilterReaderWriter
+	User() UserReaderWriter
+	RestrictionProfile() RestrictionProfileReaderWriter
+	Schedule() ScheduleReaderWriter
+	JobRecord() JobRecordReaderWriter
 }
 
 type ReaderRepository interface {
@@ -24,4 +28,9 @@ type ReaderRepository interface {
 	Studio() StudioReader
 	Tag() TagReader
 	SavedFilter() SavedFilterReader
+	User() UserReader
+	RestrictionProfile() RestrictionProfileReader
+	Schedule() ScheduleReader
+	JobRecord() JobRecordReader
+	Search() SearchReader
 }
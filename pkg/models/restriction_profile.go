@@ -0,0 +1,82 @@
+package models
+
+import "context"
+
+type RestrictionProfileReader interface {
+	Find(id int) (*RestrictionProfile, error)
+	FindByName(name string) (*RestrictionProfile, error)
+	All() ([]*RestrictionProfile, error)
+	GetTagIDs(id int) ([]int, error)
+	GetStudioIDs(id int) ([]int, error)
+	GetPerformerIDs(id int) ([]int, error)
+	// GetContentRestriction returns the restriction applied by the profile,
+	// including the children of the excluded tags.
+	GetContentRestriction(id int) (*ContentRestriction, error)
+}
+
+type RestrictionProfileWriter interface {
+	Create(newProfile RestrictionProfile) (*RestrictionProfile, error)
+	Update(updatedProfile RestrictionProfile) (*RestrictionProfile, error)
+	Destroy(id int) error
+	UpdateTags(id int, tagIDs []int) error
+	UpdateStudios(id int, studioIDs []int) error
+	UpdatePerformers(id int, performerIDs []int) error
+}
+
+type RestrictionProfileReaderWriter interface {
+	RestrictionProfileReader
+	RestrictionProfileWriter
+}
+
+// ContentRestriction is the set of tags, studios and performers hidden by a
+// restriction profile. Content with any of these is hidden, as are the
+// tags, studios and performers themselves.
+type ContentRestriction struct {
+	TagIDs       []int
+	StudioIDs    []int
+	PerformerIDs []int
+}
+
+// IsEmpty returns true if the restriction hides nothing.
+func (r *ContentRestriction) IsEmpty() bool {
+	return r == nil || (len(r.TagIDs) == 0 && len(r.StudioIDs) == 0 && len(r.PerformerIDs) == 0)
+}
+
+type contentRestrictionKey struct{}
+
+// SetContentRestriction returns a copy of ctx with the provided restriction.
+// Repositories created by transactions with the returned context exclude the
+// restricted content.
+func SetContentRestriction(ctx context.Context, r *ContentRestriction) context.Context {
+	return context.WithValue(ctx, contentRestrictionKey{}, r)
+}
+
+// GetContentRestriction returns the restriction set in ctx, or nil if there
+// is none.
+func GetContentRestriction(ctx context.Context) *ContentRestriction {
+	if ctx == nil {
+		return nil
+	}
+
+	r, _ := ctx.Value(contentRestrictionKey{}).(*ContentRestriction)
+	return r
+}
+
+// WithRestrictionProfile returns a copy of ctx with the restriction of the
+// profile with the provided id. ctx is returned unchanged if profileID is 0.
+func WithRestrictionProfile(ctx context.Context, txnManager TransactionManager, profileID int) (context.Context, error) {
+	if profileID == 0 {
+		return ctx, nil
+	}
+
+	var r *ContentRestriction
+	if err := txnManager.WithReadTxn(ctx, func(repo ReaderRepository) error {
+		var err error
+		r, err = repo.RestrictionProfile().GetContentRestriction(profileID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return SetContentRestriction(ctx, r), nil
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type SceneQueryOptions struct {
 	QueryOptions
 	SceneFilter *SceneFilterType
@@ -46,7 +48,10 @@ type SceneReader interface {
 	FindByPath(path string) (*Scene, error)
 	FindByPerformerID(performerID int) ([]*Scene, error)
 	FindByGalleryID(performerID int) ([]*Scene, error)
-	FindDuplicates(distance int) ([][]*Scene, error)
+	// FindDuplicates returns the groups of scenes with phashes within the
+	// distance of the options of each other. Groups are ordered by the
+	// total size of their files.
+	FindDuplicates(options DuplicateOptions) ([][]*Scene, error)
 	CountByPerformerID(performerID int) (int, error)
 	// FindByStudioID(studioID int) ([]*Scene, error)
 	FindByMovieID(movieID int) ([]*Scene, error)
@@ -69,6 +74,11 @@ type SceneReader interface {
 	GetGalleryIDs(sceneID int) ([]int, error)
 	GetPerformerIDs(sceneID int) ([]int, error)
 	GetStashIDs(sceneID int) ([]*StashID, error)
+	GetFiles(sceneID int) ([]*SceneFile, error)
+	FindFile(id int) (*SceneFile, error)
+	FindFileByPath(path string) (*SceneFile, error)
+	GetPlayHistory(sceneID int, userID *int) ([]*ScenePlay, error)
+	GetUserData(sceneID int, userID int) (*SceneUserData, error)
 }
 
 type SceneWriter interface {
@@ -78,8 +88,15 @@ type SceneWriter interface {
 	IncrementOCounter(id int) (int, error)
 	DecrementOCounter(id int) (int, error)
 	ResetOCounter(id int) (int, error)
+	AddPlay(id int, userID *int, playedAt time.Time) (int, error)
+	SaveActivity(id int, userID *int, resumeTime *float64, playDuration *float64) error
+	UpdateUserData(data SceneUserData) error
 	UpdateFileModTime(id int, modTime NullSQLiteTimestamp) error
 	Destroy(id int) error
+	// Merge moves the relationships, markers, files, o-counters and play
+	// data of the source scenes to the destination scene, then destroys the
+	// source scenes.
+	Merge(source []int, destination int) error
 	UpdateCaptions(id int, captions []*SceneCaption) error
 	UpdateCover(sceneID int, cover []byte) error
 	DestroyCover(sceneID int) error
@@ -88,6 +105,10 @@ type SceneWriter interface {
 	UpdateGalleries(sceneID int, galleryIDs []int) error
 	UpdateMovies(sceneID int, movies []MoviesScenes) error
 	UpdateStashIDs(sceneID int, stashIDs []StashID) error
+	CreateFile(newFile SceneFile) (*SceneFile, error)
+	UpdateFile(updatedFile SceneFile) (*SceneFile, error)
+	DestroyFile(id int) error
+	SetPrimaryFile(sceneID int, fileID int) error
 }
 
 type SceneReaderWriter interface {
@@ -0,0 +1,18 @@
+package models
+
+type ScheduleReader interface {
+	Find(id int) (*Schedule, error)
+	FindByName(name string) (*Schedule, error)
+	All() ([]*Schedule, error)
+}
+
+type ScheduleWriter interface {
+	Create(newSchedule Schedule) (*Schedule, error)
+	Update(updatedSchedule Schedule) (*Schedule, error)
+	Destroy(id int) error
+}
+
+type ScheduleReaderWriter interface {
+	ScheduleReader
+	ScheduleWriter
+}
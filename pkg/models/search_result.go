@@ -0,0 +1,29 @@
+package models
+
+// SearchMatch is an object matching a search.
+type SearchMatch struct {
+	Type SearchResultType `db:"type"`
+	ID   int              `db:"id"`
+	// Rank is the relevance of the match compared to the matches of the
+	// same type. Lower is more relevant.
+	Rank float64 `db:"rank"`
+}
+
+type SearchReader interface {
+	// Search returns the objects of the provided types matching the search
+	// string q, or the objects of all types if types is empty. The matches
+	// of each type are ordered by relevance, most relevant first, and are
+	// interleaved with the matches of the other types. No more than limit
+	// matches are returned.
+	Search(q string, types []SearchResultType, limit int) ([]*SearchMatch, error)
+}
+
+// the models are bound to the SearchResult union of the graphql schema
+func (Scene) IsSearchResult()       {}
+func (SceneMarker) IsSearchResult() {}
+func (Image) IsSearchResult()       {}
+func (Gallery) IsSearchResult()     {}
+func (Performer) IsSearchResult()   {}
+func (Studio) IsSearchResult()      {}
+func (Tag) IsSearchResult()         {}
+func (Movie) IsSearchResult()       {}
@@ -26,6 +26,18 @@ func NullInt64(v int64) sql.NullInt64 {
 	}
 }
 
+// NullFloat64FromPtr returns a sql.NullFloat64 that is valid if v is not nil.
+func NullFloat64FromPtr(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+
+	return sql.NullFloat64{
+		Float64: *v,
+		Valid:   true,
+	}
+}
+
 func nullStringPtrToStringPtr(v *sql.NullString) *string {
 	if v == nil || !v.Valid {
 		return nil
@@ -27,6 +27,10 @@ type StudioWriter interface {
 	DestroyImage(studioID int) error
 	UpdateStashIDs(studioID int, stashIDs []StashID) error
 	UpdateAliases(studioID int, aliases []string) error
+	// Merge moves the relationships, child studios and stash ids of the
+	// source studios to the destination studio, adding their names to its
+	// aliases, then destroys the source studios.
+	Merge(source []int, destination int) error
 }
 
 type StudioReaderWriter interface {
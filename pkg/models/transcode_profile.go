@@ -0,0 +1,28 @@
+package models
+
+// TranscodeProfile is a named set of encoder settings stored in the
+// configuration, used for live transcoding and generated transcodes.
+type TranscodeProfile struct {
+	Name         string `json:"name" yaml:"name" mapstructure:"name"`
+	Container    string `json:"container" yaml:"container" mapstructure:"container"`
+	VideoCodec   string `json:"video_codec" yaml:"video_codec" mapstructure:"video_codec"`
+	Preset       string `json:"preset" yaml:"preset,omitempty" mapstructure:"preset"`
+	CRF          int    `json:"crf" yaml:"crf,omitempty" mapstructure:"crf"`
+	VideoBitrate string `json:"video_bitrate" yaml:"video_bitrate,omitempty" mapstructure:"video_bitrate"`
+	AudioCodec   string `json:"audio_codec" yaml:"audio_codec,omitempty" mapstructure:"audio_codec"`
+	AudioBitrate string `json:"audio_bitrate" yaml:"audio_bitrate,omitempty" mapstructure:"audio_bitrate"`
+	MaxHeight    int    `json:"max_height" yaml:"max_height,omitempty" mapstructure:"max_height"`
+}
+
+type TranscodeProfiles []*TranscodeProfile
+
+// Find returns the profile with the provided name, or nil if not found.
+func (p TranscodeProfiles) Find(name string) *TranscodeProfile {
+	for _, pp := range p {
+		if pp.Name == name {
+			return pp
+		}
+	}
+
+	return nil
+}
@@ -18,6 +18,7 @@ const (
 	SceneCreatePost  HookTriggerEnum = "Scene.Create.Post"
 	SceneUpdatePost  HookTriggerEnum = "Scene.Update.Post"
 	SceneDestroyPost HookTriggerEnum = "Scene.Destroy.Post"
+	SceneMergePost   HookTriggerEnum = "Scene.Merge.Post"
 
 	ImageCreatePost  HookTriggerEnum = "Image.Create.Post"
 	ImageUpdatePost  HookTriggerEnum = "Image.Update.Post"
@@ -53,6 +54,7 @@ var AllHookTriggerEnum = []HookTriggerEnum{
 	SceneCreatePost,
 	SceneUpdatePost,
 	SceneDestroyPost,
+	SceneMergePost,
 
 	ImageCreatePost,
 	ImageUpdatePost,
@@ -90,6 +92,7 @@ func (e HookTriggerEnum) IsValid() bool {
 		SceneCreatePost,
 		SceneUpdatePost,
 		SceneDestroyPost,
+		SceneMergePost,
 
 		ImageCreatePost,
 		ImageUpdatePost,
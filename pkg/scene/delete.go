@@ -114,6 +114,24 @@ func (d *FileDeleter) MarkMarkerFiles(scene *models.Scene, seconds int) error {
 	return d.Files(files)
 }
 
+// MarkSceneFile marks for deletion the provided scene file and its
+// funscript, if present.
+func (d *FileDeleter) MarkSceneFile(f *models.SceneFile) error {
+	if err := d.Files([]string{f.Path}); err != nil {
+		return err
+	}
+
+	funscriptPath := GetFunscriptPath(f.Path)
+	funscriptExists, _ := fsutil.FileExists(funscriptPath)
+	if funscriptExists {
+		if err := d.Files([]string{funscriptPath}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Destroy deletes a scene and its associated relationships from the
 // database.
 func Destroy(scene *models.Scene, repo models.Repository, fileDeleter *FileDeleter, deleteGenerated, deleteFile bool) error {
@@ -132,14 +150,13 @@ func Destroy(scene *models.Scene, repo models.Repository, fileDeleter *FileDelet
 	}
 
 	if deleteFile {
-		if err := fileDeleter.Files([]string{scene.Path}); err != nil {
+		files, err := qb.GetFiles(scene.ID)
+		if err != nil {
 			return err
 		}
 
-		funscriptPath := GetFunscriptPath(scene.Path)
-		funscriptExists, _ := fsutil.FileExists(funscriptPath)
-		if funscriptExists {
-			if err := fileDeleter.Files([]string{funscriptPath}); err != nil {
+		for _, f := range files {
+			if err := fileDeleter.MarkSceneFile(f); err != nil {
 				return err
 			}
 		}
@@ -56,7 +56,7 @@ func ToBasicJSON(reader models.SceneReader, scene *models.Scene) (*jsonschema.Sc
 		newSceneJSON.Details = scene.Details.String
 	}
 
-	newSceneJSON.File = getSceneFileJSON(scene)
+	newSceneJSON.File = getSceneFileJSON(scene.SceneFile())
 
 	cover, err := reader.GetCover(scene.ID)
 	if err != nil {
@@ -82,7 +82,7 @@ func ToBasicJSON(reader models.SceneReader, scene *models.Scene) (*jsonschema.Sc
 	return &newSceneJSON, nil
 }
 
-func getSceneFileJSON(scene *models.Scene) *jsonschema.SceneFile {
+func getSceneFileJSON(scene models.SceneFile) *jsonschema.SceneFile {
 	ret := &jsonschema.SceneFile{}
 
 	if scene.FileModTime.Valid {
@@ -128,6 +128,31 @@ func getSceneFileJSON(scene *models.Scene) *jsonschema.SceneFile {
 	return ret
 }
 
+// GetSceneFilesJSON returns a slice of SceneFile JSON representation objects
+// corresponding to the additional, non-primary files of the provided scene.
+func GetSceneFilesJSON(reader models.SceneReader, scene *models.Scene) ([]jsonschema.SceneFile, error) {
+	files, err := reader.GetFiles(scene.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scene files: %v", err)
+	}
+
+	var results []jsonschema.SceneFile
+	for _, f := range files {
+		if f.Primary {
+			continue
+		}
+
+		fileJSON := getSceneFileJSON(*f)
+		fileJSON.Path = f.Path
+		fileJSON.Checksum = f.Checksum.String
+		fileJSON.OSHash = f.OSHash.String
+
+		results = append(results, *fileJSON)
+	}
+
+	return results, nil
+}
+
 // GetStudioName returns the name of the provided scene's studio. It returns an
 // empty string if there is no studio assigned to the scene.
 func GetStudioName(reader models.StudioReader, scene *models.Scene) (string, error) {
@@ -273,6 +298,10 @@ func GetSceneMarkersJSON(markerReader models.SceneMarkerReader, tagReader models
 			UpdatedAt:  json.JSONTime{Time: sceneMarker.UpdatedAt.Timestamp},
 		}
 
+		if sceneMarker.EndSeconds.Valid {
+			sceneMarkerJSON.EndSeconds = getDecimalString(sceneMarker.EndSeconds.Float64)
+		}
+
 		results = append(results, sceneMarkerJSON)
 	}
 
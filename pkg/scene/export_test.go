@@ -38,6 +38,8 @@ const (
 	errMarkersID        = 17
 	errFindPrimaryTagID = 18
 	errFindByMarkerID   = 19
+
+	errFilesID = 20
 )
 
 const (
@@ -466,6 +468,52 @@ func TestGetSceneMoviesJSON(t *testing.T) {
 	mockMovieReader.AssertExpectations(t)
 }
 
+const (
+	primaryFilePath    = "primaryFilePath"
+	secondFilePath     = "secondFilePath"
+	secondFileChecksum = "secondFileChecksum"
+)
+
+func TestGetSceneFilesJSON(t *testing.T) {
+	mockSceneReader := &mocks.SceneReaderWriter{}
+
+	filesErr := errors.New("error getting scene files")
+
+	mockSceneReader.On("GetFiles", sceneID).Return([]*models.SceneFile{
+		{
+			SceneID: sceneID,
+			Primary: true,
+			Path:    primaryFilePath,
+		},
+		{
+			SceneID:  sceneID,
+			Path:     secondFilePath,
+			Checksum: models.NullString(secondFileChecksum),
+			Size:     models.NullString(size),
+			Width:    models.NullInt64(width),
+		},
+	}, nil).Once()
+	mockSceneReader.On("GetFiles", errFilesID).Return(nil, filesErr).Once()
+
+	scene := createEmptyScene(sceneID)
+	json, err := GetSceneFilesJSON(mockSceneReader, &scene)
+	assert.Nil(t, err)
+	assert.Equal(t, []jsonschema.SceneFile{
+		{
+			Path:     secondFilePath,
+			Checksum: secondFileChecksum,
+			Size:     size,
+			Width:    width,
+		},
+	}, json)
+
+	scene = createEmptyScene(errFilesID)
+	_, err = GetSceneFilesJSON(mockSceneReader, &scene)
+	assert.NotNil(t, err)
+
+	mockSceneReader.AssertExpectations(t)
+}
+
 const (
 	validMarkerID1 = 1
 	validMarkerID2 = 2
@@ -489,6 +537,9 @@ const (
 
 	markerSeconds1Str = "1.0"
 	markerSeconds2Str = "2.3"
+
+	markerEndSeconds2    = 4.5
+	markerEndSeconds2Str = "4.5"
 )
 
 type sceneMarkersTestScenario struct {
@@ -520,6 +571,7 @@ var getSceneMarkersJSONScenarios = []sceneMarkersTestScenario{
 				Title:      markerTitle2,
 				PrimaryTag: validTagName2,
 				Seconds:    markerSeconds2Str,
+				EndSeconds: markerEndSeconds2Str,
 				Tags: []string{
 					validTagName2,
 				},
@@ -573,6 +625,7 @@ var validMarkers = []*models.SceneMarker{
 		Title:        markerTitle2,
 		PrimaryTagID: validTagID2,
 		Seconds:      markerSeconds2,
+		EndSeconds:   sql.NullFloat64{Float64: markerEndSeconds2, Valid: true},
 		CreatedAt: models.SQLiteTimestamp{
 			Timestamp: createTime,
 		},
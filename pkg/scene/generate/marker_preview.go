@@ -20,7 +20,10 @@ const (
 	markerScreenshotQuality = 2
 )
 
-func (g Generator) MarkerPreviewVideo(ctx context.Context, input string, hash string, seconds int, includeAudio bool) error {
+// MarkerPreviewVideo generates the preview video for a marker starting at
+// seconds. If duration is greater than zero, then the preview covers the
+// marker range, otherwise a preview of the default length is generated.
+func (g Generator) MarkerPreviewVideo(ctx context.Context, input string, hash string, seconds int, duration float64, includeAudio bool) error {
 	lockCtx := g.LockManager.ReadLock(ctx, input)
 	defer lockCtx.Cancel()
 
@@ -32,8 +35,9 @@ func (g Generator) MarkerPreviewVideo(ctx context.Context, input string, hash st
 	}
 
 	if err := g.generateFile(lockCtx, g.MarkerPaths, mp4Pattern, output, g.markerPreviewVideo(input, sceneMarkerOptions{
-		Seconds: seconds,
-		Audio:   includeAudio,
+		Seconds:  seconds,
+		Duration: duration,
+		Audio:    includeAudio,
 	})); err != nil {
 		return err
 	}
@@ -45,7 +49,25 @@ func (g Generator) MarkerPreviewVideo(ctx context.Context, input string, hash st
 
 type sceneMarkerOptions struct {
 	Seconds int
-	Audio   bool
+	// Duration is the length of the marker range. Zero if the marker has no
+	// end time.
+	Duration float64
+	Audio    bool
+}
+
+// getDuration returns the duration of the generated output, which is the
+// marker range limited to maxDuration, or defaultDuration if the marker has
+// no range. A maxDuration of zero means no limit.
+func (o sceneMarkerOptions) getDuration(defaultDuration float64, maxDuration float64) float64 {
+	if o.Duration <= 0 {
+		return defaultDuration
+	}
+
+	if maxDuration > 0 && o.Duration > maxDuration {
+		return maxDuration
+	}
+
+	return o.Duration
 }
 
 func (g Generator) markerPreviewVideo(input string, options sceneMarkerOptions) generateFn {
@@ -69,7 +91,7 @@ func (g Generator) markerPreviewVideo(input string, options sceneMarkerOptions)
 		)
 
 		trimOptions := transcoder.TranscodeOptions{
-			Duration:   markerPreviewDuration,
+			Duration:   options.getDuration(markerPreviewDuration, 0),
 			StartTime:  float64(options.Seconds),
 			OutputPath: tmpFn,
 			VideoCodec: ffmpeg.VideoCodecLibX264,
@@ -90,7 +112,10 @@ func (g Generator) markerPreviewVideo(input string, options sceneMarkerOptions)
 	}
 }
 
-func (g Generator) SceneMarkerWebp(ctx context.Context, input string, hash string, seconds int) error {
+// SceneMarkerWebp generates the animated preview image for a marker starting
+// at seconds. The image is limited to the marker range if duration is greater
+// than zero.
+func (g Generator) SceneMarkerWebp(ctx context.Context, input string, hash string, seconds int, duration float64) error {
 	lockCtx := g.LockManager.ReadLock(ctx, input)
 	defer lockCtx.Cancel()
 
@@ -102,7 +127,8 @@ func (g Generator) SceneMarkerWebp(ctx context.Context, input string, hash strin
 	}
 
 	if err := g.generateFile(lockCtx, g.MarkerPaths, webpPattern, output, g.sceneMarkerWebp(input, sceneMarkerOptions{
-		Seconds: seconds,
+		Seconds:  seconds,
+		Duration: duration,
 	})); err != nil {
 		return err
 	}
@@ -130,7 +156,7 @@ func (g Generator) sceneMarkerWebp(input string, options sceneMarkerOptions) gen
 		)
 
 		trimOptions := transcoder.TranscodeOptions{
-			Duration:   markerImageDuration,
+			Duration:   options.getDuration(markerImageDuration, markerImageDuration),
 			StartTime:  float64(options.Seconds),
 			OutputPath: tmpFn,
 			VideoCodec: ffmpeg.VideoCodecLibWebP,
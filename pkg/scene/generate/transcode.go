@@ -12,6 +12,45 @@ import (
 type TranscodeOptions struct {
 	Width  int
 	Height int
+
+	// Profile sets the encoder settings of the transcode. The default
+	// settings are used if nil.
+	Profile *ffmpeg.TranscodeProfile
+}
+
+// videoEncoder returns the video codec and arguments used to encode the
+// video.
+func (o TranscodeOptions) videoEncoder() (ffmpeg.VideoCodec, ffmpeg.Args) {
+	var videoArgs ffmpeg.Args
+	if o.Width != 0 && o.Height != 0 {
+		var videoFilter ffmpeg.VideoFilter
+		videoFilter = videoFilter.ScaleDimensions(o.Width, o.Height)
+		videoArgs = videoArgs.VideoFilter(videoFilter)
+	}
+
+	if o.Profile != nil {
+		return o.Profile.VideoCodec, append(videoArgs, o.Profile.VideoArgs()...)
+	}
+
+	videoArgs = append(videoArgs,
+		"-pix_fmt", "yuv420p",
+		"-profile:v", "high",
+		"-level", "4.2",
+		"-preset", "superfast",
+		"-crf", "23",
+	)
+
+	return ffmpeg.VideoCodecLibX264, videoArgs
+}
+
+// audioEncoder returns the audio codec and arguments used to encode the
+// audio.
+func (o TranscodeOptions) audioEncoder() (ffmpeg.AudioCodec, ffmpeg.Args) {
+	if o.Profile != nil && o.Profile.AudioCodec != "" {
+		return o.Profile.AudioCodec, o.Profile.AudioArgs()
+	}
+
+	return ffmpeg.AudioCodecAAC, nil
 }
 
 func (g Generator) Transcode(ctx context.Context, input string, hash string, options TranscodeOptions) error {
@@ -66,26 +105,15 @@ func (g Generator) makeTranscode(lockCtx *fsutil.LockContext, hash string, gener
 
 func (g Generator) transcode(input string, options TranscodeOptions) generateFn {
 	return func(lockCtx *fsutil.LockContext, tmpFn string) error {
-		var videoArgs ffmpeg.Args
-		if options.Width != 0 && options.Height != 0 {
-			var videoFilter ffmpeg.VideoFilter
-			videoFilter = videoFilter.ScaleDimensions(options.Width, options.Height)
-			videoArgs = videoArgs.VideoFilter(videoFilter)
-		}
-
-		videoArgs = append(videoArgs,
-			"-pix_fmt", "yuv420p",
-			"-profile:v", "high",
-			"-level", "4.2",
-			"-preset", "superfast",
-			"-crf", "23",
-		)
+		videoCodec, videoArgs := options.videoEncoder()
+		audioCodec, audioArgs := options.audioEncoder()
 
 		args := transcoder.Transcode(input, transcoder.TranscodeOptions{
 			OutputPath: tmpFn,
-			VideoCodec: ffmpeg.VideoCodecLibX264,
+			VideoCodec: videoCodec,
 			VideoArgs:  videoArgs,
-			AudioCodec: ffmpeg.AudioCodecAAC,
+			AudioCodec: audioCodec,
+			AudioArgs:  audioArgs,
 		})
 
 		return g.generate(lockCtx, args)
@@ -94,27 +122,14 @@ func (g Generator) transcode(input string, options TranscodeOptions) generateFn
 
 func (g Generator) transcodeVideo(input string, options TranscodeOptions) generateFn {
 	return func(lockCtx *fsutil.LockContext, tmpFn string) error {
-		var videoArgs ffmpeg.Args
-		if options.Width != 0 && options.Height != 0 {
-			var videoFilter ffmpeg.VideoFilter
-			videoFilter = videoFilter.ScaleDimensions(options.Width, options.Height)
-			videoArgs = videoArgs.VideoFilter(videoFilter)
-		}
-
-		videoArgs = append(videoArgs,
-			"-pix_fmt", "yuv420p",
-			"-profile:v", "high",
-			"-level", "4.2",
-			"-preset", "superfast",
-			"-crf", "23",
-		)
+		videoCodec, videoArgs := options.videoEncoder()
 
 		var audioArgs ffmpeg.Args
 		audioArgs = audioArgs.SkipAudio()
 
 		args := transcoder.Transcode(input, transcoder.TranscodeOptions{
 			OutputPath: tmpFn,
-			VideoCodec: ffmpeg.VideoCodecLibX264,
+			VideoCodec: videoCodec,
 			VideoArgs:  videoArgs,
 			AudioArgs:  audioArgs,
 		})
@@ -101,40 +101,61 @@ func (i *Importer) sceneJSONToScene(sceneJSON jsonschema.Scene) models.Scene {
 	newScene.UpdatedAt = models.SQLiteTimestamp{Timestamp: sceneJSON.UpdatedAt.GetTime()}
 
 	if sceneJSON.File != nil {
-		if sceneJSON.File.Size != "" {
-			newScene.Size = sql.NullString{String: sceneJSON.File.Size, Valid: true}
-		}
-		if sceneJSON.File.Duration != "" {
-			duration, _ := strconv.ParseFloat(sceneJSON.File.Duration, 64)
-			newScene.Duration = sql.NullFloat64{Float64: duration, Valid: true}
-		}
-		if sceneJSON.File.VideoCodec != "" {
-			newScene.VideoCodec = sql.NullString{String: sceneJSON.File.VideoCodec, Valid: true}
-		}
-		if sceneJSON.File.AudioCodec != "" {
-			newScene.AudioCodec = sql.NullString{String: sceneJSON.File.AudioCodec, Valid: true}
-		}
-		if sceneJSON.File.Format != "" {
-			newScene.Format = sql.NullString{String: sceneJSON.File.Format, Valid: true}
-		}
-		if sceneJSON.File.Width != 0 {
-			newScene.Width = sql.NullInt64{Int64: int64(sceneJSON.File.Width), Valid: true}
-		}
-		if sceneJSON.File.Height != 0 {
-			newScene.Height = sql.NullInt64{Int64: int64(sceneJSON.File.Height), Valid: true}
-		}
-		if sceneJSON.File.Framerate != "" {
-			framerate, _ := strconv.ParseFloat(sceneJSON.File.Framerate, 64)
-			newScene.Framerate = sql.NullFloat64{Float64: framerate, Valid: true}
-		}
-		if sceneJSON.File.Bitrate != 0 {
-			newScene.Bitrate = sql.NullInt64{Int64: int64(sceneJSON.File.Bitrate), Valid: true}
-		}
+		f := sceneFileJSONToSceneFile(*sceneJSON.File)
+		newScene.Size = f.Size
+		newScene.Duration = f.Duration
+		newScene.VideoCodec = f.VideoCodec
+		newScene.AudioCodec = f.AudioCodec
+		newScene.Format = f.Format
+		newScene.Width = f.Width
+		newScene.Height = f.Height
+		newScene.Framerate = f.Framerate
+		newScene.Bitrate = f.Bitrate
 	}
 
 	return newScene
 }
 
+func sceneFileJSONToSceneFile(fileJSON jsonschema.SceneFile) models.SceneFile {
+	ret := models.SceneFile{
+		Path:     fileJSON.Path,
+		Checksum: sql.NullString{String: fileJSON.Checksum, Valid: fileJSON.Checksum != ""},
+		OSHash:   sql.NullString{String: fileJSON.OSHash, Valid: fileJSON.OSHash != ""},
+	}
+
+	if fileJSON.Size != "" {
+		ret.Size = sql.NullString{String: fileJSON.Size, Valid: true}
+	}
+	if fileJSON.Duration != "" {
+		duration, _ := strconv.ParseFloat(fileJSON.Duration, 64)
+		ret.Duration = sql.NullFloat64{Float64: duration, Valid: true}
+	}
+	if fileJSON.VideoCodec != "" {
+		ret.VideoCodec = sql.NullString{String: fileJSON.VideoCodec, Valid: true}
+	}
+	if fileJSON.AudioCodec != "" {
+		ret.AudioCodec = sql.NullString{String: fileJSON.AudioCodec, Valid: true}
+	}
+	if fileJSON.Format != "" {
+		ret.Format = sql.NullString{String: fileJSON.Format, Valid: true}
+	}
+	if fileJSON.Width != 0 {
+		ret.Width = sql.NullInt64{Int64: int64(fileJSON.Width), Valid: true}
+	}
+	if fileJSON.Height != 0 {
+		ret.Height = sql.NullInt64{Int64: int64(fileJSON.Height), Valid: true}
+	}
+	if fileJSON.Framerate != "" {
+		framerate, _ := strconv.ParseFloat(fileJSON.Framerate, 64)
+		ret.Framerate = sql.NullFloat64{Float64: framerate, Valid: true}
+	}
+	if fileJSON.Bitrate != 0 {
+		ret.Bitrate = sql.NullInt64{Int64: int64(fileJSON.Bitrate), Valid: true}
+	}
+
+	return ret
+}
+
 func (i *Importer) populateStudio() error {
 	if i.Input.Studio != "" {
 		studio, err := i.StudioWriter.FindByName(i.Input.Studio, false)
@@ -393,6 +414,35 @@ func (i *Importer) PostImport(id int) error {
 		}
 	}
 
+	for _, fileJSON := range i.Input.Files {
+		if err := i.importFile(id, fileJSON); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *Importer) importFile(id int, fileJSON jsonschema.SceneFile) error {
+	existing, err := i.ReaderWriter.FindFileByPath(fileJSON.Path)
+	if err != nil {
+		return fmt.Errorf("error finding scene file %s: %v", fileJSON.Path, err)
+	}
+
+	// files already known to the database are left where they are
+	if existing != nil {
+		return nil
+	}
+
+	newFile := sceneFileJSONToSceneFile(fileJSON)
+	newFile.SceneID = id
+	newFile.CreatedAt = i.scene.CreatedAt
+	newFile.UpdatedAt = i.scene.UpdatedAt
+
+	if _, err := i.ReaderWriter.CreateFile(newFile); err != nil {
+		return fmt.Errorf("error creating scene file %s: %v", fileJSON.Path, err)
+	}
+
 	return nil
 }
 
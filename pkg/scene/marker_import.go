@@ -30,6 +30,11 @@ func (i *MarkerImporter) PreImport() error {
 		UpdatedAt: models.SQLiteTimestamp{Timestamp: i.Input.UpdatedAt.GetTime()},
 	}
 
+	if i.Input.EndSeconds != "" {
+		endSeconds, _ := strconv.ParseFloat(i.Input.EndSeconds, 64)
+		i.marker.EndSeconds = sql.NullFloat64{Float64: endSeconds, Valid: true}
+	}
+
 	if err := i.populateTags(); err != nil {
 		return err
 	}
@@ -70,6 +70,36 @@ func TestMarkerImporterPreImportWithTag(t *testing.T) {
 	tagReaderWriter.AssertExpectations(t)
 }
 
+func TestMarkerImporterPreImportEndSeconds(t *testing.T) {
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := MarkerImporter{
+		TagWriter:           tagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+		Input: jsonschema.SceneMarker{
+			Seconds:    seconds,
+			EndSeconds: "7.5",
+			PrimaryTag: existingTagName,
+		},
+	}
+
+	tagReaderWriter.On("FindByNames", []string{existingTagName}, false).Return([]*models.Tag{
+		{
+			ID:   existingTagID,
+			Name: existingTagName,
+		},
+	}, nil).Once()
+
+	err := i.PreImport()
+	assert.Nil(t, err)
+	assert.Equal(t, secondsFloat, i.marker.Seconds)
+	assert.True(t, i.marker.EndSeconds.Valid)
+	assert.Equal(t, 7.5, i.marker.EndSeconds.Float64)
+	assert.Equal(t, 2.5, i.marker.Duration())
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
 func TestMarkerImporterPostImportUpdateTags(t *testing.T) {
 	sceneMarkerReaderWriter := &mocks.SceneMarkerReaderWriter{}
 
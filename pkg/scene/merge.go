@@ -0,0 +1,133 @@
+package scene
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// MergeOptions are the options used when merging scenes.
+type MergeOptions struct {
+	// PrimaryFileID is the id of the file to use as the primary file of the
+	// merged scene. The primary file of the destination is kept if zero.
+	PrimaryFileID int
+	// DeleteFiles deletes the files of the merged scene other than its
+	// primary file from the filesystem and the database.
+	DeleteFiles bool
+}
+
+// Merge merges the source scenes into the destination scene and returns the
+// merged scene. The generated files of the scenes that do not match the
+// merged scene are marked for deletion.
+func Merge(destination *models.Scene, sources []*models.Scene, repo models.Repository, fileDeleter *FileDeleter, options MergeOptions) (*models.Scene, error) {
+	qb := repo.Scene()
+
+	var sourceIDs []int
+	for _, s := range sources {
+		sourceIDs = append(sourceIDs, s.ID)
+	}
+
+	if err := qb.Merge(sourceIDs, destination.ID); err != nil {
+		return nil, err
+	}
+
+	if options.PrimaryFileID != 0 {
+		if err := qb.SetPrimaryFile(destination.ID, options.PrimaryFileID); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.DeleteFiles {
+		files, err := qb.GetFiles(destination.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range files {
+			if f.Primary {
+				continue
+			}
+
+			if err := fileDeleter.MarkSceneFile(f); err != nil {
+				return nil, err
+			}
+
+			if err := qb.DestroyFile(f.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	merged, err := qb.Find(destination.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if merged == nil {
+		return nil, fmt.Errorf("scene with id %d not found", destination.ID)
+	}
+
+	// the merged scene takes the hash of its primary file, which may have
+	// belonged to a source
+	hash := merged.GetHash(fileDeleter.FileNamingAlgo)
+	for _, s := range append([]*models.Scene{destination}, sources...) {
+		if s.GetHash(fileDeleter.FileNamingAlgo) == hash {
+			continue
+		}
+
+		if err := fileDeleter.MarkGeneratedFiles(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+var duplicateResolutionValues = map[models.DuplicateResolutionRule]func(s *models.Scene) float64{
+	models.DuplicateResolutionRuleResolution: func(s *models.Scene) float64 {
+		return float64(s.Width.Int64 * s.Height.Int64)
+	},
+	models.DuplicateResolutionRuleBitrate: func(s *models.Scene) float64 {
+		return float64(s.Bitrate.Int64)
+	},
+	models.DuplicateResolutionRuleFramerate: func(s *models.Scene) float64 {
+		return s.Framerate.Float64
+	},
+	models.DuplicateResolutionRuleFileSize: func(s *models.Scene) float64 {
+		ret, _ := strconv.ParseFloat(s.Size.String, 64)
+		return ret
+	},
+	models.DuplicateResolutionRuleDuration: func(s *models.Scene) float64 {
+		return s.Duration.Float64
+	},
+}
+
+// ChooseMergeDestination returns the scene to keep when merging the
+// provided duplicate scenes. The scene with the highest value of the first
+// rule is chosen, with ties broken by the following rules and then by the
+// lowest id.
+func ChooseMergeDestination(scenes []*models.Scene, rules []models.DuplicateResolutionRule) *models.Scene {
+	if len(scenes) == 0 {
+		return nil
+	}
+
+	sorted := append([]*models.Scene(nil), scenes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, rule := range rules {
+			value := duplicateResolutionValues[rule]
+			if value == nil {
+				continue
+			}
+
+			if a, b := value(sorted[i]), value(sorted[j]); a != b {
+				return a > b
+			}
+		}
+
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	return sorted[0]
+}
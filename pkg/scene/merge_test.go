@@ -0,0 +1,68 @@
+package scene
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChooseMergeDestination(t *testing.T) {
+	small := &models.Scene{
+		ID:      1,
+		Width:   sql.NullInt64{Int64: 640, Valid: true},
+		Height:  sql.NullInt64{Int64: 480, Valid: true},
+		Bitrate: sql.NullInt64{Int64: 5000000, Valid: true},
+		Size:    sql.NullString{String: "2000", Valid: true},
+	}
+	large := &models.Scene{
+		ID:      2,
+		Width:   sql.NullInt64{Int64: 1920, Valid: true},
+		Height:  sql.NullInt64{Int64: 1080, Valid: true},
+		Bitrate: sql.NullInt64{Int64: 1000000, Valid: true},
+		Size:    sql.NullString{String: "1000", Valid: true},
+	}
+	largeCopy := &models.Scene{
+		ID:      3,
+		Width:   sql.NullInt64{Int64: 1920, Valid: true},
+		Height:  sql.NullInt64{Int64: 1080, Valid: true},
+		Bitrate: sql.NullInt64{Int64: 1000000, Valid: true},
+		Size:    sql.NullString{String: "3000", Valid: true},
+	}
+
+	scenes := []*models.Scene{small, large, largeCopy}
+
+	tests := []struct {
+		name  string
+		rules []models.DuplicateResolutionRule
+		want  *models.Scene
+	}{
+		{
+			"resolution",
+			[]models.DuplicateResolutionRule{models.DuplicateResolutionRuleResolution},
+			large,
+		},
+		{
+			"resolution then file size",
+			[]models.DuplicateResolutionRule{models.DuplicateResolutionRuleResolution, models.DuplicateResolutionRuleFileSize},
+			largeCopy,
+		},
+		{
+			"bitrate",
+			[]models.DuplicateResolutionRule{models.DuplicateResolutionRuleBitrate},
+			small,
+		},
+		{
+			"no rules",
+			nil,
+			small,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ChooseMergeDestination(scenes, tt.rules))
+		})
+	}
+}
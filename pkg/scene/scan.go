@@ -50,18 +50,20 @@ func FileScanner(hasher file.Hasher, fileNamingAlgorithm models.HashAlgorithm, c
 	}
 }
 
+// ScanExisting rescans an existing scene file. The scene that owns the file
+// is updated if the file is its primary file.
 func (scanner *Scanner) ScanExisting(ctx context.Context, existing file.FileBased, file file.SourceFile) (err error) {
 	scanned, err := scanner.Scanner.ScanExisting(existing, file)
 	if err != nil {
 		return err
 	}
 
-	s := existing.(*models.Scene)
+	f := existing.(*models.SceneFile)
 
 	path := scanned.New.Path
 	interactive := getInteractive(path)
 
-	oldHash := s.GetHash(scanner.FileNamingAlgorithm)
+	oldHash := f.GetHash(scanner.FileNamingAlgorithm)
 	changed := false
 
 	var videoFile *ffmpeg.VideoFile
@@ -69,27 +71,27 @@ func (scanner *Scanner) ScanExisting(ctx context.Context, existing file.FileBase
 	if scanned.ContentsChanged() {
 		logger.Infof("%s has been updated: rescanning", path)
 
-		s.SetFile(*scanned.New)
+		f.SetFile(*scanned.New)
 
 		videoFile, err = scanner.VideoFileCreator.NewVideoFile(path)
 		if err != nil {
 			return err
 		}
 
-		if err := videoFileToScene(s, videoFile); err != nil {
+		if err := videoFileToSceneFile(f, videoFile); err != nil {
 			return err
 		}
 		changed = true
-	} else if scanned.FileUpdated() || s.Interactive != interactive {
+	} else if scanned.FileUpdated() || f.Interactive != interactive {
 		logger.Infof("Updated scene file %s", path)
 
 		// update fields as needed
-		s.SetFile(*scanned.New)
+		f.SetFile(*scanned.New)
 		changed = true
 	}
 
 	// check for container
-	if !s.Format.Valid {
+	if !f.Format.Valid {
 		if videoFile == nil {
 			videoFile, err = scanner.VideoFileCreator.NewVideoFile(path)
 			if err != nil {
@@ -101,29 +103,31 @@ func (scanner *Scanner) ScanExisting(ctx context.Context, existing file.FileBase
 			return fmt.Errorf("getting container for %s: %w", path, err)
 		}
 		logger.Infof("Adding container %s to file %s", container, path)
-		s.Format = models.NullString(string(container))
+		f.Format = models.NullString(string(container))
 		changed = true
 	}
 
-	if err := scanner.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
-		var err error
-		sqb := r.Scene()
-
-		captions, er := sqb.GetCaptions(s.ID)
-		if er == nil {
-			if len(captions) > 0 {
-				clean, altered := CleanCaptions(s.Path, captions)
-				if altered {
-					er = sqb.UpdateCaptions(s.ID, clean)
-					if er == nil {
-						logger.Debugf("Captions for %s cleaned: %s -> %s", path, captions, clean)
+	if f.Primary {
+		if err := scanner.TxnManager.WithTxn(context.TODO(), func(r models.Repository) error {
+			var err error
+			sqb := r.Scene()
+
+			captions, er := sqb.GetCaptions(f.SceneID)
+			if er == nil {
+				if len(captions) > 0 {
+					clean, altered := CleanCaptions(f.Path, captions)
+					if altered {
+						er = sqb.UpdateCaptions(f.SceneID, clean)
+						if er == nil {
+							logger.Debugf("Captions for %s cleaned: %s -> %s", path, captions, clean)
+						}
 					}
 				}
 			}
+			return err
+		}); err != nil {
+			logger.Error(err.Error())
 		}
-		return err
-	}); err != nil {
-		logger.Error(err.Error())
 	}
 
 	if changed {
@@ -140,42 +144,52 @@ func (scanner *Scanner) ScanExisting(ctx context.Context, existing file.FileBase
 			defer close(done)
 			qb := r.Scene()
 
-			// ensure no clashes of hashes
+			// ensure no clashes of hashes with other scenes
 			if scanned.New.Checksum != "" && scanned.Old.Checksum != scanned.New.Checksum {
-				dupe, _ := qb.FindByChecksum(s.Checksum.String)
-				if dupe != nil {
+				dupe, _ := qb.FindByChecksum(f.Checksum.String)
+				if dupe != nil && dupe.ID != f.SceneID {
 					return fmt.Errorf("MD5 for file %s is the same as that of %s", path, dupe.Path)
 				}
 			}
 
 			if scanned.New.OSHash != "" && scanned.Old.OSHash != scanned.New.OSHash {
 				dupe, _ := qb.FindByOSHash(scanned.New.OSHash)
-				if dupe != nil {
+				if dupe != nil && dupe.ID != f.SceneID {
 					return fmt.Errorf("OSHash for file %s is the same as that of %s", path, dupe.Path)
 				}
 			}
 
-			s.Interactive = interactive
-			s.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
+			f.Interactive = interactive
+			f.UpdatedAt = models.SQLiteTimestamp{Timestamp: time.Now()}
 
-			_, err := qb.UpdateFull(*s)
+			_, err := qb.UpdateFile(*f)
+			if err != nil || !f.Primary {
+				return err
+			}
+
+			_, err = qb.Update(models.ScenePartial{
+				ID:        f.SceneID,
+				UpdatedAt: &f.UpdatedAt,
+			})
 			return err
 		}); err != nil {
 			return err
 		}
 
-		// Migrate any generated files if the hash has changed
-		newHash := s.GetHash(scanner.FileNamingAlgorithm)
-		if newHash != oldHash {
+		// Migrate any generated files if the hash of the primary file has changed
+		newHash := f.GetHash(scanner.FileNamingAlgorithm)
+		if f.Primary && newHash != oldHash {
 			MigrateHash(scanner.Paths, oldHash, newHash)
 		}
 
-		scanner.PluginCache.ExecutePostHooks(ctx, s.ID, plugin.SceneUpdatePost, nil, nil)
+		scanner.PluginCache.ExecutePostHooks(ctx, f.SceneID, plugin.SceneUpdatePost, nil, nil)
 	}
 
 	// We already have this item in the database
-	// check for thumbnails, screenshots
-	scanner.makeScreenshots(path, videoFile, s.GetHash(scanner.FileNamingAlgorithm))
+	// check for thumbnails, screenshots of the primary file
+	if f.Primary {
+		scanner.makeScreenshots(path, videoFile, f.GetHash(scanner.FileNamingAlgorithm))
+	}
 
 	return nil
 }
@@ -201,9 +215,10 @@ func (scanner *Scanner) ScanNew(ctx context.Context, file file.SourceFile) (retS
 
 	defer close(done)
 
-	// check for scene by checksum and oshash - MD5 should be
+	// check for a scene file by checksum and oshash - MD5 should be
 	// redundant, but check both
 	var s *models.Scene
+	var existing *models.SceneFile
 	if err := scanner.TxnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
 		qb := r.Scene()
 		if checksum != "" {
@@ -214,6 +229,16 @@ func (scanner *Scanner) ScanNew(ctx context.Context, file file.SourceFile) (retS
 			s, _ = qb.FindByOSHash(oshash)
 		}
 
+		if s == nil {
+			return nil
+		}
+
+		files, err := qb.GetFiles(s.ID)
+		if err != nil {
+			return err
+		}
+
+		existing = matchSceneFile(files, checksum, oshash)
 		return nil
 	}); err != nil {
 		return nil, err
@@ -227,35 +252,54 @@ func (scanner *Scanner) ScanNew(ctx context.Context, file file.SourceFile) (retS
 
 	interactive := getInteractive(file.Path())
 
-	if s != nil {
-		exists, _ := fsutil.FileExists(s.Path)
+	if s != nil && existing != nil {
+		exists, _ := fsutil.FileExists(existing.Path)
 		if !scanner.CaseSensitiveFs {
 			// #1426 - if file exists but is a case-insensitive match for the
 			// original filename, then treat it as a move
-			if exists && strings.EqualFold(path, s.Path) {
+			if exists && strings.EqualFold(path, existing.Path) {
 				exists = false
 			}
 		}
 
+		now := models.SQLiteTimestamp{Timestamp: time.Now()}
+
 		if exists {
-			logger.Infof("%s already exists. Duplicate of %s", path, s.Path)
+			logger.Infof("%s already exists. Adding as a file of scene %s", path, s.Path)
+
+			// the contents are identical, so copy the metadata of the existing file
+			newFile := *existing
+			newFile.Primary = false
+			newFile.SetFile(*scanned)
+			newFile.Interactive = interactive
+			newFile.CreatedAt = now
+			newFile.UpdatedAt = now
+
+			if err := scanner.TxnManager.WithTxn(ctx, func(r models.Repository) error {
+				_, err := r.Scene().CreateFile(newFile)
+				return err
+			}); err != nil {
+				return nil, err
+			}
 		} else {
 			logger.Infof("%s already exists. Updating path...", path)
-			scenePartial := models.ScenePartial{
-				ID:          s.ID,
-				Path:        &path,
-				Interactive: &interactive,
-			}
+			existing.Path = path
+			existing.Interactive = interactive
+			existing.UpdatedAt = now
+
 			if err := scanner.TxnManager.WithTxn(ctx, func(r models.Repository) error {
-				_, err := r.Scene().Update(scenePartial)
+				_, err := r.Scene().UpdateFile(*existing)
 				return err
 			}); err != nil {
 				return nil, err
 			}
 
-			scanner.makeScreenshots(path, nil, sceneHash)
-			scanner.PluginCache.ExecutePostHooks(ctx, s.ID, plugin.SceneUpdatePost, nil, nil)
+			if existing.Primary {
+				scanner.makeScreenshots(path, nil, sceneHash)
+			}
 		}
+
+		scanner.PluginCache.ExecutePostHooks(ctx, s.ID, plugin.SceneUpdatePost, nil, nil)
 	} else {
 		logger.Infof("%s doesn't exist. Creating new item...", path)
 		currentTime := time.Now()
@@ -274,24 +318,22 @@ func (scanner *Scanner) ScanNew(ctx context.Context, file file.SourceFile) (retS
 			title = videoFile.Title
 		}
 
-		newScene := models.Scene{
-			Checksum: sql.NullString{String: checksum, Valid: checksum != ""},
-			OSHash:   sql.NullString{String: oshash, Valid: oshash != ""},
-			Path:     path,
-			FileModTime: models.NullSQLiteTimestamp{
-				Timestamp: scanned.FileModTime,
-				Valid:     true,
-			},
-			Title:       sql.NullString{String: title, Valid: true},
-			CreatedAt:   models.SQLiteTimestamp{Timestamp: currentTime},
-			UpdatedAt:   models.SQLiteTimestamp{Timestamp: currentTime},
+		newFile := models.SceneFile{
 			Interactive: interactive,
 		}
+		newFile.SetFile(*scanned)
 
-		if err := videoFileToScene(&newScene, videoFile); err != nil {
+		if err := videoFileToSceneFile(&newFile, videoFile); err != nil {
 			return nil, err
 		}
 
+		newScene := models.Scene{
+			Title:     sql.NullString{String: title, Valid: true},
+			CreatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+			UpdatedAt: models.SQLiteTimestamp{Timestamp: currentTime},
+		}
+		newScene.SetSceneFile(newFile)
+
 		if scanner.UseFileMetadata {
 			newScene.Details = sql.NullString{String: videoFile.Comment, Valid: true}
 			_ = newScene.Date.Scan(videoFile.CreationTime)
@@ -312,12 +354,27 @@ func (scanner *Scanner) ScanNew(ctx context.Context, file file.SourceFile) (retS
 	return retScene, nil
 }
 
+// matchSceneFile returns the file with the provided checksum or oshash,
+// preferring the primary file.
+func matchSceneFile(files []*models.SceneFile, checksum string, oshash string) *models.SceneFile {
+	for _, f := range files {
+		if checksum != "" && f.Checksum.String == checksum {
+			return f
+		}
+		if oshash != "" && f.OSHash.String == oshash {
+			return f
+		}
+	}
+
+	return nil
+}
+
 func stripExtension(path string) string {
 	ext := filepath.Ext(path)
 	return strings.TrimSuffix(path, ext)
 }
 
-func videoFileToScene(s *models.Scene, videoFile *ffmpeg.VideoFile) error {
+func videoFileToSceneFile(s *models.SceneFile, videoFile *ffmpeg.VideoFile) error {
 	container, err := ffmpeg.MatchContainer(videoFile.Container, s.Path)
 	if err != nil {
 		return fmt.Errorf("matching container: %w", err)
@@ -0,0 +1,556 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// ErrAPIResponse is returned when an API responds with an error.
+var ErrAPIResponse = errors.New("api error")
+
+// apiRequestConfig configures a request sent to an API. The URL, header
+// values, body, form values and GraphQL variables are templates.
+type apiRequestConfig struct {
+	// Defaults to POST if the request has a body, or GET otherwise
+	Method  string    `yaml:"method"`
+	URL     string    `yaml:"url"`
+	Headers []*header `yaml:"headers"`
+	// Raw request body
+	Body string `yaml:"body"`
+	// Form values sent URL encoded in the body
+	Form map[string]string `yaml:"form"`
+	// GraphQL query sent as a JSON body
+	GraphQL *graphQLRequestConfig `yaml:"graphql"`
+}
+
+type graphQLRequestConfig struct {
+	Query string `yaml:"query"`
+	// Variables of the query. String values are templates.
+	Variables map[string]interface{} `yaml:"variables"`
+}
+
+func (c apiRequestConfig) validate() error {
+	set := 0
+	if c.Body != "" {
+		set++
+	}
+	if len(c.Form) > 0 {
+		set++
+	}
+	if c.GraphQL != nil {
+		set++
+		if strings.TrimSpace(c.GraphQL.Query) == "" {
+			return errors.New("query is mandatory for graphql requests")
+		}
+	}
+
+	if set > 1 {
+		return errors.New("requests must have only one of body, form and graphql")
+	}
+
+	return nil
+}
+
+func (c apiRequestConfig) method() string {
+	if c.Method != "" {
+		return strings.ToUpper(c.Method)
+	}
+
+	if c.Body != "" || len(c.Form) > 0 || c.GraphQL != nil {
+		return http.MethodPost
+	}
+
+	return http.MethodGet
+}
+
+// apiTemplate executes request templates. Templates can use the input
+// values of the scrape, such as {{ .url }} or {{ .query }}, and the
+// functions credential, which returns a credential configured for the
+// scraper, and json, which encodes a value as JSON.
+type apiTemplate struct {
+	scraperID   string
+	credentials []*models.ScraperCredential
+	data        map[string]string
+}
+
+func (t apiTemplate) credential(key string) (string, error) {
+	for _, c := range t.credentials {
+		if c.ScraperID == t.scraperID && strings.EqualFold(c.Key, key) {
+			return c.Value, nil
+		}
+	}
+
+	return "", fmt.Errorf("credential %s is not configured for scraper %s", key, t.scraperID)
+}
+
+func (t apiTemplate) execute(text string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=zero").Funcs(template.FuncMap{
+		"credential": t.credential,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, t.data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// executeValue executes the templates of the strings in v, converting
+// YAML maps to JSON compatible maps.
+func (t apiTemplate) executeValue(v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case string:
+		return t.execute(vv)
+	case map[interface{}]interface{}:
+		ret := make(map[string]interface{})
+		for k, v := range vv {
+			value, err := t.executeValue(v)
+			if err != nil {
+				return nil, err
+			}
+			ret[fmt.Sprint(k)] = value
+		}
+		return ret, nil
+	case map[string]interface{}:
+		ret := make(map[string]interface{})
+		for k, v := range vv {
+			value, err := t.executeValue(v)
+			if err != nil {
+				return nil, err
+			}
+			ret[k] = value
+		}
+		return ret, nil
+	case []interface{}:
+		ret := make([]interface{}, len(vv))
+		for i, v := range vv {
+			value, err := t.executeValue(v)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = value
+		}
+		return ret, nil
+	}
+
+	return v, nil
+}
+
+// newRequest creates the request of c. defaultURL is used if c does not
+// set a URL.
+func (t apiTemplate) newRequest(ctx context.Context, c apiRequestConfig, defaultURL string) (*http.Request, error) {
+	u := defaultURL
+	if c.URL != "" {
+		var err error
+		u, err = t.execute(c.URL)
+		if err != nil {
+			return nil, fmt.Errorf("error executing url template: %w", err)
+		}
+	}
+
+	var body io.Reader
+	contentType := ""
+	switch {
+	case c.Body != "":
+		b, err := t.execute(c.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error executing body template: %w", err)
+		}
+		body = strings.NewReader(b)
+	case len(c.Form) > 0:
+		form := url.Values{}
+		for k, v := range c.Form {
+			value, err := t.execute(v)
+			if err != nil {
+				return nil, fmt.Errorf("error executing form template %s: %w", k, err)
+			}
+			form.Set(k, value)
+		}
+		body = strings.NewReader(form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	case c.GraphQL != nil:
+		var variables interface{}
+		if c.GraphQL.Variables != nil {
+			var err error
+			variables, err = t.executeValue(c.GraphQL.Variables)
+			if err != nil {
+				return nil, fmt.Errorf("error executing graphql variables template: %w", err)
+			}
+		}
+
+		data, err := json.Marshal(map[string]interface{}{
+			"query":     c.GraphQL.Query,
+			"variables": variables,
+		})
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, c.method(), u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	for _, h := range c.Headers {
+		if h.Key == "" {
+			continue
+		}
+
+		value, err := t.execute(h.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error executing header template %s: %w", h.Key, err)
+		}
+		req.Header.Set(h.Key, value)
+	}
+
+	return req, nil
+}
+
+type apiScraper struct {
+	scraper      scraperTypeConfig
+	config       config
+	globalConfig GlobalConfig
+	client       *http.Client
+	txnManager   models.TransactionManager
+}
+
+func newAPIScraper(scraper scraperTypeConfig, client *http.Client, txnManager models.TransactionManager, config config, globalConfig GlobalConfig) *apiScraper {
+	return &apiScraper{
+		scraper:      scraper,
+		config:       config,
+		globalConfig: globalConfig,
+		client:       client,
+		txnManager:   txnManager,
+	}
+}
+
+// jsonScraper returns the json scraper used to map the responses.
+func (s *apiScraper) jsonScraper() *jsonScraper {
+	return newJsonScraper(s.scraper, s.client, s.txnManager, s.config, s.globalConfig)
+}
+
+func (s *apiScraper) getMappedScraper() (*mappedScraper, error) {
+	scraper := s.config.JsonScrapers[s.scraper.Scraper]
+	if scraper == nil {
+		return nil, errors.New("json scraper with name " + s.scraper.Scraper + " not found in config")
+	}
+
+	return scraper, nil
+}
+
+func (s *apiScraper) template(data map[string]string) apiTemplate {
+	return apiTemplate{
+		scraperID:   s.config.ID,
+		credentials: s.globalConfig.GetScraperCredentials(),
+		data:        data,
+	}
+}
+
+// session returns the login session of the scraper, or nil if the API
+// does not require authentication.
+func (s *apiScraper) session() *apiSession {
+	if s.config.Auth == nil {
+		return nil
+	}
+
+	return getAPISession(s.config)
+}
+
+// load sends the request of the scraper with the input values and returns
+// the query of the JSON response.
+func (s *apiScraper) load(ctx context.Context, data map[string]string, defaultURL string) (*apiQuery, error) {
+	t := s.template(data)
+
+	var request apiRequestConfig
+	if s.scraper.Request != nil {
+		request = *s.scraper.Request
+	}
+
+	// the URL of the response is used to resolve relative URLs
+	u := defaultURL
+	host := ""
+	newRequest := func() (*http.Request, error) {
+		req, err := t.newRequest(ctx, request, defaultURL)
+		if err != nil {
+			return nil, err
+		}
+
+		userAgent := s.globalConfig.GetScraperUserAgent()
+		if userAgent != "" && req.Header.Get("User-Agent") == "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+
+		u = req.URL.String()
+		host = req.URL.Host
+		return req, nil
+	}
+
+	doc, err := s.session().do(ctx, s.client, t, newRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	// GraphQL errors are only fatal if there is no data
+	if request.GraphQL != nil {
+		if errs := gjson.Get(doc, "errors"); errs.Exists() && !gjson.Get(doc, "data").IsObject() {
+			return nil, fmt.Errorf("%w: %s", ErrAPIResponse, errs.Raw)
+		}
+	}
+
+	if s.config.DebugOptions != nil && s.config.DebugOptions.PrintHTML {
+		logger.Infof("api response: \n%s", doc)
+	}
+
+	return s.getAPIQuery(doc, u, host), nil
+}
+
+// loadURL sends a GET request for the URL and returns the JSON response.
+// The request is only sent through the session of the scraper if the URL
+// is on the host of the API request or of the login, so that tokens and
+// session cookies are not sent to other sites. URLs on other hosts are
+// loaded like the URLs of json scrapers.
+func (s *apiScraper) loadURL(ctx context.Context, u string, host string) (string, error) {
+	session := s.session()
+	if session == nil || !session.isAuthorizedHost(u, host) {
+		return s.jsonScraper().loadURL(ctx, u)
+	}
+
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Accept", "application/json")
+
+		userAgent := s.globalConfig.GetScraperUserAgent()
+		if userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+
+		if s.config.DriverOptions != nil {
+			for _, h := range s.config.DriverOptions.Headers {
+				if h.Key != "" {
+					req.Header.Set(h.Key, h.Value)
+				}
+			}
+		}
+
+		return req, nil
+	}
+
+	doc, err := session.do(ctx, s.client, s.template(nil), newRequest)
+	if err != nil {
+		return "", err
+	}
+
+	if !gjson.Valid(doc) {
+		return "", errors.New("not valid json")
+	}
+
+	if s.config.DebugOptions != nil && s.config.DebugOptions.PrintHTML {
+		logger.Infof("api response (%s): \n%s", u, doc)
+	}
+
+	return doc, nil
+}
+
+func (s *apiScraper) getAPIQuery(doc string, u string, host string) *apiQuery {
+	return &apiQuery{
+		jsonQuery: s.jsonScraper().getJsonQuery(doc, u),
+		scraper:   s,
+		host:      host,
+	}
+}
+
+// apiQuery queries the JSON responses of an API. Sub-scrapes and further
+// pages on the host of the API are loaded through the session of the
+// scraper, so that they are authenticated like the initial request.
+type apiQuery struct {
+	*jsonQuery
+	scraper *apiScraper
+	// host of the API request
+	host string
+}
+
+func (q *apiQuery) subScrape(ctx context.Context, value string) mappedQuery {
+	doc, err := q.scraper.loadURL(ctx, value, q.host)
+	if err != nil {
+		logger.Warnf("Error getting URL '%s' for sub-scraper: %s", value, err.Error())
+		return nil
+	}
+
+	return q.scraper.getAPIQuery(doc, value, q.host)
+}
+
+func (s *apiScraper) scrapeByURL(ctx context.Context, url string, ty models.ScrapeContentType) (models.ScrapedContent, error) {
+	scraper, err := s.getMappedScraper()
+	if err != nil {
+		return nil, err
+	}
+
+	u := replaceURL(url, s.scraper) // allow a URL Replace for url-queries
+	q, err := s.load(ctx, queryURLParameterFromURL(u), u)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ty {
+	case models.ScrapeContentTypePerformer:
+		return scraper.scrapePerformer(ctx, q)
+	case models.ScrapeContentTypeScene:
+		return scraper.scrapeScene(ctx, q)
+	case models.ScrapeContentTypeGallery:
+		return scraper.scrapeGallery(ctx, q)
+	case models.ScrapeContentTypeMovie:
+		return scraper.scrapeMovie(ctx, q)
+	}
+
+	return nil, ErrNotSupported
+}
+
+func (s *apiScraper) scrapeByName(ctx context.Context, name string, ty models.ScrapeContentType) ([]models.ScrapedContent, error) {
+	scraper, err := s.getMappedScraper()
+	if err != nil {
+		return nil, err
+	}
+
+	const placeholder = "{}"
+
+	// replace the placeholder string with the URL-escaped name
+	u := strings.ReplaceAll(s.scraper.QueryURL, placeholder, url.QueryEscape(name))
+
+	q, err := s.load(ctx, map[string]string{"query": name}, u)
+	if err != nil {
+		return nil, err
+	}
+	q.setType(SearchQuery)
+
+	var content []models.ScrapedContent
+	switch ty {
+	case models.ScrapeContentTypePerformer:
+		performers, err := scraper.scrapePerformers(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range performers {
+			content = append(content, p)
+		}
+
+		return content, nil
+	case models.ScrapeContentTypeScene:
+		scenes, err := scraper.scrapeScenes(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range scenes {
+			content = append(content, s)
+		}
+
+		return content, nil
+	}
+
+	return nil, ErrNotSupported
+}
+
+// loadQueryURL loads the scraper with the query URL parameters.
+func (s *apiScraper) loadQueryURL(ctx context.Context, params queryURLParameters) (*apiQuery, error) {
+	if s.scraper.QueryURLReplacements != nil {
+		params.applyReplacements(s.scraper.QueryURLReplacements)
+	}
+	u := params.constructURL(s.scraper.QueryURL)
+
+	return s.load(ctx, params, u)
+}
+
+func (s *apiScraper) scrapeSceneByScene(ctx context.Context, scene *models.Scene) (*models.ScrapedScene, error) {
+	scraper, err := s.getMappedScraper()
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.loadQueryURL(ctx, queryURLParametersFromScene(scene))
+	if err != nil {
+		return nil, err
+	}
+
+	return scraper.scrapeScene(ctx, q)
+}
+
+func (s *apiScraper) scrapeByFragment(ctx context.Context, input Input) (models.ScrapedContent, error) {
+	switch {
+	case input.Gallery != nil:
+		return nil, fmt.Errorf("%w: cannot use an api scraper as a gallery fragment scraper", ErrNotSupported)
+	case input.Image != nil:
+		return nil, fmt.Errorf("%w: cannot use an api scraper as an image fragment scraper", ErrNotSupported)
+	case input.Performer != nil:
+		return nil, fmt.Errorf("%w: cannot use an api scraper as a performer fragment scraper", ErrNotSupported)
+	case input.Scene == nil:
+		return nil, fmt.Errorf("%w: scene input is nil", ErrNotSupported)
+	}
+
+	scraper, err := s.getMappedScraper()
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.loadQueryURL(ctx, queryURLParametersFromScrapedScene(*input.Scene))
+	if err != nil {
+		return nil, err
+	}
+
+	return scraper.scrapeScene(ctx, q)
+}
+
+func (s *apiScraper) scrapeGalleryByGallery(ctx context.Context, gallery *models.Gallery) (*models.ScrapedGallery, error) {
+	scraper, err := s.getMappedScraper()
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.loadQueryURL(ctx, queryURLParametersFromGallery(gallery))
+	if err != nil {
+		return nil, err
+	}
+
+	return scraper.scrapeGallery(ctx, q)
+}
+
+func (s *apiScraper) scrapeImageByImage(ctx context.Context, image *models.Image) (*models.ScrapedImage, error) {
+	return nil, fmt.Errorf("%w: cannot use an api scraper as an image scraper", ErrNotSupported)
+}
@@ -0,0 +1,307 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/stashapp/stash/pkg/logger"
+)
+
+type apiAuthType string
+
+const (
+	// apiAuthToken sends the bearer token returned by the login request
+	apiAuthToken apiAuthType = "token"
+	// apiAuthCookies sends the cookies set by the login request
+	apiAuthCookies apiAuthType = "cookies"
+)
+
+const (
+	defaultTokenSelector        = "access_token"
+	defaultRefreshTokenSelector = "refresh_token"
+	defaultExpiresInSelector    = "expires_in"
+
+	// tokens are refreshed this long before they expire
+	tokenExpiryMargin = 30 * time.Second
+)
+
+// apiAuthConfig configures how scrapeAPI scrapers log in. Credentials are
+// not part of the scraper configuration, but are referenced in the
+// templates of the requests using {{ credential "<key>" }}.
+type apiAuthConfig struct {
+	Type  apiAuthType       `yaml:"type"`
+	Login *apiRequestConfig `yaml:"login"`
+	// Refresh is sent instead of the login request when the token expires.
+	// The refresh token is available in its templates as
+	// {{ .refresh_token }}.
+	Refresh *apiRequestConfig `yaml:"refresh"`
+
+	// GJSON selectors of the values in the login and refresh responses
+	TokenSelector        string `yaml:"tokenSelector"`
+	RefreshTokenSelector string `yaml:"refreshTokenSelector"`
+	ExpiresInSelector    string `yaml:"expiresInSelector"`
+}
+
+func (c apiAuthConfig) validate() error {
+	if c.Type != apiAuthToken && c.Type != apiAuthCookies {
+		return fmt.Errorf("%s is not a valid auth type", c.Type)
+	}
+
+	if c.Login == nil {
+		return errors.New("login is mandatory for auth")
+	}
+
+	if err := c.Login.validate(); err != nil {
+		return err
+	}
+
+	if c.Refresh != nil {
+		return c.Refresh.validate()
+	}
+
+	return nil
+}
+
+func selectorOrDefault(selector string, def string) string {
+	if selector == "" {
+		return def
+	}
+
+	return selector
+}
+
+// apiSession is the login state of a scraper. Sessions are kept between
+// scrapes, so that scrapers only log in when their token expires.
+type apiSession struct {
+	auth apiAuthConfig
+	jar  http.CookieJar
+
+	mutex        sync.Mutex
+	loggedIn     bool
+	token        string
+	refreshToken string
+	// zero if the token does not expire
+	expires time.Time
+	// host of the last login request
+	loginHost string
+}
+
+var apiSessions = struct {
+	sync.Mutex
+	sessions map[string]*apiSession
+}{
+	sessions: make(map[string]*apiSession),
+}
+
+// getAPISession returns the session of the scraper. The session is
+// replaced if the auth configuration of the scraper changed.
+func getAPISession(c config) *apiSession {
+	apiSessions.Lock()
+	defer apiSessions.Unlock()
+
+	s := apiSessions.sessions[c.ID]
+	if s != nil && reflect.DeepEqual(s.auth, *c.Auth) {
+		return s
+	}
+
+	s = &apiSession{
+		auth: *c.Auth,
+	}
+
+	// start with the cookies of the driver options
+	jar, err := c.jar()
+	if err != nil {
+		logger.Warnf("error creating cookie jar: %v", err)
+	} else {
+		s.jar = jar
+	}
+
+	apiSessions.sessions[c.ID] = s
+	return s
+}
+
+// clearAPISessions logs out all scrapers.
+func clearAPISessions() {
+	apiSessions.Lock()
+	defer apiSessions.Unlock()
+
+	apiSessions.sessions = make(map[string]*apiSession)
+}
+
+// readAPIResponse returns the body of the response, or an error if the
+// request failed.
+func readAPIResponse(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("%w: http error %d:%s", ErrAPIResponse, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	return string(body), nil
+}
+
+// isAuthorizedHost returns true if the URL is on the host of the API
+// request or of the login request.
+func (s *apiSession) isAuthorizedHost(u string, requestHost string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(parsed.Host, requestHost) {
+		return true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.loginHost != "" && strings.EqualFold(parsed.Host, s.loginHost)
+}
+
+// do sends the request created by newRequest and returns the response
+// body. The session logs in first if needed, and logs in again if the
+// request is rejected as unauthorized. s may be nil for APIs without
+// authentication.
+func (s *apiSession) do(ctx context.Context, client *http.Client, t apiTemplate, newRequest func() (*http.Request, error)) (string, error) {
+	if s == nil {
+		req, err := newRequest()
+		if err != nil {
+			return "", err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+
+		return readAPIResponse(resp)
+	}
+
+	// send the session cookies
+	sessionClient := *client
+	if s.jar != nil {
+		sessionClient.Jar = s.jar
+	}
+
+	for retry := false; ; retry = true {
+		token, err := s.authorize(ctx, &sessionClient, t, retry)
+		if err != nil {
+			return "", err
+		}
+
+		req, err := newRequest()
+		if err != nil {
+			return "", err
+		}
+
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := sessionClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+
+		if !retry && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			resp.Body.Close()
+			logger.Debugf("[scraper] request unauthorized, logging in again")
+			continue
+		}
+
+		return readAPIResponse(resp)
+	}
+}
+
+// authorize logs in if the session is not logged in, if the token
+// expired or if force is true, and returns the current token.
+func (s *apiSession) authorize(ctx context.Context, client *http.Client, t apiTemplate, force bool) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	valid := s.expires.IsZero() || time.Now().Before(s.expires)
+	if s.loggedIn && valid && !force {
+		return s.token, nil
+	}
+
+	if s.loggedIn && s.refreshToken != "" && s.auth.Refresh != nil {
+		err := s.login(ctx, client, t, *s.auth.Refresh)
+		if err == nil {
+			return s.token, nil
+		}
+
+		logger.Warnf("[scraper] error refreshing token, logging in again: %v", err)
+	}
+
+	if err := s.login(ctx, client, t, *s.auth.Login); err != nil {
+		return "", err
+	}
+
+	return s.token, nil
+}
+
+// login sends the login or refresh request and stores the returned
+// tokens. Cookies are stored by the cookie jar of the client.
+func (s *apiSession) login(ctx context.Context, client *http.Client, t apiTemplate, request apiRequestConfig) error {
+	s.loggedIn = false
+
+	// make the refresh token available to the templates
+	data := make(map[string]string)
+	for k, v := range t.data {
+		data[k] = v
+	}
+	data["refresh_token"] = s.refreshToken
+	t.data = data
+
+	req, err := t.newRequest(ctx, request, "")
+	if err != nil {
+		return err
+	}
+	s.loginHost = req.URL.Host
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	body, err := readAPIResponse(resp)
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	if s.auth.Type == apiAuthToken {
+		selector := selectorOrDefault(s.auth.TokenSelector, defaultTokenSelector)
+		token := gjson.Get(body, selector)
+		if !token.Exists() {
+			return fmt.Errorf("%w: login response has no token at %s", ErrAPIResponse, selector)
+		}
+		s.token = token.String()
+
+		if refreshToken := gjson.Get(body, selectorOrDefault(s.auth.RefreshTokenSelector, defaultRefreshTokenSelector)); refreshToken.Exists() {
+			s.refreshToken = refreshToken.String()
+		}
+
+		s.expires = time.Time{}
+		if expiresIn := gjson.Get(body, selectorOrDefault(s.auth.ExpiresInSelector, defaultExpiresInSelector)); expiresIn.Int() > 0 {
+			s.expires = time.Now().Add(time.Duration(expiresIn.Int())*time.Second - tokenExpiryMargin)
+		}
+	}
+
+	s.loggedIn = true
+	return nil
+}
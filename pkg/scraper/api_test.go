@@ -0,0 +1,312 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type credentialsGlobalConfig struct {
+	mockGlobalConfig
+	credentials []*models.ScraperCredential
+}
+
+func (c credentialsGlobalConfig) GetScraperCredentials() []*models.ScraperCredential {
+	return c.credentials
+}
+
+func TestAPIScraperTokenLogin(t *testing.T) {
+	logins := 0
+	token := ""
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if r.FormValue("username") != "user" || r.FormValue("password") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			logins++
+			token = fmt.Sprintf("token%d", logins)
+			fmt.Fprintf(w, `{"auth": {"token": "%s"}}`, token)
+		case "/graphql":
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			var body struct {
+				Query     string `json:"query"`
+				Variables struct {
+					Term string `json:"term"`
+				} `json:"variables"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			fmt.Fprintf(w, `{"data": {"searchScene": [{"title": "%s 1"}, {"title": "%s 2"}]}}`, body.Variables.Term, body.Variables.Term)
+		}
+	}))
+	defer ts.Close()
+
+	c := loadTestConfig(t, `name: Test
+sceneByName:
+  action: scrapeAPI
+  queryURL: `+ts.URL+`/graphql
+  scraper: sceneSearch
+  request:
+    graphql:
+      query: |
+        query ($term: String!) { searchScene(term: $term) { title } }
+      variables:
+        term: "{{ .query }}"
+auth:
+  type: token
+  tokenSelector: auth.token
+  login:
+    url: `+ts.URL+`/login
+    form:
+      username: '{{ credential "username" }}'
+      password: '{{ credential "password" }}'
+jsonScrapers:
+  sceneSearch:
+    scene:
+      Title: data.searchScene.#.title
+`)
+	c.ID = "test"
+	defer clearAPISessions()
+
+	globalConfig := credentialsGlobalConfig{
+		credentials: []*models.ScraperCredential{
+			{ScraperID: "test", Key: "username", Value: "user"},
+			{ScraperID: "test", Key: "password", Value: "secret"},
+			{ScraperID: "other", Key: "password", Value: "other"},
+		},
+	}
+
+	if err := c.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	s := newGroupScraper(c, nil, globalConfig)
+	search := func(query string) []string {
+		content, err := s.(nameScraper).viaName(context.Background(), &http.Client{}, query, models.ScrapeContentTypeScene)
+		if err != nil {
+			t.Fatalf("viaName() error = %v", err)
+		}
+
+		var got []string
+		for _, c := range content {
+			got = append(got, *c.(*models.ScrapedScene).Title)
+		}
+		return got
+	}
+
+	assert.Equal(t, []string{"foo 1", "foo 2"}, search("foo"))
+	assert.Equal(t, 1, logins)
+
+	// the session is reused
+	assert.Equal(t, []string{"bar 1", "bar 2"}, search("bar"))
+	assert.Equal(t, 1, logins)
+
+	// logs in again if the token is rejected
+	token = "revoked"
+	assert.Equal(t, []string{"baz 1", "baz 2"}, search("baz"))
+	assert.Equal(t, 2, logins)
+}
+
+func TestAPIScraperCookieLogin(t *testing.T) {
+	const sessionID = "abc"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: sessionID, Path: "/"})
+		case "/scene":
+			cookie, err := r.Cookie("session")
+			if err != nil || cookie.Value != sessionID {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			fmt.Fprintf(w, `{"title": "%s"}`, r.URL.Query().Get("id"))
+		}
+	}))
+	defer ts.Close()
+
+	c := loadTestConfig(t, `name: Test
+sceneByURL:
+  - action: scrapeAPI
+    url:
+      - `+ts.URL+`
+    scraper: sceneScraper
+auth:
+  type: cookies
+  login:
+    method: POST
+    url: `+ts.URL+`/login
+jsonScrapers:
+  sceneScraper:
+    scene:
+      Title: title
+`)
+	c.ID = "test"
+	defer clearAPISessions()
+
+	s := newGroupScraper(c, nil, mockGlobalConfig{})
+	content, err := s.(urlScraper).viaURL(context.Background(), &http.Client{}, ts.URL+"/scene?id=123", models.ScrapeContentTypeScene)
+	if err != nil {
+		t.Fatalf("viaURL() error = %v", err)
+	}
+
+	assert.Equal(t, "123", *content.(*models.ScrapedScene).Title)
+}
+
+func TestAPIScraperPaginationUsesSession(t *testing.T) {
+	const token = "secret-token"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			fmt.Fprintf(w, `{"access_token": "%s"}`, token)
+		case "/search":
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			page := r.URL.Query().Get("page")
+			if page == "2" {
+				fmt.Fprint(w, `{"results": [{"title": "second"}]}`)
+				return
+			}
+
+			fmt.Fprint(w, `{"results": [{"title": "first"}], "next": "/search?page=2"}`)
+		}
+	}))
+	defer ts.Close()
+
+	c := loadTestConfig(t, `name: Test
+sceneByName:
+  action: scrapeAPI
+  queryURL: `+ts.URL+`/search?q={}
+  scraper: sceneSearch
+auth:
+  type: token
+  login:
+    url: `+ts.URL+`/login
+jsonScrapers:
+  sceneSearch:
+    pagination:
+      nextPage: next
+    scene:
+      Title: results.#.title
+`)
+	c.ID = "test"
+	defer clearAPISessions()
+
+	s := newGroupScraper(c, nil, mockGlobalConfig{})
+	content, err := s.(nameScraper).viaName(context.Background(), &http.Client{}, "foo", models.ScrapeContentTypeScene)
+	if err != nil {
+		t.Fatalf("viaName() error = %v", err)
+	}
+
+	var got []string
+	for _, c := range content {
+		got = append(got, *c.(*models.ScrapedScene).Title)
+	}
+
+	assert.Equal(t, []string{"first", "second"}, got)
+}
+
+func TestAPIScraperOtherHostsWithoutSession(t *testing.T) {
+	const token = "secret-token"
+
+	var otherAuthorization, otherCookie string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAuthorization = r.Header.Get("Authorization")
+		otherCookie = r.Header.Get("Cookie")
+		fmt.Fprint(w, `{"results": [{"title": "other"}]}`)
+	}))
+	defer other.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			fmt.Fprintf(w, `{"access_token": "%s"}`, token)
+		case "/search":
+			if r.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			fmt.Fprintf(w, `{"results": [{"title": "first"}], "next": "%s/search?page=2"}`, other.URL)
+		}
+	}))
+	defer ts.Close()
+
+	c := loadTestConfig(t, `name: Test
+sceneByName:
+  action: scrapeAPI
+  queryURL: `+ts.URL+`/search?q={}
+  scraper: sceneSearch
+auth:
+  type: token
+  login:
+    url: `+ts.URL+`/login
+jsonScrapers:
+  sceneSearch:
+    pagination:
+      nextPage: next
+    scene:
+      Title: results.#.title
+`)
+	c.ID = "test"
+	defer clearAPISessions()
+
+	s := newGroupScraper(c, nil, mockGlobalConfig{})
+	content, err := s.(nameScraper).viaName(context.Background(), &http.Client{}, "foo", models.ScrapeContentTypeScene)
+	if err != nil {
+		t.Fatalf("viaName() error = %v", err)
+	}
+
+	var got []string
+	for _, c := range content {
+		got = append(got, *c.(*models.ScrapedScene).Title)
+	}
+
+	assert.Equal(t, []string{"first", "other"}, got)
+	assert.Empty(t, otherAuthorization, "authorization sent to other host")
+	assert.Empty(t, otherCookie, "session cookie sent to other host")
+}
+
+func TestAPIRequestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       apiRequestConfig
+		wantErr bool
+	}{
+		{"empty", apiRequestConfig{}, false},
+		{"body", apiRequestConfig{Body: "{}"}, false},
+		{"body and form", apiRequestConfig{Body: "{}", Form: map[string]string{"a": "b"}}, true},
+		{"empty graphql query", apiRequestConfig{GraphQL: &graphQLRequestConfig{}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.c.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("apiRequestConfig.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
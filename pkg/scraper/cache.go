@@ -3,6 +3,7 @@ package scraper
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -37,6 +38,9 @@ type GlobalConfig interface {
 	GetScraperCDPPath() string
 	GetScraperCertCheck() bool
 	GetPythonPath() string
+	GetCachePath() string
+	GetScraperCacheTTL() int
+	GetScraperCredentials() []*models.ScraperCredential
 }
 
 func isCDPPathHTTP(c GlobalConfig) bool {
@@ -53,14 +57,29 @@ type Cache struct {
 	scrapers     map[string]scraper // Scraper ID -> Scraper
 	globalConfig GlobalConfig
 	txnManager   models.TransactionManager
+
+	// results caches scraped content and HTTP responses
+	results *diskCache
+	// hashes holds the definition hashes of the scrapers by scraper ID
+	hashes map[string]string
+	// limiters throttles the requests of the client
+	limiters *hostLimiters
 }
 
 // newClient creates a scraper-local http client we use throughout the scraper subsystem.
-func newClient(gc GlobalConfig) *http.Client {
+// Responses are cached in the disk cache, and requests to hosts are limited by
+// the host limiters.
+func newClient(gc GlobalConfig, cache *diskCache, limiters *hostLimiters) *http.Client {
 	client := &http.Client{
-		Transport: &http.Transport{ // ignore insecure certificates
-			TLSClientConfig:     &tls.Config{InsecureSkipVerify: !gc.GetScraperCertCheck()},
-			MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		Transport: &cachingTransport{
+			cache: cache,
+			next: &rateLimitedTransport{
+				limiters: limiters,
+				next: &http.Transport{ // ignore insecure certificates
+					TLSClientConfig:     &tls.Config{InsecureSkipVerify: !gc.GetScraperCertCheck()},
+					MaxIdleConnsPerHost: maxIdleConnsPerHost,
+				},
+			},
 		},
 		Timeout: scrapeGetTimeout,
 		// defaultCheckRedirect code with max changed from 10 to maxRedirects
@@ -82,25 +101,48 @@ func newClient(gc GlobalConfig) *http.Client {
 // Scraper configurations are loaded from yml files in the provided scrapers
 // directory and any subdirectories.
 func NewCache(globalConfig GlobalConfig, txnManager models.TransactionManager) (*Cache, error) {
+	results := newDiskCache(globalConfig)
+	limiters := &hostLimiters{}
+
 	// HTTP Client setup
-	client := newClient(globalConfig)
+	client := newClient(globalConfig, results, limiters)
 
-	scrapers, err := loadScrapers(globalConfig, txnManager)
+	scrapers, configs, err := loadScrapers(globalConfig, txnManager)
 	if err != nil {
 		return nil, err
 	}
 
+	limiters.setLimits(configs)
+
+	// remove the entries which expired since the last run
+	go results.prune()
+
 	return &Cache{
 		client:       client,
 		globalConfig: globalConfig,
 		scrapers:     scrapers,
 		txnManager:   txnManager,
+		results:      results,
+		hashes:       getDefinitionHashes(configs),
+		limiters:     limiters,
 	}, nil
 }
 
-func loadScrapers(globalConfig GlobalConfig, txnManager models.TransactionManager) (map[string]scraper, error) {
+func getDefinitionHashes(configs []config) map[string]string {
+	ret := make(map[string]string)
+	for _, c := range configs {
+		ret[c.ID] = c.hash
+	}
+
+	return ret
+}
+
+// loadScrapers returns the scrapers and the configurations of the scrapers
+// loaded from the scrapers path.
+func loadScrapers(globalConfig GlobalConfig, txnManager models.TransactionManager) (map[string]scraper, []config, error) {
 	path := globalConfig.GetScrapersPath()
 	scrapers := make(map[string]scraper)
+	var configs []config
 
 	// Add built-in scrapers
 	freeOnes := getFreeonesScraper(txnManager, globalConfig)
@@ -119,6 +161,7 @@ func loadScrapers(globalConfig GlobalConfig, txnManager models.TransactionManage
 			} else {
 				scraper := newGroupScraper(*c, txnManager, globalConfig)
 				scrapers[scraper.spec().ID] = scraper
+				configs = append(configs, *c)
 			}
 			scraperFiles = append(scraperFiles, fp)
 		}
@@ -127,22 +170,25 @@ func loadScrapers(globalConfig GlobalConfig, txnManager models.TransactionManage
 
 	if err != nil {
 		logger.Errorf("Error reading scraper configs: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	return scrapers, nil
+	return scrapers, configs, nil
 }
 
 // ReloadScrapers clears the scraper cache and reloads from the scraper path.
 // In the event of an error during loading, the cache will be left empty.
 func (c *Cache) ReloadScrapers() error {
 	c.scrapers = nil
-	scrapers, err := loadScrapers(c.globalConfig, c.txnManager)
+	clearAPISessions()
+	scrapers, configs, err := loadScrapers(c.globalConfig, c.txnManager)
 	if err != nil {
 		return err
 	}
 
 	c.scrapers = scrapers
+	c.hashes = getDefinitionHashes(configs)
+	c.limiters.setLimits(configs)
 	return nil
 }
 
@@ -202,7 +248,9 @@ func (c Cache) ScrapeName(ctx context.Context, id, query string, ty models.Scrap
 		return nil, fmt.Errorf("%w: cannot use scraper %s to scrape by name", ErrNotSupported, id)
 	}
 
-	return ns.viaName(ctx, c.client, query, ty)
+	return c.cachedScrape([]string{"name", id, c.hashes[id], string(ty), query}, func() ([]models.ScrapedContent, error) {
+		return ns.viaName(ctx, c.client, query, ty)
+	})
 }
 
 // ScrapeFragment uses the given fragment input to scrape
@@ -217,7 +265,14 @@ func (c Cache) ScrapeFragment(ctx context.Context, id string, input Input) (mode
 		return nil, fmt.Errorf("%w: cannot use scraper %s as a fragment scraper", ErrNotSupported, id)
 	}
 
-	content, err := fs.viaFragment(ctx, c.client, input)
+	key, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := c.cachedScrapeSingle([]string{"fragment", id, c.hashes[id], string(key)}, func() (models.ScrapedContent, error) {
+		return fs.viaFragment(ctx, c.client, input)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error while fragment scraping with scraper %s: %w", id, err)
 	}
@@ -235,7 +290,9 @@ func (c Cache) ScrapeURL(ctx context.Context, url string, ty models.ScrapeConten
 			if !ok {
 				return nil, fmt.Errorf("%w: cannot use scraper %s as an url scraper", ErrNotSupported, s.spec().ID)
 			}
-			ret, err := ul.viaURL(ctx, c.client, url, ty)
+			ret, err := c.cachedScrapeSingle([]string{"url", s.spec().ID, c.hashes[s.spec().ID], string(ty), url}, func() (models.ScrapedContent, error) {
+				return ul.viaURL(ctx, c.client, url, ty)
+			})
 			if err != nil {
 				return nil, err
 			}
@@ -302,6 +359,27 @@ func (c Cache) ScrapeID(ctx context.Context, scraperID string, id int, ty models
 			return nil, fmt.Errorf("scraper %s: %w", scraperID, err)
 		}
 
+		if scraped != nil {
+			ret = scraped
+		}
+	case models.ScrapeContentTypeImage:
+		is, ok := s.(imageScraper)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot use scraper %s as an image scraper", ErrNotSupported, scraperID)
+		}
+
+		image, err := getImageByID(ctx, id, c.txnManager)
+		if err != nil {
+			return nil, fmt.Errorf("scraper %s: unable to load image id %v: %w", scraperID, id, err)
+		}
+
+		// don't assign nil concrete pointer to ret interface, otherwise nil
+		// detection is harder
+		scraped, err := is.viaImage(ctx, c.client, image)
+		if err != nil {
+			return nil, fmt.Errorf("scraper %s: %w", scraperID, err)
+		}
+
 		if scraped != nil {
 			ret = scraped
 		}
@@ -309,3 +387,50 @@ func (c Cache) ScrapeID(ctx context.Context, scraperID string, id int, ty models
 
 	return c.postScrape(ctx, ret)
 }
+
+// cachedScrape returns the cached content for the key, or the content
+// returned by scrape, which is added to the cache.
+func (c Cache) cachedScrape(key []string, scrape func() ([]models.ScrapedContent, error)) ([]models.ScrapedContent, error) {
+	if !c.results.enabled() {
+		return scrape()
+	}
+
+	key = append([]string{"content"}, key...)
+
+	var cached []cachedContent
+	if c.results.get(key, &cached) {
+		ret := make([]models.ScrapedContent, len(cached))
+		for i, item := range cached {
+			ret[i] = item.content()
+		}
+		return ret, nil
+	}
+
+	ret, err := scrape()
+	if err != nil {
+		return nil, err
+	}
+
+	if items, ok := newCachedContent(ret); ok {
+		c.results.set(key, items)
+	}
+
+	return ret, nil
+}
+
+// cachedScrapeSingle is cachedScrape for scrapers returning single
+// results.
+func (c Cache) cachedScrapeSingle(key []string, scrape func() (models.ScrapedContent, error)) (models.ScrapedContent, error) {
+	content, err := c.cachedScrape(key, func() ([]models.ScrapedContent, error) {
+		ret, err := scrape()
+		if err != nil || ret == nil {
+			return nil, err
+		}
+		return []models.ScrapedContent{ret}, nil
+	})
+	if err != nil || len(content) == 0 {
+		return nil, err
+	}
+
+	return content[0], nil
+}
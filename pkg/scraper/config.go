@@ -1,6 +1,9 @@
 package scraper
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -15,6 +18,10 @@ import (
 type config struct {
 	ID   string
 	path string
+	// hash is the hash of the definition of the scraper. It is part of the
+	// keys of the cached results, so that results scraped using a previous
+	// definition are not used.
+	hash string
 
 	// The name of the scraper. This is displayed in the UI.
 	Name string `yaml:"name"`
@@ -46,6 +53,12 @@ type config struct {
 	// Configuration for querying a gallery by a URL
 	GalleryByURL []*scrapeByURLConfig `yaml:"galleryByURL"`
 
+	// Configuration for querying image by an Image fragment
+	ImageByFragment *scraperTypeConfig `yaml:"imageByFragment"`
+
+	// Configuration for querying an image by a URL
+	ImageByURL []*scrapeByURLConfig `yaml:"imageByURL"`
+
 	// Configuration for querying a movie by a URL
 	MovieByURL []*scrapeByURLConfig `yaml:"movieByURL"`
 
@@ -63,6 +76,12 @@ type config struct {
 
 	// Scraping driver options
 	DriverOptions *scraperDriverOptions `yaml:"driver"`
+
+	// Rate limits of the requests to hosts
+	RateLimits []*rateLimitConfig `yaml:"rateLimits"`
+
+	// API login configuration for scrapeAPI scrapers
+	Auth *apiAuthConfig `yaml:"auth"`
 }
 
 func (c config) validate() error {
@@ -106,6 +125,30 @@ func (c config) validate() error {
 		}
 	}
 
+	if c.ImageByFragment != nil {
+		if err := c.ImageByFragment.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range c.ImageByURL {
+		if err := s.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range c.RateLimits {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Auth != nil {
+		if err := c.Auth.validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -121,6 +164,9 @@ type scraperTypeConfig struct {
 	// for xpath name scraper only
 	QueryURL             string               `yaml:"queryURL"`
 	QueryURLReplacements queryURLReplacements `yaml:"queryURLReplace"`
+
+	// for api scraper only
+	Request *apiRequestConfig `yaml:"request"`
 }
 
 func (c scraperTypeConfig) validate() error {
@@ -132,6 +178,12 @@ func (c scraperTypeConfig) validate() error {
 		return errors.New("script is mandatory for script scraper action")
 	}
 
+	if c.Request != nil {
+		if err := c.Request.validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -194,16 +246,23 @@ type scraperDriverOptions struct {
 }
 
 func loadConfigFromYAML(id string, reader io.Reader) (*config, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
 	ret := &config{}
 
-	parser := yaml.NewDecoder(reader)
+	parser := yaml.NewDecoder(bytes.NewReader(data))
 	parser.SetStrict(true)
-	err := parser.Decode(&ret)
+	err = parser.Decode(&ret)
 	if err != nil {
 		return nil, err
 	}
 
 	ret.ID = id
+	hash := sha256.Sum256(data)
+	ret.hash = hex.EncodeToString(hash[:])
 
 	if err := ret.validate(); err != nil {
 		return nil, err
@@ -290,6 +349,21 @@ func (c config) spec() models.Scraper {
 		ret.Gallery = &gallery
 	}
 
+	image := models.ScraperSpec{}
+	if c.ImageByFragment != nil {
+		image.SupportedScrapes = append(image.SupportedScrapes, models.ScrapeTypeFragment)
+	}
+	if len(c.ImageByURL) > 0 {
+		image.SupportedScrapes = append(image.SupportedScrapes, models.ScrapeTypeURL)
+		for _, v := range c.ImageByURL {
+			image.Urls = append(image.Urls, v.URL...)
+		}
+	}
+
+	if len(image.SupportedScrapes) > 0 {
+		ret.Image = &image
+	}
+
 	movie := models.ScraperSpec{}
 	if len(c.MovieByURL) > 0 {
 		movie.SupportedScrapes = append(movie.SupportedScrapes, models.ScrapeTypeURL)
@@ -313,6 +387,8 @@ func (c config) supports(ty models.ScrapeContentType) bool {
 		return (c.SceneByName != nil && c.SceneByQueryFragment != nil) || c.SceneByFragment != nil || len(c.SceneByURL) > 0
 	case models.ScrapeContentTypeGallery:
 		return c.GalleryByFragment != nil || len(c.GalleryByURL) > 0
+	case models.ScrapeContentTypeImage:
+		return c.ImageByFragment != nil || len(c.ImageByURL) > 0
 	case models.ScrapeContentTypeMovie:
 		return len(c.MovieByURL) > 0
 	}
@@ -340,6 +416,12 @@ func (c config) matchesURL(url string, ty models.ScrapeContentType) bool {
 				return true
 			}
 		}
+	case models.ScrapeContentTypeImage:
+		for _, scraper := range c.ImageByURL {
+			if scraper.matchesURL(url) {
+				return true
+			}
+		}
 	case models.ScrapeContentTypeMovie:
 		for _, scraper := range c.MovieByURL {
 			if scraper.matchesURL(url) {
@@ -0,0 +1,324 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// diskCacheDir is the directory of the scraper cache in the cache path.
+const diskCacheDir = "scrapers"
+
+// diskCache stores values as JSON files in the cache path. Entries expire
+// after the scraper cache TTL of the global config. The cache is disabled
+// if the TTL or the cache path is not set.
+type diskCache struct {
+	globalConfig GlobalConfig
+}
+
+func newDiskCache(globalConfig GlobalConfig) *diskCache {
+	return &diskCache{
+		globalConfig: globalConfig,
+	}
+}
+
+func (c *diskCache) dir() string {
+	cachePath := c.globalConfig.GetCachePath()
+	if cachePath == "" {
+		return ""
+	}
+
+	return filepath.Join(cachePath, diskCacheDir)
+}
+
+func (c *diskCache) ttl() time.Duration {
+	return time.Duration(c.globalConfig.GetScraperCacheTTL()) * time.Second
+}
+
+// enabled returns false if the cache is nil, so that clients can be
+// created without a cache.
+func (c *diskCache) enabled() bool {
+	return c != nil && c.dir() != "" && c.ttl() > 0
+}
+
+// filename returns the name of the file storing the entry for key. Keys
+// are hashed so that they can be of any length and content.
+func (c *diskCache) filename(key []string) string {
+	// encoding a string slice cannot fail
+	data, _ := json.Marshal(key)
+	hash := sha256.Sum256(data)
+	return filepath.Join(c.dir(), hex.EncodeToString(hash[:])+".json")
+}
+
+func (c *diskCache) expired(info fs.FileInfo) bool {
+	return time.Since(info.ModTime()) > c.ttl()
+}
+
+// get decodes the entry for key into v. Returns false if there is no
+// unexpired entry.
+func (c *diskCache) get(key []string, v interface{}) bool {
+	if !c.enabled() {
+		return false
+	}
+
+	fn := c.filename(key)
+	info, err := os.Stat(fn)
+	if err != nil {
+		return false
+	}
+
+	if c.expired(info) {
+		if err := os.Remove(fn); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logger.Warnf("[scraper] error removing expired cache entry %s: %v", fn, err)
+		}
+		return false
+	}
+
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return false
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		logger.Warnf("[scraper] error reading cache entry %s: %v", fn, err)
+		return false
+	}
+
+	return true
+}
+
+// set stores v as the entry for key. Errors are logged since the cache is
+// not essential to scraping.
+func (c *diskCache) set(key []string, v interface{}) {
+	if !c.enabled() {
+		return
+	}
+
+	if err := c.write(c.filename(key), v); err != nil {
+		logger.Warnf("[scraper] error writing cache entry: %v", err)
+	}
+}
+
+func (c *diskCache) write(fn string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that concurrent reads never see
+	// partial entries
+	f, err := os.CreateTemp(filepath.Dir(fn), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), fn)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+
+	return err
+}
+
+// prune removes the expired entries, or all entries if the cache is
+// disabled.
+func (c *diskCache) prune() {
+	dir := c.dir()
+	if dir == "" {
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Warnf("[scraper] error reading cache directory %s: %v", dir, err)
+		}
+		return
+	}
+
+	enabled := c.enabled()
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		if !enabled || c.expired(info) {
+			fn := filepath.Join(dir, e.Name())
+			if err := os.Remove(fn); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				logger.Warnf("[scraper] error removing expired cache entry %s: %v", fn, err)
+			}
+		}
+	}
+}
+
+// cachedResponse is a successful HTTP response stored in the cache.
+type cachedResponse struct {
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// cachingTransport caches the successful responses of GET requests.
+// Requests are cached by their URL and headers, since scrapers may set
+// headers which change the response. Requests with cookies or
+// authorization are not cached, since their responses depend on the
+// session, and cookies set by responses are not stored, so that cache hits
+// do not replace the cookies of the session.
+type cachingTransport struct {
+	next  http.RoundTripper
+	cache *diskCache
+}
+
+func responseCacheKey(req *http.Request) []string {
+	ret := []string{"response", req.Method, req.URL.String()}
+
+	var headers []string
+	for k, v := range req.Header {
+		headers = append(headers, k+": "+strings.Join(v, ", "))
+	}
+	sort.Strings(headers)
+
+	return append(ret, headers...)
+}
+
+// isCacheableRequest returns true if the response of the request may be
+// cached.
+func isCacheableRequest(req *http.Request) bool {
+	return req.Method == http.MethodGet && req.Header.Get("Authorization") == "" && req.Header.Get("Cookie") == ""
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isCacheableRequest(req) || !t.cache.enabled() {
+		return t.next.RoundTrip(req)
+	}
+
+	key := responseCacheKey(req)
+
+	var cached cachedResponse
+	if t.cache.get(key, &cached) {
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", http.StatusOK, http.StatusText(http.StatusOK)),
+			StatusCode:    http.StatusOK,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        cached.Header,
+			Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	t.cache.set(key, cachedResponse{
+		Header: header,
+		Body:   body,
+	})
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// cachedContent is an item of scraped content stored in the cache. Since
+// ScrapedContent is an interface, the item is stored in the field of its
+// type.
+type cachedContent struct {
+	Scene     *models.ScrapedScene     `json:"scene,omitempty"`
+	Performer *models.ScrapedPerformer `json:"performer,omitempty"`
+	Gallery   *models.ScrapedGallery   `json:"gallery,omitempty"`
+	Image     *models.ScrapedImage     `json:"image,omitempty"`
+	Movie     *models.ScrapedMovie     `json:"movie,omitempty"`
+}
+
+// newCachedContent returns the cache items of the content. Returns false
+// if the content contains types which cannot be cached.
+func newCachedContent(content []models.ScrapedContent) ([]cachedContent, bool) {
+	// empty results are cached as well
+	ret := make([]cachedContent, 0, len(content))
+	for _, c := range content {
+		var item cachedContent
+		switch v := c.(type) {
+		case *models.ScrapedScene:
+			item.Scene = v
+		case models.ScrapedScene:
+			item.Scene = &v
+		case *models.ScrapedPerformer:
+			item.Performer = v
+		case models.ScrapedPerformer:
+			item.Performer = &v
+		case *models.ScrapedGallery:
+			item.Gallery = v
+		case models.ScrapedGallery:
+			item.Gallery = &v
+		case *models.ScrapedImage:
+			item.Image = v
+		case models.ScrapedImage:
+			item.Image = &v
+		case *models.ScrapedMovie:
+			item.Movie = v
+		case models.ScrapedMovie:
+			item.Movie = &v
+		case nil:
+		default:
+			return nil, false
+		}
+
+		ret = append(ret, item)
+	}
+
+	return ret, true
+}
+
+func (c cachedContent) content() models.ScrapedContent {
+	switch {
+	case c.Scene != nil:
+		return c.Scene
+	case c.Performer != nil:
+		return c.Performer
+	case c.Gallery != nil:
+		return c.Gallery
+	case c.Image != nil:
+		return c.Image
+	case c.Movie != nil:
+		return c.Movie
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+type cacheGlobalConfig struct {
+	mockGlobalConfig
+	cachePath string
+	ttl       int
+}
+
+func (c cacheGlobalConfig) GetCachePath() string {
+	return c.cachePath
+}
+
+func (c cacheGlobalConfig) GetScraperCacheTTL() int {
+	return c.ttl
+}
+
+func TestDiskCache(t *testing.T) {
+	gc := &cacheGlobalConfig{
+		cachePath: t.TempDir(),
+		ttl:       60,
+	}
+	c := newDiskCache(gc)
+
+	key := []string{"a", "b"}
+	c.set(key, "value")
+
+	var got string
+	if !c.get(key, &got) || got != "value" {
+		t.Errorf("get() = %q, want value", got)
+	}
+
+	if c.get([]string{"a\x00b"}, &got) {
+		t.Errorf("get() of a different key should miss")
+	}
+
+	// expire the entry
+	fn := c.filename(key)
+	old := time.Now().Add(-2 * time.Minute)
+	if err := os.Chtimes(fn, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.get(key, &got) {
+		t.Errorf("get() of an expired entry should miss")
+	}
+	if _, err := os.Stat(fn); !os.IsNotExist(err) {
+		t.Errorf("expired entry should be removed")
+	}
+
+	// disabling the cache removes the entries when pruned
+	c.set(key, "value")
+	gc.ttl = 0
+	if c.get(key, &got) {
+		t.Errorf("get() of a disabled cache should miss")
+	}
+
+	c.prune()
+	entries, _ := os.ReadDir(filepath.Join(gc.cachePath, diskCacheDir))
+	if len(entries) != 0 {
+		t.Errorf("prune() left %d entries", len(entries))
+	}
+}
+
+func TestCachingTransport(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		fmt.Fprintf(w, "%s %s", r.URL.Path, r.Header.Get("X-Test"))
+	}))
+	defer ts.Close()
+
+	cache := newDiskCache(&cacheGlobalConfig{
+		cachePath: t.TempDir(),
+		ttl:       60,
+	})
+	client := newClient(mockGlobalConfig{}, cache, &hostLimiters{})
+
+	get := func(path string, header string, sessionHeader string) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("X-Test", header)
+		if sessionHeader != "" {
+			req.Header.Set(sessionHeader, "secret")
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	tests := []struct {
+		path          string
+		header        string
+		sessionHeader string
+		status        int
+		body          string
+		requests      int
+	}{
+		{"/a", "x", "", http.StatusOK, "/a x", 1},
+		{"/a", "x", "", http.StatusOK, "/a x", 1},
+		// headers are part of the key
+		{"/a", "y", "", http.StatusOK, "/a y", 2},
+		// errors are not cached
+		{"/missing", "", "", http.StatusNotFound, "404 page not found\n", 3},
+		{"/missing", "", "", http.StatusNotFound, "404 page not found\n", 4},
+		// requests with cookies or authorization are not cached
+		{"/b", "", "Cookie", http.StatusOK, "/b ", 5},
+		{"/b", "", "Cookie", http.StatusOK, "/b ", 6},
+		{"/c", "", "Authorization", http.StatusOK, "/c ", 7},
+		{"/c", "", "Authorization", http.StatusOK, "/c ", 8},
+	}
+
+	for _, tt := range tests {
+		resp, body := get(tt.path, tt.header, tt.sessionHeader)
+		if resp.StatusCode != tt.status || body != tt.body || requests != tt.requests {
+			t.Errorf("get(%s, %s, %s) = %d %q after %d requests, want %d %q after %d requests", tt.path, tt.header, tt.sessionHeader, resp.StatusCode, body, requests, tt.status, tt.body, tt.requests)
+		}
+	}
+
+	// cookies set by the response are not replayed
+	resp, _ := get("/a", "x", "")
+	if len(resp.Cookies()) != 0 {
+		t.Errorf("cached response set cookies %v", resp.Cookies())
+	}
+}
+
+func TestCachedScrape(t *testing.T) {
+	c := Cache{
+		results: newDiskCache(&cacheGlobalConfig{
+			cachePath: t.TempDir(),
+			ttl:       60,
+		}),
+	}
+
+	title := "title"
+	name := "name"
+	calls := 0
+	scrape := func() ([]models.ScrapedContent, error) {
+		calls++
+		return []models.ScrapedContent{
+			&models.ScrapedScene{Title: &title},
+			models.ScrapedPerformer{Name: &name},
+		}, nil
+	}
+
+	key := []string{"name", "scraper", "query"}
+	if _, err := c.cachedScrape(key, scrape); err != nil {
+		t.Fatal(err)
+	}
+
+	// cached content is returned as pointers
+	got, err := c.cachedScrape(key, scrape)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 || *got[0].(*models.ScrapedScene).Title != title || *got[1].(*models.ScrapedPerformer).Name != name {
+		t.Errorf("cachedScrape() = %v", got)
+	}
+
+	if calls != 1 {
+		t.Errorf("scrape called %d times, want 1", calls)
+	}
+
+	// empty single results are cached
+	calls = 0
+	for i := 0; i < 2; i++ {
+		got, err := c.cachedScrapeSingle([]string{"url", "scraper", "url"}, func() (models.ScrapedContent, error) {
+			calls++
+			return nil, nil
+		})
+		if err != nil || got != nil {
+			t.Errorf("cachedScrapeSingle() = %v, %v", got, err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("scrape called %d times, want 1", calls)
+	}
+}
+
+func TestHostLimiters(t *testing.T) {
+	limiters := &hostLimiters{}
+	limiters.setLimits([]config{
+		{
+			RateLimits: []*rateLimitConfig{
+				{Hosts: []string{"Example.com"}, Interval: time.Second, Concurrency: 2},
+			},
+		},
+		{
+			RateLimits: []*rateLimitConfig{
+				{Hosts: []string{"example.com", "www.other.org"}, Concurrency: 1},
+			},
+		},
+	})
+
+	l := limiters.get("www.example.COM")
+	if l == nil || l.limit != (hostLimit{interval: time.Second, concurrency: 1}) {
+		t.Errorf("get() should return the strictest limit of the parent domain")
+	}
+
+	if limiters.get("other.org") != nil || limiters.get("example.com.au") != nil {
+		t.Errorf("get() should not return the limits of other hosts")
+	}
+}
+
+func TestHostLimiter(t *testing.T) {
+	l := newHostLimiter(hostLimit{
+		interval:    20 * time.Millisecond,
+		concurrency: 1,
+	})
+
+	ctx := context.Background()
+	start := time.Now()
+	if err := l.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the concurrency is exhausted until the request is released
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(timeoutCtx); err == nil {
+		t.Errorf("acquire() should wait for the running request")
+	}
+
+	l.release()
+	if err := l.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	l.release()
+
+	if err := l.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	l.release()
+
+	// requests are started at least one interval apart
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("three requests took %v, want at least 40ms", elapsed)
+	}
+}
+
+type scrapersPathGlobalConfig struct {
+	cacheGlobalConfig
+	scrapersPath string
+}
+
+func (c scrapersPathGlobalConfig) GetScrapersPath() string {
+	return c.scrapersPath
+}
+
+func TestCacheReloadChangesResultKeys(t *testing.T) {
+	gc := &scrapersPathGlobalConfig{
+		cacheGlobalConfig: cacheGlobalConfig{
+			cachePath: t.TempDir(),
+			ttl:       60,
+		},
+		scrapersPath: t.TempDir(),
+	}
+
+	fn := filepath.Join(gc.scrapersPath, "test.yml")
+	write := func(name string) {
+		if err := os.WriteFile(fn, []byte("name: "+name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("before")
+	c, err := NewCache(gc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := c.hashes["test"]
+	if before == "" {
+		t.Fatalf("scraper has no definition hash")
+	}
+
+	// results scraped using the previous definition must not be used
+	write("after")
+	if err := c.ReloadScrapers(); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := c.hashes["test"]; after == "" || after == before {
+		t.Errorf("definition hash = %q after reload, want a new hash", after)
+	}
+}
@@ -104,12 +104,12 @@ func getImage(ctx context.Context, url string, client *http.Client, globalConfig
 		return nil, err
 	}
 
+	defer resp.Body.Close()
+
 	if resp.StatusCode >= 400 {
 		return nil, fmt.Errorf("http error %d", resp.StatusCode)
 	}
 
-	defer resp.Body.Close()
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
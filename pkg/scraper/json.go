@@ -82,7 +82,7 @@ func (s *jsonScraper) scrapeByURL(ctx context.Context, url string, ty models.Scr
 		return nil, err
 	}
 
-	q := s.getJsonQuery(doc)
+	q := s.getJsonQuery(doc, u)
 	switch ty {
 	case models.ScrapeContentTypePerformer:
 		return scraper.scrapePerformer(ctx, q)
@@ -118,7 +118,7 @@ func (s *jsonScraper) scrapeByName(ctx context.Context, name string, ty models.S
 		return nil, err
 	}
 
-	q := s.getJsonQuery(doc)
+	q := s.getJsonQuery(doc, url)
 	q.setType(SearchQuery)
 
 	var content []models.ScrapedContent
@@ -170,7 +170,7 @@ func (s *jsonScraper) scrapeSceneByScene(ctx context.Context, scene *models.Scen
 		return nil, err
 	}
 
-	q := s.getJsonQuery(doc)
+	q := s.getJsonQuery(doc, url)
 	return scraper.scrapeScene(ctx, q)
 }
 
@@ -178,6 +178,8 @@ func (s *jsonScraper) scrapeByFragment(ctx context.Context, input Input) (models
 	switch {
 	case input.Gallery != nil:
 		return nil, fmt.Errorf("%w: cannot use a json scraper as a gallery fragment scraper", ErrNotSupported)
+	case input.Image != nil:
+		return nil, fmt.Errorf("%w: cannot use a json scraper as an image fragment scraper", ErrNotSupported)
 	case input.Performer != nil:
 		return nil, fmt.Errorf("%w: cannot use a json scraper as a performer fragment scraper", ErrNotSupported)
 	case input.Scene == nil:
@@ -205,7 +207,7 @@ func (s *jsonScraper) scrapeByFragment(ctx context.Context, input Input) (models
 		return nil, err
 	}
 
-	q := s.getJsonQuery(doc)
+	q := s.getJsonQuery(doc, url)
 	return scraper.scrapeScene(ctx, q)
 }
 
@@ -229,19 +231,25 @@ func (s *jsonScraper) scrapeGalleryByGallery(ctx context.Context, gallery *model
 		return nil, err
 	}
 
-	q := s.getJsonQuery(doc)
+	q := s.getJsonQuery(doc, url)
 	return scraper.scrapeGallery(ctx, q)
 }
 
-func (s *jsonScraper) getJsonQuery(doc string) *jsonQuery {
+func (s *jsonScraper) scrapeImageByImage(ctx context.Context, image *models.Image) (*models.ScrapedImage, error) {
+	return nil, fmt.Errorf("%w: cannot use a json scraper as an image scraper", ErrNotSupported)
+}
+
+func (s *jsonScraper) getJsonQuery(doc string, url string) *jsonQuery {
 	return &jsonQuery{
 		doc:     doc,
+		url:     url,
 		scraper: s,
 	}
 }
 
 type jsonQuery struct {
 	doc       string
+	url       string
 	scraper   *jsonScraper
 	queryType QueryType
 }
@@ -250,6 +258,10 @@ func (q *jsonQuery) getType() QueryType {
 	return q.queryType
 }
 
+func (q *jsonQuery) getURL() string {
+	return q.url
+}
+
 func (q *jsonQuery) setType(t QueryType) {
 	q.queryType = t
 }
@@ -285,5 +297,5 @@ func (q *jsonQuery) subScrape(ctx context.Context, value string) mappedQuery {
 		return nil
 	}
 
-	return q.scraper.getJsonQuery(doc)
+	return q.scraper.getJsonQuery(doc, value)
 }
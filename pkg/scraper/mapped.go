@@ -21,14 +21,15 @@ type mappedQuery interface {
 	runQuery(selector string) ([]string, error)
 	getType() QueryType
 	setType(QueryType)
+	// getURL returns the URL of the queried document
+	getURL() string
 	subScrape(ctx context.Context, value string) mappedQuery
 }
 
 type commonMappedConfig map[string]string
 
-type mappedConfig map[string]mappedScraperAttrConfig
-
-func (s mappedConfig) applyCommon(c commonMappedConfig, src string) string {
+// apply replaces the common keys in src with their values.
+func (c commonMappedConfig) apply(src string) string {
 	if c == nil {
 		return src
 	}
@@ -41,15 +42,23 @@ func (s mappedConfig) applyCommon(c commonMappedConfig, src string) string {
 	return ret
 }
 
+type mappedConfig map[string]mappedScraperAttrConfig
+
+func (s mappedConfig) applyCommon(c commonMappedConfig, src string) string {
+	return c.apply(src)
+}
+
 func (s mappedConfig) process(ctx context.Context, q mappedQuery, common commonMappedConfig) mappedResults {
 	var ret mappedResults
 
 	for k, attrConfig := range s {
+		fieldCtx, field := traceField(ctx, k, attrConfig)
 
 		if attrConfig.Fixed != "" {
 			// TODO - not sure if this needs to set _all_ indexes for the key
 			const i = 0
 			ret = ret.setKey(i, k, attrConfig.Fixed)
+			field.setResult([]string{attrConfig.Fixed})
 		} else {
 			selector := attrConfig.Selector
 			selector = s.applyCommon(common, selector)
@@ -58,9 +67,11 @@ func (s mappedConfig) process(ctx context.Context, q mappedQuery, common commonM
 			if err != nil {
 				logger.Warnf("key '%v': %v", k, err)
 			}
+			field.setFound(found)
 
 			if len(found) > 0 {
-				result := s.postProcess(ctx, q, attrConfig, found)
+				result := s.postProcess(fieldCtx, q, attrConfig, found)
+				field.setResult(result)
 				for i, text := range result {
 					ret = ret.setKey(i, k, text)
 				}
@@ -452,7 +463,7 @@ func (p *postProcessSubScraper) Apply(ctx context.Context, value string, q mappe
 				result = found[0]
 			}
 
-			result = subScrapeConfig.postProcess(ctx, result, ss)
+			result = subScrapeConfig.postProcess(traceSubScraper(ctx), result, ss)
 			return result
 		}
 	}
@@ -704,7 +715,9 @@ func (c mappedScraperAttrConfig) splitString(value string) []string {
 
 func (c mappedScraperAttrConfig) postProcess(ctx context.Context, value string, q mappedQuery) string {
 	for _, action := range c.postProcessActions {
+		input := value
 		value = action.Apply(ctx, value, q)
+		traceStep(ctx, action, input, value)
 	}
 
 	return value
@@ -713,11 +726,12 @@ func (c mappedScraperAttrConfig) postProcess(ctx context.Context, value string,
 type mappedScrapers map[string]*mappedScraper
 
 type mappedScraper struct {
-	Common    commonMappedConfig            `yaml:"common"`
-	Scene     *mappedSceneScraperConfig     `yaml:"scene"`
-	Gallery   *mappedGalleryScraperConfig   `yaml:"gallery"`
-	Performer *mappedPerformerScraperConfig `yaml:"performer"`
-	Movie     *mappedMovieScraperConfig     `yaml:"movie"`
+	Common     commonMappedConfig            `yaml:"common"`
+	Pagination *mappedPaginationConfig       `yaml:"pagination"`
+	Scene      *mappedSceneScraperConfig     `yaml:"scene"`
+	Gallery    *mappedGalleryScraperConfig   `yaml:"gallery"`
+	Performer  *mappedPerformerScraperConfig `yaml:"performer"`
+	Movie      *mappedMovieScraperConfig     `yaml:"movie"`
 }
 
 type mappedResult map[string]string
@@ -778,7 +792,7 @@ func (s mappedScraper) scrapePerformer(ctx context.Context, q mappedQuery) (*mod
 		// now apply the tags
 		if performerTagsMap != nil {
 			logger.Debug(`Processing performer tags:`)
-			tagResults := performerTagsMap.process(ctx, q, s.Common)
+			tagResults := performerTagsMap.process(traceSection(ctx, mappedScraperConfigPerformerTags), q, s.Common)
 
 			for _, p := range tagResults {
 				tag := &models.ScrapedTag{}
@@ -799,12 +813,17 @@ func (s mappedScraper) scrapePerformers(ctx context.Context, q mappedQuery) ([]*
 		return nil, nil
 	}
 
-	results := performerMap.process(ctx, q, s.Common)
-	for _, r := range results {
-		var p models.ScrapedPerformer
-		r.apply(&p)
-		ret = append(ret, &p)
-	}
+	s.eachPage(ctx, q, func(page mappedQuery) bool {
+		results := performerMap.process(ctx, page, s.Common)
+		for _, r := range results {
+			var p models.ScrapedPerformer
+			r.apply(&p)
+			ret = append(ret, &p)
+		}
+
+		// stop at pages without results
+		return len(results) > 0
+	})
 
 	return ret, nil
 }
@@ -826,13 +845,13 @@ func (s mappedScraper) processScene(ctx context.Context, q mappedQuery, r mapped
 	// process performer tags once
 	var performerTagResults mappedResults
 	if scenePerformerTagsMap != nil {
-		performerTagResults = scenePerformerTagsMap.process(ctx, q, s.Common)
+		performerTagResults = scenePerformerTagsMap.process(traceSection(ctx, mappedScraperConfigScenePerformers+"."+mappedScraperConfigPerformerTags), q, s.Common)
 	}
 
 	// now apply the performers and tags
 	if scenePerformersMap.mappedConfig != nil {
 		logger.Debug(`Processing scene performers:`)
-		performerResults := scenePerformersMap.process(ctx, q, s.Common)
+		performerResults := scenePerformersMap.process(traceSection(ctx, mappedScraperConfigScenePerformers), q, s.Common)
 
 		for _, p := range performerResults {
 			performer := &models.ScrapedPerformer{}
@@ -850,7 +869,7 @@ func (s mappedScraper) processScene(ctx context.Context, q mappedQuery, r mapped
 
 	if sceneTagsMap != nil {
 		logger.Debug(`Processing scene tags:`)
-		tagResults := sceneTagsMap.process(ctx, q, s.Common)
+		tagResults := sceneTagsMap.process(traceSection(ctx, mappedScraperConfigSceneTags), q, s.Common)
 
 		for _, p := range tagResults {
 			tag := &models.ScrapedTag{}
@@ -861,7 +880,7 @@ func (s mappedScraper) processScene(ctx context.Context, q mappedQuery, r mapped
 
 	if sceneStudioMap != nil {
 		logger.Debug(`Processing scene studio:`)
-		studioResults := sceneStudioMap.process(ctx, q, s.Common)
+		studioResults := sceneStudioMap.process(traceSection(ctx, mappedScraperConfigSceneStudio), q, s.Common)
 
 		if len(studioResults) > 0 {
 			studio := &models.ScrapedStudio{}
@@ -872,7 +891,7 @@ func (s mappedScraper) processScene(ctx context.Context, q mappedQuery, r mapped
 
 	if sceneMoviesMap != nil {
 		logger.Debug(`Processing scene movies:`)
-		movieResults := sceneMoviesMap.process(ctx, q, s.Common)
+		movieResults := sceneMoviesMap.process(traceSection(ctx, mappedScraperConfigSceneMovies), q, s.Common)
 
 		for _, p := range movieResults {
 			movie := &models.ScrapedMovie{}
@@ -894,11 +913,16 @@ func (s mappedScraper) scrapeScenes(ctx context.Context, q mappedQuery) ([]*mode
 	}
 
 	logger.Debug(`Processing scenes:`)
-	results := sceneMap.process(ctx, q, s.Common)
-	for _, r := range results {
-		logger.Debug(`Processing scene:`)
-		ret = append(ret, s.processScene(ctx, q, r))
-	}
+	s.eachPage(ctx, q, func(page mappedQuery) bool {
+		results := sceneMap.process(ctx, page, s.Common)
+		for _, r := range results {
+			logger.Debug(`Processing scene:`)
+			ret = append(ret, s.processScene(ctx, page, r))
+		}
+
+		// stop at pages without results
+		return len(results) > 0
+	})
 
 	return ret, nil
 }
@@ -941,32 +965,37 @@ func (s mappedScraper) scrapeGallery(ctx context.Context, q mappedQuery) (*model
 
 		results[0].apply(ret)
 
-		// now apply the performers and tags
-		if galleryPerformersMap != nil {
-			logger.Debug(`Processing gallery performers:`)
-			performerResults := galleryPerformersMap.process(ctx, q, s.Common)
-
-			for _, p := range performerResults {
-				performer := &models.ScrapedPerformer{}
-				p.apply(performer)
-				ret.Performers = append(ret.Performers, performer)
+		// now apply the performers and tags, which may be listed over
+		// multiple pages
+		s.eachPage(ctx, q, func(page mappedQuery) bool {
+			if galleryPerformersMap != nil {
+				logger.Debug(`Processing gallery performers:`)
+				performerResults := galleryPerformersMap.process(traceSection(ctx, mappedScraperConfigScenePerformers), page, s.Common)
+
+				for _, p := range performerResults {
+					performer := &models.ScrapedPerformer{}
+					p.apply(performer)
+					ret.Performers = append(ret.Performers, performer)
+				}
 			}
-		}
 
-		if galleryTagsMap != nil {
-			logger.Debug(`Processing gallery tags:`)
-			tagResults := galleryTagsMap.process(ctx, q, s.Common)
+			if galleryTagsMap != nil {
+				logger.Debug(`Processing gallery tags:`)
+				tagResults := galleryTagsMap.process(traceSection(ctx, mappedScraperConfigSceneTags), page, s.Common)
 
-			for _, p := range tagResults {
-				tag := &models.ScrapedTag{}
-				p.apply(tag)
-				ret.Tags = append(ret.Tags, tag)
+				for _, p := range tagResults {
+					tag := &models.ScrapedTag{}
+					p.apply(tag)
+					ret.Tags = append(ret.Tags, tag)
+				}
 			}
-		}
+
+			return true
+		})
 
 		if galleryStudioMap != nil {
 			logger.Debug(`Processing gallery studio:`)
-			studioResults := galleryStudioMap.process(ctx, q, s.Common)
+			studioResults := galleryStudioMap.process(traceSection(ctx, mappedScraperConfigSceneStudio), q, s.Common)
 
 			if len(studioResults) > 0 {
 				studio := &models.ScrapedStudio{}
@@ -997,7 +1026,7 @@ func (s mappedScraper) scrapeMovie(ctx context.Context, q mappedQuery) (*models.
 
 		if movieStudioMap != nil {
 			logger.Debug(`Processing movie studio:`)
-			studioResults := movieStudioMap.process(ctx, q, s.Common)
+			studioResults := movieStudioMap.process(traceSection(ctx, mappedScraperConfigMovieStudio), q, s.Common)
 
 			if len(studioResults) > 0 {
 				studio := &models.ScrapedStudio{}
@@ -0,0 +1,130 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/stashapp/stash/pkg/logger"
+)
+
+// defaultMaxPages is the maximum number of pages scraped if the pagination
+// config does not set one.
+const defaultMaxPages = 10
+
+// mappedPaginationConfig configures how mapped scrapers follow "next page"
+// links. Search results are aggregated from all pages, as are the
+// performers and tags of galleries.
+type mappedPaginationConfig struct {
+	// Selects the URL of the next page. Scraping stops if nothing is found.
+	NextPage *mappedScraperAttrConfig `yaml:"nextPage"`
+	// Maximum number of pages to scrape, including the first page
+	MaxPages int `yaml:"maxPages"`
+	// Scraping stops at the first page where the selector finds a value
+	StopSelector string `yaml:"stopSelector"`
+}
+
+func (c mappedPaginationConfig) maxPages() int {
+	if c.MaxPages <= 0 {
+		return defaultMaxPages
+	}
+
+	return c.MaxPages
+}
+
+// stop returns true if the stop selector matches the page.
+func (c mappedPaginationConfig) stop(q mappedQuery, common commonMappedConfig) bool {
+	if c.StopSelector == "" {
+		return false
+	}
+
+	found, err := q.runQuery(common.apply(c.StopSelector))
+	if err != nil {
+		logger.Warnf("pagination stop selector: %v", err)
+		return false
+	}
+
+	return len(found) > 0
+}
+
+// nextPageURL returns the URL of the page after q, or an empty string if
+// there is none. Relative URLs are resolved against the URL of q.
+func (c mappedPaginationConfig) nextPageURL(ctx context.Context, q mappedQuery, common commonMappedConfig) string {
+	if c.NextPage == nil || c.NextPage.Selector == "" {
+		return ""
+	}
+
+	found, err := q.runQuery(common.apply(c.NextPage.Selector))
+	if err != nil {
+		logger.Warnf("pagination next page selector: %v", err)
+		return ""
+	}
+
+	if len(found) == 0 {
+		return ""
+	}
+
+	next := c.NextPage.postProcess(ctx, found[0], q)
+	if next == "" {
+		return ""
+	}
+
+	base, err := url.Parse(q.getURL())
+	if err != nil {
+		return next
+	}
+
+	ref, err := url.Parse(next)
+	if err != nil {
+		logger.Warnf("invalid next page url %s: %v", next, err)
+		return ""
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+// eachPage calls fn with the query of each page, starting with q. Pages are
+// scraped until fn returns false or a stop condition is met.
+func (c mappedPaginationConfig) eachPage(ctx context.Context, q mappedQuery, common commonMappedConfig, fn func(page mappedQuery) bool) {
+	visited := map[string]bool{
+		q.getURL(): true,
+	}
+	page := q
+
+	for i := 1; ; i++ {
+		if c.stop(page, common) {
+			logger.Debugf("Pagination stopped at page %d", i)
+			return
+		}
+
+		if !fn(page) || i >= c.maxPages() {
+			return
+		}
+
+		next := c.nextPageURL(ctx, page, common)
+		if next == "" || visited[next] {
+			return
+		}
+		visited[next] = true
+
+		logger.Debugf("Scraping page %d: %s", i+1, next)
+		nextPage := page.subScrape(ctx, next)
+		if nextPage == nil {
+			return
+		}
+
+		// keep searching on subsequent pages
+		nextPage.setType(q.getType())
+		page = nextPage
+	}
+}
+
+// eachPage calls fn with the query of each page if the scraper is
+// paginated, or with q otherwise.
+func (s mappedScraper) eachPage(ctx context.Context, q mappedQuery, fn func(page mappedQuery) bool) {
+	if s.Pagination == nil {
+		fn(q)
+		return
+	}
+
+	s.Pagination.eachPage(ctx, q, s.Common, fn)
+}
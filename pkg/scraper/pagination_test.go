@@ -0,0 +1,157 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func loadTestConfig(t *testing.T, yamlStr string) config {
+	c := &config{}
+	if err := yaml.Unmarshal([]byte(yamlStr), &c); err != nil {
+		t.Fatalf("Error loading yaml: %v", err)
+	}
+
+	return *c
+}
+
+func TestXPathPagination(t *testing.T) {
+	// page 4 links back to page 1
+	pages := map[string]string{
+		"/search/1": `<div class="scene">Scene 1</div><div class="scene">Scene 2</div><a class="next" href="/search/2">next</a>`,
+		"/search/2": `<div class="scene">Scene 3</div><a class="next" href="/search/3">next</a>`,
+		"/search/3": `<div class="scene">Scene 4</div><a class="next" href="/search/4">next</a>`,
+		"/search/4": `<div class="scene">Scene 5</div><a class="next" href="/search/1">next</a>`,
+		"/search/5": `<div class="empty">No results</div><a class="next" href="/search/1">next</a>`,
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body>"+pages[r.URL.Path]+"</body></html>")
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		firstPage  string
+		pagination string
+		want       []string
+	}{
+		{
+			"no pagination",
+			"1",
+			"",
+			[]string{"Scene 1", "Scene 2"},
+		},
+		{
+			"max pages",
+			"1",
+			"maxPages: 2",
+			[]string{"Scene 1", "Scene 2", "Scene 3"},
+		},
+		{
+			"visited page",
+			"2",
+			"maxPages: 10",
+			[]string{"Scene 3", "Scene 4", "Scene 5", "Scene 1", "Scene 2"},
+		},
+		{
+			"stop selector",
+			"5",
+			"stopSelector: //div[@class=\"empty\"]",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagination := ""
+			if tt.pagination != "" {
+				pagination = `
+    pagination:
+      nextPage:
+        selector: //a[@class="next"]/@href
+        postProcess:
+          - replace:
+              - regex: ^
+                with: ` + ts.URL + `
+      ` + tt.pagination
+			}
+
+			c := loadTestConfig(t, `name: Test
+sceneByName:
+  action: scrapeXPath
+  queryURL: `+ts.URL+`/search/{}
+  scraper: sceneSearch
+xPathScrapers:
+  sceneSearch:`+pagination+`
+    scene:
+      Title: //div[@class="scene"]
+`)
+
+			s := newGroupScraper(c, nil, mockGlobalConfig{})
+			content, err := s.(nameScraper).viaName(context.Background(), &http.Client{}, tt.firstPage, models.ScrapeContentTypeScene)
+			if err != nil {
+				t.Fatalf("viaName() error = %v", err)
+			}
+
+			var got []string
+			for _, c := range content {
+				got = append(got, *c.(*models.ScrapedScene).Title)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJsonGalleryPagination(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gallery":
+			fmt.Fprint(w, `{"title": "Gallery", "tags": ["a", "b"], "next": "/gallery/tags/2"}`)
+		case "/gallery/tags/2":
+			fmt.Fprint(w, `{"tags": ["c"], "next": "/gallery/tags/3"}`)
+		case "/gallery/tags/3":
+			fmt.Fprint(w, `{"tags": []}`)
+		}
+	}))
+	defer ts.Close()
+
+	c := loadTestConfig(t, `name: Test
+galleryByURL:
+  - action: scrapeJson
+    url:
+      - `+ts.URL+`
+    scraper: galleryScraper
+jsonScrapers:
+  galleryScraper:
+    pagination:
+      # relative to the current page
+      nextPage: next
+    gallery:
+      Title: title
+      Tags:
+        Name: tags
+`)
+
+	s := newGroupScraper(c, nil, mockGlobalConfig{})
+	content, err := s.(urlScraper).viaURL(context.Background(), &http.Client{}, ts.URL+"/gallery", models.ScrapeContentTypeGallery)
+	if err != nil {
+		t.Fatalf("viaURL() error = %v", err)
+	}
+
+	gallery := content.(*models.ScrapedGallery)
+	assert.Equal(t, "Gallery", *gallery.Title)
+
+	var tags []string
+	for _, tag := range gallery.Tags {
+		tags = append(tags, tag.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, tags)
+}
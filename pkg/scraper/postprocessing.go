@@ -32,6 +32,12 @@ func (c Cache) postScrape(ctx context.Context, content models.ScrapedContent) (m
 		}
 	case models.ScrapedGallery:
 		return c.postScrapeGallery(ctx, v)
+	case *models.ScrapedImage:
+		if v != nil {
+			return c.postScrapeImage(ctx, *v)
+		}
+	case models.ScrapedImage:
+		return c.postScrapeImage(ctx, v)
 	case *models.ScrapedMovie:
 		if v != nil {
 			return c.postScrapeMovie(ctx, *v)
@@ -193,6 +199,40 @@ func (c Cache) postScrapeGallery(ctx context.Context, g models.ScrapedGallery) (
 	return g, nil
 }
 
+func (c Cache) postScrapeImage(ctx context.Context, i models.ScrapedImage) (models.ScrapedContent, error) {
+	if err := c.txnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		pqb := r.Performer()
+		tqb := r.Tag()
+		sqb := r.Studio()
+
+		for _, p := range i.Performers {
+			err := match.ScrapedPerformer(pqb, p, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		tags, err := postProcessTags(tqb, i.Tags)
+		if err != nil {
+			return err
+		}
+		i.Tags = tags
+
+		if i.Studio != nil {
+			err := match.ScrapedStudio(sqb, i.Studio, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
 func postProcessTags(tqb models.TagReader, scrapedTags []*models.ScrapedTag) ([]*models.ScrapedTag, error) {
 	var ret []*models.ScrapedTag
 
@@ -0,0 +1,229 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitConfig limits the requests made to a set of hosts.
+type rateLimitConfig struct {
+	// Hosts the limit applies to. Includes the subdomains of the hosts.
+	Hosts []string `yaml:"hosts,flow"`
+	// Minimum time between the start of requests to the hosts
+	Interval time.Duration `yaml:"interval"`
+	// Maximum number of concurrent requests to the hosts. Unlimited if 0.
+	Concurrency int `yaml:"concurrency"`
+}
+
+func (c rateLimitConfig) validate() error {
+	if len(c.Hosts) == 0 {
+		return errors.New("hosts is mandatory for rate limits")
+	}
+
+	for _, h := range c.Hosts {
+		if strings.TrimSpace(h) == "" {
+			return errors.New("rate limit hosts must not be empty")
+		}
+	}
+
+	if c.Interval < 0 {
+		return fmt.Errorf("rate limit interval %v must not be negative", c.Interval)
+	}
+
+	if c.Concurrency < 0 {
+		return fmt.Errorf("rate limit concurrency %d must not be negative", c.Concurrency)
+	}
+
+	return nil
+}
+
+// hostLimit is the combined rate limit of a host.
+type hostLimit struct {
+	interval    time.Duration
+	concurrency int
+}
+
+// stricter returns the stricter limits of l and o.
+func (l hostLimit) stricter(o hostLimit) hostLimit {
+	if o.interval > l.interval {
+		l.interval = o.interval
+	}
+	if o.concurrency > 0 && (l.concurrency == 0 || o.concurrency < l.concurrency) {
+		l.concurrency = o.concurrency
+	}
+	return l
+}
+
+// hostLimiter throttles the requests to a host.
+type hostLimiter struct {
+	limit hostLimit
+	// slots holds a value for each running request. Nil if concurrency is
+	// unlimited.
+	slots chan struct{}
+
+	mutex sync.Mutex
+	// next is the earliest start of the next request
+	next time.Time
+}
+
+func newHostLimiter(limit hostLimit) *hostLimiter {
+	ret := &hostLimiter{
+		limit: limit,
+	}
+
+	if limit.concurrency > 0 {
+		ret.slots = make(chan struct{}, limit.concurrency)
+	}
+
+	return ret
+}
+
+// acquire waits until a request may be started. release must be called
+// when the request is finished if acquire returns nil.
+func (l *hostLimiter) acquire(ctx context.Context) error {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if l.limit.interval <= 0 {
+		return nil
+	}
+
+	l.mutex.Lock()
+	start := l.next
+	if now := time.Now(); start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.limit.interval)
+	l.mutex.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.release()
+		return ctx.Err()
+	}
+}
+
+func (l *hostLimiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// hostLimiters holds the limiters of the hosts with rate limits.
+type hostLimiters struct {
+	mutex    sync.RWMutex
+	limiters map[string]*hostLimiter
+}
+
+// setLimits replaces the limiters with the rate limits of the scraper
+// configs. Where hosts have multiple limits, the strictest is used.
+func (h *hostLimiters) setLimits(configs []config) {
+	limits := make(map[string]hostLimit)
+	for _, c := range configs {
+		for _, rl := range c.RateLimits {
+			for _, host := range rl.Hosts {
+				host = strings.ToLower(strings.TrimSpace(host))
+				limits[host] = limits[host].stricter(hostLimit{
+					interval:    rl.Interval,
+					concurrency: rl.Concurrency,
+				})
+			}
+		}
+	}
+
+	limiters := make(map[string]*hostLimiter)
+	for host, limit := range limits {
+		limiters[host] = newHostLimiter(limit)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.limiters = limiters
+}
+
+// get returns the limiter of the host or of its closest parent domain, or
+// nil if the host is not limited.
+func (h *hostLimiters) get(host string) *hostLimiter {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	host = strings.ToLower(host)
+	for host != "" {
+		if l, found := h.limiters[host]; found {
+			return l
+		}
+
+		i := strings.IndexByte(host, '.')
+		if i == -1 {
+			break
+		}
+		host = host[i+1:]
+	}
+
+	return nil
+}
+
+// rateLimitedTransport throttles requests to the hosts with rate limits.
+type rateLimitedTransport struct {
+	next     http.RoundTripper
+	limiters *hostLimiters
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := t.limiters.get(req.URL.Hostname())
+	if l == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	if err := l.acquire(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		l.release()
+		return nil, err
+	}
+
+	// the request is running until its body is closed
+	resp.Body = &releasingBody{
+		ReadCloser: resp.Body,
+		release:    l.release,
+	}
+
+	return resp, nil
+}
+
+// releasingBody releases its host limiter when closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
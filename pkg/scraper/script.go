@@ -8,6 +8,7 @@ import (
 	"io"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	stashExec "github.com/stashapp/stash/pkg/exec"
@@ -167,6 +168,9 @@ func (s *scriptScraper) scrapeByFragment(ctx context.Context, input Input) (mode
 	case input.Gallery != nil:
 		inString, err = json.Marshal(*input.Gallery)
 		ty = models.ScrapeContentTypeGallery
+	case input.Image != nil:
+		inString, err = json.Marshal(*input.Image)
+		ty = models.ScrapeContentTypeImage
 	case input.Scene != nil:
 		inString, err = json.Marshal(*input.Scene)
 		ty = models.ScrapeContentTypeScene
@@ -197,6 +201,10 @@ func (s *scriptScraper) scrape(ctx context.Context, input string, ty models.Scra
 		var scene *models.ScrapedScene
 		err := s.runScraperScript(ctx, input, &scene)
 		return scene, err
+	case models.ScrapeContentTypeImage:
+		var image *models.ScrapedImage
+		err := s.runScraperScript(ctx, input, &image)
+		return image, err
 	case models.ScrapeContentTypeMovie:
 		var movie *models.ScrapedMovie
 		err := s.runScraperScript(ctx, input, &movie)
@@ -234,6 +242,43 @@ func (s *scriptScraper) scrapeGalleryByGallery(ctx context.Context, gallery *mod
 	return ret, err
 }
 
+func (s *scriptScraper) scrapeImageByImage(ctx context.Context, image *models.Image) (*models.ScrapedImage, error) {
+	inString, err := json.Marshal(imageToScriptInput(image))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var ret *models.ScrapedImage
+
+	err = s.runScraperScript(ctx, string(inString), &ret)
+
+	return ret, err
+}
+
+// scriptImageInput is the input of image scripts. Images have no URL, so
+// the checksum and path are passed to identify the image.
+type scriptImageInput struct {
+	ID       string  `json:"id"`
+	Title    *string `json:"title"`
+	Checksum string  `json:"checksum"`
+	Path     string  `json:"path"`
+}
+
+func imageToScriptInput(image *models.Image) scriptImageInput {
+	ret := scriptImageInput{
+		ID:       strconv.Itoa(image.ID),
+		Checksum: image.Checksum,
+		Path:     image.Path,
+	}
+
+	if image.Title.Valid {
+		ret.Title = &image.Title.String
+	}
+
+	return ret
+}
+
 func handleScraperStderr(name string, scraperOutputReader io.ReadCloser) {
 	const scraperPrefix = "[Scrape / %s] "
 
@@ -84,7 +84,7 @@ type scrapedPerformerStash struct {
 }
 
 func (s *stashScraper) scrapeByFragment(ctx context.Context, input Input) (models.ScrapedContent, error) {
-	if input.Gallery != nil || input.Scene != nil {
+	if input.Gallery != nil || input.Scene != nil || input.Image != nil {
 		return nil, fmt.Errorf("%w: using stash scraper as a fragment scraper", ErrNotSupported)
 	}
 
@@ -304,6 +304,42 @@ func (s *stashScraper) scrapeGalleryByGallery(ctx context.Context, gallery *mode
 	return &ret, nil
 }
 
+type scrapedImageStash struct {
+	ID         string                   `graphql:"id" json:"id"`
+	Title      *string                  `graphql:"title" json:"title"`
+	Studio     *scrapedStudioStash      `graphql:"studio" json:"studio"`
+	Tags       []*scrapedTagStash       `graphql:"tags" json:"tags"`
+	Performers []*scrapedPerformerStash `graphql:"performers" json:"performers"`
+}
+
+func (s *stashScraper) scrapeImageByImage(ctx context.Context, image *models.Image) (*models.ScrapedImage, error) {
+	// query by MD5
+	var q struct {
+		FindImage *scrapedImageStash `graphql:"findImage(checksum: $c)"`
+	}
+
+	vars := map[string]interface{}{
+		"c": graphql.String(image.Checksum),
+	}
+
+	client := s.getStashClient()
+	if err := client.Query(ctx, &q, vars); err != nil {
+		return nil, err
+	}
+
+	if q.FindImage == nil {
+		return nil, nil
+	}
+
+	// need to copy back to a scraped image
+	ret := models.ScrapedImage{}
+	if err := copier.Copy(&ret, q.FindImage); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
 func (s *stashScraper) scrapeByURL(_ context.Context, _ string, _ models.ScrapeContentType) (models.ScrapedContent, error) {
 	return nil, ErrNotSupported
 }
@@ -358,6 +394,18 @@ func getGallery(ctx context.Context, galleryID int, txnManager models.Transactio
 	return ret, nil
 }
 
+func getImageByID(ctx context.Context, imageID int, txnManager models.TransactionManager) (*models.Image, error) {
+	var ret *models.Image
+	if err := txnManager.WithReadTxn(ctx, func(r models.ReaderRepository) error {
+		var err error
+		ret, err = r.Image().Find(imageID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
 func galleryToUpdateInput(gallery *models.Gallery) models.GalleryUpdateInput {
 	toStringPtr := func(s sql.NullString) *string {
 		if s.Valid {
@@ -0,0 +1,71 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStashScrapeImageByImage(t *testing.T) {
+	const checksum = "abc123"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !strings.Contains(body.Query, "findImage(checksum: $c)") || body.Variables["c"] != checksum {
+			fmt.Fprint(w, `{"data": {"findImage": null}}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"data": {"findImage": {
+			"id": "1",
+			"title": "Image",
+			"studio": {"name": "Studio", "url": null},
+			"tags": [{"name": "Tag"}],
+			"performers": [{"name": "Performer", "tags": []}]
+		}}}`)
+	}))
+	defer ts.Close()
+
+	c := loadTestConfig(t, `name: Test
+imageByFragment:
+  action: stash
+stashServer:
+  url: `+ts.URL+`
+`)
+
+	s := newGroupScraper(c, nil, mockGlobalConfig{}).(imageScraper)
+	assert.True(t, s.supports(models.ScrapeContentTypeImage))
+
+	got, err := s.viaImage(context.Background(), &http.Client{}, &models.Image{Checksum: checksum})
+	if err != nil {
+		t.Fatalf("viaImage() error = %v", err)
+	}
+
+	assert.Equal(t, "Image", *got.Title)
+	assert.Equal(t, "Studio", got.Studio.Name)
+	assert.Equal(t, "Tag", got.Tags[0].Name)
+	assert.Equal(t, "Performer", *got.Performers[0].Name)
+
+	// unknown images are not scraped
+	got, err = s.viaImage(context.Background(), &http.Client{}, &models.Image{Checksum: "unknown"})
+	if err != nil {
+		t.Fatalf("viaImage() error = %v", err)
+	}
+
+	assert.Nil(t, got)
+}
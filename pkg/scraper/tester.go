@@ -0,0 +1,332 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/stashapp/stash/pkg/fsutil"
+	"github.com/stashapp/stash/pkg/models"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrNoFixtureDocument is returned when a scraper test loads a URL which
+// is not in the fixture.
+var ErrNoFixtureDocument = errors.New("fixture has no document for url")
+
+// ErrInvalidTestPath is returned when the fixture or record path of a
+// scraper test is not within the scrapers path.
+var ErrInvalidTestPath = errors.New("path must be relative to and within the scrapers path")
+
+// TestInput is the input of a scraper test.
+type TestInput struct {
+	// ID of the scraper. Taken from the fixture if empty.
+	ScraperID string
+	// Type of the scraped content. Taken from the fixture if empty.
+	Type models.ScrapeContentType
+	// URL to scrape with the byURL configuration of the type
+	URL string
+	// Query to search for with the byName configuration of the type
+	Query string
+	// Fixture is the path of a fixture recorded by a previous test, or of a
+	// saved HTML or JSON document. The documents are used instead of
+	// loading the URLs. The path is relative to the scrapers path, and must
+	// be within it.
+	Fixture string
+	// Record is the path the loaded documents and the scraped content are
+	// recorded to. The path is relative to the scrapers path, and must be
+	// within it.
+	Record string
+}
+
+// TestResult is the result of a scraper test.
+type TestResult struct {
+	Content []models.ScrapedContent
+	Trace   *ScrapeTrace
+	// Passed is whether the content matches the content recorded in the
+	// fixture. Nil if the test did not use a recorded fixture.
+	Passed *bool
+}
+
+// Fixture is a recorded scraper test. Only the documents loaded by xpath
+// and json scrapers are recorded.
+type Fixture struct {
+	ScraperID string                   `yaml:"scraper"`
+	Type      models.ScrapeContentType `yaml:"type"`
+	URL       string                   `yaml:"url,omitempty"`
+	Query     string                   `yaml:"query,omitempty"`
+	// Documents maps the loaded URLs to their contents
+	Documents map[string]string `yaml:"documents"`
+	// Content is the JSON encoded scraped content
+	Content string `yaml:"content"`
+}
+
+func isFixtureFile(fn string) bool {
+	ext := strings.ToLower(filepath.Ext(fn))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+func loadFixture(fn string) (*Fixture, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret Fixture
+	if err := yaml.UnmarshalStrict(data, &ret); err != nil {
+		return nil, fmt.Errorf("error reading fixture %s: %w", fn, err)
+	}
+
+	return &ret, nil
+}
+
+func (f Fixture) write(fn string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fn, data, 0644)
+}
+
+// matches returns true if the content is equal to the recorded content.
+func (f Fixture) matches(content string) bool {
+	var expected, actual interface{}
+	if err := json.Unmarshal([]byte(f.Content), &expected); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(content), &actual); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
+
+// testDocuments replays or records the documents loaded during a scraper
+// test.
+type testDocuments struct {
+	mutex     sync.Mutex
+	documents map[string]string
+	// replay serves the documents instead of loading the URLs
+	replay bool
+	// saved is a document which is served for the first loaded URL
+	saved *string
+}
+
+type testDocumentsContextKey struct{}
+
+func withTestDocuments(ctx context.Context, d *testDocuments) context.Context {
+	return context.WithValue(ctx, testDocumentsContextKey{}, d)
+}
+
+func getTestDocuments(ctx context.Context) *testDocuments {
+	d, _ := ctx.Value(testDocumentsContextKey{}).(*testDocuments)
+	return d
+}
+
+// load returns the document of the URL, which is loaded using fetch if
+// the documents are recorded.
+func (d *testDocuments) load(url string, fetch func() (io.Reader, error)) (io.Reader, error) {
+	if d.replay {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+
+		doc, found := d.documents[url]
+		if !found && d.saved != nil {
+			doc = *d.saved
+			d.saved = nil
+			d.documents[url] = doc
+			found = true
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrNoFixtureDocument, url)
+		}
+
+		return strings.NewReader(doc), nil
+	}
+
+	r, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.documents[url] = string(data)
+
+	return bytes.NewReader(data), nil
+}
+
+// testPath returns the path relative to the scrapers path. Absolute paths
+// and paths outside of the scrapers path are rejected.
+func (c Cache) testPath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidTestPath, path)
+	}
+
+	dir := filepath.Clean(c.globalConfig.GetScrapersPath())
+	ret := filepath.Join(dir, filepath.Clean(path))
+	if ret == dir || !fsutil.IsPathInDir(dir, ret) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidTestPath, path)
+	}
+
+	return ret, nil
+}
+
+// TestScraper scrapes the URL or query of the input, tracing the
+// processing of the fields of mapped scrapers. Responses are not cached,
+// and the scraped content is not matched against the database.
+func (c Cache) TestScraper(ctx context.Context, input TestInput) (*TestResult, error) {
+	docs := &testDocuments{
+		documents: make(map[string]string),
+	}
+
+	// check the record path before scraping
+	var recordPath string
+	if input.Record != "" {
+		var err error
+		recordPath, err = c.testPath(input.Record)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var fixture *Fixture
+	if input.Fixture != "" {
+		fn, err := c.testPath(input.Fixture)
+		if err != nil {
+			return nil, err
+		}
+
+		if isFixtureFile(fn) {
+			fixture, err = loadFixture(fn)
+			if err != nil {
+				return nil, err
+			}
+
+			if fixture.Documents != nil {
+				docs.documents = fixture.Documents
+			}
+
+			if input.ScraperID == "" {
+				input.ScraperID = fixture.ScraperID
+			}
+			if input.Type == "" {
+				input.Type = fixture.Type
+			}
+			if input.URL == "" && input.Query == "" {
+				input.URL = fixture.URL
+				input.Query = fixture.Query
+			}
+		} else {
+			data, err := os.ReadFile(fn)
+			if err != nil {
+				return nil, err
+			}
+
+			saved := string(data)
+			docs.saved = &saved
+		}
+
+		docs.replay = true
+	}
+
+	if !input.Type.IsValid() {
+		return nil, fmt.Errorf("%q is not a valid scrape content type", input.Type)
+	}
+
+	s := c.findScraper(input.ScraperID)
+	if s == nil {
+		return nil, fmt.Errorf("%w: id %s", ErrNotFound, input.ScraperID)
+	}
+
+	trace := &ScrapeTrace{}
+	ctx = withTrace(withTestDocuments(ctx, docs), trace)
+
+	// bypass the response cache so that the site is scraped
+	client := newClient(c.globalConfig, nil, c.limiters)
+
+	var content []models.ScrapedContent
+	switch {
+	case input.URL != "":
+		ul, ok := s.(urlScraper)
+		if !ok || !s.supportsURL(input.URL, input.Type) {
+			return nil, fmt.Errorf("%w: cannot use scraper %s to scrape %v from url %s", ErrNotSupported, input.ScraperID, input.Type, input.URL)
+		}
+
+		ret, err := ul.viaURL(ctx, client, input.URL, input.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		if ret != nil {
+			content = append(content, ret)
+		}
+	case input.Query != "":
+		ns, ok := s.(nameScraper)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot use scraper %s to scrape by name", ErrNotSupported, input.ScraperID)
+		}
+
+		ret, err := ns.viaName(ctx, client, input.Query, input.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		content = ret
+	default:
+		return nil, errors.New("url or query is required")
+	}
+
+	trace.sort()
+
+	ret := &TestResult{
+		Content: content,
+		Trace:   trace,
+	}
+
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if fixture != nil {
+		passed := fixture.matches(string(data))
+		ret.Passed = &passed
+	}
+
+	if input.Record != "" {
+		f := Fixture{
+			ScraperID: input.ScraperID,
+			Type:      input.Type,
+			URL:       input.URL,
+			Query:     input.Query,
+			Documents: docs.documents,
+			Content:   string(data),
+		}
+
+		if err := f.write(recordPath); err != nil {
+			return nil, fmt.Errorf("error recording fixture: %w", err)
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,168 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"gopkg.in/yaml.v2"
+)
+
+type testerGlobalConfig struct {
+	mockGlobalConfig
+	scrapersPath string
+}
+
+func (c testerGlobalConfig) GetScrapersPath() string {
+	return c.scrapersPath
+}
+
+func TestTestScraper(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/getName" {
+			fmt.Fprint(w, `<span>The name</span>`)
+		} else {
+			fmt.Fprint(w, `<div><a href="/getName">A link</a><p>180</p></div>`)
+		}
+	}))
+
+	yamlStr := `name: Test
+performerByURL:
+  - action: scrapeXPath
+    url:
+      - ` + ts.URL + `
+    scraper: performerScraper
+xPathScrapers:
+  performerScraper:
+    performer:
+      Name:
+        selector: //div/a/@href
+        postProcess:
+          - replace:
+              - regex: ^
+                with: ` + ts.URL + `
+          - subScraper:
+              selector: //span
+              postProcess:
+                - map:
+                    The name: Mapped name
+      Height: //div/p
+`
+
+	c := &config{}
+	if err := yaml.Unmarshal([]byte(yamlStr), &c); err != nil {
+		t.Fatalf("Error loading yaml: %v", err)
+	}
+
+	gc := testerGlobalConfig{
+		scrapersPath: t.TempDir(),
+	}
+	cache := Cache{
+		scrapers:     map[string]scraper{"test": newGroupScraper(*c, nil, gc)},
+		globalConfig: gc,
+		limiters:     &hostLimiters{},
+	}
+
+	ctx := context.Background()
+	result, err := cache.TestScraper(ctx, TestInput{
+		ScraperID: "test",
+		Type:      models.ScrapeContentTypePerformer,
+		URL:       ts.URL,
+		Record:    "fixtures/test.yml",
+	})
+	if err != nil {
+		t.Fatalf("TestScraper() error = %v", err)
+	}
+
+	if result.Passed != nil {
+		t.Errorf("Passed should be nil for live tests")
+	}
+
+	wantFields := []*TraceField{
+		{
+			Name:     "Height",
+			Selector: "//div/p",
+			Found:    []string{"180"},
+			Result:   []string{"180"},
+		},
+		{
+			Name:     "Name",
+			Selector: "//div/a/@href",
+			Found:    []string{"/getName"},
+			Steps: []*TraceStep{
+				{Action: "replace", Input: "/getName", Output: ts.URL + "/getName"},
+				{Action: "subScraper.map", Input: "The name", Output: "Mapped name"},
+				{Action: "subScraper", Input: ts.URL + "/getName", Output: "Mapped name"},
+			},
+			Result: []string{"Mapped name"},
+		},
+	}
+
+	if !reflect.DeepEqual(result.Trace.Fields, wantFields) {
+		for _, f := range result.Trace.Fields {
+			t.Logf("%+v", *f)
+		}
+		t.Errorf("TestScraper() traced unexpected fields")
+	}
+
+	// replay the fixture without the site
+	ts.Close()
+
+	result, err = cache.TestScraper(ctx, TestInput{
+		Fixture: "fixtures/test.yml",
+	})
+	if err != nil {
+		t.Fatalf("TestScraper() replay error = %v", err)
+	}
+
+	if result.Passed == nil || !*result.Passed {
+		t.Errorf("replayed fixture should pass")
+	}
+
+	if len(result.Content) != 1 || *result.Content[0].(*models.ScrapedPerformer).Name != "Mapped name" {
+		t.Errorf("TestScraper() replay content = %v", result.Content)
+	}
+}
+
+func TestTestPath(t *testing.T) {
+	dir := t.TempDir()
+	cache := Cache{
+		globalConfig: testerGlobalConfig{
+			scrapersPath: dir,
+		},
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"fixtures/test.yml", filepath.Join(dir, "fixtures", "test.yml"), false},
+		{"fixtures/../test.yml", filepath.Join(dir, "test.yml"), false},
+		{"../test.yml", "", true},
+		{"fixtures/../../test.yml", "", true},
+		{".", "", true},
+		{filepath.Join(dir, "test.yml"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := cache.testPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("testPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTestPath) {
+				t.Errorf("testPath() error = %v, want ErrInvalidTestPath", err)
+			}
+			if got != tt.want {
+				t.Errorf("testPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ScrapeTrace records how mapped scrapers process each field. It is used
+// when testing scrapers.
+type ScrapeTrace struct {
+	Fields []*TraceField
+}
+
+// TraceField is the processing of a mapped field.
+type TraceField struct {
+	// Name of the field, prefixed by the object it belongs to. For example
+	// Performers.Name.
+	Name     string
+	Selector string
+	Fixed    string
+	// Values found by the selector
+	Found []string
+	// Post-processing steps in the order they were applied
+	Steps []*TraceStep
+	// Values of the field after post-processing
+	Result []string
+}
+
+// TraceStep is the result of a post-processing action.
+type TraceStep struct {
+	// Name of the action. Actions of sub-scrapers are prefixed with
+	// subScraper.
+	Action string
+	Input  string
+	Output string
+}
+
+func (f *TraceField) setFound(found []string) {
+	if f != nil {
+		f.Found = found
+	}
+}
+
+func (f *TraceField) setResult(result []string) {
+	if f != nil {
+		f.Result = result
+	}
+}
+
+// sort sorts the fields by name, since mapped fields are processed in
+// random order.
+func (t *ScrapeTrace) sort() {
+	sort.SliceStable(t.Fields, func(i, j int) bool {
+		return t.Fields[i].Name < t.Fields[j].Name
+	})
+}
+
+type traceContextKey struct{}
+
+// traceState is the position of the processing in the trace.
+type traceState struct {
+	trace   *ScrapeTrace
+	section string
+	field   *TraceField
+	// prefix of the names of the actions
+	prefix string
+}
+
+func withTrace(ctx context.Context, trace *ScrapeTrace) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, traceState{
+		trace: trace,
+	})
+}
+
+func getTraceState(ctx context.Context) (traceState, bool) {
+	s, ok := ctx.Value(traceContextKey{}).(traceState)
+	return s, ok
+}
+
+// traceSection returns a context where traced fields belong to the named
+// object.
+func traceSection(ctx context.Context, section string) context.Context {
+	s, ok := getTraceState(ctx)
+	if !ok {
+		return ctx
+	}
+
+	s.section = section
+	return context.WithValue(ctx, traceContextKey{}, s)
+}
+
+// traceField adds a field to the trace. Returns a context where
+// post-processing steps are added to the field, and the field, which is
+// nil if the context is not traced.
+func traceField(ctx context.Context, key string, attrConfig mappedScraperAttrConfig) (context.Context, *TraceField) {
+	s, ok := getTraceState(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	name := key
+	if s.section != "" {
+		name = s.section + "." + key
+	}
+
+	f := &TraceField{
+		Name:     name,
+		Selector: attrConfig.Selector,
+		Fixed:    attrConfig.Fixed,
+	}
+	s.trace.Fields = append(s.trace.Fields, f)
+
+	s.field = f
+	s.prefix = ""
+	return context.WithValue(ctx, traceContextKey{}, s), f
+}
+
+// traceSubScraper returns a context where the post-processing steps of a
+// sub-scraper are added to the current field.
+func traceSubScraper(ctx context.Context) context.Context {
+	s, ok := getTraceState(ctx)
+	if !ok {
+		return ctx
+	}
+
+	s.prefix += "subScraper."
+	return context.WithValue(ctx, traceContextKey{}, s)
+}
+
+func traceStep(ctx context.Context, action postProcessAction, input string, output string) {
+	s, ok := getTraceState(ctx)
+	if !ok || s.field == nil {
+		return
+	}
+
+	s.field.Steps = append(s.field.Steps, &TraceStep{
+		Action: s.prefix + postProcessActionName(action),
+		Input:  input,
+		Output: output,
+	})
+}
+
+func postProcessActionName(action postProcessAction) string {
+	switch action.(type) {
+	case *postProcessParseDate:
+		return "parseDate"
+	case *postProcessSubtractDays:
+		return "subtractDays"
+	case *postProcessReplace:
+		return "replace"
+	case *postProcessSubScraper:
+		return "subScraper"
+	case *postProcessMap:
+		return "map"
+	case *postProcessFeetToCm:
+		return "feetToCm"
+	case *postProcessLbToKg:
+		return "lbToKg"
+	}
+
+	return fmt.Sprintf("%T", action)
+}
@@ -24,6 +24,17 @@ import (
 const scrapeDefaultSleep = time.Second * 2
 
 func loadURL(ctx context.Context, loadURL string, client *http.Client, scraperConfig config, globalConfig GlobalConfig) (io.Reader, error) {
+	// scraper tests replay or record the documents
+	if d := getTestDocuments(ctx); d != nil {
+		return d.load(loadURL, func() (io.Reader, error) {
+			return fetchURL(ctx, loadURL, client, scraperConfig, globalConfig)
+		})
+	}
+
+	return fetchURL(ctx, loadURL, client, scraperConfig, globalConfig)
+}
+
+func fetchURL(ctx context.Context, loadURL string, client *http.Client, scraperConfig config, globalConfig GlobalConfig) (io.Reader, error) {
 	driverOptions := scraperConfig.DriverOptions
 	if driverOptions != nil && driverOptions.UseCDP {
 		// get the page using chrome dp
@@ -69,12 +80,12 @@ func loadURL(ctx context.Context, loadURL string, client *http.Client, scraperCo
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode >= 400 {
 		return nil, fmt.Errorf("http error %d:%s", resp.StatusCode, http.StatusText(resp.StatusCode))
 	}
 
-	defer resp.Body.Close()
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
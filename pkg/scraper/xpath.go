@@ -63,7 +63,7 @@ func (s *xpathScraper) scrapeByURL(ctx context.Context, url string, ty models.Sc
 		return nil, err
 	}
 
-	q := s.getXPathQuery(doc)
+	q := s.getXPathQuery(doc, u)
 	switch ty {
 	case models.ScrapeContentTypePerformer:
 		return scraper.scrapePerformer(ctx, q)
@@ -99,7 +99,7 @@ func (s *xpathScraper) scrapeByName(ctx context.Context, name string, ty models.
 		return nil, err
 	}
 
-	q := s.getXPathQuery(doc)
+	q := s.getXPathQuery(doc, url)
 	q.setType(SearchQuery)
 
 	var content []models.ScrapedContent
@@ -149,7 +149,7 @@ func (s *xpathScraper) scrapeSceneByScene(ctx context.Context, scene *models.Sce
 		return nil, err
 	}
 
-	q := s.getXPathQuery(doc)
+	q := s.getXPathQuery(doc, url)
 	return scraper.scrapeScene(ctx, q)
 }
 
@@ -157,6 +157,8 @@ func (s *xpathScraper) scrapeByFragment(ctx context.Context, input Input) (model
 	switch {
 	case input.Gallery != nil:
 		return nil, fmt.Errorf("%w: cannot use an xpath scraper as a gallery fragment scraper", ErrNotSupported)
+	case input.Image != nil:
+		return nil, fmt.Errorf("%w: cannot use an xpath scraper as an image fragment scraper", ErrNotSupported)
 	case input.Performer != nil:
 		return nil, fmt.Errorf("%w: cannot use an xpath scraper as a performer fragment scraper", ErrNotSupported)
 	case input.Scene == nil:
@@ -184,7 +186,7 @@ func (s *xpathScraper) scrapeByFragment(ctx context.Context, input Input) (model
 		return nil, err
 	}
 
-	q := s.getXPathQuery(doc)
+	q := s.getXPathQuery(doc, url)
 	return scraper.scrapeScene(ctx, q)
 }
 
@@ -208,10 +210,14 @@ func (s *xpathScraper) scrapeGalleryByGallery(ctx context.Context, gallery *mode
 		return nil, err
 	}
 
-	q := s.getXPathQuery(doc)
+	q := s.getXPathQuery(doc, url)
 	return scraper.scrapeGallery(ctx, q)
 }
 
+func (s *xpathScraper) scrapeImageByImage(ctx context.Context, image *models.Image) (*models.ScrapedImage, error) {
+	return nil, fmt.Errorf("%w: cannot use an xpath scraper as an image scraper", ErrNotSupported)
+}
+
 func (s *xpathScraper) loadURL(ctx context.Context, url string) (*html.Node, error) {
 	r, err := loadURL(ctx, url, s.client, s.config, s.globalConfig)
 	if err != nil {
@@ -231,15 +237,17 @@ func (s *xpathScraper) loadURL(ctx context.Context, url string) (*html.Node, err
 	return ret, err
 }
 
-func (s *xpathScraper) getXPathQuery(doc *html.Node) *xpathQuery {
+func (s *xpathScraper) getXPathQuery(doc *html.Node, url string) *xpathQuery {
 	return &xpathQuery{
 		doc:     doc,
+		url:     url,
 		scraper: s,
 	}
 }
 
 type xpathQuery struct {
 	doc       *html.Node
+	url       string
 	scraper   *xpathScraper
 	queryType QueryType
 }
@@ -248,6 +256,10 @@ func (q *xpathQuery) getType() QueryType {
 	return q.queryType
 }
 
+func (q *xpathQuery) getURL() string {
+	return q.url
+}
+
 func (q *xpathQuery) setType(t QueryType) {
 	q.queryType = t
 }
@@ -300,5 +312,5 @@ func (q *xpathQuery) subScrape(ctx context.Context, value string) mappedQuery {
 		return nil
 	}
 
-	return q.scraper.getXPathQuery(doc)
+	return q.scraper.getXPathQuery(doc, value)
 }
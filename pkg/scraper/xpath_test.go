@@ -834,6 +834,18 @@ func (mockGlobalConfig) GetPythonPath() string {
 	return ""
 }
 
+func (mockGlobalConfig) GetCachePath() string {
+	return ""
+}
+
+func (mockGlobalConfig) GetScraperCacheTTL() int {
+	return 0
+}
+
+func (mockGlobalConfig) GetScraperCredentials() []*models.ScraperCredential {
+	return nil
+}
+
 func TestSubScrape(t *testing.T) {
 	retHTML := `
 	<div>
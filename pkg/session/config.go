@@ -13,5 +13,6 @@ type SessionConfig interface {
 
 	GetSessionStoreKey() []byte
 	GetMaxSessionAge() int
+	HasCredentials() bool
 	ValidateCredentials(username string, password string) bool
 }
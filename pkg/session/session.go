@@ -8,6 +8,7 @@ import (
 	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 )
 
@@ -16,6 +17,7 @@ type key int
 const (
 	contextUser key = iota
 	contextVisitedPlugins
+	contextUserAccount
 )
 
 const (
@@ -36,16 +38,21 @@ const (
 
 var ErrInvalidCredentials = errors.New("invalid username or password")
 var ErrUnauthorized = errors.New("unauthorized")
+var ErrForbidden = errors.New("forbidden")
 
 type Store struct {
 	sessionStore *sessions.CookieStore
 	config       SessionConfig
+	users        UserFinder
 }
 
-func NewStore(c SessionConfig) *Store {
+// NewStore returns a new session store. Users are authenticated against the
+// configured credentials and the stored users in users, which may be nil.
+func NewStore(c SessionConfig, users UserFinder) *Store {
 	ret := &Store{
 		sessionStore: sessions.NewCookieStore(c.GetSessionStoreKey()),
 		config:       c,
+		users:        users,
 	}
 
 	ret.sessionStore.MaxAge(c.GetMaxSessionAge())
@@ -60,9 +67,17 @@ func (s *Store) Login(w http.ResponseWriter, r *http.Request) error {
 	username := r.FormValue(usernameFormKey)
 	password := r.FormValue(passwordFormKey)
 
-	// authenticate the user
-	if !s.config.ValidateCredentials(username, password) {
-		return ErrInvalidCredentials
+	// authenticate the user. The configured credentials are only checked if
+	// they are set, since any credentials are valid otherwise.
+	if !s.config.HasCredentials() || !s.config.ValidateCredentials(username, password) {
+		valid, err := s.validateUserCredentials(r.Context(), username, password)
+		if err != nil {
+			return err
+		}
+
+		if !valid {
+			return ErrInvalidCredentials
+		}
 	}
 
 	newSession.Values[userIDKey] = username
@@ -205,14 +220,25 @@ func (s *Store) Authenticate(w http.ResponseWriter, r *http.Request) (userID str
 	}
 
 	if apiKey != "" {
-		// match against configured API and set userID to the
-		// configured username. In future, we'll want to
-		// get the username from the key.
-		if c.GetAPIKey() != apiKey {
+		// match against configured API key and set userID to the
+		// configured username, otherwise find the user with the key
+		if c.GetAPIKey() == apiKey {
+			return c.GetUsername(), nil
+		}
+
+		var user *models.User
+		if s.users != nil {
+			user, err = s.users.FindUserByAPIKey(r.Context(), apiKey)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if user == nil {
 			return "", ErrUnauthorized
 		}
 
-		userID = c.GetUsername()
+		userID = user.Username
 	} else {
 		// handle session
 		userID, err = s.GetSessionUserID(w, r)
@@ -0,0 +1,112 @@
+package session
+
+import (
+	"context"
+
+	"github.com/stashapp/stash/pkg/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserFinder finds the user accounts stored in the database.
+type UserFinder interface {
+	FindUser(ctx context.Context, username string) (*models.User, error)
+	FindUserByAPIKey(ctx context.Context, apiKey string) (*models.User, error)
+	HasUsers(ctx context.Context) (bool, error)
+}
+
+// HashPassword returns the bcrypt hash of password, for storing in
+// models.User.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// HasUsers returns true if any user account is stored. Authentication is
+// required if there are stored users, even if no credentials are
+// configured.
+func (s *Store) HasUsers(ctx context.Context) (bool, error) {
+	if s.users == nil {
+		return false, nil
+	}
+
+	return s.users.HasUsers(ctx)
+}
+
+func (s *Store) validateUserCredentials(ctx context.Context, username string, password string) (bool, error) {
+	if s.users == nil || username == "" {
+		return false, nil
+	}
+
+	user, err := s.users.FindUser(ctx, username)
+	if err != nil || user == nil {
+		return false, err
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil, nil
+}
+
+// GetUser returns the stored user with the provided user id, as returned by
+// Authenticate. It returns nil if userID is empty or is the configured
+// username. ErrUnauthorized is returned if the user no longer exists.
+func (s *Store) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	if userID == "" || userID == s.config.GetUsername() || s.users == nil {
+		return nil, nil
+	}
+
+	user, err := s.users.FindUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrUnauthorized
+	}
+
+	return user, nil
+}
+
+// SetCurrentUser sets the stored user making the request in the context.
+func SetCurrentUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, contextUserAccount, user)
+}
+
+// GetCurrentUser returns the stored user from the provided context. It
+// returns nil if the request was made using the configured credentials or
+// authentication is disabled.
+func GetCurrentUser(ctx context.Context) *models.User {
+	user, _ := ctx.Value(contextUserAccount).(*models.User)
+	return user
+}
+
+// GetCurrentUserAccountID returns the id of the stored user from the provided
+// context, or nil if there is none.
+func GetCurrentUserAccountID(ctx context.Context) *int {
+	if user := GetCurrentUser(ctx); user != nil {
+		id := user.ID
+		return &id
+	}
+
+	return nil
+}
+
+// GetCurrentRole returns the role of the user making the request. The
+// configured credentials always have the admin role. Requests without a
+// stored user are only accepted if they were made using the configured
+// credentials, or if authentication is disabled.
+func GetCurrentRole(ctx context.Context) models.UserRole {
+	if user := GetCurrentUser(ctx); user != nil {
+		return user.Role
+	}
+
+	return models.UserRoleAdmin
+}
+
+// HasRole returns true if the user making the request has at least the
+// required role.
+func HasRole(ctx context.Context, required models.UserRole) bool {
+	return GetCurrentRole(ctx).HasRole(required)
+}
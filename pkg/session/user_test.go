@@ -0,0 +1,187 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+type sessionConfig struct {
+	username string
+	password string
+	apiKey   string
+}
+
+func (c *sessionConfig) GetUsername() string { return c.username }
+func (c *sessionConfig) GetAPIKey() string   { return c.apiKey }
+func (c *sessionConfig) GetSessionStoreKey() []byte {
+	return []byte("0123456789abcdef0123456789abcdef")
+}
+func (c *sessionConfig) GetMaxSessionAge() int { return 3600 }
+func (c *sessionConfig) HasCredentials() bool  { return c.username != "" && c.password != "" }
+func (c *sessionConfig) ValidateCredentials(username string, password string) bool {
+	// like the stash configuration, any credentials are valid if none are
+	// configured
+	if !c.HasCredentials() {
+		return true
+	}
+	return username == c.username && password == c.password
+}
+
+type userFinder []*models.User
+
+func (f userFinder) FindUser(ctx context.Context, username string) (*models.User, error) {
+	for _, u := range f {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f userFinder) FindUserByAPIKey(ctx context.Context, apiKey string) (*models.User, error) {
+	for _, u := range f {
+		if u.APIKey.Valid && u.APIKey.String == apiKey {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f userFinder) HasUsers(ctx context.Context) (bool, error) {
+	return len(f) > 0, nil
+}
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	return newTestStoreWithConfig(t, &sessionConfig{
+		username: "admin",
+		password: "adminpw",
+		apiKey:   "adminkey",
+	})
+}
+
+func newTestStoreWithConfig(t *testing.T, c *sessionConfig) *Store {
+	t.Helper()
+
+	hash, err := HashPassword("viewerpw")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	viewer := &models.User{
+		ID:       1,
+		Username: "viewer",
+		Password: hash,
+		Role:     models.UserRoleViewer,
+	}
+	viewer.APIKey.String = "viewerkey"
+	viewer.APIKey.Valid = true
+
+	return NewStore(c, userFinder{viewer})
+}
+
+func TestStoreAuthenticateAPIKey(t *testing.T) {
+	s := newTestStore(t)
+
+	tests := []struct {
+		apiKey  string
+		want    string
+		wantErr bool
+	}{
+		{"adminkey", "admin", false},
+		{"viewerkey", "viewer", false},
+		{"invalid", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.apiKey, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(ApiKeyHeader, tt.apiKey)
+
+			got, err := s.Authenticate(httptest.NewRecorder(), r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Authenticate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func login(s *Store, username string, password string) error {
+	form := url.Values{}
+	form.Set(usernameFormKey, username)
+	form.Set(passwordFormKey, password)
+
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return s.Login(httptest.NewRecorder(), r)
+}
+
+func TestStoreLogin(t *testing.T) {
+	s := newTestStore(t)
+
+	tests := []struct {
+		username string
+		password string
+		wantErr  bool
+	}{
+		{"admin", "adminpw", false},
+		{"viewer", "viewerpw", false},
+		{"viewer", "adminpw", true},
+		{"missing", "viewerpw", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.username+"/"+tt.password, func(t *testing.T) {
+			if err := login(s, tt.username, tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("Login() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStoreLoginWithoutConfiguredCredentials(t *testing.T) {
+	s := newTestStoreWithConfig(t, &sessionConfig{})
+
+	if err := login(s, "viewer", "wrong"); err != ErrInvalidCredentials {
+		t.Errorf("Login() with wrong password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+
+	if err := login(s, "viewer", "viewerpw"); err != nil {
+		t.Errorf("Login() error = %v", err)
+	}
+}
+
+func TestStoreGetUser(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	if user, err := s.GetUser(ctx, "admin"); err != nil || user != nil {
+		t.Errorf("GetUser(admin) = %v, %v; want nil, nil", user, err)
+	}
+
+	user, err := s.GetUser(ctx, "viewer")
+	if err != nil || user == nil || user.ID != 1 {
+		t.Errorf("GetUser(viewer) = %v, %v; want user 1", user, err)
+	}
+
+	if _, err := s.GetUser(ctx, "deleted"); err != ErrUnauthorized {
+		t.Errorf("GetUser(deleted) error = %v, want %v", err, ErrUnauthorized)
+	}
+
+	ctx = SetCurrentUser(ctx, user)
+	if HasRole(ctx, models.UserRoleEditor) {
+		t.Errorf("HasRole(EDITOR) = true for viewer")
+	}
+	if !HasRole(context.Background(), models.UserRoleAdmin) {
+		t.Errorf("HasRole(ADMIN) = false without a stored user")
+	}
+}
@@ -159,6 +159,74 @@ func (f *filterBuilder) not(n *filterBuilder) {
 	f.subFilterOp = notOp
 }
 
+// addGroup adds a where clause matching the rows of table that match all of
+// the filters if op is andOp, or any of the filters if op is orOp. Unlike
+// sub-filters, the filters of a group do not share joins, so they may use
+// the same criteria, and groups may be nested to any depth. Filters without
+// joins or having clauses are included directly. Other filters are matched
+// using a subquery of table.
+func (f *filterBuilder) addGroup(table string, op string, filters []*filterBuilder) {
+	var clauses []string
+	var args []interface{}
+	for _, sub := range filters {
+		if err := sub.getError(); err != nil {
+			f.setError(err)
+			return
+		}
+
+		// with clauses cannot be used in subqueries, so are added to this filter
+		for _, w := range sub.getAllWithClauses() {
+			if !f.hasWith(w) {
+				f.addWith(w.sql, w.args...)
+			}
+		}
+		f.recursiveWith = f.recursiveWith || sub.hasRecursiveWith()
+
+		c, a := sub.groupClause(table)
+		if c == "" {
+			if op == orOp {
+				// an empty filter matches every row
+				return
+			}
+			continue
+		}
+
+		clauses = append(clauses, c)
+		args = append(args, a...)
+	}
+
+	if len(clauses) > 0 {
+		f.addWhere("("+strings.Join(clauses, " "+op+" ")+")", args...)
+	}
+}
+
+// groupClause returns the where clause matching the rows of table that match
+// the filter, for use in a group.
+func (f *filterBuilder) groupClause(table string) (string, []interface{}) {
+	clause, args := f.generateWhereClauses()
+	having, havingArgs := f.generateHavingClauses()
+	joins := f.getAllJoins()
+
+	if len(joins) == 0 && having == "" {
+		if clause == "" {
+			return "", nil
+		}
+
+		return "(" + clause + ")", args
+	}
+
+	query := fmt.Sprintf("SELECT %[1]s.id FROM %[1]s%[2]s", table, joins.toSQL())
+	if clause != "" {
+		query += " WHERE " + clause
+	}
+	if having != "" {
+		query += fmt.Sprintf(" GROUP BY %s.id HAVING %s", table, having)
+		args = append(args, havingArgs...)
+	}
+
+	return fmt.Sprintf("%s.id IN (%s)", table, query), args
+}
+
 // addLeftJoin adds a left join to the filter. The join is expressed in SQL as:
 // LEFT JOIN <table> [AS <as>] ON <onClause>
 // The AS is omitted if as is empty.
@@ -287,7 +355,7 @@ func (f *filterBuilder) generateHavingClauses() (string, []interface{}) {
 func (f *filterBuilder) generateWithClauses() (string, []interface{}) {
 	var clauses []string
 	var args []interface{}
-	for _, w := range f.withClauses {
+	for _, w := range f.getAllWithClauses() {
 		clauses = append(clauses, w.sql)
 		args = append(args, w.args...)
 	}
@@ -299,6 +367,35 @@ func (f *filterBuilder) generateWithClauses() (string, []interface{}) {
 	return "", nil
 }
 
+// getAllWithClauses returns the with clauses of this filter and any
+// sub-filter(s).
+func (f *filterBuilder) getAllWithClauses() []sqlClause {
+	ret := f.withClauses
+	if f.subFilter != nil {
+		ret = append(ret[:len(ret):len(ret)], f.subFilter.getAllWithClauses()...)
+	}
+
+	return ret
+}
+
+// hasRecursiveWith returns true if this filter or any sub-filter(s) has a
+// recursive with clause.
+func (f *filterBuilder) hasRecursiveWith() bool {
+	return f.recursiveWith || (f.subFilter != nil && f.subFilter.hasRecursiveWith())
+}
+
+// hasWith returns true if the filter has the with clause, so that with
+// clauses used by several filters of a group are added once.
+func (f *filterBuilder) hasWith(w sqlClause) bool {
+	for _, ww := range f.withClauses {
+		if ww.sql == w.sql && fmt.Sprint(ww.args) == fmt.Sprint(w.args) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // getAllJoins returns all of the joins in this filter and any sub-filter(s).
 // Redundant joins will not be duplicated in the return value.
 func (f *filterBuilder) getAllJoins() joins {
@@ -407,6 +504,15 @@ func intCriterionHandler(c *models.IntCriterionInput, column string) criterionHa
 	}
 }
 
+func timestampCriterionHandler(c *models.TimestampCriterionInput, column string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if c != nil {
+			clause, args := getTimestampCriterionWhereClause(column, *c)
+			f.addWhere(clause, args...)
+		}
+	}
+}
+
 func boolCriterionHandler(c *bool, column string) criterionHandlerFunc {
 	return func(f *filterBuilder) {
 		if c != nil {
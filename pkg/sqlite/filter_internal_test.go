@@ -332,6 +332,63 @@ func TestGenerateWhereClauses(t *testing.T) {
 	assert.Len(rArgs, 3)
 }
 
+func TestAddGroup(t *testing.T) {
+	assert := assert.New(t)
+
+	const clause1 = "a = ?"
+	const clause2 = "j.b = ?"
+	const having = "count(j.b) = ?"
+	const with = "w AS (SELECT ?)"
+
+	const arg1 = "1"
+	const arg2 = "2"
+	const arg3 = "3"
+	const arg4 = "4"
+
+	inline := &filterBuilder{}
+	inline.addWhere(clause1, arg1)
+
+	joined := &filterBuilder{}
+	joined.addLeftJoin("j", "", "j.t_id = t.id")
+	joined.addWhere(clause2, arg2)
+	joined.addHaving(having, arg3)
+	joined.addWith(with, arg4)
+
+	// ensure filters without joins are inlined, and other filters use a
+	// subquery with its own joins
+	f := &filterBuilder{}
+	f.addGroup("t", orOp, []*filterBuilder{inline, joined})
+
+	r, rArgs := f.generateWhereClauses()
+	assert.Equal(fmt.Sprintf("((((%s)) OR t.id IN (SELECT t.id FROM t LEFT JOIN j ON j.t_id = t.id WHERE (%s) GROUP BY t.id HAVING (%s))))", clause1, clause2, having), r)
+	assert.Equal([]interface{}{arg1, arg2, arg3}, rArgs)
+	assert.Len(f.getAllJoins(), 0)
+
+	// ensure with clauses are added to the filter once
+	f.addGroup("t", andOp, []*filterBuilder{joined, inline})
+	w, wArgs := f.generateWithClauses()
+	assert.Equal(with, w)
+	assert.Equal([]interface{}{arg4}, wArgs)
+
+	// ensure empty filters match everything
+	f = &filterBuilder{}
+	f.addGroup("t", orOp, []*filterBuilder{inline, {}})
+	r, _ = f.generateWhereClauses()
+	assert.Equal("", r)
+
+	f.addGroup("t", andOp, []*filterBuilder{inline, {}})
+	r, rArgs = f.generateWhereClauses()
+	assert.Equal(fmt.Sprintf("((((%s))))", clause1), r)
+	assert.Equal([]interface{}{arg1}, rArgs)
+
+	// ensure errors are propagated
+	errFilter := &filterBuilder{}
+	errFilter.setError(errors.New("test error"))
+	f = &filterBuilder{}
+	f.addGroup("t", andOp, []*filterBuilder{inline, errFilter})
+	assert.NotNil(f.getError())
+}
+
 func TestGenerateHavingClauses(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,224 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// relevanceSort is the sort of the find filter ordering the objects
+// matching the search string by relevance, most relevant first.
+const relevanceSort = "relevance"
+
+// ftsTable is the full-text search table of the searchable text of the
+// objects of a table. Its rows are keyed by the id of the object. The
+// writers of the table update the rows of the objects they change.
+type ftsTable struct {
+	name  string
+	table string
+	// columns are the columns of the full-text search table.
+	columns []string
+	// values are the expressions selecting the value of each column from
+	// the table.
+	values []string
+}
+
+var (
+	scenesFTS = ftsTable{
+		name:    "scenes_fts",
+		table:   sceneTable,
+		columns: []string{"title", "details", "path", "oshash", "checksum", "markers"},
+		values: []string{
+			"scenes.title", "scenes.details", "scenes.path", "scenes.oshash", "scenes.checksum",
+			"(SELECT GROUP_CONCAT(scene_markers.title, ' ') FROM scene_markers WHERE scene_markers.scene_id = scenes.id)",
+		},
+	}
+	sceneMarkersFTS = ftsTable{
+		name:    "scene_markers_fts",
+		table:   sceneMarkerTable,
+		columns: []string{"title", "scene_title"},
+		values: []string{
+			"scene_markers.title",
+			"(SELECT scenes.title FROM scenes WHERE scenes.id = scene_markers.scene_id)",
+		},
+	}
+	imagesFTS = ftsTable{
+		name:    "images_fts",
+		table:   imageTable,
+		columns: []string{"title", "path", "checksum"},
+		values:  []string{"images.title", "images.path", "images.checksum"},
+	}
+	galleriesFTS = ftsTable{
+		name:    "galleries_fts",
+		table:   galleryTable,
+		columns: []string{"title", "path", "checksum"},
+		values:  []string{"galleries.title", "galleries.path", "galleries.checksum"},
+	}
+	performersFTS = ftsTable{
+		name:    "performers_fts",
+		table:   performerTable,
+		columns: []string{"name", "aliases"},
+		values:  []string{"performers.name", "performers.aliases"},
+	}
+	studiosFTS = ftsTable{
+		name:    "studios_fts",
+		table:   studioTable,
+		columns: []string{"name", "aliases"},
+		values: []string{
+			"studios.name",
+			"(SELECT GROUP_CONCAT(studio_aliases.alias, ' ') FROM studio_aliases WHERE studio_aliases.studio_id = studios.id)",
+		},
+	}
+	tagsFTS = ftsTable{
+		name:    "tags_fts",
+		table:   tagTable,
+		columns: []string{"name", "aliases"},
+		values: []string{
+			"tags.name",
+			"(SELECT GROUP_CONCAT(tag_aliases.alias, ' ') FROM tag_aliases WHERE tag_aliases.tag_id = tags.id)",
+		},
+	}
+	moviesFTS = ftsTable{
+		name:    "movies_fts",
+		table:   movieTable,
+		columns: []string{"name", "aliases"},
+		values:  []string{"movies.name", "movies.aliases"},
+	}
+)
+
+// update reloads the rows of the objects with the provided ids from the
+// table. The rows of objects that no longer exist are removed.
+func (t ftsTable) update(tx dbi, ids ...int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	in := intList(ids)
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE rowid IN (%s)", t.name, in)); err != nil {
+		return fmt.Errorf("updating %s: %w", t.name, err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (rowid, %s) SELECT %s.id, %s FROM %s WHERE %s.id IN (%s)",
+		t.name, strings.Join(t.columns, ", "), t.table, strings.Join(t.values, ", "), t.table, t.table, in)
+	if _, err := tx.Exec(query); err != nil {
+		return fmt.Errorf("updating %s: %w", t.name, err)
+	}
+
+	return nil
+}
+
+// updateSceneSearch updates the search rows of the scenes and of their
+// markers, which include the title of the scene.
+func updateSceneSearch(tx dbi, ids ...int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := scenesFTS.update(tx, ids...); err != nil {
+		return err
+	}
+
+	var markerIDs []int
+	if err := tx.Select(&markerIDs, fmt.Sprintf("SELECT id FROM %s WHERE scene_id IN (%s)", sceneMarkerTable, intList(ids))); err != nil {
+		return err
+	}
+
+	return sceneMarkersFTS.update(tx, markerIDs...)
+}
+
+// ftsTerm returns the full-text query matching the tokens of the search
+// term in order, with the last token matched as a prefix. It returns an
+// empty string if the term has no tokens.
+func ftsTerm(term string) string {
+	hasToken := strings.IndexFunc(term, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsNumber(r)
+	}) != -1
+	if !hasToken {
+		return ""
+	}
+
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+}
+
+func ftsTerms(terms []string) []string {
+	var ret []string
+	for _, t := range terms {
+		if term := ftsTerm(t); term != "" {
+			ret = append(ret, term)
+		}
+	}
+
+	return ret
+}
+
+// getFTSQuery returns the full-text queries matching the objects that
+// must and must not match the search specs. Either query is empty if there
+// are no such terms.
+func getFTSQuery(specs models.SearchSpecs) (match string, notMatch string) {
+	conditions := ftsTerms(specs.MustHave)
+	for _, set := range specs.AnySets {
+		if terms := ftsTerms(set); len(terms) > 0 {
+			conditions = append(conditions, "("+strings.Join(terms, " OR ")+")")
+		}
+	}
+
+	return strings.Join(conditions, " AND "), strings.Join(ftsTerms(specs.MustNot), " OR ")
+}
+
+// sqlString returns s quoted as an SQL string literal.
+func sqlString(s string) string {
+	s = strings.ReplaceAll(s, "\x00", "")
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// searchAlias returns the alias of the matches of the full-text search of
+// a query of the table.
+func searchAlias(table string) string {
+	return table + "_search"
+}
+
+// addFullTextSearch filters the query to the objects of the table matching
+// the search string q, using the full-text search table t. The relevance of
+// each match may be used to sort the query using getRelevanceSort.
+func (qb *queryBuilder) addFullTextSearch(t ftsTable, q string) {
+	match, notMatch := getFTSQuery(models.ParseSearchString(q))
+
+	if match != "" {
+		// joins cannot have arguments, so the query is included as a literal
+		qb.addJoins(join{
+			table:    fmt.Sprintf("(SELECT rowid AS id, rank FROM %[1]s WHERE %[1]s MATCH %[2]s)", t.name, sqlString(match)),
+			as:       searchAlias(t.table),
+			onClause: fmt.Sprintf("%s.id = %s.id", searchAlias(t.table), t.table),
+			joinType: "INNER",
+		})
+		qb.hasSearch = true
+	}
+
+	if notMatch != "" {
+		qb.addWhere(fmt.Sprintf("%[1]s.id NOT IN (SELECT rowid FROM %[2]s WHERE %[2]s MATCH ?)", t.table, t.name))
+		qb.addArg(notMatch)
+	}
+}
+
+// getRelevanceSort returns the ORDER BY clause sorting the query by the
+// relevance of the full-text search of the table, if the find filter sorts
+// by relevance, or has no sort and the query has a search. Queries sorted
+// by relevance without a search are not sorted. ok is false if the query is
+// not sorted by relevance.
+func (qb *queryBuilder) getRelevanceSort(table string, findFilter *models.FindFilterType) (sort string, ok bool) {
+	var sortBy string
+	if findFilter != nil && findFilter.Sort != nil {
+		sortBy = *findFilter.Sort
+	}
+
+	switch {
+	case sortBy == relevanceSort && !qb.hasSearch:
+		return "", true
+	case (sortBy == "" || sortBy == relevanceSort) && qb.hasSearch:
+		return fmt.Sprintf(" ORDER BY %s.rank ASC, %s.id ASC", searchAlias(table), table), true
+	default:
+		return "", false
+	}
+}
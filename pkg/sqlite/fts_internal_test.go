@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFTSQuery(t *testing.T) {
+	tests := []struct {
+		q        string
+		match    string
+		notMatch string
+	}{
+		{"foo", `"foo"*`, ""},
+		{"foo bar", `"foo"* AND "bar"*`, ""},
+		{`"foo bar"`, `"foo bar"*`, ""},
+		{"foo or bar baz", `"baz"* AND ("foo"* OR "bar"*)`, ""},
+		{"foo -bar -baz", `"foo"*`, `"bar"* OR "baz"*`},
+		{`fo"o`, `"fo""o"*`, ""},
+		{"foo - *", `"foo"*`, ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		match, notMatch := getFTSQuery(models.ParseSearchString(tt.q))
+		assert.Equal(t, tt.match, match, tt.q)
+		assert.Equal(t, tt.notMatch, notMatch, tt.q)
+	}
+}
+
+func TestSQLString(t *testing.T) {
+	assert.Equal(t, `'it''s'`, sqlString("it's"))
+	assert.Equal(t, `'ab'`, sqlString("a\x00b"))
+}
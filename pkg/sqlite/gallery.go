@@ -36,6 +36,10 @@ func (qb *galleryQueryBuilder) Create(newObject models.Gallery) (*models.Gallery
 		return nil, err
 	}
 
+	if err := galleriesFTS.update(qb.tx, ret.ID); err != nil {
+		return nil, err
+	}
+
 	return &ret, nil
 }
 
@@ -45,6 +49,10 @@ func (qb *galleryQueryBuilder) Update(updatedObject models.Gallery) (*models.Gal
 		return nil, err
 	}
 
+	if err := galleriesFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.Find(updatedObject.ID)
 }
 
@@ -54,13 +62,21 @@ func (qb *galleryQueryBuilder) UpdatePartial(updatedObject models.GalleryPartial
 		return nil, err
 	}
 
+	if err := galleriesFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.Find(updatedObject.ID)
 }
 
 func (qb *galleryQueryBuilder) UpdateChecksum(id int, checksum string) error {
-	return qb.updateMap(id, map[string]interface{}{
+	if err := qb.updateMap(id, map[string]interface{}{
 		"checksum": checksum,
-	})
+	}); err != nil {
+		return err
+	}
+
+	return galleriesFTS.update(qb.tx, id)
 }
 
 func (qb *galleryQueryBuilder) UpdateFileModTime(id int, modTime models.NullSQLiteTimestamp) error {
@@ -70,24 +86,33 @@ func (qb *galleryQueryBuilder) UpdateFileModTime(id int, modTime models.NullSQLi
 }
 
 func (qb *galleryQueryBuilder) Destroy(id int) error {
-	return qb.destroyExisting([]int{id})
+	if err := qb.destroyExisting([]int{id}); err != nil {
+		return err
+	}
+
+	return galleriesFTS.update(qb.tx, id)
 }
 
 func (qb *galleryQueryBuilder) Find(id int) (*models.Gallery, error) {
-	var ret models.Gallery
-	if err := qb.get(id, &ret); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
+	if restricted, err := qb.isRestricted(id); err != nil || restricted {
 		return nil, err
 	}
-	return &ret, nil
+
+	return qb.find(id)
 }
 
+// FindMany returns the galleries with the provided ids. Galleries hidden by
+// the content restriction are omitted.
 func (qb *galleryQueryBuilder) FindMany(ids []int) ([]*models.Gallery, error) {
 	var galleries []*models.Gallery
 	for _, id := range ids {
-		gallery, err := qb.Find(id)
+		if restricted, err := qb.isRestricted(id); err != nil {
+			return nil, err
+		} else if restricted {
+			continue
+		}
+
+		gallery, err := qb.find(id)
 		if err != nil {
 			return nil, err
 		}
@@ -102,6 +127,17 @@ func (qb *galleryQueryBuilder) FindMany(ids []int) ([]*models.Gallery, error) {
 	return galleries, nil
 }
 
+func (qb *galleryQueryBuilder) find(id int) (*models.Gallery, error) {
+	var ret models.Gallery
+	if err := qb.get(id, &ret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
 func (qb *galleryQueryBuilder) FindByChecksum(checksum string) (*models.Gallery, error) {
 	query := "SELECT * FROM galleries WHERE checksum = ? LIMIT 1"
 	args := []interface{}{checksum}
@@ -144,15 +180,15 @@ func (qb *galleryQueryBuilder) FindByImageID(imageID int) ([]*models.Gallery, er
 }
 
 func (qb *galleryQueryBuilder) CountByImageID(imageID int) (int, error) {
-	query := `SELECT image_id FROM galleries_images
+	query := `SELECT gallery_id AS id FROM galleries_images
 	WHERE image_id = ?
 	GROUP BY gallery_id`
 	args := []interface{}{imageID}
-	return qb.runCountQuery(qb.buildCountQuery(query), args)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery(query), args)
 }
 
 func (qb *galleryQueryBuilder) Count() (int, error) {
-	return qb.runCountQuery(qb.buildCountQuery("SELECT galleries.id FROM galleries"), nil)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery("SELECT galleries.id FROM galleries"), nil)
 }
 
 func (qb *galleryQueryBuilder) All() ([]*models.Gallery, error) {
@@ -236,10 +272,10 @@ func (qb *galleryQueryBuilder) makeQuery(galleryFilter *models.GalleryFilterType
 
 	query := qb.newQuery()
 	distinctIDs(&query, galleryTable)
+	query.addWhere(qb.restrictionClause())
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"galleries.title", "galleries.path", "galleries.checksum"}
-		query.parseQueryString(searchColumns, *q)
+		query.addFullTextSearch(galleriesFTS, *q)
 	}
 
 	if err := qb.validateFilter(galleryFilter); err != nil {
@@ -249,7 +285,11 @@ func (qb *galleryQueryBuilder) makeQuery(galleryFilter *models.GalleryFilterType
 
 	query.addFilter(filter)
 
-	query.sortAndPagination = qb.getGallerySort(findFilter) + getPagination(findFilter)
+	sort, ok := query.getRelevanceSort(galleryTable, findFilter)
+	if !ok {
+		sort = qb.getGallerySort(findFilter)
+	}
+	query.sortAndPagination = sort + getPagination(findFilter)
 
 	return &query, nil
 }
@@ -515,7 +555,7 @@ func (qb *galleryQueryBuilder) queryGallery(query string, args []interface{}) (*
 
 func (qb *galleryQueryBuilder) queryGalleries(query string, args []interface{}) ([]*models.Gallery, error) {
 	var ret models.Galleries
-	if err := qb.query(query, args, &ret); err != nil {
+	if err := qb.query(qb.restrictQuery(query), args, &ret); err != nil {
 		return nil, err
 	}
 
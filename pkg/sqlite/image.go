@@ -20,7 +20,7 @@ GROUP BY images.id
 `
 
 var countImagesForGalleryQuery = `
-SELECT gallery_id FROM galleries_images
+SELECT image_id AS id FROM galleries_images
 WHERE gallery_id = ?
 GROUP BY image_id
 `
@@ -45,6 +45,12 @@ func (qb *imageQueryBuilder) Create(newObject models.Image) (*models.Image, erro
 		return nil, err
 	}
 
+	imagePhashIndex.invalidate(qb.tx, ret.ID)
+
+	if err := imagesFTS.update(qb.tx, ret.ID); err != nil {
+		return nil, err
+	}
+
 	return &ret, nil
 }
 
@@ -54,6 +60,12 @@ func (qb *imageQueryBuilder) Update(updatedObject models.ImagePartial) (*models.
 		return nil, err
 	}
 
+	imagePhashIndex.invalidate(qb.tx, updatedObject.ID)
+
+	if err := imagesFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.find(updatedObject.ID)
 }
 
@@ -63,6 +75,12 @@ func (qb *imageQueryBuilder) UpdateFull(updatedObject models.Image) (*models.Ima
 		return nil, err
 	}
 
+	imagePhashIndex.invalidate(qb.tx, updatedObject.ID)
+
+	if err := imagesFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.find(updatedObject.ID)
 }
 
@@ -118,17 +136,34 @@ func (qb *imageQueryBuilder) ResetOCounter(id int) (int, error) {
 }
 
 func (qb *imageQueryBuilder) Destroy(id int) error {
-	return qb.destroyExisting([]int{id})
+	if err := qb.destroyExisting([]int{id}); err != nil {
+		return err
+	}
+
+	imagePhashIndex.invalidate(qb.tx, id)
+	return imagesFTS.update(qb.tx, id)
 }
 
 func (qb *imageQueryBuilder) Find(id int) (*models.Image, error) {
+	if restricted, err := qb.isRestricted(id); err != nil || restricted {
+		return nil, err
+	}
+
 	return qb.find(id)
 }
 
+// FindMany returns the images with the provided ids. Images hidden by the
+// content restriction are omitted.
 func (qb *imageQueryBuilder) FindMany(ids []int) ([]*models.Image, error) {
 	var images []*models.Image
 	for _, id := range ids {
-		image, err := qb.Find(id)
+		if restricted, err := qb.isRestricted(id); err != nil {
+			return nil, err
+		} else if restricted {
+			continue
+		}
+
+		image, err := qb.find(id)
 		if err != nil {
 			return nil, err
 		}
@@ -166,6 +201,25 @@ func (qb *imageQueryBuilder) FindByPath(path string) (*models.Image, error) {
 	return qb.queryImage(query, args)
 }
 
+func (qb *imageQueryBuilder) FindDuplicates(options models.DuplicateOptions) ([][]*models.Image, error) {
+	dupeIds, err := qb.findPhashDuplicateIDs(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var duplicates [][]*models.Image
+	for _, imageIds := range dupeIds {
+		images, err := qb.FindMany(imageIds)
+		if err != nil {
+			return nil, err
+		}
+
+		duplicates = append(duplicates, images)
+	}
+
+	return duplicates, nil
+}
+
 func (qb *imageQueryBuilder) FindByGalleryID(galleryID int) ([]*models.Image, error) {
 	args := []interface{}{galleryID}
 	sort := "path"
@@ -178,15 +232,15 @@ func (qb *imageQueryBuilder) FindByGalleryID(galleryID int) ([]*models.Image, er
 
 func (qb *imageQueryBuilder) CountByGalleryID(galleryID int) (int, error) {
 	args := []interface{}{galleryID}
-	return qb.runCountQuery(qb.buildCountQuery(countImagesForGalleryQuery), args)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery(countImagesForGalleryQuery), args)
 }
 
 func (qb *imageQueryBuilder) Count() (int, error) {
-	return qb.runCountQuery(qb.buildCountQuery("SELECT images.id FROM images"), nil)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery("SELECT images.id FROM images"), nil)
 }
 
 func (qb *imageQueryBuilder) Size() (float64, error) {
-	return qb.runSumQuery("SELECT SUM(cast(size as double)) as sum FROM images", nil)
+	return qb.runSumQuery(qb.addRestrictionWhere("SELECT SUM(cast(size as double)) as sum FROM images"), nil)
 }
 
 func (qb *imageQueryBuilder) All() ([]*models.Image, error) {
@@ -240,6 +294,8 @@ func (qb *imageQueryBuilder) makeFilter(imageFilter *models.ImageFilterType) *fi
 	query.handleCriterion(stringCriterionHandler(imageFilter.Checksum, "images.checksum"))
 	query.handleCriterion(stringCriterionHandler(imageFilter.Title, "images.title"))
 	query.handleCriterion(stringCriterionHandler(imageFilter.Path, "images.path"))
+	query.handleCriterion(phashCriterionHandler(imageFilter.Phash, "images.phash"))
+	query.handleCriterion(phashDuplicatedCriterionHandler(&qb.repository, imageFilter.Duplicated))
 	query.handleCriterion(intCriterionHandler(imageFilter.Rating, "images.rating"))
 	query.handleCriterion(intCriterionHandler(imageFilter.OCounter, "images.o_counter"))
 	query.handleCriterion(boolCriterionHandler(imageFilter.Organized, "images.organized"))
@@ -268,10 +324,10 @@ func (qb *imageQueryBuilder) makeQuery(imageFilter *models.ImageFilterType, find
 
 	query := qb.newQuery()
 	distinctIDs(&query, imageTable)
+	query.addWhere(qb.restrictionClause())
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"images.title", "images.path", "images.checksum"}
-		query.parseQueryString(searchColumns, *q)
+		query.addFullTextSearch(imagesFTS, *q)
 	}
 
 	if err := qb.validateFilter(imageFilter); err != nil {
@@ -281,7 +337,11 @@ func (qb *imageQueryBuilder) makeQuery(imageFilter *models.ImageFilterType, find
 
 	query.addFilter(filter)
 
-	query.sortAndPagination = qb.getImageSort(findFilter) + getPagination(findFilter)
+	sort, ok := query.getRelevanceSort(imageTable, findFilter)
+	if !ok {
+		sort = qb.getImageSort(findFilter)
+	}
+	query.sortAndPagination = sort + getPagination(findFilter)
 
 	return &query, nil
 }
@@ -548,7 +608,7 @@ func (qb *imageQueryBuilder) queryImage(query string, args []interface{}) (*mode
 
 func (qb *imageQueryBuilder) queryImages(query string, args []interface{}) ([]*models.Image, error) {
 	var ret models.Images
-	if err := qb.query(query, args, &ret); err != nil {
+	if err := qb.query(qb.restrictQuery(query), args, &ret); err != nil {
 		return nil, err
 	}
 
@@ -1208,3 +1208,67 @@ func TestImageQueryPagination(t *testing.T) {
 // TODO Count
 // TODO SizeCount
 // TODO All
+
+func TestImageFindDuplicates(t *testing.T) {
+	if err := withRollbackTxn(func(r models.Repository) error {
+		sqb := r.Image()
+
+		const phash = 0x1234567890abcdef
+		// the third image is one bit from the others
+		phashes := []int64{phash, phash, phash ^ 1}
+		for i, p := range phashes {
+			if _, err := sqb.Update(models.ImagePartial{
+				ID:    imageIDs[i],
+				Phash: &sql.NullInt64{Int64: p, Valid: true},
+			}); err != nil {
+				return err
+			}
+		}
+
+		dupes, err := sqb.FindDuplicates(models.DuplicateOptions{})
+		if err != nil {
+			return err
+		}
+
+		assert.Len(t, dupes, 1)
+		assert.Len(t, dupes[0], 2)
+
+		dupes, err = sqb.FindDuplicates(models.DuplicateOptions{Distance: 1})
+		if err != nil {
+			return err
+		}
+
+		assert.Len(t, dupes, 1)
+		assert.Len(t, dupes[0], 3)
+
+		duplicated := true
+		images := queryImages(t, sqb, &models.ImageFilterType{
+			Duplicated: &models.PHashDuplicationCriterionInput{
+				Duplicated: &duplicated,
+			},
+		}, nil)
+		assert.ElementsMatch(t, imageIDs[:2], imagesToIDs(images))
+
+		distance := 1
+		images = queryImages(t, sqb, &models.ImageFilterType{
+			Duplicated: &models.PHashDuplicationCriterionInput{
+				Duplicated: &duplicated,
+				Distance:   &distance,
+			},
+		}, nil)
+		assert.ElementsMatch(t, imageIDs[:3], imagesToIDs(images))
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func imagesToIDs(images []*models.Image) []int {
+	var ret []int
+	for _, i := range images {
+		ret = append(ret, i.ID)
+	}
+
+	return ret
+}
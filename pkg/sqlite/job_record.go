@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const jobTable = "jobs"
+
+type jobRecordQueryBuilder struct {
+	repository
+}
+
+func NewJobRecordReaderWriter(tx dbi) *jobRecordQueryBuilder {
+	return &jobRecordQueryBuilder{
+		repository{
+			tx:        tx,
+			tableName: jobTable,
+			idColumn:  idColumn,
+		},
+	}
+}
+
+func (qb *jobRecordQueryBuilder) Save(job models.JobRecord) error {
+	// job ids are assigned by the job manager
+	stmt := fmt.Sprintf("INSERT OR REPLACE INTO %s (id, %s) VALUES (:id, %s)", jobTable, listKeys(job, false), listKeys(job, true))
+	_, err := qb.tx.NamedExec(stmt, job)
+	return err
+}
+
+func (qb *jobRecordQueryBuilder) Prune(keep int) error {
+	// unfinished jobs are kept so that they can be requeued
+	stmt := fmt.Sprintf("DELETE FROM %[1]s WHERE status IN %[2]s AND id NOT IN (SELECT id FROM %[1]s ORDER BY id DESC LIMIT ?)", jobTable, getInBinding(3))
+	_, err := qb.tx.Exec(stmt, models.JobStatusFinished, models.JobStatusCancelled, models.JobStatusFailed, keep)
+	return err
+}
+
+func (qb *jobRecordQueryBuilder) Find(id int) (*models.JobRecord, error) {
+	var ret models.JobRecord
+	if err := qb.get(id, &ret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (qb *jobRecordQueryBuilder) FindUnfinished() ([]*models.JobRecord, error) {
+	query := selectAll(jobTable) + "WHERE status IN " + getInBinding(4) + getSort("id", "ASC", jobTable)
+	args := []interface{}{models.JobStatusReady, models.JobStatusPaused, models.JobStatusRunning, models.JobStatusStopping}
+
+	var ret models.JobRecords
+	if err := qb.query(query, args, &ret); err != nil {
+		return nil, err
+	}
+
+	return []*models.JobRecord(ret), nil
+}
+
+func (qb *jobRecordQueryBuilder) Query(statuses []models.JobStatus, findFilter *models.FindFilterType) ([]*models.JobRecord, int, error) {
+	if findFilter == nil {
+		findFilter = &models.FindFilterType{}
+	}
+
+	var where []string
+	var args []interface{}
+	if len(statuses) > 0 {
+		where = append(where, "status IN "+getInBinding(len(statuses)))
+		for _, s := range statuses {
+			args = append(args, s)
+		}
+	}
+
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	count, err := qb.runCountQuery("SELECT COUNT(*) as count FROM "+jobTable+whereClause, args)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// most recent jobs first unless otherwise specified
+	direction := "DESC"
+	if findFilter.Direction != nil {
+		direction = findFilter.GetDirection()
+	}
+
+	query := selectAll(jobTable) + whereClause + getSort(findFilter.GetSort("add_time"), direction, jobTable) + ", jobs.id " + getSortDirection(direction) + getPagination(findFilter)
+
+	var ret models.JobRecords
+	if err := qb.query(query, args, &ret); err != nil {
+		return nil, 0, err
+	}
+
+	return []*models.JobRecord(ret), count, nil
+}
+
+func (qb *jobRecordQueryBuilder) MaxID() (int, error) {
+	return qb.runCountQuery("SELECT COALESCE(MAX(id), 0) as count FROM "+jobTable, nil)
+}
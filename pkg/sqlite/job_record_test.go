@@ -0,0 +1,151 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobRecordSaveQuery(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		qb := r.JobRecord()
+
+		now := time.Now()
+		jobs := []models.JobRecord{
+			{ID: 1001, Status: models.JobStatusFinished, Description: "finished", AddTime: models.SQLiteTimestamp{Timestamp: now.Add(-2 * time.Hour)}},
+			{ID: 1002, Status: models.JobStatusFailed, Description: "failed", AddTime: models.SQLiteTimestamp{Timestamp: now.Add(-time.Hour)}, Error: sql.NullString{String: "error", Valid: true}},
+			{ID: 1003, Status: models.JobStatusRunning, Description: "running", AddTime: models.SQLiteTimestamp{Timestamp: now}, Type: sql.NullString{String: "scan", Valid: true}, Input: sql.NullString{String: "{}", Valid: true}},
+		}
+
+		for _, j := range jobs {
+			if err := qb.Save(j); err != nil {
+				t.Errorf("Error saving job: %s", err.Error())
+				return nil
+			}
+		}
+
+		// saving again replaces the job
+		jobs[2].Progress = sql.NullFloat64{Float64: 0.5, Valid: true}
+		jobs[2].Issues = sql.NullString{String: `[{"level":"ERROR","message":"failed"}]`, Valid: true}
+		jobs[2].IssueCount = 1
+		jobs[2].Lane = sql.NullString{String: "IO", Valid: true}
+		jobs[2].Priority = 2
+		if err := qb.Save(jobs[2]); err != nil {
+			t.Errorf("Error saving job: %s", err.Error())
+			return nil
+		}
+
+		found, err := qb.Find(1003)
+		if err != nil {
+			t.Errorf("Error finding job: %s", err.Error())
+			return nil
+		}
+		if assert.NotNil(t, found) {
+			assert.Equal(t, 0.5, found.Progress.Float64)
+			assert.Equal(t, "scan", found.Type.String)
+			assert.Equal(t, jobs[2].Issues, found.Issues)
+			assert.Equal(t, 1, found.IssueCount)
+			assert.Equal(t, "IO", found.Lane.String)
+			assert.Equal(t, 2, found.Priority)
+		}
+
+		maxID, err := qb.MaxID()
+		if err != nil {
+			t.Errorf("Error getting max id: %s", err.Error())
+			return nil
+		}
+		assert.Equal(t, 1003, maxID)
+
+		unfinished, err := qb.FindUnfinished()
+		if err != nil {
+			t.Errorf("Error finding unfinished jobs: %s", err.Error())
+			return nil
+		}
+		if assert.Len(t, unfinished, 1) {
+			assert.Equal(t, 1003, unfinished[0].ID)
+		}
+
+		// most recent first by default
+		perPage := 2
+		results, count, err := qb.Query(nil, &models.FindFilterType{PerPage: &perPage})
+		if err != nil {
+			t.Errorf("Error querying jobs: %s", err.Error())
+			return nil
+		}
+		assert.Equal(t, 3, count)
+		if assert.Len(t, results, 2) {
+			assert.Equal(t, 1003, results[0].ID)
+			assert.Equal(t, 1002, results[1].ID)
+		}
+
+		results, count, err = qb.Query([]models.JobStatus{models.JobStatusFailed, models.JobStatusFinished}, nil)
+		if err != nil {
+			t.Errorf("Error querying jobs: %s", err.Error())
+			return nil
+		}
+		assert.Equal(t, 2, count)
+		assert.Len(t, results, 2)
+
+		// paused jobs are unfinished
+		if err := qb.Save(models.JobRecord{ID: 1004, Status: models.JobStatusPaused, Description: "paused", AddTime: models.SQLiteTimestamp{Timestamp: now}}); err != nil {
+			t.Errorf("Error saving job: %s", err.Error())
+			return nil
+		}
+
+		unfinished, err = qb.FindUnfinished()
+		if err != nil {
+			t.Errorf("Error finding unfinished jobs: %s", err.Error())
+			return nil
+		}
+		assert.Len(t, unfinished, 2)
+
+		return nil
+	})
+}
+
+func TestJobRecordPrune(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		qb := r.JobRecord()
+
+		now := models.SQLiteTimestamp{Timestamp: time.Now()}
+		jobs := []models.JobRecord{
+			{ID: 2001, Status: models.JobStatusRunning, Description: "running", AddTime: now},
+			{ID: 2002, Status: models.JobStatusFinished, Description: "finished", AddTime: now},
+			{ID: 2003, Status: models.JobStatusFailed, Description: "failed", AddTime: now},
+			{ID: 2004, Status: models.JobStatusCancelled, Description: "cancelled", AddTime: now},
+		}
+
+		for _, j := range jobs {
+			if err := qb.Save(j); err != nil {
+				t.Errorf("Error saving job: %s", err.Error())
+				return nil
+			}
+		}
+
+		if err := qb.Prune(1); err != nil {
+			t.Errorf("Error pruning jobs: %s", err.Error())
+			return nil
+		}
+
+		// the most recent job and unfinished jobs are kept
+		results, _, err := qb.Query(nil, nil)
+		if err != nil {
+			t.Errorf("Error querying jobs: %s", err.Error())
+			return nil
+		}
+
+		var ids []int
+		for _, j := range results {
+			ids = append(ids, j.ID)
+		}
+		assert.ElementsMatch(t, []int{2001, 2004}, ids)
+
+		return nil
+	})
+}
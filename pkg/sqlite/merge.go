@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// checkMergeIDs returns an error if the destination of a merge is one of the
+// sources.
+func checkMergeIDs(source []int, destination int) error {
+	for _, id := range source {
+		if id == destination {
+			return errors.New("cannot merge where source == destination")
+		}
+	}
+
+	return nil
+}
+
+// moveJoins moves the rows of the join tables from each source object to the
+// destination object, where idColumn is the column holding the id of the
+// merged objects. joinTables maps each table to the column identifying the
+// related object, or to an empty string if an object may have only one row
+// in the table. Rows relating the destination to the same object are left
+// with the source, to be deleted along with it.
+func moveJoins(tx dbi, joinTables map[string]string, idColumn string, source []int, destination int) error {
+	// sources are moved one at a time so that relationships shared by the
+	// sources are not duplicated
+	for _, id := range source {
+		for table, column := range joinTables {
+			exists := "o." + idColumn + " = ?"
+			if column != "" {
+				exists += " AND o." + column + " = " + table + "." + column
+			}
+
+			_, err := tx.Exec(`UPDATE `+table+`
+SET `+idColumn+` = ?
+WHERE `+idColumn+` = ?
+AND NOT EXISTS(SELECT 1 FROM `+table+` o WHERE `+exists+`)`,
+				destination, id, destination,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// splitAliases splits a comma-separated list of aliases.
+func splitAliases(aliases string) []string {
+	var ret []string
+	for _, alias := range strings.Split(aliases, ",") {
+		ret = append(ret, strings.TrimSpace(alias))
+	}
+
+	return ret
+}
+
+// combineAliases returns the aliases without duplicates, empty aliases or
+// the name of the merged object.
+func combineAliases(name string, aliases []string) []string {
+	seen := map[string]bool{
+		strings.ToLower(name): true,
+	}
+
+	var ret []string
+	for _, alias := range aliases {
+		key := strings.ToLower(alias)
+		if alias == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		ret = append(ret, alias)
+	}
+
+	return ret
+}
+
+// firstNonEmpty returns the first of the values that is not empty.
+func firstNonEmpty(values ...sql.NullString) sql.NullString {
+	for _, v := range values {
+		if v.Valid && v.String != "" {
+			return v
+		}
+	}
+
+	return sql.NullString{}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/stashapp/stash/pkg/models"
 )
@@ -31,6 +32,10 @@ func (qb *movieQueryBuilder) Create(newObject models.Movie) (*models.Movie, erro
 		return nil, err
 	}
 
+	if err := moviesFTS.update(qb.tx, ret.ID); err != nil {
+		return nil, err
+	}
+
 	return &ret, nil
 }
 
@@ -40,6 +45,10 @@ func (qb *movieQueryBuilder) Update(updatedObject models.MoviePartial) (*models.
 		return nil, err
 	}
 
+	if err := moviesFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.Find(updatedObject.ID)
 }
 
@@ -49,11 +58,87 @@ func (qb *movieQueryBuilder) UpdateFull(updatedObject models.Movie) (*models.Mov
 		return nil, err
 	}
 
+	if err := moviesFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.Find(updatedObject.ID)
 }
 
 func (qb *movieQueryBuilder) Destroy(id int) error {
-	return qb.destroyExisting([]int{id})
+	if err := qb.destroyExisting([]int{id}); err != nil {
+		return err
+	}
+
+	return moviesFTS.update(qb.tx, id)
+}
+
+// Merge moves the scenes of the source movies to the destination movie,
+// then destroys the source movies. The names and aliases of the sources are
+// added to the aliases of the destination, and the images and url of the
+// sources are used where the destination has none.
+func (qb *movieQueryBuilder) Merge(source []int, destination int) error {
+	if len(source) == 0 {
+		return nil
+	}
+
+	if err := checkMergeIDs(source, destination); err != nil {
+		return err
+	}
+
+	dest, err := qb.Find(destination)
+	if err != nil {
+		return err
+	}
+	if dest == nil {
+		return fmt.Errorf("movie with id %d not found", destination)
+	}
+
+	aliases := splitAliases(dest.Aliases.String)
+	urls := []sql.NullString{dest.URL}
+	for _, id := range source {
+		s, err := qb.Find(id)
+		if err != nil {
+			return err
+		}
+		if s == nil {
+			return fmt.Errorf("movie with id %d not found", id)
+		}
+
+		aliases = append(aliases, s.Name.String)
+		aliases = append(aliases, splitAliases(s.Aliases.String)...)
+		urls = append(urls, s.URL)
+	}
+
+	// the column identifying the related object of each join table
+	joinTables := map[string]string{
+		moviesScenesTable: sceneIDColumn,
+		"movies_images":   "",
+	}
+
+	if err := moveJoins(qb.tx, joinTables, movieIDColumn, source, destination); err != nil {
+		return err
+	}
+
+	mergedAliases := sql.NullString{String: strings.Join(combineAliases(dest.Name.String, aliases), ", ")}
+	mergedAliases.Valid = mergedAliases.String != ""
+	url := firstNonEmpty(urls...)
+
+	if _, err := qb.Update(models.MoviePartial{
+		ID:      destination,
+		Aliases: &mergedAliases,
+		URL:     &url,
+	}); err != nil {
+		return err
+	}
+
+	for _, id := range source {
+		if err := qb.Destroy(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (qb *movieQueryBuilder) Find(id int) (*models.Movie, error) {
@@ -144,15 +229,18 @@ func (qb *movieQueryBuilder) Query(movieFilter *models.MovieFilterType, findFilt
 	distinctIDs(&query, movieTable)
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"movies.name"}
-		query.parseQueryString(searchColumns, *q)
+		query.addFullTextSearch(moviesFTS, *q)
 	}
 
 	filter := qb.makeFilter(movieFilter)
 
 	query.addFilter(filter)
 
-	query.sortAndPagination = qb.getMovieSort(findFilter) + getPagination(findFilter)
+	sort, ok := query.getRelevanceSort(movieTable, findFilter)
+	if !ok {
+		sort = qb.getMovieSort(findFilter)
+	}
+	query.sortAndPagination = sort + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
@@ -328,6 +328,64 @@ func TestMovieDestroyMovieImages(t *testing.T) {
 	}
 }
 
+func TestMovieMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	// merge tests - perform these in a transaction that we'll rollback
+	if err := withRollbackTxn(func(r models.Repository) error {
+		qb := r.Movie()
+
+		// try merging into same movie
+		err := qb.Merge([]int{movieIDs[movieIdxWithScene]}, movieIDs[movieIdxWithScene])
+		assert.NotNil(err)
+
+		const name = "merge_destination"
+		dest, err := qb.Create(models.Movie{
+			Name:     sql.NullString{String: name, Valid: true},
+			Checksum: md5.FromString(name),
+			Aliases:  sql.NullString{String: "alias1", Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		srcIDs := []int{movieIDs[movieIdxWithScene], movieIDs[movieIdxWithStudio]}
+		if err := qb.Merge(srcIDs, dest.ID); err != nil {
+			return err
+		}
+
+		for _, id := range srcIDs {
+			m, err := qb.Find(id)
+			if err != nil {
+				return err
+			}
+			assert.Nil(m)
+		}
+
+		merged, err := qb.Find(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(strings.Join([]string{
+			"alias1",
+			movieNames[movieIdxWithScene],
+			movieNames[movieIdxWithStudio],
+		}, ", "), merged.Aliases.String)
+		assert.Equal(getMovieNullStringValue(movieIdxWithScene, urlField), merged.URL)
+
+		movies, err := r.Scene().GetMovies(sceneIDs[sceneIdxWithMovie])
+		if err != nil {
+			return err
+		}
+		assert.Len(movies, 1)
+		assert.Equal(dest.ID, movies[0].MovieID)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 // TODO Update
 // TODO Destroy
 // TODO Find
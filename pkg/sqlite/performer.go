@@ -16,7 +16,7 @@ const performersTagsTable = "performers_tags"
 const performersImageTable = "performers_image" // performer cover image
 
 var countPerformersForTagQuery = `
-SELECT tag_id AS id FROM performers_tags
+SELECT performer_id AS id FROM performers_tags
 WHERE performers_tags.tag_id = ?
 GROUP BY performers_tags.performer_id
 `
@@ -41,6 +41,10 @@ func (qb *performerQueryBuilder) Create(newObject models.Performer) (*models.Per
 		return nil, err
 	}
 
+	if err := performersFTS.update(qb.tx, ret.ID); err != nil {
+		return nil, err
+	}
+
 	return &ret, nil
 }
 
@@ -50,6 +54,10 @@ func (qb *performerQueryBuilder) Update(updatedObject models.PerformerPartial) (
 		return nil, err
 	}
 
+	if err := performersFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	var ret models.Performer
 	if err := qb.get(updatedObject.ID, &ret); err != nil {
 		return nil, err
@@ -64,6 +72,10 @@ func (qb *performerQueryBuilder) UpdateFull(updatedObject models.Performer) (*mo
 		return nil, err
 	}
 
+	if err := performersFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	var ret models.Performer
 	if err := qb.get(updatedObject.ID, &ret); err != nil {
 		return nil, err
@@ -79,24 +91,115 @@ func (qb *performerQueryBuilder) Destroy(id int) error {
 		return err
 	}
 
-	return qb.destroyExisting([]int{id})
+	if err := qb.destroyExisting([]int{id}); err != nil {
+		return err
+	}
+
+	return performersFTS.update(qb.tx, id)
 }
 
-func (qb *performerQueryBuilder) Find(id int) (*models.Performer, error) {
-	var ret models.Performer
-	if err := qb.get(id, &ret); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
+// Merge moves the scenes, images, galleries, tags, stash ids and
+// restriction profiles of the source performers to the destination
+// performer, then destroys the source performers. The names and aliases of
+// the sources are added to the aliases of the destination, and the cover
+// image and urls of the sources are used where the destination has none.
+func (qb *performerQueryBuilder) Merge(source []int, destination int) error {
+	if len(source) == 0 {
+		return nil
+	}
+
+	if err := checkMergeIDs(source, destination); err != nil {
+		return err
+	}
+
+	dest, err := qb.find(destination)
+	if err != nil {
+		return err
+	}
+	if dest == nil {
+		return fmt.Errorf("performer with id %d not found", destination)
+	}
+
+	aliases := splitAliases(dest.Aliases.String)
+	urls := []sql.NullString{dest.URL}
+	twitters := []sql.NullString{dest.Twitter}
+	instagrams := []sql.NullString{dest.Instagram}
+	for _, id := range source {
+		s, err := qb.find(id)
+		if err != nil {
+			return err
+		}
+		if s == nil {
+			return fmt.Errorf("performer with id %d not found", id)
 		}
+
+		aliases = append(aliases, s.Name.String)
+		aliases = append(aliases, splitAliases(s.Aliases.String)...)
+		urls = append(urls, s.URL)
+		twitters = append(twitters, s.Twitter)
+		instagrams = append(instagrams, s.Instagram)
+	}
+
+	// the column identifying the related object of each join table
+	joinTables := map[string]string{
+		performersScenesTable:              sceneIDColumn,
+		performersImagesTable:              imageIDColumn,
+		performersGalleriesTable:           galleryIDColumn,
+		performersTagsTable:                tagIDColumn,
+		"performer_stash_ids":              "endpoint",
+		restrictionProfilesPerformersTable: restrictionProfileIDColumn,
+		performersImageTable:               "",
+	}
+
+	if err := moveJoins(qb.tx, joinTables, performerIDColumn, source, destination); err != nil {
+		return err
+	}
+
+	mergedAliases := sql.NullString{String: strings.Join(combineAliases(dest.Name.String, aliases), ", ")}
+	mergedAliases.Valid = mergedAliases.String != ""
+	url := firstNonEmpty(urls...)
+	twitter := firstNonEmpty(twitters...)
+	instagram := firstNonEmpty(instagrams...)
+
+	if _, err := qb.Update(models.PerformerPartial{
+		ID:        destination,
+		Aliases:   &mergedAliases,
+		URL:       &url,
+		Twitter:   &twitter,
+		Instagram: &instagram,
+	}); err != nil {
+		return err
+	}
+
+	for _, id := range source {
+		if err := qb.Destroy(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (qb *performerQueryBuilder) Find(id int) (*models.Performer, error) {
+	if restricted, err := qb.isRestricted(id); err != nil || restricted {
 		return nil, err
 	}
-	return &ret, nil
+
+	return qb.find(id)
 }
 
+// FindMany returns the performers with the provided ids. Performers hidden
+// by the content restriction are omitted.
 func (qb *performerQueryBuilder) FindMany(ids []int) ([]*models.Performer, error) {
 	var performers []*models.Performer
 	for _, id := range ids {
-		performer, err := qb.Find(id)
+		if restricted, err := qb.isRestricted(id); err != nil {
+			return nil, err
+		} else if restricted {
+			continue
+		}
+
+		performer, err := qb.find(id)
 		if err != nil {
 			return nil, err
 		}
@@ -111,6 +214,17 @@ func (qb *performerQueryBuilder) FindMany(ids []int) ([]*models.Performer, error
 	return performers, nil
 }
 
+func (qb *performerQueryBuilder) find(id int) (*models.Performer, error) {
+	var ret models.Performer
+	if err := qb.get(id, &ret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
 func (qb *performerQueryBuilder) FindBySceneID(sceneID int) ([]*models.Performer, error) {
 	query := selectAll("performers") + `
 		LEFT JOIN performers_scenes as scenes_join on scenes_join.performer_id = performers.id
@@ -140,7 +254,7 @@ func (qb *performerQueryBuilder) FindByGalleryID(galleryID int) ([]*models.Perfo
 
 func (qb *performerQueryBuilder) FindNamesBySceneID(sceneID int) ([]*models.Performer, error) {
 	query := `
-		SELECT performers.name FROM performers
+		SELECT performers.id, performers.name FROM performers
 		LEFT JOIN performers_scenes as scenes_join on scenes_join.performer_id = performers.id
 		WHERE scenes_join.scene_id = ?
 	`
@@ -164,11 +278,11 @@ func (qb *performerQueryBuilder) FindByNames(names []string, nocase bool) ([]*mo
 
 func (qb *performerQueryBuilder) CountByTagID(tagID int) (int, error) {
 	args := []interface{}{tagID}
-	return qb.runCountQuery(qb.buildCountQuery(countPerformersForTagQuery), args)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery(countPerformersForTagQuery), args)
 }
 
 func (qb *performerQueryBuilder) Count() (int, error) {
-	return qb.runCountQuery(qb.buildCountQuery("SELECT performers.id FROM performers"), nil)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery("SELECT performers.id FROM performers"), nil)
 }
 
 func (qb *performerQueryBuilder) All() ([]*models.Performer, error) {
@@ -307,10 +421,10 @@ func (qb *performerQueryBuilder) Query(performerFilter *models.PerformerFilterTy
 
 	query := qb.newQuery()
 	distinctIDs(&query, performerTable)
+	query.addWhere(qb.restrictionClause())
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"performers.name", "performers.aliases"}
-		query.parseQueryString(searchColumns, *q)
+		query.addFullTextSearch(performersFTS, *q)
 	}
 
 	if err := qb.validateFilter(performerFilter); err != nil {
@@ -320,7 +434,11 @@ func (qb *performerQueryBuilder) Query(performerFilter *models.PerformerFilterTy
 
 	query.addFilter(filter)
 
-	query.sortAndPagination = qb.getPerformerSort(findFilter) + getPagination(findFilter)
+	sort, ok := query.getRelevanceSort(performerTable, findFilter)
+	if !ok {
+		sort = qb.getPerformerSort(findFilter)
+	}
+	query.sortAndPagination = sort + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
@@ -542,7 +660,7 @@ func (qb *performerQueryBuilder) getPerformerSort(findFilter *models.FindFilterT
 
 func (qb *performerQueryBuilder) queryPerformers(query string, args []interface{}) ([]*models.Performer, error) {
 	var ret models.Performers
-	if err := qb.query(query, args, &ret); err != nil {
+	if err := qb.query(qb.restrictQuery(query), args, &ret); err != nil {
 		return nil, err
 	}
 
@@ -990,6 +990,118 @@ func TestPerformerQuerySortScenesCount(t *testing.T) {
 	})
 }
 
+func TestPerformerMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	// merge tests - perform these in a transaction that we'll rollback
+	if err := withRollbackTxn(func(r models.Repository) error {
+		qb := r.Performer()
+
+		// try merging into same performer
+		err := qb.Merge([]int{performerIDs[performerIdxWithScene]}, performerIDs[performerIdxWithScene])
+		assert.NotNil(err)
+
+		const name = "merge_destination"
+		dest, err := qb.Create(models.Performer{
+			Name:     sql.NullString{String: name, Valid: true},
+			Checksum: md5.FromString(name),
+			Favorite: sql.NullBool{Bool: false, Valid: true},
+			Aliases:  sql.NullString{String: "alias1, " + performerNames[performerIdxWithImage], Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := qb.UpdateStashIDs(dest.ID, []models.StashID{{Endpoint: "endpoint1", StashID: "dest"}}); err != nil {
+			return err
+		}
+		if err := qb.UpdateStashIDs(performerIDs[performerIdxWithScene], []models.StashID{
+			{Endpoint: "endpoint1", StashID: "source"},
+			{Endpoint: "endpoint2", StashID: "source"},
+		}); err != nil {
+			return err
+		}
+
+		srcIdxs := []int{
+			performerIdxWithScene,
+			performerIdxWithImage,
+			performerIdxWithGallery,
+			performerIdxWithTag,
+		}
+		var srcIDs []int
+		for _, idx := range srcIdxs {
+			srcIDs = append(srcIDs, performerIDs[idx])
+		}
+
+		if err := qb.Merge(srcIDs, dest.ID); err != nil {
+			return err
+		}
+
+		for _, id := range srcIDs {
+			p, err := qb.Find(id)
+			if err != nil {
+				return err
+			}
+			assert.Nil(p)
+		}
+
+		merged, err := qb.Find(dest.ID)
+		if err != nil {
+			return err
+		}
+
+		// source names are added once
+		assert.Equal(strings.Join([]string{
+			"alias1",
+			performerNames[performerIdxWithImage],
+			performerNames[performerIdxWithScene],
+			performerNames[performerIdxWithGallery],
+			performerNames[performerIdxWithTag],
+		}, ", "), merged.Aliases.String)
+		assert.Equal(getPerformerNullStringValue(performerIdxWithScene, urlField), merged.URL)
+
+		performers, err := qb.FindBySceneID(sceneIDs[sceneIdxWithPerformer])
+		if err != nil {
+			return err
+		}
+		assert.Len(performers, 1)
+		assert.Equal(dest.ID, performers[0].ID)
+
+		performers, err = qb.FindByImageID(imageIDs[imageIdxWithPerformer])
+		if err != nil {
+			return err
+		}
+		assert.Len(performers, 1)
+		assert.Equal(dest.ID, performers[0].ID)
+
+		performers, err = qb.FindByGalleryID(galleryIDs[galleryIdxWithPerformer])
+		if err != nil {
+			return err
+		}
+		assert.Len(performers, 1)
+		assert.Equal(dest.ID, performers[0].ID)
+
+		destTagIDs, err := qb.GetTagIDs(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal([]int{tagIDs[tagIdxWithPerformer]}, destTagIDs)
+
+		stashIDs, err := qb.GetStashIDs(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.ElementsMatch([]*models.StashID{
+			{Endpoint: "endpoint1", StashID: "dest"},
+			{Endpoint: "endpoint2", StashID: "source"},
+		}, stashIDs)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 // TODO Update
 // TODO Destroy
 // TODO Find
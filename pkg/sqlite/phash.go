@@ -0,0 +1,386 @@
+package sqlite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stashapp/stash/pkg/database"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// phashRefreshBatchSize is the number of changed objects reloaded into a
+// phash index per query.
+const phashRefreshBatchSize = 500
+
+type phashEntry struct {
+	ID       int     `db:"id"`
+	Phash    int64   `db:"phash"`
+	Size     float64 `db:"size"`
+	Duration float64 `db:"duration"`
+}
+
+// phashIndex is an in-memory index of the phashes of a table, used to find
+// duplicates without comparing every pair of phashes. It is loaded on first
+// use, and then only the objects changed by committed transactions are
+// reloaded.
+type phashIndex struct {
+	tableName string
+	// durationColumn is the column holding the duration of the objects.
+	// Empty if the objects have no duration.
+	durationColumn string
+
+	mutex sync.Mutex
+	// db is the database the index was loaded from. The index is reloaded
+	// if the database is reopened.
+	db      *sqlx.DB
+	tree    *utils.PhashTree
+	entries map[int]phashEntry
+
+	// changed holds the ids of the objects changed by transactions that
+	// have not yet finished.
+	changed map[dbi][]int
+	// stale holds the ids of the objects changed by committed transactions
+	// that have not been reloaded.
+	stale map[int]struct{}
+}
+
+func newPhashIndex(tableName string, durationColumn string) *phashIndex {
+	return &phashIndex{
+		tableName:      tableName,
+		durationColumn: durationColumn,
+		changed:        make(map[dbi][]int),
+		stale:          make(map[int]struct{}),
+	}
+}
+
+var (
+	scenePhashIndex = newPhashIndex(sceneTable, "duration")
+	imagePhashIndex = newPhashIndex(imageTable, "")
+
+	phashIndexes = map[string]*phashIndex{
+		sceneTable: scenePhashIndex,
+		imageTable: imagePhashIndex,
+	}
+)
+
+// invalidate marks the object with the provided id as changed by tx.
+func (idx *phashIndex) invalidate(tx dbi, id int) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if _, ok := tx.(*sqlx.Tx); !ok {
+		// not in a transaction, so the change is already visible
+		idx.stale[id] = struct{}{}
+		return
+	}
+
+	idx.changed[tx] = append(idx.changed[tx], id)
+}
+
+// finish is called when tx is committed or rolled back.
+func (idx *phashIndex) finish(tx dbi, committed bool) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if committed {
+		for _, id := range idx.changed[tx] {
+			idx.stale[id] = struct{}{}
+		}
+	}
+
+	delete(idx.changed, tx)
+}
+
+// finishPhashIndexes is called when tx is committed or rolled back, so that
+// the phash indexes reload the objects changed by committed transactions.
+func finishPhashIndexes(tx dbi, committed bool) {
+	for _, idx := range phashIndexes {
+		idx.finish(tx, committed)
+	}
+}
+
+func (idx *phashIndex) selectQuery() string {
+	duration := "0"
+	if idx.durationColumn != "" {
+		duration = "COALESCE(" + idx.durationColumn + ", 0)"
+	}
+
+	return fmt.Sprintf("SELECT id, phash, COALESCE(CAST(size AS REAL), 0) AS size, %s AS duration FROM %s WHERE phash IS NOT NULL", duration, idx.tableName)
+}
+
+func (idx *phashIndex) queryEntries(tx dbi, query string, fn func(e phashEntry)) error {
+	rows, err := tx.Queryx(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e phashEntry
+		if err := rows.StructScan(&e); err != nil {
+			return err
+		}
+
+		fn(e)
+	}
+
+	return rows.Err()
+}
+
+func (idx *phashIndex) load(tx dbi) (*utils.PhashTree, map[int]phashEntry, error) {
+	tree := &utils.PhashTree{}
+	entries := make(map[int]phashEntry)
+	if err := idx.queryEntries(tx, idx.selectQuery(), func(e phashEntry) {
+		tree.Add(e.Phash, e.ID)
+		entries[e.ID] = e
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return tree, entries, nil
+}
+
+// refresh brings the index up to date with the committed state of the
+// database. Must be called with the mutex held.
+func (idx *phashIndex) refresh() error {
+	// read outside of the current transaction so that changes committed
+	// since it began are seen
+	db := database.DB
+	if idx.tree == nil || idx.db != db {
+		tree, entries, err := idx.load(db)
+		if err != nil {
+			return err
+		}
+
+		idx.db = db
+		idx.tree = tree
+		idx.entries = entries
+		idx.stale = make(map[int]struct{})
+		return nil
+	}
+
+	stale := make([]int, 0, len(idx.stale))
+	for id := range idx.stale {
+		stale = append(stale, id)
+	}
+
+	for len(stale) > 0 {
+		batch := stale
+		if len(batch) > phashRefreshBatchSize {
+			batch = batch[:phashRefreshBatchSize]
+		}
+		stale = stale[len(batch):]
+
+		for _, id := range batch {
+			if e, found := idx.entries[id]; found {
+				idx.tree.Remove(e.Phash, id)
+				delete(idx.entries, id)
+			}
+		}
+
+		// ids are embedded to avoid the limit on the number of arguments
+		query := fmt.Sprintf("%s AND id IN (%s)", idx.selectQuery(), strings.Join(intslice.IntSliceToStringSlice(batch), ","))
+		if err := idx.queryEntries(db, query, func(e phashEntry) {
+			idx.tree.Add(e.Phash, e.ID)
+			idx.entries[e.ID] = e
+		}); err != nil {
+			// reload everything next time
+			idx.tree = nil
+			return err
+		}
+
+		for _, id := range batch {
+			delete(idx.stale, id)
+		}
+	}
+
+	return nil
+}
+
+// duplicates returns the groups of ids of the objects with phashes within
+// distance of another object in the group, as seen by tx. If durationDiff
+// is not negative, groups are split so that the durations of the objects in
+// each group are within durationDiff of another. Groups are ordered by
+// the total size of the objects, and objects by size.
+func (idx *phashIndex) duplicates(tx dbi, distance int, durationDiff float64) ([][]int, error) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	var tree *utils.PhashTree
+	var entries map[int]phashEntry
+	if len(idx.changed[tx]) > 0 {
+		// the changes of the transaction are not visible to the shared
+		// index, so build one for the transaction
+		var err error
+		tree, entries, err = idx.load(tx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if err := idx.refresh(); err != nil {
+			return nil, err
+		}
+		tree, entries = idx.tree, idx.entries
+	}
+
+	groups := tree.FindDuplicates(distance)
+	if durationDiff >= 0 && idx.durationColumn != "" {
+		groups = splitGroupsByDuration(groups, entries, durationDiff)
+	}
+
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			a, b := entries[group[i]], entries[group[j]]
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+			return a.ID < b.ID
+		})
+	}
+
+	// groups don't overlap, so they are keyed by their first id
+	sizes := make(map[int]float64, len(groups))
+	for _, group := range groups {
+		for _, id := range group {
+			sizes[group[0]] += entries[id].Size
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := sizes[groups[i][0]], sizes[groups[j][0]]
+		if a != b {
+			return a > b
+		}
+		return groups[i][0] < groups[j][0]
+	})
+
+	return groups, nil
+}
+
+// splitGroupsByDuration splits the groups into groups of objects with
+// durations within durationDiff of another object in the group.
+func splitGroupsByDuration(groups [][]int, entries map[int]phashEntry, durationDiff float64) [][]int {
+	var ret [][]int
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			return entries[group[i]].Duration < entries[group[j]].Duration
+		})
+
+		start := 0
+		for i := 1; i <= len(group); i++ {
+			if i < len(group) && entries[group[i]].Duration-entries[group[i-1]].Duration <= durationDiff {
+				continue
+			}
+
+			if i-start > 1 {
+				ret = append(ret, append([]int(nil), group[start:i]...))
+			}
+			start = i
+		}
+	}
+
+	return ret
+}
+
+// findPhashDuplicateIDs returns the groups of ids of the objects of the
+// repository table matching the options, excluding those hidden by the
+// content restriction. The groups are paged using the find filter of the
+// options.
+func (r *repository) findPhashDuplicateIDs(options models.DuplicateOptions) ([][]int, error) {
+	idx := phashIndexes[r.tableName]
+	groups, err := idx.duplicates(r.tx, options.Distance, options.DurationDiff)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.restrictedIDs != "" {
+		restricted, err := r.runIdsQuery(r.restrictedIDs, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		hidden := make(map[int]bool, len(restricted))
+		for _, id := range restricted {
+			hidden[id] = true
+		}
+
+		var filtered [][]int
+		for _, group := range groups {
+			var visible []int
+			for _, id := range group {
+				if !hidden[id] {
+					visible = append(visible, id)
+				}
+			}
+
+			if len(visible) > 1 {
+				filtered = append(filtered, visible)
+			}
+		}
+		groups = filtered
+	}
+
+	if options.FindFilter != nil && !options.FindFilter.IsGetAll() {
+		perPage := options.FindFilter.GetPageSize()
+		start := (options.FindFilter.GetPage() - 1) * perPage
+		if start > len(groups) {
+			start = len(groups)
+		}
+		end := start + perPage
+		if end > len(groups) {
+			end = len(groups)
+		}
+		groups = groups[start:end]
+	}
+
+	return groups, nil
+}
+
+// phashDuplicatedCriterionHandler filters the objects of the repository
+// table by whether another object has the same phash, or a phash within the
+// distance of the filter if set.
+func phashDuplicatedCriterionHandler(r *repository, duplicatedFilter *models.PHashDuplicationCriterionInput) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if duplicatedFilter == nil {
+			return
+		}
+
+		duplicated := duplicatedFilter.Duplicated == nil || *duplicatedFilter.Duplicated
+		table := r.tableName
+
+		if duplicatedFilter.Distance == nil || *duplicatedFilter.Distance <= 0 {
+			v := "="
+			if duplicated {
+				v = ">"
+			}
+
+			subquery := fmt.Sprintf("(SELECT id FROM %[1]s JOIN (SELECT phash FROM %[1]s GROUP BY phash HAVING COUNT(phash) %[2]s 1) dupes on %[1]s.phash = dupes.phash)", table, v)
+			f.addInnerJoin(subquery, "phdupes", table+".id = phdupes.id")
+			return
+		}
+
+		groups, err := phashIndexes[table].duplicates(r.tx, *duplicatedFilter.Distance, -1)
+		if err != nil {
+			f.setError(err)
+			return
+		}
+
+		var ids []int
+		for _, group := range groups {
+			ids = append(ids, group...)
+		}
+
+		in := "IN"
+		if !duplicated {
+			in = "NOT IN"
+		}
+
+		// ids are embedded to avoid the limit on the number of arguments
+		f.addWhere(fmt.Sprintf("%s.id %s (%s)", table, in, strings.Join(intslice.IntSliceToStringSlice(ids), ",")))
+	}
+}
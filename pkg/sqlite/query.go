@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/stashapp/stash/pkg/logger"
-	"github.com/stashapp/stash/pkg/models"
 )
 
 type queryBuilder struct {
@@ -23,6 +22,9 @@ type queryBuilder struct {
 
 	sortAndPagination string
 
+	// hasSearch is true if the query joins the matches of a full-text search
+	hasSearch bool
+
 	err error
 }
 
@@ -146,7 +148,7 @@ func (qb *queryBuilder) addFilter(f *filterBuilder) {
 
 	clause, args := f.generateWithClauses()
 	if len(clause) > 0 {
-		qb.addWith(f.recursiveWith, clause)
+		qb.addWith(f.hasRecursiveWith(), clause)
 	}
 
 	if len(args) > 0 {
@@ -174,38 +176,3 @@ func (qb *queryBuilder) addFilter(f *filterBuilder) {
 
 	qb.addJoins(f.getAllJoins()...)
 }
-
-func (qb *queryBuilder) parseQueryString(columns []string, q string) {
-	specs := models.ParseSearchString(q)
-
-	for _, t := range specs.MustHave {
-		var clauses []string
-
-		for _, column := range columns {
-			clauses = append(clauses, column+" LIKE ?")
-			qb.addArg(like(t))
-		}
-
-		qb.addWhere("(" + strings.Join(clauses, " OR ") + ")")
-	}
-
-	for _, t := range specs.MustNot {
-		for _, column := range columns {
-			qb.addWhere(coalesce(column) + " NOT LIKE ?")
-			qb.addArg(like(t))
-		}
-	}
-
-	for _, set := range specs.AnySets {
-		var clauses []string
-
-		for _, column := range columns {
-			for _, v := range set {
-				clauses = append(clauses, column+" LIKE ?")
-				qb.addArg(like(v))
-			}
-		}
-
-		qb.addWhere("(" + strings.Join(clauses, " OR ") + ")")
-	}
-}
@@ -24,6 +24,10 @@ type repository struct {
 	tx        dbi
 	tableName string
 	idColumn  string
+
+	// restrictedIDs is a query selecting the ids of the rows hidden by the
+	// content restriction. Empty if there is no restriction.
+	restrictedIDs string
 }
 
 func (r *repository) get(id int, dest interface{}) error {
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// intList returns the ids as a comma-separated list for use in an IN
+// clause. The ids are integers so they are safe to include in the query.
+func intList(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+
+	return strings.Join(s, ", ")
+}
+
+type restrictedIDsBuilder struct {
+	queries []string
+}
+
+// addJoin adds the ids of the rows joined to the restricted ids using
+// joinTable.
+func (b *restrictedIDsBuilder) addJoin(joinTable string, idColumn string, fkColumn string, ids []int) {
+	if len(ids) > 0 {
+		b.queries = append(b.queries, fmt.Sprintf("SELECT %s FROM %s WHERE %s IN (%s)", idColumn, joinTable, fkColumn, intList(ids)))
+	}
+}
+
+// addColumn adds the ids of the rows of table where column is one of the
+// restricted ids.
+func (b *restrictedIDsBuilder) addColumn(table string, column string, ids []int) {
+	b.addJoin(table, idColumn, column, ids)
+}
+
+// addIDs adds the restricted ids themselves.
+func (b *restrictedIDsBuilder) addIDs(table string, ids []int) {
+	b.addColumn(table, idColumn, ids)
+}
+
+// addQuery adds the ids returned by query.
+func (b *restrictedIDsBuilder) addQuery(query string) {
+	if query != "" {
+		b.queries = append(b.queries, query)
+	}
+}
+
+func (b restrictedIDsBuilder) toSQL() string {
+	return strings.Join(b.queries, " UNION ")
+}
+
+// getRestrictedIDsQuery returns a query selecting the ids of the rows of
+// table hidden by the restriction. It returns an empty string if no rows are
+// hidden.
+func getRestrictedIDsQuery(table string, r *models.ContentRestriction) string {
+	if r.IsEmpty() {
+		return ""
+	}
+
+	var b restrictedIDsBuilder
+	switch table {
+	case sceneTable:
+		b.addJoin(scenesTagsTable, sceneIDColumn, tagIDColumn, r.TagIDs)
+		b.addColumn(sceneTable, studioIDColumn, r.StudioIDs)
+		b.addJoin(performersScenesTable, sceneIDColumn, performerIDColumn, r.PerformerIDs)
+	case imageTable:
+		b.addJoin(imagesTagsTable, imageIDColumn, tagIDColumn, r.TagIDs)
+		b.addColumn(imageTable, studioIDColumn, r.StudioIDs)
+		b.addJoin(performersImagesTable, imageIDColumn, performerIDColumn, r.PerformerIDs)
+	case galleryTable:
+		b.addJoin(galleriesTagsTable, galleryIDColumn, tagIDColumn, r.TagIDs)
+		b.addColumn(galleryTable, studioIDColumn, r.StudioIDs)
+		b.addJoin(performersGalleriesTable, galleryIDColumn, performerIDColumn, r.PerformerIDs)
+	case sceneMarkerTable:
+		b.addColumn(sceneMarkerTable, "primary_tag_id", r.TagIDs)
+		b.addJoin("scene_markers_tags", "scene_marker_id", tagIDColumn, r.TagIDs)
+		if scenes := getRestrictedIDsQuery(sceneTable, r); scenes != "" {
+			b.addQuery(fmt.Sprintf("SELECT id FROM %s WHERE %s IN (%s)", sceneMarkerTable, sceneIDColumn, scenes))
+		}
+	case tagTable:
+		b.addIDs(tagTable, r.TagIDs)
+	case studioTable:
+		b.addIDs(studioTable, r.StudioIDs)
+	case performerTable:
+		b.addIDs(performerTable, r.PerformerIDs)
+	}
+
+	return b.toSQL()
+}
+
+// restrict hides the rows of the repository table excluded by the
+// restriction from the queries of the repository that support it.
+func (r *repository) restrict(restriction *models.ContentRestriction) {
+	r.restrictedIDs = getRestrictedIDsQuery(r.tableName, restriction)
+}
+
+// restrictionClause returns a where clause excluding the restricted rows, or
+// an empty string if there are none.
+func (r *repository) restrictionClause() string {
+	if r.restrictedIDs == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s NOT IN (%s)", getColumn(r.tableName, r.idColumn), r.restrictedIDs)
+}
+
+// addRestrictionWhere adds a where clause excluding the restricted rows to
+// query, which must not have a where clause.
+func (r *repository) addRestrictionWhere(query string) string {
+	if clause := r.restrictionClause(); clause != "" {
+		return query + " WHERE " + clause
+	}
+
+	return query
+}
+
+// restrictQuery wraps query, which selects rows of the repository table,
+// to exclude the restricted rows.
+func (r *repository) restrictQuery(query string) string {
+	if r.restrictedIDs == "" {
+		return query
+	}
+
+	return fmt.Sprintf("SELECT * FROM (%s) AS %s WHERE %s", query, r.tableName, r.restrictionClause())
+}
+
+// buildRestrictedCountQuery returns a query counting the rows selected by
+// query, excluding the restricted rows. query must select the id column.
+func (r *repository) buildRestrictedCountQuery(query string) string {
+	if r.restrictedIDs == "" {
+		return r.buildCountQuery(query)
+	}
+
+	return fmt.Sprintf("SELECT COUNT(*) as count FROM (%s) as temp WHERE temp.%s NOT IN (%s)", query, r.idColumn, r.restrictedIDs)
+}
+
+// isRestricted returns true if the row with the provided id is hidden by
+// the restriction.
+func (r *repository) isRestricted(id int) (bool, error) {
+	if r.restrictedIDs == "" {
+		return false, nil
+	}
+
+	query := fmt.Sprintf("SELECT (? IN (%s)) as count", r.restrictedIDs)
+	c, err := r.runCountQuery(query, []interface{}{id})
+	if err != nil {
+		return false, err
+	}
+
+	return c > 0, nil
+}
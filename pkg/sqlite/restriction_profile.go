@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const restrictionProfileTable = "restriction_profiles"
+const restrictionProfileIDColumn = "restriction_profile_id"
+const restrictionProfilesTagsTable = "restriction_profiles_tags"
+const restrictionProfilesStudiosTable = "restriction_profiles_studios"
+const restrictionProfilesPerformersTable = "restriction_profiles_performers"
+
+type restrictionProfileQueryBuilder struct {
+	repository
+}
+
+func NewRestrictionProfileReaderWriter(tx dbi) *restrictionProfileQueryBuilder {
+	return &restrictionProfileQueryBuilder{
+		repository{
+			tx:        tx,
+			tableName: restrictionProfileTable,
+			idColumn:  idColumn,
+		},
+	}
+}
+
+func (qb *restrictionProfileQueryBuilder) Create(newObject models.RestrictionProfile) (*models.RestrictionProfile, error) {
+	var ret models.RestrictionProfile
+	if err := qb.insertObject(newObject, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *restrictionProfileQueryBuilder) Update(updatedObject models.RestrictionProfile) (*models.RestrictionProfile, error) {
+	const partial = false
+	if err := qb.update(updatedObject.ID, updatedObject, partial); err != nil {
+		return nil, err
+	}
+
+	var ret models.RestrictionProfile
+	if err := qb.get(updatedObject.ID, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *restrictionProfileQueryBuilder) Destroy(id int) error {
+	return qb.destroyExisting([]int{id})
+}
+
+func (qb *restrictionProfileQueryBuilder) Find(id int) (*models.RestrictionProfile, error) {
+	var ret models.RestrictionProfile
+	if err := qb.get(id, &ret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (qb *restrictionProfileQueryBuilder) FindByName(name string) (*models.RestrictionProfile, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE name = ? LIMIT 1`, restrictionProfileTable)
+
+	var ret models.RestrictionProfiles
+	if err := qb.query(query, []interface{}{name}, &ret); err != nil {
+		return nil, err
+	}
+
+	if len(ret) > 0 {
+		return ret[0], nil
+	}
+
+	return nil, nil
+}
+
+func (qb *restrictionProfileQueryBuilder) All() ([]*models.RestrictionProfile, error) {
+	var ret models.RestrictionProfiles
+	if err := qb.query(selectAll(restrictionProfileTable)+getSort("name", "ASC", restrictionProfileTable), nil, &ret); err != nil {
+		return nil, err
+	}
+
+	return []*models.RestrictionProfile(ret), nil
+}
+
+func (qb *restrictionProfileQueryBuilder) joinRepository(tableName string, fkColumn string) *joinRepository {
+	return &joinRepository{
+		repository: repository{
+			tx:        qb.tx,
+			tableName: tableName,
+			idColumn:  restrictionProfileIDColumn,
+		},
+		fkColumn: fkColumn,
+	}
+}
+
+func (qb *restrictionProfileQueryBuilder) tagsRepository() *joinRepository {
+	return qb.joinRepository(restrictionProfilesTagsTable, tagIDColumn)
+}
+
+func (qb *restrictionProfileQueryBuilder) studiosRepository() *joinRepository {
+	return qb.joinRepository(restrictionProfilesStudiosTable, studioIDColumn)
+}
+
+func (qb *restrictionProfileQueryBuilder) performersRepository() *joinRepository {
+	return qb.joinRepository(restrictionProfilesPerformersTable, performerIDColumn)
+}
+
+func (qb *restrictionProfileQueryBuilder) GetTagIDs(id int) ([]int, error) {
+	return qb.tagsRepository().getIDs(id)
+}
+
+func (qb *restrictionProfileQueryBuilder) UpdateTags(id int, tagIDs []int) error {
+	return qb.tagsRepository().replace(id, tagIDs)
+}
+
+func (qb *restrictionProfileQueryBuilder) GetStudioIDs(id int) ([]int, error) {
+	return qb.studiosRepository().getIDs(id)
+}
+
+func (qb *restrictionProfileQueryBuilder) UpdateStudios(id int, studioIDs []int) error {
+	return qb.studiosRepository().replace(id, studioIDs)
+}
+
+func (qb *restrictionProfileQueryBuilder) GetPerformerIDs(id int) ([]int, error) {
+	return qb.performersRepository().getIDs(id)
+}
+
+func (qb *restrictionProfileQueryBuilder) UpdatePerformers(id int, performerIDs []int) error {
+	return qb.performersRepository().replace(id, performerIDs)
+}
+
+func (qb *restrictionProfileQueryBuilder) GetContentRestriction(id int) (*models.ContentRestriction, error) {
+	// include all descendants of the excluded tags
+	tagQuery := `WITH RECURSIVE excluded(id) AS (
+	SELECT tag_id FROM ` + restrictionProfilesTagsTable + ` WHERE restriction_profile_id = ?
+	UNION
+	SELECT tr.child_id FROM tags_relations tr INNER JOIN excluded e ON e.id = tr.parent_id
+)
+SELECT id FROM excluded`
+
+	tagIDs, err := qb.runIdsQuery(tagQuery, []interface{}{id})
+	if err != nil {
+		return nil, err
+	}
+
+	studioIDs, err := qb.GetStudioIDs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	performerIDs, err := qb.GetPerformerIDs(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ContentRestriction{
+		TagIDs:       tagIDs,
+		StudioIDs:    studioIDs,
+		PerformerIDs: performerIDs,
+	}, nil
+}
@@ -0,0 +1,147 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+func withRestrictedReadTxn(r *models.ContentRestriction, f func(r models.ReaderRepository) error) error {
+	t := sqlite.NewTransactionManager()
+	return t.WithReadTxn(models.SetContentRestriction(context.TODO(), r), f)
+}
+
+func TestRestrictionProfileGetContentRestriction(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		qb := r.RestrictionProfile()
+
+		now := models.SQLiteTimestamp{Timestamp: time.Now()}
+		profile, err := qb.Create(models.RestrictionProfile{
+			Name:      "TestRestrictionProfileGetContentRestriction",
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			t.Errorf("Error creating restriction profile: %s", err.Error())
+			return nil
+		}
+
+		if err := qb.UpdateTags(profile.ID, []int{tagIDs[tagIdxWithGrandChild]}); err != nil {
+			t.Errorf("Error updating restriction profile tags: %s", err.Error())
+			return nil
+		}
+		if err := qb.UpdateStudios(profile.ID, []int{studioIDs[studioIdxWithScene]}); err != nil {
+			t.Errorf("Error updating restriction profile studios: %s", err.Error())
+			return nil
+		}
+
+		restriction, err := qb.GetContentRestriction(profile.ID)
+		if err != nil {
+			t.Errorf("Error getting content restriction: %s", err.Error())
+			return nil
+		}
+
+		// child tags are excluded with the parent
+		assert.ElementsMatch(t, []int{
+			tagIDs[tagIdxWithGrandChild],
+			tagIDs[tagIdxWithParentAndChild],
+			tagIDs[tagIdxWithGrandParent],
+		}, restriction.TagIDs)
+		assert.Equal(t, []int{studioIDs[studioIdxWithScene]}, restriction.StudioIDs)
+		assert.Len(t, restriction.PerformerIDs, 0)
+
+		return nil
+	})
+}
+
+func TestRestrictionProfileScenes(t *testing.T) {
+	var totalCount int
+	if err := withTxn(func(r models.Repository) error {
+		var err error
+		totalCount, err = r.Scene().Count()
+		return err
+	}); err != nil {
+		t.Errorf("Error counting scenes: %s", err.Error())
+		return
+	}
+
+	restriction := &models.ContentRestriction{
+		TagIDs:    []int{tagIDs[tagIdxWithScene]},
+		StudioIDs: []int{studioIDs[studioIdxWithScene]},
+	}
+	hiddenIDs := []int{sceneIDs[sceneIdxWithTag], sceneIDs[sceneIdxWithStudio]}
+
+	if err := withRestrictedReadTxn(restriction, func(r models.ReaderRepository) error {
+		sqb := r.Scene()
+
+		for _, id := range hiddenIDs {
+			scene, err := sqb.Find(id)
+			if err != nil {
+				return fmt.Errorf("Error finding scene: %s", err.Error())
+			}
+			assert.Nil(t, scene)
+		}
+
+		scenes, err := sqb.FindMany(append([]int{sceneIDs[sceneIdxWithPerformer]}, hiddenIDs...))
+		if err != nil {
+			return fmt.Errorf("Error finding scenes: %s", err.Error())
+		}
+		if assert.Len(t, scenes, 1) {
+			assert.Equal(t, sceneIDs[sceneIdxWithPerformer], scenes[0].ID)
+		}
+
+		count, err := sqb.Count()
+		if err != nil {
+			return fmt.Errorf("Error counting scenes: %s", err.Error())
+		}
+		assert.Equal(t, totalCount-len(hiddenIDs), count)
+
+		perPage := -1
+		result, err := sqb.Query(models.SceneQueryOptions{
+			QueryOptions: models.QueryOptions{
+				FindFilter: &models.FindFilterType{
+					PerPage: &perPage,
+				},
+				Count: true,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Error querying scenes: %s", err.Error())
+		}
+		assert.Equal(t, count, result.Count)
+		for _, id := range hiddenIDs {
+			assert.NotContains(t, result.IDs, id)
+		}
+
+		tagCount, err := sqb.CountByTagID(tagIDs[tagIdxWithScene])
+		if err != nil {
+			return fmt.Errorf("Error counting scenes: %s", err.Error())
+		}
+		assert.Zero(t, tagCount)
+
+		// the excluded tags and studios are hidden themselves
+		tag, err := r.Tag().Find(tagIDs[tagIdxWithScene])
+		if err != nil {
+			return fmt.Errorf("Error finding tag: %s", err.Error())
+		}
+		assert.Nil(t, tag)
+
+		studio, err := r.Studio().Find(studioIDs[studioIdxWithScene])
+		if err != nil {
+			return fmt.Errorf("Error finding studio: %s", err.Error())
+		}
+		assert.Nil(t, studio)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -4,6 +4,8 @@
 package sqlite_test
 
 import (
+	"encoding/json"
+	"strconv"
 	"testing"
 
 	"github.com/stashapp/stash/pkg/models"
@@ -39,6 +41,64 @@ func TestSavedFilterFindByMode(t *testing.T) {
 	})
 }
 
+func TestSavedFilterGroups(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		tagCriterion := func(idx int) *models.HierarchicalMultiCriterionInput {
+			return &models.HierarchicalMultiCriterionInput{
+				Value:    []string{strconv.Itoa(tagIDs[idx])},
+				Modifier: models.CriterionModifierIncludes,
+			}
+		}
+
+		sceneFilter := models.SceneFilterType{
+			Any: []*models.SceneFilterType{
+				{Tags: tagCriterion(tagIdx1WithScene)},
+				{
+					All: []*models.SceneFilterType{
+						{Tags: tagCriterion(tagIdx2WithScene)},
+						{Not: &models.SceneFilterType{Tags: tagCriterion(tagIdxWithScene)}},
+					},
+				},
+			},
+		}
+
+		filter, err := json.Marshal(sceneFilter)
+		if err != nil {
+			t.Errorf("Error encoding filter: %s", err.Error())
+			return nil
+		}
+
+		created, err := r.SavedFilter().Create(models.SavedFilter{
+			Name:   "filterWithGroups",
+			Mode:   models.FilterModeScenes,
+			Filter: string(filter),
+		})
+		if err != nil {
+			t.Errorf("Error creating saved filter: %s", err.Error())
+			return nil
+		}
+
+		savedFilter, err := r.SavedFilter().Find(created.ID)
+		if err != nil {
+			t.Errorf("Error finding saved filter: %s", err.Error())
+			return nil
+		}
+
+		var decoded models.SceneFilterType
+		if err := json.Unmarshal([]byte(savedFilter.Filter), &decoded); err != nil {
+			t.Errorf("Error decoding filter: %s", err.Error())
+			return nil
+		}
+
+		assert.Equal(t, sceneFilter, decoded)
+
+		// the decoded filter matches the same scenes
+		assert.Equal(t, queryScene(t, r.Scene(), &sceneFilter, nil), queryScene(t, r.Scene(), &decoded, nil))
+
+		return nil
+	})
+}
+
 func TestSavedFilterDestroy(t *testing.T) {
 	const filterName = "filterToDestroy"
 	const testFilter = "{}"
@@ -4,8 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/stashapp/stash/pkg/models"
@@ -24,6 +24,36 @@ const sceneCaptionCodeColumn = "language_code"
 const sceneCaptionFilenameColumn = "filename"
 const sceneCaptionTypeColumn = "caption_type"
 
+const sceneFilesTable = "scene_files"
+const scenesPlayHistoryTable = "scenes_play_history"
+const scenesUsersTable = "scenes_users"
+
+// sceneUserJoinAs is the alias of the scenes_users row of the current user.
+const sceneUserJoinAs = "scene_user"
+
+// sceneFileColumns are the columns of the scenes table that mirror the
+// primary file of the scene.
+var sceneFileColumns = []string{
+	"path",
+	"checksum",
+	"oshash",
+	"size",
+	"duration",
+	"video_codec",
+	"format",
+	"audio_codec",
+	"width",
+	"height",
+	"framerate",
+	"bitrate",
+	"file_mod_time",
+	"interactive",
+}
+
+var sceneFilesJoin = `
+INNER JOIN scene_files ON scene_files.scene_id = scenes.id
+`
+
 var scenesForPerformerQuery = selectAll(sceneTable) + `
 LEFT JOIN performers_scenes as performers_join on performers_join.scene_id = scenes.id
 WHERE performers_join.performer_id = ?
@@ -31,7 +61,7 @@ GROUP BY scenes.id
 `
 
 var countScenesForPerformerQuery = `
-SELECT performer_id FROM performers_scenes as performers_join
+SELECT scene_id AS id FROM performers_scenes as performers_join
 WHERE performer_id = ?
 GROUP BY scene_id
 `
@@ -48,7 +78,7 @@ GROUP BY scenes.id
 `
 
 var countScenesForTagQuery = `
-SELECT tag_id AS id FROM scenes_tags
+SELECT scene_id AS id FROM scenes_tags
 WHERE scenes_tags.tag_id = ?
 GROUP BY scenes_tags.scene_id
 `
@@ -69,29 +99,18 @@ SELECT id FROM scenes
 WHERE scenes.oshash is null
 `
 
-var findExactDuplicateQuery = `
-SELECT GROUP_CONCAT(id) as ids
-FROM scenes
-WHERE phash IS NOT NULL
-GROUP BY phash
-HAVING COUNT(phash) > 1
-ORDER BY SUM(size) DESC;
-`
-
-var findAllPhashesQuery = `
-SELECT id, phash
-FROM scenes
-WHERE phash IS NOT NULL
-ORDER BY size DESC
-`
-
 type sceneQueryBuilder struct {
 	repository
+
+	// userID is the id of the stored user making the request. If it is set,
+	// then the rating, o-counter, resume time and plays used by the filters
+	// and sorts are those of the user.
+	userID *int
 }
 
 func NewSceneReaderWriter(tx dbi) *sceneQueryBuilder {
 	return &sceneQueryBuilder{
-		repository{
+		repository: repository{
 			tx:        tx,
 			tableName: sceneTable,
 			idColumn:  idColumn,
@@ -99,12 +118,103 @@ func NewSceneReaderWriter(tx dbi) *sceneQueryBuilder {
 	}
 }
 
+// setUser sets the stored user whose scene data is used by the filters and
+// sorts. userID is nil if the request has no stored user.
+func (qb *sceneQueryBuilder) setUser(userID *int) {
+	qb.userID = userID
+}
+
+// userDataJoin returns the join of the scenes_users row of the current user.
+// It must only be used if the user is set.
+func (qb *sceneQueryBuilder) userDataJoin() join {
+	return join{
+		table:    scenesUsersTable,
+		as:       sceneUserJoinAs,
+		onClause: fmt.Sprintf("%[1]s.scene_id = scenes.id AND %[1]s.user_id = %[2]d", sceneUserJoinAs, *qb.userID),
+		joinType: "LEFT",
+	}
+}
+
+// userDataColumn returns the expression for column of the scene data of the
+// current user, or the column of the scenes table if the user is not set.
+// Scenes the user has no data for have the value def, or NULL if def is
+// empty.
+func (qb *sceneQueryBuilder) userDataColumn(column string, def string) string {
+	if qb.userID == nil {
+		return "scenes." + column
+	}
+
+	if def == "" {
+		return sceneUserJoinAs + "." + column
+	}
+
+	return fmt.Sprintf("COALESCE(%s.%s, %s)", sceneUserJoinAs, column, def)
+}
+
+// userDataCriterionHandler adds the join of the scene data of the current
+// user to the filter before handling the criterion.
+func (qb *sceneQueryBuilder) userDataCriterionHandler(isSet bool, handler criterionHandlerFunc) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if !isSet {
+			return
+		}
+
+		if qb.userID != nil {
+			j := qb.userDataJoin()
+			f.addLeftJoin(j.table, j.as, j.onClause)
+		}
+
+		handler(f)
+	}
+}
+
+// playHistoryWhere returns the where clause selecting the plays of the
+// scene by the current user, or the plays not attributed to a user if the
+// user is not set.
+func (qb *sceneQueryBuilder) playHistoryWhere() string {
+	userClause := "user_id IS NULL"
+	if qb.userID != nil {
+		userClause = fmt.Sprintf("user_id = %d", *qb.userID)
+	}
+
+	return fmt.Sprintf("%s.scene_id = scenes.id AND %s.%s", scenesPlayHistoryTable, scenesPlayHistoryTable, userClause)
+}
+
+// playCountColumn returns the expression for the number of plays of the
+// scene by the current user.
+func (qb *sceneQueryBuilder) playCountColumn() string {
+	return fmt.Sprintf("(SELECT COUNT(*) FROM %s WHERE %s)", scenesPlayHistoryTable, qb.playHistoryWhere())
+}
+
+// lastPlayedAtColumn returns the expression for the time the scene was last
+// played by the current user.
+func (qb *sceneQueryBuilder) lastPlayedAtColumn() string {
+	return fmt.Sprintf("(SELECT MAX(played_at) FROM %s WHERE %s)", scenesPlayHistoryTable, qb.playHistoryWhere())
+}
+
+// playDurationColumn returns the expression for the total play duration of
+// the scene by the current user.
+func (qb *sceneQueryBuilder) playDurationColumn() string {
+	return fmt.Sprintf("(SELECT COALESCE(SUM(play_duration), 0) FROM %s WHERE %s)", scenesPlayHistoryTable, qb.playHistoryWhere())
+}
+
 func (qb *sceneQueryBuilder) Create(newObject models.Scene) (*models.Scene, error) {
 	var ret models.Scene
 	if err := qb.insertObject(newObject, &ret); err != nil {
 		return nil, err
 	}
 
+	scenePhashIndex.invalidate(qb.tx, ret.ID)
+
+	// the file of a new scene becomes its primary file
+	if _, err := qb.fileRepository().insert(ret.SceneFile()); err != nil {
+		return nil, err
+	}
+
+	if err := updateSceneSearch(qb.tx, ret.ID); err != nil {
+		return nil, err
+	}
+
 	return &ret, nil
 }
 
@@ -114,6 +224,18 @@ func (qb *sceneQueryBuilder) Update(updatedObject models.ScenePartial) (*models.
 		return nil, err
 	}
 
+	scenePhashIndex.invalidate(qb.tx, updatedObject.ID)
+
+	if err := updateSceneSearch(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
+	if updatedObject.HasFileFields() {
+		if err := qb.updatePrimaryFileFromScene(updatedObject.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	return qb.find(updatedObject.ID)
 }
 
@@ -123,13 +245,27 @@ func (qb *sceneQueryBuilder) UpdateFull(updatedObject models.Scene) (*models.Sce
 		return nil, err
 	}
 
+	scenePhashIndex.invalidate(qb.tx, updatedObject.ID)
+
+	if err := updateSceneSearch(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
+	if err := qb.updatePrimaryFileFromScene(updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.find(updatedObject.ID)
 }
 
 func (qb *sceneQueryBuilder) UpdateFileModTime(id int, modTime models.NullSQLiteTimestamp) error {
-	return qb.updateMap(id, map[string]interface{}{
+	if err := qb.updateMap(id, map[string]interface{}{
 		"file_mod_time": modTime,
-	})
+	}); err != nil {
+		return err
+	}
+
+	return qb.updatePrimaryFileFromScene(id)
 }
 
 func (qb *sceneQueryBuilder) captionRepository() *captionRepository {
@@ -202,6 +338,115 @@ func (qb *sceneQueryBuilder) ResetOCounter(id int) (int, error) {
 	return scene.OCounter, nil
 }
 
+// GetPlayHistory returns the plays of the scene by the user with the
+// provided id, most recent first. If userID is nil, then the plays not
+// attributed to a user are returned.
+func (qb *sceneQueryBuilder) GetPlayHistory(sceneID int, userID *int) ([]*models.ScenePlay, error) {
+	query := "SELECT * FROM " + scenesPlayHistoryTable + " WHERE scene_id = ? AND user_id IS ? ORDER BY played_at DESC, id DESC"
+
+	var ret models.ScenePlays
+	if err := qb.query(query, []interface{}{sceneID, userID}, &ret); err != nil {
+		return nil, err
+	}
+
+	return []*models.ScenePlay(ret), nil
+}
+
+func (qb *sceneQueryBuilder) countPlays(sceneID int, userID *int) (int, error) {
+	query := "SELECT COUNT(*) as count FROM " + scenesPlayHistoryTable + " WHERE scene_id = ? AND user_id IS ?"
+	return qb.runCountQuery(query, []interface{}{sceneID, userID})
+}
+
+// AddPlay records a play of the scene by the user with the provided id at
+// the provided time. It returns the new play count of the scene for the
+// user.
+func (qb *sceneQueryBuilder) AddPlay(id int, userID *int, playedAt time.Time) (int, error) {
+	_, err := qb.tx.Exec(
+		`INSERT INTO `+scenesPlayHistoryTable+` (scene_id, user_id, played_at) VALUES (?, ?, ?)`,
+		id, userID, models.SQLiteTimestamp{Timestamp: playedAt},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return qb.countPlays(id, userID)
+}
+
+// SaveActivity sets the resume time of the scene if resumeTime is not nil,
+// and adds playDuration to the most recent play of the scene. A new play is
+// recorded if the scene has not been played before. If userID is not nil,
+// then the resume time and play are those of the user.
+func (qb *sceneQueryBuilder) SaveActivity(id int, userID *int, resumeTime *float64, playDuration *float64) error {
+	if resumeTime != nil {
+		var err error
+		if userID != nil {
+			_, err = qb.tx.Exec(
+				`INSERT INTO `+scenesUsersTable+` (scene_id, user_id, resume_time) VALUES (?, ?, ?)
+				ON CONFLICT (scene_id, user_id) DO UPDATE SET resume_time = excluded.resume_time`,
+				id, *userID, *resumeTime,
+			)
+		} else {
+			_, err = qb.tx.Exec(`UPDATE scenes SET resume_time = ? WHERE scenes.id = ?`, *resumeTime, id)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if playDuration == nil || *playDuration <= 0 {
+		return nil
+	}
+
+	result, err := qb.tx.Exec(
+		`UPDATE `+scenesPlayHistoryTable+` SET play_duration = play_duration + ? WHERE id = (
+			SELECT id FROM `+scenesPlayHistoryTable+` WHERE scene_id = ? AND user_id IS ? ORDER BY played_at DESC, id DESC LIMIT 1
+		)`,
+		*playDuration, id, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+
+	_, err = qb.tx.Exec(
+		`INSERT INTO `+scenesPlayHistoryTable+` (scene_id, user_id, played_at, play_duration) VALUES (?, ?, ?, ?)`,
+		id, userID, models.SQLiteTimestamp{Timestamp: time.Now()}, *playDuration,
+	)
+	return err
+}
+
+// GetUserData returns the rating, o-counter and resume time of the scene for
+// the user with the provided id. Zero values are returned if the user has
+// none stored.
+func (qb *sceneQueryBuilder) GetUserData(sceneID int, userID int) (*models.SceneUserData, error) {
+	ret := models.SceneUserData{
+		SceneID: sceneID,
+		UserID:  userID,
+	}
+
+	query := "SELECT * FROM " + scenesUsersTable + " WHERE scene_id = ? AND user_id = ?"
+	if err := qb.tx.Get(&ret, query, sceneID, userID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// UpdateUserData sets the rating, o-counter and resume time of a scene for a
+// user.
+func (qb *sceneQueryBuilder) UpdateUserData(data models.SceneUserData) error {
+	_, err := qb.tx.Exec(
+		`INSERT INTO `+scenesUsersTable+` (scene_id, user_id, rating, o_counter, resume_time) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (scene_id, user_id) DO UPDATE SET rating = excluded.rating, o_counter = excluded.o_counter, resume_time = excluded.resume_time`,
+		data.SceneID, data.UserID, data.Rating, data.OCounter, data.ResumeTime,
+	)
+	return err
+}
+
 func (qb *sceneQueryBuilder) Destroy(id int) error {
 	// delete all related table rows
 	// TODO - this should be handled by a delete cascade
@@ -212,17 +457,110 @@ func (qb *sceneQueryBuilder) Destroy(id int) error {
 	// scene markers should be handled prior to calling destroy
 	// galleries should be handled prior to calling destroy
 
-	return qb.destroyExisting([]int{id})
+	if err := qb.destroyExisting([]int{id}); err != nil {
+		return err
+	}
+
+	scenePhashIndex.invalidate(qb.tx, id)
+	return updateSceneSearch(qb.tx, id)
+}
+
+// Merge moves the tags, performers, galleries, movies, stash ids, markers,
+// files, o-counters and play data of the source scenes to the destination
+// scene, then destroys the source scenes. The moved files are not primary.
+// Relationships that the destination already has are not duplicated.
+func (qb *sceneQueryBuilder) Merge(source []int, destination int) error {
+	if len(source) == 0 {
+		return nil
+	}
+
+	if err := checkMergeIDs(source, destination); err != nil {
+		return err
+	}
+
+	inBinding := getInBinding(len(source))
+
+	args := []interface{}{destination}
+	for _, id := range source {
+		args = append(args, id)
+	}
+
+	// the column identifying the related object of each join table
+	joinTables := map[string]string{
+		scenesTagsTable:       tagIDColumn,
+		performersScenesTable: performerIDColumn,
+		scenesGalleriesTable:  galleryIDColumn,
+		moviesScenesTable:     movieIDColumn,
+		"scene_stash_ids":     "endpoint",
+	}
+
+	if err := moveJoins(qb.tx, joinTables, sceneIDColumn, source, destination); err != nil {
+		return err
+	}
+
+	for _, id := range source {
+		// keep the rating and resume time of the destination
+		_, err := qb.tx.Exec(`INSERT INTO `+scenesUsersTable+` (scene_id, user_id, rating, o_counter, resume_time)
+SELECT ?, user_id, rating, o_counter, resume_time FROM `+scenesUsersTable+` WHERE scene_id = ?
+ON CONFLICT (scene_id, user_id) DO UPDATE SET
+rating = COALESCE(`+scenesUsersTable+`.rating, excluded.rating),
+o_counter = `+scenesUsersTable+`.o_counter + excluded.o_counter,
+resume_time = CASE WHEN `+scenesUsersTable+`.resume_time > 0 THEN `+scenesUsersTable+`.resume_time ELSE excluded.resume_time END`,
+			destination, id,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	oCounterArgs := append(append([]interface{}{}, args[1:]...), destination)
+	_, err := qb.tx.Exec("UPDATE "+sceneTable+" SET o_counter = o_counter + (SELECT COALESCE(SUM(o_counter), 0) FROM "+sceneTable+" WHERE id IN "+inBinding+") WHERE id = ?",
+		oCounterArgs...,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{sceneMarkerTable, scenesPlayHistoryTable} {
+		if _, err := qb.tx.Exec("UPDATE "+table+" SET scene_id = ? WHERE scene_id IN "+inBinding, args...); err != nil {
+			return err
+		}
+	}
+
+	if _, err := qb.tx.Exec("UPDATE "+sceneFilesTable+" SET scene_id = ?, is_primary = 0 WHERE scene_id IN "+inBinding, args...); err != nil {
+		return err
+	}
+
+	for _, id := range source {
+		if err := qb.Destroy(id); err != nil {
+			return err
+		}
+	}
+
+	// the destination takes the markers of the sources
+	return updateSceneSearch(qb.tx, destination)
 }
 
 func (qb *sceneQueryBuilder) Find(id int) (*models.Scene, error) {
+	if restricted, err := qb.isRestricted(id); err != nil || restricted {
+		return nil, err
+	}
+
 	return qb.find(id)
 }
 
+// FindMany returns the scenes with the provided ids. Scenes hidden by the
+// content restriction are omitted.
 func (qb *sceneQueryBuilder) FindMany(ids []int) ([]*models.Scene, error) {
 	var scenes []*models.Scene
 	for _, id := range ids {
-		scene, err := qb.Find(id)
+		if restricted, err := qb.isRestricted(id); err != nil {
+			return nil, err
+		} else if restricted {
+			continue
+		}
+
+		scene, err := qb.find(id)
 		if err != nil {
 			return nil, err
 		}
@@ -248,20 +586,24 @@ func (qb *sceneQueryBuilder) find(id int) (*models.Scene, error) {
 	return &ret, nil
 }
 
+// FindByChecksum returns the scene that owns a file with the provided
+// checksum.
 func (qb *sceneQueryBuilder) FindByChecksum(checksum string) (*models.Scene, error) {
-	query := "SELECT * FROM scenes WHERE checksum = ? LIMIT 1"
+	query := selectAll(sceneTable) + sceneFilesJoin + "WHERE scene_files.checksum = ? LIMIT 1"
 	args := []interface{}{checksum}
 	return qb.queryScene(query, args)
 }
 
+// FindByOSHash returns the scene that owns a file with the provided oshash.
 func (qb *sceneQueryBuilder) FindByOSHash(oshash string) (*models.Scene, error) {
-	query := "SELECT * FROM scenes WHERE oshash = ? LIMIT 1"
+	query := selectAll(sceneTable) + sceneFilesJoin + "WHERE scene_files.oshash = ? LIMIT 1"
 	args := []interface{}{oshash}
 	return qb.queryScene(query, args)
 }
 
+// FindByPath returns the scene that owns the file with the provided path.
 func (qb *sceneQueryBuilder) FindByPath(path string) (*models.Scene, error) {
-	query := selectAll(sceneTable) + "WHERE path = ? LIMIT 1"
+	query := selectAll(sceneTable) + sceneFilesJoin + "WHERE scene_files.path = ? LIMIT 1"
 	args := []interface{}{path}
 	return qb.queryScene(query, args)
 }
@@ -278,7 +620,7 @@ func (qb *sceneQueryBuilder) FindByGalleryID(galleryID int) ([]*models.Scene, er
 
 func (qb *sceneQueryBuilder) CountByPerformerID(performerID int) (int, error) {
 	args := []interface{}{performerID}
-	return qb.runCountQuery(qb.buildCountQuery(countScenesForPerformerQuery), args)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery(countScenesForPerformerQuery), args)
 }
 
 func (qb *sceneQueryBuilder) FindByMovieID(movieID int) ([]*models.Scene, error) {
@@ -288,29 +630,29 @@ func (qb *sceneQueryBuilder) FindByMovieID(movieID int) ([]*models.Scene, error)
 
 func (qb *sceneQueryBuilder) CountByMovieID(movieID int) (int, error) {
 	args := []interface{}{movieID}
-	return qb.runCountQuery(qb.buildCountQuery(scenesForMovieQuery), args)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery(scenesForMovieQuery), args)
 }
 
 func (qb *sceneQueryBuilder) Count() (int, error) {
-	return qb.runCountQuery(qb.buildCountQuery("SELECT scenes.id FROM scenes"), nil)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery("SELECT scenes.id FROM scenes"), nil)
 }
 
 func (qb *sceneQueryBuilder) Size() (float64, error) {
-	return qb.runSumQuery("SELECT SUM(cast(size as double)) as sum FROM scenes", nil)
+	return qb.runSumQuery(qb.addRestrictionWhere("SELECT SUM(cast(size as double)) as sum FROM scenes"), nil)
 }
 
 func (qb *sceneQueryBuilder) Duration() (float64, error) {
-	return qb.runSumQuery("SELECT SUM(cast(duration as double)) as sum FROM scenes", nil)
+	return qb.runSumQuery(qb.addRestrictionWhere("SELECT SUM(cast(duration as double)) as sum FROM scenes"), nil)
 }
 
 func (qb *sceneQueryBuilder) CountByStudioID(studioID int) (int, error) {
 	args := []interface{}{studioID}
-	return qb.runCountQuery(qb.buildCountQuery(scenesForStudioQuery), args)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery(scenesForStudioQuery), args)
 }
 
 func (qb *sceneQueryBuilder) CountByTagID(tagID int) (int, error) {
 	args := []interface{}{tagID}
-	return qb.runCountQuery(qb.buildCountQuery(countScenesForTagQuery), args)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery(countScenesForTagQuery), args)
 }
 
 // CountMissingChecksum returns the number of scenes missing a checksum value.
@@ -353,7 +695,9 @@ func (qb *sceneQueryBuilder) validateFilter(sceneFilter *models.SceneFilterType)
 			return illegalFilterCombination(and, not)
 		}
 
-		return qb.validateFilter(sceneFilter.And)
+		if err := qb.validateFilter(sceneFilter.And); err != nil {
+			return err
+		}
 	}
 
 	if sceneFilter.Or != nil {
@@ -361,11 +705,23 @@ func (qb *sceneQueryBuilder) validateFilter(sceneFilter *models.SceneFilterType)
 			return illegalFilterCombination(or, not)
 		}
 
-		return qb.validateFilter(sceneFilter.Or)
+		if err := qb.validateFilter(sceneFilter.Or); err != nil {
+			return err
+		}
 	}
 
 	if sceneFilter.Not != nil {
-		return qb.validateFilter(sceneFilter.Not)
+		if err := qb.validateFilter(sceneFilter.Not); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range [][]*models.SceneFilterType{sceneFilter.All, sceneFilter.Any} {
+		for _, f := range group {
+			if err := qb.validateFilter(f); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -383,15 +739,24 @@ func (qb *sceneQueryBuilder) makeFilter(sceneFilter *models.SceneFilterType) *fi
 	if sceneFilter.Not != nil {
 		query.not(qb.makeFilter(sceneFilter.Not))
 	}
+	if len(sceneFilter.All) > 0 {
+		query.addGroup(sceneTable, andOp, qb.makeFilters(sceneFilter.All))
+	}
+	if len(sceneFilter.Any) > 0 {
+		query.addGroup(sceneTable, orOp, qb.makeFilters(sceneFilter.Any))
+	}
 
 	query.handleCriterion(stringCriterionHandler(sceneFilter.Path, "scenes.path"))
 	query.handleCriterion(stringCriterionHandler(sceneFilter.Title, "scenes.title"))
 	query.handleCriterion(stringCriterionHandler(sceneFilter.Details, "scenes.details"))
 	query.handleCriterion(stringCriterionHandler(sceneFilter.Oshash, "scenes.oshash"))
 	query.handleCriterion(stringCriterionHandler(sceneFilter.Checksum, "scenes.checksum"))
-	query.handleCriterion(phashCriterionHandler(sceneFilter.Phash))
-	query.handleCriterion(intCriterionHandler(sceneFilter.Rating, "scenes.rating"))
-	query.handleCriterion(intCriterionHandler(sceneFilter.OCounter, "scenes.o_counter"))
+	query.handleCriterion(phashCriterionHandler(sceneFilter.Phash, "scenes.phash"))
+	query.handleCriterion(qb.userDataCriterionHandler(sceneFilter.Rating != nil, intCriterionHandler(sceneFilter.Rating, qb.userDataColumn("rating", ""))))
+	query.handleCriterion(qb.userDataCriterionHandler(sceneFilter.OCounter != nil, intCriterionHandler(sceneFilter.OCounter, qb.userDataColumn("o_counter", "0"))))
+	query.handleCriterion(intCriterionHandler(sceneFilter.PlayCount, qb.playCountColumn()))
+	query.handleCriterion(timestampCriterionHandler(sceneFilter.LastPlayedAt, qb.lastPlayedAtColumn()))
+	query.handleCriterion(qb.userDataCriterionHandler(sceneFilter.InProgress != nil, sceneInProgressCriterionHandler(sceneFilter.InProgress, qb.userDataColumn("resume_time", "0"))))
 	query.handleCriterion(boolCriterionHandler(sceneFilter.Organized, "scenes.organized"))
 	query.handleCriterion(durationCriterionHandler(sceneFilter.Duration, "scenes.duration"))
 	query.handleCriterion(resolutionCriterionHandler(sceneFilter.Resolution, "scenes.height", "scenes.width"))
@@ -420,11 +785,20 @@ func (qb *sceneQueryBuilder) makeFilter(sceneFilter *models.SceneFilterType) *fi
 	query.handleCriterion(scenePerformerTagsCriterionHandler(qb, sceneFilter.PerformerTags))
 	query.handleCriterion(scenePerformerFavoriteCriterionHandler(sceneFilter.PerformerFavorite))
 	query.handleCriterion(scenePerformerAgeCriterionHandler(sceneFilter.PerformerAge))
-	query.handleCriterion(scenePhashDuplicatedCriterionHandler(sceneFilter.Duplicated))
+	query.handleCriterion(phashDuplicatedCriterionHandler(&qb.repository, sceneFilter.Duplicated))
 
 	return query
 }
 
+func (qb *sceneQueryBuilder) makeFilters(sceneFilters []*models.SceneFilterType) []*filterBuilder {
+	var ret []*filterBuilder
+	for _, f := range sceneFilters {
+		ret = append(ret, qb.makeFilter(f))
+	}
+
+	return ret
+}
+
 func (qb *sceneQueryBuilder) Query(options models.SceneQueryOptions) (*models.SceneQueryResult, error) {
 	sceneFilter := options.SceneFilter
 	findFilter := options.FindFilter
@@ -438,11 +812,10 @@ func (qb *sceneQueryBuilder) Query(options models.SceneQueryOptions) (*models.Sc
 
 	query := qb.newQuery()
 	distinctIDs(&query, sceneTable)
+	query.addWhere(qb.restrictionClause())
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		query.join("scene_markers", "", "scene_markers.scene_id = scenes.id")
-		searchColumns := []string{"scenes.title", "scenes.details", "scenes.path", "scenes.oshash", "scenes.checksum", "scene_markers.title"}
-		query.parseQueryString(searchColumns, *q)
+		query.addFullTextSearch(scenesFTS, *q)
 	}
 
 	if err := qb.validateFilter(sceneFilter); err != nil {
@@ -510,7 +883,7 @@ func (qb *sceneQueryBuilder) queryGroupedFields(options models.SceneQueryOptions
 	return ret, nil
 }
 
-func phashCriterionHandler(phashFilter *models.StringCriterionInput) criterionHandlerFunc {
+func phashCriterionHandler(phashFilter *models.StringCriterionInput, column string) criterionHandlerFunc {
 	return func(f *filterBuilder) {
 		if phashFilter != nil {
 			// convert value to int from hex
@@ -520,34 +893,19 @@ func phashCriterionHandler(phashFilter *models.StringCriterionInput) criterionHa
 			if modifier := phashFilter.Modifier; phashFilter.Modifier.IsValid() {
 				switch modifier {
 				case models.CriterionModifierEquals:
-					f.addWhere("scenes.phash = ?", value)
+					f.addWhere(column+" = ?", value)
 				case models.CriterionModifierNotEquals:
-					f.addWhere("scenes.phash != ?", value)
+					f.addWhere(column+" != ?", value)
 				case models.CriterionModifierIsNull:
-					f.addWhere("scenes.phash IS NULL")
+					f.addWhere(column + " IS NULL")
 				case models.CriterionModifierNotNull:
-					f.addWhere("scenes.phash IS NOT NULL")
+					f.addWhere(column + " IS NOT NULL")
 				}
 			}
 		}
 	}
 }
 
-func scenePhashDuplicatedCriterionHandler(duplicatedFilter *models.PHashDuplicationCriterionInput) criterionHandlerFunc {
-	return func(f *filterBuilder) {
-		// TODO: Wishlist item: Implement Distance matching
-		if duplicatedFilter != nil {
-			var v string
-			if *duplicatedFilter.Duplicated {
-				v = ">"
-			} else {
-				v = "="
-			}
-			f.addInnerJoin("(SELECT id FROM scenes JOIN (SELECT phash FROM scenes GROUP BY phash HAVING COUNT(phash) "+v+" 1) dupes on scenes.phash = dupes.phash)", "scph", "scenes.id = scph.id")
-		}
-	}
-}
-
 func durationCriterionHandler(durationFilter *models.IntCriterionInput, column string) criterionHandlerFunc {
 	return func(f *filterBuilder) {
 		if durationFilter != nil {
@@ -672,6 +1030,18 @@ func sceneTagCountCriterionHandler(qb *sceneQueryBuilder, tagCount *models.IntCr
 	return h.handler(tagCount)
 }
 
+func sceneInProgressCriterionHandler(inProgress *bool, resumeTimeColumn string) criterionHandlerFunc {
+	return func(f *filterBuilder) {
+		if inProgress != nil {
+			if *inProgress {
+				f.addWhere(resumeTimeColumn + " > 0")
+			} else {
+				f.addWhere(resumeTimeColumn + " = 0")
+			}
+		}
+	}
+}
+
 func scenePerformersCriterionHandler(qb *sceneQueryBuilder, performers *models.MultiCriterionInput) criterionHandlerFunc {
 	h := joinedMultiCriterionHandlerBuilder{
 		primaryTable: sceneTable,
@@ -798,6 +1168,11 @@ func (qb *sceneQueryBuilder) getDefaultSceneSort() string {
 }
 
 func (qb *sceneQueryBuilder) setSceneSort(query *queryBuilder, findFilter *models.FindFilterType) {
+	if sort, ok := query.getRelevanceSort(sceneTable, findFilter); ok {
+		query.sortAndPagination += sort
+		return
+	}
+
 	if findFilter == nil || findFilter.Sort == nil || *findFilter.Sort == "" {
 		return
 	}
@@ -811,6 +1186,20 @@ func (qb *sceneQueryBuilder) setSceneSort(query *queryBuilder, findFilter *model
 		query.sortAndPagination += getCountSort(sceneTable, scenesTagsTable, sceneIDColumn, direction)
 	case "performer_count":
 		query.sortAndPagination += getCountSort(sceneTable, performersScenesTable, sceneIDColumn, direction)
+	case "play_count":
+		query.sortAndPagination += fmt.Sprintf(" ORDER BY %s %s", qb.playCountColumn(), getSortDirection(direction))
+	case "last_played_at":
+		query.sortAndPagination += fmt.Sprintf(" ORDER BY %s %s", qb.lastPlayedAtColumn(), getSortDirection(direction))
+	case "play_duration":
+		query.sortAndPagination += fmt.Sprintf(" ORDER BY %s %s", qb.playDurationColumn(), getSortDirection(direction))
+	case "rating", "o_counter":
+		if qb.userID == nil {
+			query.sortAndPagination += getSort(sort, direction, "scenes")
+			break
+		}
+
+		query.addJoins(qb.userDataJoin())
+		query.sortAndPagination += fmt.Sprintf(" ORDER BY %s %s", qb.userDataColumn(sort, "0"), getSortDirection(direction))
 	default:
 		query.sortAndPagination += getSort(sort, direction, "scenes")
 	}
@@ -826,7 +1215,7 @@ func (qb *sceneQueryBuilder) queryScene(query string, args []interface{}) (*mode
 
 func (qb *sceneQueryBuilder) queryScenes(query string, args []interface{}) ([]*models.Scene, error) {
 	var ret models.Scenes
-	if err := qb.query(query, args, &ret); err != nil {
+	if err := qb.query(qb.restrictQuery(query), args, &ret); err != nil {
 		return nil, err
 	}
 
@@ -975,49 +1364,190 @@ func (qb *sceneQueryBuilder) UpdateStashIDs(sceneID int, stashIDs []models.Stash
 	return qb.stashIDRepository().replace(sceneID, stashIDs)
 }
 
-func (qb *sceneQueryBuilder) FindDuplicates(distance int) ([][]*models.Scene, error) {
-	var dupeIds [][]int
-	if distance == 0 {
-		var ids []string
-		if err := qb.tx.Select(&ids, findExactDuplicateQuery); err != nil {
+func (qb *sceneQueryBuilder) fileRepository() *repository {
+	return &repository{
+		tx:        qb.tx,
+		tableName: sceneFilesTable,
+		idColumn:  idColumn,
+	}
+}
+
+// GetFiles returns the files of the scene, with the primary file first.
+func (qb *sceneQueryBuilder) GetFiles(sceneID int) ([]*models.SceneFile, error) {
+	query := "SELECT * FROM scene_files WHERE scene_id = ? ORDER BY is_primary DESC, path ASC"
+	return qb.querySceneFiles(query, []interface{}{sceneID})
+}
+
+func (qb *sceneQueryBuilder) FindFile(id int) (*models.SceneFile, error) {
+	query := "SELECT * FROM scene_files WHERE id = ? LIMIT 1"
+	return qb.querySceneFile(query, []interface{}{id})
+}
+
+func (qb *sceneQueryBuilder) FindFileByPath(path string) (*models.SceneFile, error) {
+	query := "SELECT * FROM scene_files WHERE path = ? LIMIT 1"
+	return qb.querySceneFile(query, []interface{}{path})
+}
+
+// CreateFile adds a file to a scene. If the new file is primary, then the
+// scene is updated to reflect it.
+func (qb *sceneQueryBuilder) CreateFile(newObject models.SceneFile) (*models.SceneFile, error) {
+	primary := newObject.Primary
+	newObject.Primary = false
+
+	var ret models.SceneFile
+	if err := qb.fileRepository().insertObject(newObject, &ret); err != nil {
+		return nil, err
+	}
+
+	if primary {
+		if err := qb.SetPrimaryFile(ret.SceneID, ret.ID); err != nil {
 			return nil, err
 		}
+		return qb.FindFile(ret.ID)
+	}
 
-		for _, id := range ids {
-			strIds := strings.Split(id, ",")
-			var sceneIds []int
-			for _, strId := range strIds {
-				if intId, err := strconv.Atoi(strId); err == nil {
-					sceneIds = append(sceneIds, intId)
-				}
-			}
-			dupeIds = append(dupeIds, sceneIds)
-		}
-	} else {
-		var hashes []*utils.Phash
+	return &ret, nil
+}
 
-		if err := qb.queryFunc(findAllPhashesQuery, nil, false, func(rows *sqlx.Rows) error {
-			phash := utils.Phash{
-				Bucket: -1,
-			}
-			if err := rows.StructScan(&phash); err != nil {
-				return err
-			}
+// UpdateFile updates a scene file. The primary flag of the file is not
+// changed by this method; use SetPrimaryFile instead. If the scene of the
+// file is changed, then the file is no longer primary, and the previous
+// scene has a new primary file assigned if needed.
+func (qb *sceneQueryBuilder) UpdateFile(updatedObject models.SceneFile) (*models.SceneFile, error) {
+	existing, err := qb.FindFile(updatedObject.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		return nil, fmt.Errorf("scene file with id %d not found", updatedObject.ID)
+	}
+
+	moved := existing.SceneID != updatedObject.SceneID
+	updatedObject.Primary = existing.Primary && !moved
+
+	const partial = false
+	if err := qb.fileRepository().update(updatedObject.ID, updatedObject, partial); err != nil {
+		return nil, err
+	}
 
-			hashes = append(hashes, &phash)
-			return nil
-		}); err != nil {
+	if moved {
+		if err := qb.ensurePrimaryFile(existing.SceneID); err != nil {
+			return nil, err
+		}
+	} else if updatedObject.Primary {
+		if err := qb.updateSceneFromPrimaryFile(updatedObject.SceneID); err != nil {
 			return nil, err
 		}
+	}
+
+	return qb.FindFile(updatedObject.ID)
+}
+
+// DestroyFile removes a file from its scene. If the file was the primary
+// file, then another file of the scene becomes primary.
+func (qb *sceneQueryBuilder) DestroyFile(id int) error {
+	existing, err := qb.FindFile(id)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		return fmt.Errorf("scene file with id %d not found", id)
+	}
+
+	if err := qb.fileRepository().destroy([]int{id}); err != nil {
+		return err
+	}
+
+	return qb.ensurePrimaryFile(existing.SceneID)
+}
+
+// SetPrimaryFile sets the primary file of the scene and updates the file
+// fields of the scene to match it.
+func (qb *sceneQueryBuilder) SetPrimaryFile(sceneID int, fileID int) error {
+	f, err := qb.FindFile(fileID)
+	if err != nil {
+		return err
+	}
+
+	if f == nil || f.SceneID != sceneID {
+		return fmt.Errorf("file %d does not belong to scene %d", fileID, sceneID)
+	}
+
+	if _, err := qb.tx.Exec("UPDATE scene_files SET is_primary = (id = ?) WHERE scene_id = ?", fileID, sceneID); err != nil {
+		return err
+	}
+
+	return qb.updateSceneFromPrimaryFile(sceneID)
+}
+
+// ensurePrimaryFile sets the first file of the scene as primary if the
+// scene has files but no primary file.
+func (qb *sceneQueryBuilder) ensurePrimaryFile(sceneID int) error {
+	files, err := qb.GetFiles(sceneID)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 || files[0].Primary {
+		return nil
+	}
+
+	return qb.SetPrimaryFile(sceneID, files[0].ID)
+}
+
+func (qb *sceneQueryBuilder) updatePrimaryFileFromScene(sceneID int) error {
+	columns := strings.Join(sceneFileColumns, ", ")
+	query := fmt.Sprintf(`UPDATE scene_files SET (%[1]s, updated_at) = (SELECT %[1]s, updated_at FROM scenes WHERE scenes.id = scene_files.scene_id)
+WHERE scene_id = ? AND is_primary = 1`, columns)
+	_, err := qb.tx.Exec(query, sceneID)
+	return err
+}
+
+func (qb *sceneQueryBuilder) updateSceneFromPrimaryFile(sceneID int) error {
+	columns := strings.Join(sceneFileColumns, ", ")
+	query := fmt.Sprintf(`UPDATE scenes SET (%[1]s) = (SELECT %[1]s FROM scene_files WHERE scene_files.scene_id = scenes.id AND scene_files.is_primary = 1)
+WHERE id = ? AND EXISTS (SELECT 1 FROM scene_files WHERE scene_files.scene_id = scenes.id AND scene_files.is_primary = 1)`, columns)
+	if _, err := qb.tx.Exec(query, sceneID); err != nil {
+		return err
+	}
+
+	scenePhashIndex.invalidate(qb.tx, sceneID)
+	return updateSceneSearch(qb.tx, sceneID)
+}
+
+func (qb *sceneQueryBuilder) querySceneFile(query string, args []interface{}) (*models.SceneFile, error) {
+	results, err := qb.querySceneFiles(query, args)
+	if err != nil || len(results) < 1 {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+func (qb *sceneQueryBuilder) querySceneFiles(query string, args []interface{}) ([]*models.SceneFile, error) {
+	var ret models.SceneFiles
+	if err := qb.query(query, args, &ret); err != nil {
+		return nil, err
+	}
 
-		dupeIds = utils.FindDuplicates(hashes, distance)
+	return []*models.SceneFile(ret), nil
+}
+
+func (qb *sceneQueryBuilder) FindDuplicates(options models.DuplicateOptions) ([][]*models.Scene, error) {
+	dupeIds, err := qb.findPhashDuplicateIDs(options)
+	if err != nil {
+		return nil, err
 	}
 
 	var duplicates [][]*models.Scene
 	for _, sceneIds := range dupeIds {
-		if scenes, err := qb.FindMany(sceneIds); err == nil {
-			duplicates = append(duplicates, scenes)
+		scenes, err := qb.FindMany(sceneIds)
+		if err != nil {
+			return nil, err
 		}
+
+		duplicates = append(duplicates, scenes)
 	}
 
 	return duplicates, nil
@@ -38,15 +38,29 @@ func (qb *sceneMarkerQueryBuilder) Create(newObject models.SceneMarker) (*models
 		return nil, err
 	}
 
+	if err := qb.updateSearch(ret.ID); err != nil {
+		return nil, err
+	}
+
 	return &ret, nil
 }
 
 func (qb *sceneMarkerQueryBuilder) Update(updatedObject models.SceneMarker) (*models.SceneMarker, error) {
+	// the marker may be moved from another scene
+	sceneID, err := qb.getSceneID(updatedObject.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	const partial = false
 	if err := qb.update(updatedObject.ID, updatedObject, partial); err != nil {
 		return nil, err
 	}
 
+	if err := qb.updateSearch(updatedObject.ID, sceneID); err != nil {
+		return nil, err
+	}
+
 	var ret models.SceneMarker
 	if err := qb.get(updatedObject.ID, &ret); err != nil {
 		return nil, err
@@ -56,7 +70,47 @@ func (qb *sceneMarkerQueryBuilder) Update(updatedObject models.SceneMarker) (*mo
 }
 
 func (qb *sceneMarkerQueryBuilder) Destroy(id int) error {
-	return qb.destroyExisting([]int{id})
+	sceneID, err := qb.getSceneID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := qb.destroyExisting([]int{id}); err != nil {
+		return err
+	}
+
+	return qb.updateSearch(id, sceneID)
+}
+
+func (qb *sceneMarkerQueryBuilder) getSceneID(id int) (int, error) {
+	var ret sql.NullInt64
+	if err := qb.tx.Get(&ret, "SELECT scene_id FROM "+sceneMarkerTable+" WHERE id = ?", id); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	return int(ret.Int64), nil
+}
+
+// updateSearch updates the search rows of the marker and of its scene, and
+// of the other provided scenes.
+func (qb *sceneMarkerQueryBuilder) updateSearch(id int, sceneIDs ...int) error {
+	if err := sceneMarkersFTS.update(qb.tx, id); err != nil {
+		return err
+	}
+
+	sceneID, err := qb.getSceneID(id)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	for _, sid := range append(sceneIDs, sceneID) {
+		if sid != 0 {
+			ids = append(ids, sid)
+		}
+	}
+
+	return scenesFTS.update(qb.tx, ids...)
 }
 
 func (qb *sceneMarkerQueryBuilder) Find(id int) (*models.SceneMarker, error) {
@@ -69,9 +123,17 @@ func (qb *sceneMarkerQueryBuilder) Find(id int) (*models.SceneMarker, error) {
 	return results[0], nil
 }
 
+// FindMany returns the scene markers with the provided ids. Scene markers
+// hidden by the content restriction are omitted.
 func (qb *sceneMarkerQueryBuilder) FindMany(ids []int) ([]*models.SceneMarker, error) {
 	var markers []*models.SceneMarker
 	for _, id := range ids {
+		if restricted, err := qb.isRestricted(id); err != nil {
+			return nil, err
+		} else if restricted {
+			continue
+		}
+
 		marker, err := qb.Find(id)
 		if err != nil {
 			return nil, err
@@ -100,13 +162,18 @@ func (qb *sceneMarkerQueryBuilder) FindBySceneID(sceneID int) ([]*models.SceneMa
 
 func (qb *sceneMarkerQueryBuilder) CountByTagID(tagID int) (int, error) {
 	args := []interface{}{tagID, tagID}
-	return qb.runCountQuery(qb.buildCountQuery(countSceneMarkersForTagQuery), args)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery(countSceneMarkersForTagQuery), args)
 }
 
 func (qb *sceneMarkerQueryBuilder) GetMarkerStrings(q *string, sort *string) ([]*models.MarkerStringsResultType, error) {
-	query := "SELECT count(*) as `count`, scene_markers.id as id, scene_markers.title as title FROM scene_markers"
+	query := qb.addRestrictionWhere("SELECT count(*) as `count`, scene_markers.id as id, scene_markers.title as title FROM scene_markers")
 	if q != nil {
-		query += " WHERE title LIKE '%" + *q + "%'"
+		if qb.restrictedIDs != "" {
+			query += " AND"
+		} else {
+			query += " WHERE"
+		}
+		query += " title LIKE '%" + *q + "%'"
 	}
 	query += " GROUP BY title"
 	if sort != nil && *sort == "count" {
@@ -148,17 +215,21 @@ func (qb *sceneMarkerQueryBuilder) Query(sceneMarkerFilter *models.SceneMarkerFi
 
 	query := qb.newQuery()
 	distinctIDs(&query, sceneMarkerTable)
+	query.addWhere(qb.restrictionClause())
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		searchColumns := []string{"scene_markers.title", "scenes.title"}
-		query.parseQueryString(searchColumns, *q)
+		query.addFullTextSearch(sceneMarkersFTS, *q)
 	}
 
 	filter := qb.makeFilter(sceneMarkerFilter)
 
 	query.addFilter(filter)
 
-	query.sortAndPagination = qb.getSceneMarkerSort(&query, findFilter) + getPagination(findFilter)
+	sort, ok := query.getRelevanceSort(sceneMarkerTable, findFilter)
+	if !ok {
+		sort = qb.getSceneMarkerSort(&query, findFilter)
+	}
+	query.sortAndPagination = sort + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
@@ -291,7 +362,7 @@ func (qb *sceneMarkerQueryBuilder) getSceneMarkerSort(query *queryBuilder, findF
 
 func (qb *sceneMarkerQueryBuilder) querySceneMarkers(query string, args []interface{}) ([]*models.SceneMarker, error) {
 	var ret models.SceneMarkers
-	if err := qb.query(query, args, &ret); err != nil {
+	if err := qb.query(qb.restrictQuery(query), args, &ret); err != nil {
 		return nil, err
 	}
 
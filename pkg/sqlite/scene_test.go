@@ -4,17 +4,21 @@
 package sqlite_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math"
 	"regexp"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stashapp/stash/pkg/hash/md5"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
+	"github.com/stashapp/stash/pkg/sqlite"
 )
 
 func TestSceneFind(t *testing.T) {
@@ -1692,6 +1696,393 @@ func TestSceneStashIDs(t *testing.T) {
 	}
 }
 
+func TestSceneFiles(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		// create scene to test against
+		const name = "TestSceneFiles"
+		scene := models.Scene{
+			Path:     name,
+			Checksum: sql.NullString{String: md5.FromString(name), Valid: true},
+		}
+		created, err := qb.Create(scene)
+		if err != nil {
+			return fmt.Errorf("Error creating scene: %s", err.Error())
+		}
+
+		files, err := qb.GetFiles(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene files: %s", err.Error())
+		}
+		if !assert.Len(t, files, 1) {
+			return nil
+		}
+		primary := files[0]
+		assert.True(t, primary.Primary)
+		assert.Equal(t, name, primary.Path)
+
+		// add a second file
+		const secondName = "TestSceneFiles2"
+		second, err := qb.CreateFile(models.SceneFile{
+			SceneID:  created.ID,
+			Path:     secondName,
+			Checksum: sql.NullString{String: md5.FromString(secondName), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("Error creating scene file: %s", err.Error())
+		}
+		assert.False(t, second.Primary)
+
+		// scene should be found using the path of the second file
+		found, err := qb.FindByPath(secondName)
+		if err != nil {
+			return fmt.Errorf("Error finding scene: %s", err.Error())
+		}
+		assert.Equal(t, created.ID, found.ID)
+
+		// set the second file as primary
+		if err := qb.SetPrimaryFile(created.ID, second.ID); err != nil {
+			return fmt.Errorf("Error setting primary file: %s", err.Error())
+		}
+
+		found, err = qb.Find(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding scene: %s", err.Error())
+		}
+		assert.Equal(t, secondName, found.Path)
+		assert.Equal(t, md5.FromString(secondName), found.Checksum.String)
+
+		// destroying the primary file should promote the remaining file
+		if err := qb.DestroyFile(second.ID); err != nil {
+			return fmt.Errorf("Error destroying scene file: %s", err.Error())
+		}
+
+		found, err = qb.Find(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding scene: %s", err.Error())
+		}
+		assert.Equal(t, name, found.Path)
+
+		files, err = qb.GetFiles(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting scene files: %s", err.Error())
+		}
+		if assert.Len(t, files, 1) {
+			assert.True(t, files[0].Primary)
+		}
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestScenePlayHistory(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		// create scene to test against
+		const name = "TestScenePlayHistory"
+		scene := models.Scene{
+			Path:     name,
+			Checksum: sql.NullString{String: md5.FromString(name), Valid: true},
+		}
+		created, err := qb.Create(scene)
+		if err != nil {
+			return fmt.Errorf("Error creating scene: %s", err.Error())
+		}
+
+		// saving activity without a play should record a play
+		resumeTime := 12.5
+		playDuration := 30.0
+		if err := qb.SaveActivity(created.ID, nil, &resumeTime, &playDuration); err != nil {
+			return fmt.Errorf("Error saving activity: %s", err.Error())
+		}
+
+		playedAt := time.Now().Add(time.Hour)
+		count, err := qb.AddPlay(created.ID, nil, playedAt)
+		if err != nil {
+			return fmt.Errorf("Error adding play: %s", err.Error())
+		}
+		assert.Equal(t, 2, count)
+
+		// duration should be added to the most recent play
+		if err := qb.SaveActivity(created.ID, nil, nil, &playDuration); err != nil {
+			return fmt.Errorf("Error saving activity: %s", err.Error())
+		}
+
+		history, err := qb.GetPlayHistory(created.ID, nil)
+		if err != nil {
+			return fmt.Errorf("Error getting play history: %s", err.Error())
+		}
+		if assert.Len(t, history, 2) {
+			assert.Equal(t, playedAt.Unix(), history[0].PlayedAt.Timestamp.Unix())
+			assert.Equal(t, playDuration, history[0].PlayDuration)
+			assert.Equal(t, playDuration, history[1].PlayDuration)
+		}
+
+		found, err := qb.Find(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding scene: %s", err.Error())
+		}
+		assert.Equal(t, resumeTime, found.ResumeTime)
+
+		// test filters
+		inProgress := true
+		pathCriterion := models.StringCriterionInput{
+			Value:    name,
+			Modifier: models.CriterionModifierEquals,
+		}
+		sceneFilter := models.SceneFilterType{
+			Path: &pathCriterion,
+			PlayCount: &models.IntCriterionInput{
+				Value:    2,
+				Modifier: models.CriterionModifierEquals,
+			},
+			LastPlayedAt: &models.TimestampCriterionInput{
+				Value:    time.Now().Format(time.RFC3339),
+				Modifier: models.CriterionModifierGreaterThan,
+			},
+			InProgress: &inProgress,
+		}
+
+		scenes := queryScene(t, qb, &sceneFilter, nil)
+		if assert.Len(t, scenes, 1) {
+			assert.Equal(t, created.ID, scenes[0].ID)
+		}
+
+		inProgress = false
+		scenes = queryScene(t, qb, &sceneFilter, nil)
+		assert.Len(t, scenes, 0)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneQueryUserData(t *testing.T) {
+	const name = "TestSceneQueryUserData"
+
+	var user *models.User
+	var sceneID int
+	if err := withTxn(func(r models.Repository) error {
+		var err error
+		user, err = createTestUser(r, name, "")
+		if err != nil {
+			return fmt.Errorf("Error creating user: %s", err.Error())
+		}
+
+		qb := r.Scene()
+		created, err := qb.Create(models.Scene{
+			Path:     name,
+			Checksum: sql.NullString{String: md5.FromString(name), Valid: true},
+			Rating:   sql.NullInt64{Int64: 5, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("Error creating scene: %s", err.Error())
+		}
+		sceneID = created.ID
+
+		if err := qb.UpdateUserData(models.SceneUserData{
+			SceneID:    sceneID,
+			UserID:     user.ID,
+			Rating:     sql.NullInt64{Int64: 2, Valid: true},
+			OCounter:   3,
+			ResumeTime: 10,
+		}); err != nil {
+			return fmt.Errorf("Error updating user data: %s", err.Error())
+		}
+
+		if _, err := qb.AddPlay(sceneID, &user.ID, time.Now()); err != nil {
+			return fmt.Errorf("Error adding play: %s", err.Error())
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	pathCriterion := &models.StringCriterionInput{
+		Value:    name,
+		Modifier: models.CriterionModifierEquals,
+	}
+	intCriterion := func(v int) *models.IntCriterionInput {
+		return &models.IntCriterionInput{
+			Value:    v,
+			Modifier: models.CriterionModifierEquals,
+		}
+	}
+	inProgress := true
+
+	tests := []struct {
+		name   string
+		user   *models.User
+		filter models.SceneFilterType
+		want   bool
+	}{
+		{"user rating", user, models.SceneFilterType{Rating: intCriterion(2)}, true},
+		{"scene rating with user", user, models.SceneFilterType{Rating: intCriterion(5)}, false},
+		{"scene rating", nil, models.SceneFilterType{Rating: intCriterion(5)}, true},
+		{"user o-counter", user, models.SceneFilterType{OCounter: intCriterion(3)}, true},
+		{"scene o-counter", nil, models.SceneFilterType{OCounter: intCriterion(0)}, true},
+		{"user play count", user, models.SceneFilterType{PlayCount: intCriterion(1)}, true},
+		{"unattributed play count", nil, models.SceneFilterType{PlayCount: intCriterion(0)}, true},
+		{"user in progress", user, models.SceneFilterType{InProgress: &inProgress}, true},
+		{"scene in progress", nil, models.SceneFilterType{InProgress: &inProgress}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			if tt.user != nil {
+				ctx = session.SetCurrentUser(ctx, tt.user)
+			}
+
+			filter := tt.filter
+			filter.Path = pathCriterion
+
+			if err := sqlite.NewTransactionManager().WithReadTxn(ctx, func(r models.ReaderRepository) error {
+				scenes := queryScene(t, r.Scene(), &filter, nil)
+				found := len(scenes) == 1 && scenes[0].ID == sceneID
+				assert.Equal(t, tt.want, found)
+				return nil
+			}); err != nil {
+				t.Error(err.Error())
+			}
+		})
+	}
+
+	// sorting by the user data should not fail
+	ctx := session.SetCurrentUser(context.TODO(), user)
+	for _, sort := range []string{"rating", "o_counter", "play_count", "last_played_at", "play_duration"} {
+		sort := sort
+		if err := sqlite.NewTransactionManager().WithReadTxn(ctx, func(r models.ReaderRepository) error {
+			scenes := queryScene(t, r.Scene(), &models.SceneFilterType{Path: pathCriterion}, &models.FindFilterType{Sort: &sort})
+			assert.Len(t, scenes, 1)
+			return nil
+		}); err != nil {
+			t.Error(err.Error())
+		}
+	}
+}
+
+func TestSceneQueryGroups(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		const prefix = "TestSceneQueryGroups"
+		tagA := tagIDs[tagIdx1WithScene]
+		tagB := tagIDs[tagIdx2WithScene]
+		studioX := studioIDs[studioIdxWithScene]
+
+		create := func(name string, tags []int, studioID int, rating int64) int {
+			path := prefix + "_" + name
+			s, err := qb.Create(models.Scene{
+				Path:     path,
+				Checksum: sql.NullString{String: md5.FromString(path), Valid: true},
+				StudioID: sql.NullInt64{Int64: int64(studioID), Valid: studioID != 0},
+				Rating:   sql.NullInt64{Int64: rating, Valid: true},
+			})
+			if err != nil {
+				t.Fatalf("Error creating scene: %s", err.Error())
+			}
+
+			if err := qb.UpdateTags(s.ID, tags); err != nil {
+				t.Fatalf("Error updating scene tags: %s", err.Error())
+			}
+
+			return s.ID
+		}
+
+		tagAStudioX := create("tagAStudioX", []int{tagA}, studioX, 2)
+		tagBStudioX := create("tagBStudioX", []int{tagB}, studioX, 4)
+		tagsAB := create("tagsAB", []int{tagA, tagB}, 0, 1)
+		noTags := create("noTags", nil, 0, 5)
+		tagA2 := create("tagA", []int{tagA}, 0, 2)
+
+		hasTag := func(id int) *models.SceneFilterType {
+			return &models.SceneFilterType{
+				Tags: &models.HierarchicalMultiCriterionInput{
+					Value:    []string{strconv.Itoa(id)},
+					Modifier: models.CriterionModifierIncludes,
+				},
+			}
+		}
+
+		query := func(f models.SceneFilterType) []int {
+			f.Path = &models.StringCriterionInput{
+				Value:    prefix,
+				Modifier: models.CriterionModifierIncludes,
+			}
+
+			var ret []int
+			for _, s := range queryScene(t, qb, &f, nil) {
+				ret = append(ret, s.ID)
+			}
+			return ret
+		}
+
+		// (tag A or tag B) and not (studio X and rating < 3)
+		assert.ElementsMatch(t, []int{tagBStudioX, tagsAB, tagA2}, query(models.SceneFilterType{
+			Any: []*models.SceneFilterType{hasTag(tagA), hasTag(tagB)},
+			Not: &models.SceneFilterType{
+				Studios: &models.HierarchicalMultiCriterionInput{
+					Value:    []string{strconv.Itoa(studioX)},
+					Modifier: models.CriterionModifierIncludes,
+				},
+				Rating: &models.IntCriterionInput{
+					Value:    3,
+					Modifier: models.CriterionModifierLessThan,
+				},
+			},
+		}))
+
+		// filters of a group may use the same criteria
+		assert.ElementsMatch(t, []int{tagsAB}, query(models.SceneFilterType{
+			All: []*models.SceneFilterType{hasTag(tagA), hasTag(tagB)},
+		}))
+
+		// groups may be nested
+		assert.ElementsMatch(t, []int{tagsAB, noTags}, query(models.SceneFilterType{
+			Any: []*models.SceneFilterType{
+				{
+					All: []*models.SceneFilterType{hasTag(tagA), hasTag(tagB)},
+				},
+				{
+					Rating: &models.IntCriterionInput{
+						Value:    4,
+						Modifier: models.CriterionModifierGreaterThan,
+					},
+				},
+			},
+		}))
+
+		assert.ElementsMatch(t, []int{tagAStudioX, tagBStudioX, noTags}, query(models.SceneFilterType{
+			Not: &models.SceneFilterType{
+				All: []*models.SceneFilterType{
+					{
+						Rating: &models.IntCriterionInput{
+							Value:    3,
+							Modifier: models.CriterionModifierLessThan,
+						},
+					},
+					{
+						Any: []*models.SceneFilterType{hasTag(tagB), {
+							Studios: &models.HierarchicalMultiCriterionInput{
+								Modifier: models.CriterionModifierIsNull,
+							},
+						}},
+					},
+				},
+			},
+		}))
+
+		return nil
+	})
+}
+
 func TestSceneQueryQTrim(t *testing.T) {
 	if err := withTxn(func(r models.Repository) error {
 		qb := r.Scene()
@@ -1707,10 +2098,11 @@ func TestSceneQueryQTrim(t *testing.T) {
 			{query: " zzz    yyy    ", id: expectedID, count: 1},
 			{query: "   \"zzz yyy xxx\" ", id: expectedID, count: 1},
 			{query: "zzz", id: expectedID, count: 1},
-			{query: "\" zzz    yyy    \"", count: 0},
-			{query: "\"zzz    yyy\"", count: 0},
-			{query: "\" zzz yyy\"", count: 0},
-			{query: "\"zzz yyy  \"", count: 0},
+			// phrases match the words in order, regardless of whitespace
+			{query: "\" zzz    yyy    \"", id: expectedID, count: 1},
+			{query: "\"zzz    yyy\"", id: expectedID, count: 1},
+			{query: "\"yyy zzz\"", count: 0},
+			{query: "\"zzz xxx\"", count: 0},
 		}
 
 		for _, tst := range tests {
@@ -1735,6 +2127,259 @@ func TestSceneQueryQTrim(t *testing.T) {
 	}
 }
 
+func setScenePhashes(phashes map[int]sql.NullInt64) error {
+	return withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+		for idx, phash := range phashes {
+			phash := phash
+			if _, err := qb.Update(models.ScenePartial{
+				ID:    sceneIDs[idx],
+				Phash: &phash,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func TestSceneFindDuplicates(t *testing.T) {
+	const (
+		phash1 = 0x1234567890abcdef
+		phash2 = 0x7edcba0987654321
+	)
+
+	valid := func(v int64) sql.NullInt64 {
+		return sql.NullInt64{Int64: v, Valid: true}
+	}
+
+	// scenes 1 and 5 have the same duration, scene 2 is 100 seconds longer
+	// scenes 3 and 7 have the same duration
+	phashes := map[int]sql.NullInt64{
+		1: valid(phash1),
+		2: valid(phash1 ^ 1),
+		5: valid(phash1),
+		3: valid(phash2),
+		7: valid(phash2),
+	}
+	if err := setScenePhashes(phashes); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	defer func() {
+		for idx := range phashes {
+			phashes[idx] = sql.NullInt64{}
+		}
+		if err := setScenePhashes(phashes); err != nil {
+			t.Error(err.Error())
+		}
+	}()
+
+	findDuplicates := func(options models.DuplicateOptions) [][]int {
+		var ret [][]int
+		if err := withTxn(func(r models.Repository) error {
+			dupes, err := r.Scene().FindDuplicates(options)
+			if err != nil {
+				return err
+			}
+
+			for _, group := range dupes {
+				var ids []int
+				for _, s := range group {
+					ids = append(ids, s.ID)
+				}
+				ret = append(ret, ids)
+			}
+			return nil
+		}); err != nil {
+			t.Error(err.Error())
+		}
+
+		return ret
+	}
+
+	assert := assert.New(t)
+
+	// groups with the same total size are ordered by id
+	assert.Equal([][]int{
+		{sceneIDs[1], sceneIDs[5]},
+		{sceneIDs[3], sceneIDs[7]},
+	}, findDuplicates(models.DuplicateOptions{DurationDiff: -1}))
+
+	assert.Equal([][]int{
+		{sceneIDs[1], sceneIDs[2], sceneIDs[5]},
+		{sceneIDs[3], sceneIDs[7]},
+	}, findDuplicates(models.DuplicateOptions{Distance: 1, DurationDiff: -1}))
+
+	assert.Equal([][]int{
+		{sceneIDs[1], sceneIDs[5]},
+		{sceneIDs[3], sceneIDs[7]},
+	}, findDuplicates(models.DuplicateOptions{Distance: 1, DurationDiff: 10}))
+
+	page := 2
+	perPage := 1
+	assert.Equal([][]int{
+		{sceneIDs[3], sceneIDs[7]},
+	}, findDuplicates(models.DuplicateOptions{
+		Distance:     1,
+		DurationDiff: -1,
+		FindFilter: &models.FindFilterType{
+			Page:    &page,
+			PerPage: &perPage,
+		},
+	}))
+
+	// committed changes are seen by later queries
+	if err := setScenePhashes(map[int]sql.NullInt64{
+		5: {},
+		7: valid(phash1),
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	assert.Equal([][]int{
+		{sceneIDs[1], sceneIDs[2], sceneIDs[7]},
+	}, findDuplicates(models.DuplicateOptions{Distance: 1, DurationDiff: -1}))
+}
+
+func TestSceneMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	if err := withRollbackTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		create := func(name string, oCounter int) (*models.Scene, error) {
+			return qb.Create(models.Scene{
+				Path:     name,
+				Checksum: sql.NullString{String: md5.FromString(name), Valid: true},
+				OCounter: oCounter,
+			})
+		}
+
+		dest, err := create("merge_destination", 1)
+		if err != nil {
+			return err
+		}
+		src1, err := create("merge_source_1", 2)
+		if err != nil {
+			return err
+		}
+		src2, err := create("merge_source_2", 3)
+		if err != nil {
+			return err
+		}
+
+		// try merging into same scene
+		assert.NotNil(qb.Merge([]int{dest.ID}, dest.ID))
+
+		tag1 := tagIDs[tagIdx1WithScene]
+		tag2 := tagIDs[tagIdx2WithScene]
+		if err := qb.UpdateTags(dest.ID, []int{tag1}); err != nil {
+			return err
+		}
+		// both sources share tag2
+		if err := qb.UpdateTags(src1.ID, []int{tag1, tag2}); err != nil {
+			return err
+		}
+		if err := qb.UpdateTags(src2.ID, []int{tag2}); err != nil {
+			return err
+		}
+
+		if err := qb.UpdatePerformers(src1.ID, []int{performerIDs[performerIdx1WithScene]}); err != nil {
+			return err
+		}
+
+		if err := qb.UpdateStashIDs(dest.ID, []models.StashID{{Endpoint: "endpoint1", StashID: "dest"}}); err != nil {
+			return err
+		}
+		if err := qb.UpdateStashIDs(src1.ID, []models.StashID{
+			{Endpoint: "endpoint1", StashID: "source"},
+			{Endpoint: "endpoint2", StashID: "source"},
+		}); err != nil {
+			return err
+		}
+
+		if _, err := qb.AddPlay(src2.ID, nil, time.Now()); err != nil {
+			return err
+		}
+
+		now := models.SQLiteTimestamp{Timestamp: time.Now()}
+		if _, err := r.SceneMarker().Create(models.SceneMarker{
+			Title:        "merge marker",
+			PrimaryTagID: tag1,
+			SceneID:      sql.NullInt64{Int64: int64(src1.ID), Valid: true},
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}); err != nil {
+			return err
+		}
+
+		if err := qb.Merge([]int{src1.ID, src2.ID}, dest.ID); err != nil {
+			return err
+		}
+
+		for _, id := range []int{src1.ID, src2.ID} {
+			s, err := qb.Find(id)
+			if err != nil {
+				return err
+			}
+			assert.Nil(s)
+		}
+
+		merged, err := qb.Find(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(6, merged.OCounter)
+		// the destination keeps its primary file
+		assert.Equal(dest.Path, merged.Path)
+
+		files, err := qb.GetFiles(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Len(files, 3)
+
+		destTagIDs, err := qb.GetTagIDs(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.ElementsMatch([]int{tag1, tag2}, destTagIDs)
+
+		performers, err := qb.GetPerformerIDs(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal([]int{performerIDs[performerIdx1WithScene]}, performers)
+
+		stashIDs, err := qb.GetStashIDs(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.ElementsMatch([]*models.StashID{
+			{Endpoint: "endpoint1", StashID: "dest"},
+			{Endpoint: "endpoint2", StashID: "source"},
+		}, stashIDs)
+
+		plays, err := qb.GetPlayHistory(dest.ID, nil)
+		if err != nil {
+			return err
+		}
+		assert.Len(plays, 1)
+
+		markers, err := r.SceneMarker().FindBySceneID(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Len(markers, 1)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 // TODO Update
 // TODO IncrementOCounter
 // TODO DecrementOCounter
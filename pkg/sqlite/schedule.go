@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const scheduleTable = "schedules"
+
+type scheduleQueryBuilder struct {
+	repository
+}
+
+func NewScheduleReaderWriter(tx dbi) *scheduleQueryBuilder {
+	return &scheduleQueryBuilder{
+		repository{
+			tx:        tx,
+			tableName: scheduleTable,
+			idColumn:  idColumn,
+		},
+	}
+}
+
+func (qb *scheduleQueryBuilder) Create(newObject models.Schedule) (*models.Schedule, error) {
+	var ret models.Schedule
+	if err := qb.insertObject(newObject, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *scheduleQueryBuilder) Update(updatedObject models.Schedule) (*models.Schedule, error) {
+	const partial = false
+	if err := qb.update(updatedObject.ID, updatedObject, partial); err != nil {
+		return nil, err
+	}
+
+	var ret models.Schedule
+	if err := qb.get(updatedObject.ID, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *scheduleQueryBuilder) Destroy(id int) error {
+	return qb.destroyExisting([]int{id})
+}
+
+func (qb *scheduleQueryBuilder) Find(id int) (*models.Schedule, error) {
+	var ret models.Schedule
+	if err := qb.get(id, &ret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (qb *scheduleQueryBuilder) FindByName(name string) (*models.Schedule, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE name = ? LIMIT 1`, scheduleTable)
+
+	var ret models.Schedules
+	if err := qb.query(query, []interface{}{name}, &ret); err != nil {
+		return nil, err
+	}
+
+	if len(ret) > 0 {
+		return ret[0], nil
+	}
+
+	return nil, nil
+}
+
+func (qb *scheduleQueryBuilder) All() ([]*models.Schedule, error) {
+	var ret models.Schedules
+	if err := qb.query(selectAll(scheduleTable)+getSort("name", "ASC", scheduleTable), nil, &ret); err != nil {
+		return nil, err
+	}
+
+	return []*models.Schedule(ret), nil
+}
@@ -0,0 +1,77 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleCreateUpdate(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		qb := r.Schedule()
+
+		const name = "TestScheduleCreateUpdate"
+		now := models.SQLiteTimestamp{Timestamp: time.Now()}
+		schedule, err := qb.Create(models.Schedule{
+			Name:      name,
+			Task:      models.ScheduledTaskScan,
+			Interval:  sql.NullInt64{Int64: 3600, Valid: true},
+			Enabled:   true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			t.Errorf("Error creating schedule: %s", err.Error())
+			return nil
+		}
+
+		found, err := qb.FindByName(name)
+		if err != nil {
+			t.Errorf("Error finding schedule: %s", err.Error())
+			return nil
+		}
+		if assert.NotNil(t, found) {
+			assert.Equal(t, schedule.ID, found.ID)
+			assert.Equal(t, int64(3600), found.Interval.Int64)
+			assert.False(t, found.Cron.Valid)
+		}
+
+		schedule.Interval = sql.NullInt64{}
+		schedule.Cron = sql.NullString{String: "0 3 * * *", Valid: true}
+		schedule.LastRunAt = models.NullSQLiteTimestamp{Timestamp: time.Now(), Valid: true}
+		schedule.LastJobID = sql.NullInt64{Int64: 5, Valid: true}
+		schedule.LastResult = sql.NullString{String: models.ScheduleRunResultFinished.String(), Valid: true}
+
+		updated, err := qb.Update(*schedule)
+		if err != nil {
+			t.Errorf("Error updating schedule: %s", err.Error())
+			return nil
+		}
+
+		assert.False(t, updated.Interval.Valid)
+		assert.Equal(t, "0 3 * * *", updated.Cron.String)
+		assert.True(t, updated.LastRunAt.Valid)
+		assert.Equal(t, int64(5), updated.LastJobID.Int64)
+		assert.Equal(t, models.ScheduleRunResultFinished.String(), updated.LastResult.String)
+
+		if err := qb.Destroy(schedule.ID); err != nil {
+			t.Errorf("Error destroying schedule: %s", err.Error())
+			return nil
+		}
+
+		found, err = qb.Find(schedule.ID)
+		if err != nil {
+			t.Errorf("Error finding schedule: %s", err.Error())
+			return nil
+		}
+		assert.Nil(t, found)
+
+		return nil
+	})
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// searchTables are the full-text search tables of each search result type.
+var searchTables = []struct {
+	resultType models.SearchResultType
+	fts        ftsTable
+}{
+	{models.SearchResultTypeScene, scenesFTS},
+	{models.SearchResultTypeSceneMarker, sceneMarkersFTS},
+	{models.SearchResultTypeImage, imagesFTS},
+	{models.SearchResultTypeGallery, galleriesFTS},
+	{models.SearchResultTypePerformer, performersFTS},
+	{models.SearchResultTypeStudio, studiosFTS},
+	{models.SearchResultTypeTag, tagsFTS},
+	{models.SearchResultTypeMovie, moviesFTS},
+}
+
+type searchQueryBuilder struct {
+	tx          dbi
+	restriction *models.ContentRestriction
+}
+
+func NewSearchReader(tx dbi) *searchQueryBuilder {
+	return &searchQueryBuilder{
+		tx: tx,
+	}
+}
+
+// restrict hides the objects excluded by the restriction from the search.
+func (qb *searchQueryBuilder) restrict(restriction *models.ContentRestriction) {
+	qb.restriction = restriction
+}
+
+func (qb *searchQueryBuilder) Search(q string, types []models.SearchResultType, limit int) ([]*models.SearchMatch, error) {
+	match, notMatch := getFTSQuery(models.ParseSearchString(q))
+	if match == "" || limit <= 0 {
+		return nil, nil
+	}
+
+	include := make(map[models.SearchResultType]bool)
+	for _, t := range types {
+		include[t] = true
+	}
+
+	var queries []string
+	var args []interface{}
+	for _, t := range searchTables {
+		if len(types) > 0 && !include[t.resultType] {
+			continue
+		}
+
+		query := fmt.Sprintf("SELECT '%[1]s' AS type, rowid AS id, rank FROM %[2]s WHERE %[2]s MATCH ?", t.resultType, t.fts.name)
+		args = append(args, match)
+
+		if notMatch != "" {
+			query += fmt.Sprintf(" AND rowid NOT IN (SELECT rowid FROM %[1]s WHERE %[1]s MATCH ?)", t.fts.name)
+			args = append(args, notMatch)
+		}
+
+		if restricted := getRestrictedIDsQuery(t.fts.table, qb.restriction); restricted != "" {
+			query += fmt.Sprintf(" AND rowid NOT IN (%s)", restricted)
+		}
+
+		queries = append(queries, query)
+	}
+
+	if len(queries) == 0 {
+		return nil, nil
+	}
+
+	// bm25 ranks depend on the statistics of each table, so are not
+	// comparable between types. The matches of each type are interleaved
+	// by their position in the type instead.
+	query := "SELECT type, id, rank FROM (" +
+		"SELECT type, id, rank, ROW_NUMBER() OVER (PARTITION BY type ORDER BY rank ASC, id ASC) AS position FROM (" + strings.Join(queries, " UNION ALL ") + ")" +
+		") ORDER BY position ASC, type ASC LIMIT ?"
+	args = append(args, limit)
+
+	var ret []*models.SearchMatch
+	if err := qb.tx.Select(&ret, query, args...); err != nil {
+		return nil, fmt.Errorf("searching: %w", err)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,365 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stashapp/stash/pkg/hash/md5"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sqlite"
+)
+
+func createSearchPerformer(qb models.PerformerWriter, name string, aliases string) (*models.Performer, error) {
+	return qb.Create(models.Performer{
+		Name:     sql.NullString{String: name, Valid: true},
+		Aliases:  sql.NullString{String: aliases, Valid: aliases != ""},
+		Checksum: md5.FromString(name),
+		Favorite: sql.NullBool{Bool: false, Valid: true},
+	})
+}
+
+func TestPerformerQueryFullTextSearch(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		qb := r.Performer()
+
+		accented, err := createSearchPerformer(qb, "Zoë Fröhlich", "")
+		if err != nil {
+			t.Errorf("Error creating performer: %s", err.Error())
+			return nil
+		}
+
+		alias, err := createSearchPerformer(qb, "Search Alias", "Kestrel")
+		if err != nil {
+			t.Errorf("Error creating performer: %s", err.Error())
+			return nil
+		}
+
+		name, err := createSearchPerformer(qb, "Kestrel", "")
+		if err != nil {
+			t.Errorf("Error creating performer: %s", err.Error())
+			return nil
+		}
+
+		query := func(q string) []int {
+			findFilter := models.FindFilterType{
+				Q: &q,
+			}
+
+			var ret []int
+			for _, p := range queryPerformers(t, qb, nil, &findFilter) {
+				ret = append(ret, p.ID)
+			}
+			return ret
+		}
+
+		// prefixes match, ignoring case and diacritics
+		assert.Equal(t, []int{accented.ID}, query("zoe FRO"))
+		assert.Equal(t, []int{accented.ID}, query("fröh"))
+		assert.Len(t, query("zoe -frohlich"), 0)
+
+		// matches of the name are more relevant than matches of the aliases
+		assert.Equal(t, []int{name.ID, alias.ID}, query("kestrel"))
+
+		sort := "relevance"
+		q := "kestrel"
+		performers := queryPerformers(t, qb, nil, &models.FindFilterType{
+			Q:    &q,
+			Sort: &sort,
+		})
+		if assert.Len(t, performers, 2) {
+			assert.Equal(t, name.ID, performers[0].ID)
+		}
+
+		// other sorts are kept
+		sort = "name"
+		performers = queryPerformers(t, qb, nil, &models.FindFilterType{
+			Q:    &q,
+			Sort: &sort,
+		})
+		if assert.Len(t, performers, 2) {
+			assert.Equal(t, name.ID, performers[0].ID)
+			assert.Equal(t, alias.ID, performers[1].ID)
+		}
+
+		// the search is updated with the performer
+		newName := "Osprey"
+		if _, err := qb.Update(models.PerformerPartial{
+			ID:   name.ID,
+			Name: &sql.NullString{String: newName, Valid: true},
+		}); err != nil {
+			t.Errorf("Error updating performer: %s", err.Error())
+			return nil
+		}
+
+		assert.Equal(t, []int{alias.ID}, query("kestrel"))
+		assert.Equal(t, []int{name.ID}, query("osp"))
+
+		if err := qb.Destroy(name.ID); err != nil {
+			t.Errorf("Error destroying performer: %s", err.Error())
+			return nil
+		}
+
+		assert.Len(t, query("osprey"), 0)
+
+		return nil
+	})
+}
+
+func TestSceneQueryFullTextSearchMarkers(t *testing.T) {
+	withRollbackTxn(func(r models.Repository) error {
+		qb := r.Scene()
+		mqb := r.SceneMarker()
+
+		const path = "TestSceneQueryFullTextSearchMarkers"
+		scene, err := qb.Create(models.Scene{
+			Path:     path,
+			Title:    sql.NullString{String: "Pelican", Valid: true},
+			Checksum: sql.NullString{String: md5.FromString(path), Valid: true},
+		})
+		if err != nil {
+			t.Errorf("Error creating scene: %s", err.Error())
+			return nil
+		}
+
+		marker, err := mqb.Create(models.SceneMarker{
+			Title:        "Albatross",
+			SceneID:      sql.NullInt64{Int64: int64(scene.ID), Valid: true},
+			PrimaryTagID: tagIDs[tagIdxWithPrimaryMarkers],
+		})
+		if err != nil {
+			t.Errorf("Error creating marker: %s", err.Error())
+			return nil
+		}
+
+		querySceneIDs := func(q string) []int {
+			var ret []int
+			for _, s := range queryScene(t, qb, nil, &models.FindFilterType{Q: &q}) {
+				ret = append(ret, s.ID)
+			}
+			return ret
+		}
+
+		queryMarkerIDs := func(q string) []int {
+			markers, _, err := mqb.Query(nil, &models.FindFilterType{Q: &q})
+			if err != nil {
+				t.Errorf("Error querying markers: %s", err.Error())
+			}
+
+			var ret []int
+			for _, m := range markers {
+				ret = append(ret, m.ID)
+			}
+			return ret
+		}
+
+		// scenes match the titles of their markers, and markers match the
+		// title of their scene
+		assert.Equal(t, []int{scene.ID}, querySceneIDs("albatross"))
+		assert.Equal(t, []int{marker.ID}, queryMarkerIDs("pelican"))
+
+		if _, err := qb.Update(models.ScenePartial{
+			ID:    scene.ID,
+			Title: &sql.NullString{String: "Cormorant", Valid: true},
+		}); err != nil {
+			t.Errorf("Error updating scene: %s", err.Error())
+			return nil
+		}
+
+		assert.Len(t, queryMarkerIDs("pelican"), 0)
+		assert.Equal(t, []int{marker.ID}, queryMarkerIDs("cormorant"))
+
+		if err := mqb.Destroy(marker.ID); err != nil {
+			t.Errorf("Error destroying marker: %s", err.Error())
+			return nil
+		}
+
+		assert.Len(t, querySceneIDs("albatross"), 0)
+
+		return nil
+	})
+}
+
+func TestSearch(t *testing.T) {
+	const name = "Quokka"
+
+	var tag *models.Tag
+	var performer *models.Performer
+	var scene *models.Scene
+	if err := withTxn(func(r models.Repository) error {
+		var err error
+		tag, err = r.Tag().Create(models.Tag{
+			Name: name,
+		})
+		if err != nil {
+			return err
+		}
+
+		performer, err = createSearchPerformer(r.Performer(), name+" Search", "")
+		if err != nil {
+			return err
+		}
+
+		const path = "TestSearch"
+		scene, err = r.Scene().Create(models.Scene{
+			Path:     path,
+			Title:    sql.NullString{String: "Quokkas at the beach", Valid: true},
+			Checksum: sql.NullString{String: md5.FromString(path), Valid: true},
+		})
+		return err
+	}); err != nil {
+		t.Errorf("Error creating objects: %s", err.Error())
+		return
+	}
+
+	defer func() {
+		if err := withTxn(func(r models.Repository) error {
+			if err := r.Tag().Destroy(tag.ID); err != nil {
+				return err
+			}
+			if err := r.Performer().Destroy(performer.ID); err != nil {
+				return err
+			}
+			return r.Scene().Destroy(scene.ID)
+		}); err != nil {
+			t.Errorf("Error destroying objects: %s", err.Error())
+		}
+	}()
+
+	search := func(ctx context.Context, q string, types []models.SearchResultType) []models.SearchMatch {
+		var ret []models.SearchMatch
+		if err := sqlite.NewTransactionManager().WithReadTxn(ctx, func(r models.ReaderRepository) error {
+			matches, err := r.Search().Search(q, types, 10)
+			for _, m := range matches {
+				ret = append(ret, models.SearchMatch{Type: m.Type, ID: m.ID})
+			}
+			return err
+		}); err != nil {
+			t.Errorf("Error searching: %s", err.Error())
+		}
+
+		return ret
+	}
+
+	tagMatch := models.SearchMatch{Type: models.SearchResultTypeTag, ID: tag.ID}
+	performerMatch := models.SearchMatch{Type: models.SearchResultTypePerformer, ID: performer.ID}
+	sceneMatch := models.SearchMatch{Type: models.SearchResultTypeScene, ID: scene.ID}
+
+	ctx := context.Background()
+	assert.ElementsMatch(t, []models.SearchMatch{tagMatch, performerMatch, sceneMatch}, search(ctx, "quok", nil))
+	assert.ElementsMatch(t, []models.SearchMatch{tagMatch, performerMatch}, search(ctx, "quokka -beach", nil))
+	assert.Equal(t, []models.SearchMatch{sceneMatch}, search(ctx, "quokka", []models.SearchResultType{models.SearchResultTypeScene}))
+	assert.Len(t, search(ctx, "-quokka", nil), 0)
+
+	// restricted objects are not returned
+	restricted := models.SetContentRestriction(ctx, &models.ContentRestriction{
+		TagIDs: []int{tag.ID},
+	})
+	assert.ElementsMatch(t, []models.SearchMatch{performerMatch, sceneMatch}, search(restricted, "quok", nil))
+}
+
+func TestSearchInterleavesTypes(t *testing.T) {
+	var tagIDs []int
+	var performerID int
+	if err := withTxn(func(r models.Repository) error {
+		for _, name := range []string{"Wombat", "Wombat Two", "Wombat Three"} {
+			tag, err := r.Tag().Create(models.Tag{Name: name})
+			if err != nil {
+				return err
+			}
+			tagIDs = append(tagIDs, tag.ID)
+		}
+
+		performer, err := createSearchPerformer(r.Performer(), "Wombat Search Performer", "")
+		if err != nil {
+			return err
+		}
+		performerID = performer.ID
+		return nil
+	}); err != nil {
+		t.Errorf("Error creating objects: %s", err.Error())
+		return
+	}
+
+	defer func() {
+		if err := withTxn(func(r models.Repository) error {
+			for _, id := range tagIDs {
+				if err := r.Tag().Destroy(id); err != nil {
+					return err
+				}
+			}
+			return r.Performer().Destroy(performerID)
+		}); err != nil {
+			t.Errorf("Error destroying objects: %s", err.Error())
+		}
+	}()
+
+	// the best match of each type is returned before the other matches
+	var types []models.SearchResultType
+	if err := sqlite.NewTransactionManager().WithReadTxn(context.Background(), func(r models.ReaderRepository) error {
+		matches, err := r.Search().Search("wombat", nil, 2)
+		for _, m := range matches {
+			types = append(types, m.Type)
+		}
+		return err
+	}); err != nil {
+		t.Errorf("Error searching: %s", err.Error())
+	}
+
+	assert.ElementsMatch(t, []models.SearchResultType{models.SearchResultTypeTag, models.SearchResultTypePerformer}, types)
+}
+
+func TestSceneQueryFilterQuery(t *testing.T) {
+	tagID := strconv.Itoa(tagIDs[tagIdx2WithScene])
+	tagName := strings.ToUpper(getTagStringValue(tagIdx2WithScene, "Name"))
+
+	apply := func(s string) (*models.SceneFilterType, error) {
+		sceneFilter := &models.SceneFilterType{}
+		err := sqlite.NewTransactionManager().WithReadTxn(context.Background(), func(r models.ReaderRepository) error {
+			q, err := models.ParseFilterQuery(s)
+			if err != nil {
+				return err
+			}
+			return q.Apply(sceneFilter, models.NewFilterQueryResolver(r))
+		})
+		return sceneFilter, err
+	}
+
+	// names are resolved ignoring case
+	sceneFilter, err := apply(fmt.Sprintf(`tag:"%s" -performer_count:>0`, tagName))
+	if err != nil {
+		t.Errorf("Error applying filter query: %s", err.Error())
+		return
+	}
+
+	want := &models.SceneFilterType{
+		Tags: &models.HierarchicalMultiCriterionInput{
+			Value:    []string{tagID},
+			Modifier: models.CriterionModifierIncludesAll,
+		},
+		PerformerCount: &models.IntCriterionInput{
+			Value:    1,
+			Modifier: models.CriterionModifierLessThan,
+		},
+	}
+	assert.Equal(t, want, sceneFilter)
+
+	withTxn(func(r models.Repository) error {
+		scenes := queryScene(t, r.Scene(), sceneFilter, nil)
+		if assert.NotEmpty(t, scenes) {
+			assert.Equal(t, queryScene(t, r.Scene(), want, nil), scenes)
+		}
+		return nil
+	})
+
+	_, err = apply(`tag:"not a tag"`)
+	assert.EqualError(t, err, `filter query: position 1: tag "not a tag" not found`)
+}
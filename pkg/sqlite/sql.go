@@ -187,6 +187,42 @@ func getIntWhereClause(column string, modifier models.CriterionModifier, value i
 	panic("unsupported int modifier type")
 }
 
+// getTimestampCriterionWhereClause returns the where clause for a timestamp
+// criterion. Values are normalised using the sqlite datetime function so that
+// dates and timestamps with different time zones compare correctly.
+func getTimestampCriterionWhereClause(column string, input models.TimestampCriterionInput) (string, []interface{}) {
+	column = fmt.Sprintf("datetime(%s)", column)
+
+	var upper string
+	if input.Value2 != nil {
+		upper = *input.Value2
+	}
+
+	args := []interface{}{input.Value}
+	betweenArgs := []interface{}{input.Value, upper}
+
+	switch input.Modifier {
+	case models.CriterionModifierIsNull:
+		return fmt.Sprintf("%s IS NULL", column), nil
+	case models.CriterionModifierNotNull:
+		return fmt.Sprintf("%s IS NOT NULL", column), nil
+	case models.CriterionModifierEquals:
+		return fmt.Sprintf("%s = datetime(?)", column), args
+	case models.CriterionModifierNotEquals:
+		return fmt.Sprintf("%s != datetime(?)", column), args
+	case models.CriterionModifierBetween:
+		return fmt.Sprintf("%s BETWEEN datetime(?) AND datetime(?)", column), betweenArgs
+	case models.CriterionModifierNotBetween:
+		return fmt.Sprintf("%s NOT BETWEEN datetime(?) AND datetime(?)", column), betweenArgs
+	case models.CriterionModifierLessThan:
+		return fmt.Sprintf("%s < datetime(?)", column), args
+	case models.CriterionModifierGreaterThan:
+		return fmt.Sprintf("%s > datetime(?)", column), args
+	}
+
+	panic("unsupported timestamp modifier type")
+}
+
 // returns where clause and having clause
 func getMultiCriterionClause(primaryTable, foreignTable, joinTable, primaryFK, foreignFK string, criterion *models.MultiCriterionInput) (string, string) {
 	whereClause := ""
@@ -246,11 +282,3 @@ func getImage(tx dbi, query string, args ...interface{}) ([]byte, error) {
 
 	return ret, nil
 }
-
-func coalesce(column string) string {
-	return fmt.Sprintf("COALESCE(%s, '')", column)
-}
-
-func like(v string) string {
-	return "%" + v + "%"
-}
@@ -34,6 +34,10 @@ func (qb *studioQueryBuilder) Create(newObject models.Studio) (*models.Studio, e
 		return nil, err
 	}
 
+	if err := studiosFTS.update(qb.tx, ret.ID); err != nil {
+		return nil, err
+	}
+
 	return &ret, nil
 }
 
@@ -43,6 +47,10 @@ func (qb *studioQueryBuilder) Update(updatedObject models.StudioPartial) (*model
 		return nil, err
 	}
 
+	if err := studiosFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.Find(updatedObject.ID)
 }
 
@@ -52,6 +60,10 @@ func (qb *studioQueryBuilder) UpdateFull(updatedObject models.Studio) (*models.S
 		return nil, err
 	}
 
+	if err := studiosFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.Find(updatedObject.ID)
 }
 
@@ -63,24 +75,135 @@ func (qb *studioQueryBuilder) Destroy(id int) error {
 		return err
 	}
 
-	return qb.destroyExisting([]int{id})
+	if err := qb.destroyExisting([]int{id}); err != nil {
+		return err
+	}
+
+	return studiosFTS.update(qb.tx, id)
 }
 
-func (qb *studioQueryBuilder) Find(id int) (*models.Studio, error) {
-	var ret models.Studio
-	if err := qb.get(id, &ret); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
+// Merge moves the scenes, images, galleries, movies, child studios, stash
+// ids and restriction profiles of the source studios to the destination
+// studio, then destroys the source studios. The names and aliases of the
+// sources are added to the aliases of the destination, and the image and url
+// of the sources are used where the destination has none.
+func (qb *studioQueryBuilder) Merge(source []int, destination int) error {
+	if len(source) == 0 {
+		return nil
+	}
+
+	if err := checkMergeIDs(source, destination); err != nil {
+		return err
+	}
+
+	dest, err := qb.find(destination)
+	if err != nil {
+		return err
+	}
+	if dest == nil {
+		return fmt.Errorf("studio with id %d not found", destination)
+	}
+
+	aliases, err := qb.GetAliases(destination)
+	if err != nil {
+		return err
+	}
+
+	urls := []sql.NullString{dest.URL}
+	for _, id := range source {
+		s, err := qb.find(id)
+		if err != nil {
+			return err
+		}
+		if s == nil {
+			return fmt.Errorf("studio with id %d not found", id)
+		}
+
+		sourceAliases, err := qb.GetAliases(id)
+		if err != nil {
+			return err
 		}
+
+		aliases = append(aliases, s.Name.String)
+		aliases = append(aliases, sourceAliases...)
+		urls = append(urls, s.URL)
+	}
+
+	inBinding := getInBinding(len(source))
+	args := []interface{}{destination}
+	for _, id := range source {
+		args = append(args, id)
+	}
+
+	for _, table := range []string{sceneTable, imageTable, galleryTable, movieTable} {
+		if _, err := qb.tx.Exec("UPDATE "+table+" SET studio_id = ? WHERE studio_id IN "+inBinding, args...); err != nil {
+			return err
+		}
+	}
+
+	if _, err := qb.tx.Exec("UPDATE "+studioTable+" SET parent_id = ? WHERE parent_id IN "+inBinding+" AND id != ?", append(args, destination)...); err != nil {
+		return err
+	}
+
+	// the destination cannot be its own parent
+	if _, err := qb.tx.Exec("UPDATE "+studioTable+" SET parent_id = NULL WHERE id = ? AND parent_id IN "+inBinding, args...); err != nil {
+		return err
+	}
+
+	// the column identifying the related object of each join table
+	joinTables := map[string]string{
+		"studio_stash_ids":              "endpoint",
+		restrictionProfilesStudiosTable: restrictionProfileIDColumn,
+		"studios_image":                 "",
+	}
+
+	if err := moveJoins(qb.tx, joinTables, studioIDColumn, source, destination); err != nil {
+		return err
+	}
+
+	// aliases are unique, so the sources and their aliases are removed
+	// before the aliases are added to the destination
+	for _, id := range source {
+		if err := qb.Destroy(id); err != nil {
+			return err
+		}
+	}
+
+	if err := qb.UpdateAliases(destination, combineAliases(dest.Name.String, aliases)); err != nil {
+		return err
+	}
+
+	url := firstNonEmpty(urls...)
+	if _, err := qb.Update(models.StudioPartial{
+		ID:  destination,
+		URL: &url,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (qb *studioQueryBuilder) Find(id int) (*models.Studio, error) {
+	if restricted, err := qb.isRestricted(id); err != nil || restricted {
 		return nil, err
 	}
-	return &ret, nil
+
+	return qb.find(id)
 }
 
+// FindMany returns the studios with the provided ids. Studios hidden by the
+// content restriction are omitted.
 func (qb *studioQueryBuilder) FindMany(ids []int) ([]*models.Studio, error) {
 	var studios []*models.Studio
 	for _, id := range ids {
-		studio, err := qb.Find(id)
+		if restricted, err := qb.isRestricted(id); err != nil {
+			return nil, err
+		} else if restricted {
+			continue
+		}
+
+		studio, err := qb.find(id)
 		if err != nil {
 			return nil, err
 		}
@@ -95,6 +218,17 @@ func (qb *studioQueryBuilder) FindMany(ids []int) ([]*models.Studio, error) {
 	return studios, nil
 }
 
+func (qb *studioQueryBuilder) find(id int) (*models.Studio, error) {
+	var ret models.Studio
+	if err := qb.get(id, &ret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
 func (qb *studioQueryBuilder) FindChildren(id int) ([]*models.Studio, error) {
 	query := "SELECT studios.* FROM studios WHERE studios.parent_id = ?"
 	args := []interface{}{id}
@@ -128,7 +262,7 @@ func (qb *studioQueryBuilder) FindByStashID(stashID models.StashID) ([]*models.S
 }
 
 func (qb *studioQueryBuilder) Count() (int, error) {
-	return qb.runCountQuery(qb.buildCountQuery("SELECT studios.id FROM studios"), nil)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery("SELECT studios.id FROM studios"), nil)
 }
 
 func (qb *studioQueryBuilder) All() ([]*models.Studio, error) {
@@ -239,12 +373,10 @@ func (qb *studioQueryBuilder) Query(studioFilter *models.StudioFilterType, findF
 
 	query := qb.newQuery()
 	distinctIDs(&query, studioTable)
+	query.addWhere(qb.restrictionClause())
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		query.join(studioAliasesTable, "", "studio_aliases.studio_id = studios.id")
-		searchColumns := []string{"studios.name", "studio_aliases.alias"}
-
-		query.parseQueryString(searchColumns, *q)
+		query.addFullTextSearch(studiosFTS, *q)
 	}
 
 	if err := qb.validateFilter(studioFilter); err != nil {
@@ -254,7 +386,11 @@ func (qb *studioQueryBuilder) Query(studioFilter *models.StudioFilterType, findF
 
 	query.addFilter(filter)
 
-	query.sortAndPagination = qb.getStudioSort(findFilter) + getPagination(findFilter)
+	sort, ok := query.getRelevanceSort(studioTable, findFilter)
+	if !ok {
+		sort = qb.getStudioSort(findFilter)
+	}
+	query.sortAndPagination = sort + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
@@ -383,7 +519,7 @@ func (qb *studioQueryBuilder) queryStudio(query string, args []interface{}) (*mo
 
 func (qb *studioQueryBuilder) queryStudios(query string, args []interface{}) ([]*models.Studio, error) {
 	var ret models.Studios
-	if err := qb.query(query, args, &ret); err != nil {
+	if err := qb.query(qb.restrictQuery(query), args, &ret); err != nil {
 		return nil, err
 	}
 
@@ -451,5 +587,9 @@ func (qb *studioQueryBuilder) GetAliases(studioID int) ([]string, error) {
 }
 
 func (qb *studioQueryBuilder) UpdateAliases(studioID int, aliases []string) error {
-	return qb.aliasRepository().replace(studioID, aliases)
+	if err := qb.aliasRepository().replace(studioID, aliases); err != nil {
+		return err
+	}
+
+	return studiosFTS.update(qb.tx, studioID)
 }
@@ -937,6 +937,105 @@ func TestStudioQueryFast(t *testing.T) {
 	})
 }
 
+func TestStudioMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	// merge tests - perform these in a transaction that we'll rollback
+	if err := withRollbackTxn(func(r models.Repository) error {
+		qb := r.Studio()
+
+		// try merging into same studio
+		err := qb.Merge([]int{studioIDs[studioIdxWithScene]}, studioIDs[studioIdxWithScene])
+		assert.NotNil(err)
+
+		// the destination is a child of a source
+		parentID := int64(studioIDs[studioIdxWithMovie])
+		dest, err := createStudio(qb, "merge_destination", &parentID)
+		if err != nil {
+			return err
+		}
+
+		if err := qb.UpdateAliases(dest.ID, []string{"merge alias"}); err != nil {
+			return err
+		}
+
+		srcIdxs := []int{
+			studioIdxWithScene,
+			studioIdxWithMovie,
+			studioIdxWithImage,
+			studioIdxWithGallery,
+			studioIdxWithChildStudio,
+		}
+		var srcIDs []int
+		// the names and aliases of the sources become aliases
+		aliases := []string{"merge alias"}
+		for _, idx := range srcIdxs {
+			srcIDs = append(srcIDs, studioIDs[idx])
+			aliases = append(aliases, getStudioStringValue(idx, "Name"), getStudioStringValue(idx, "Alias"))
+		}
+
+		if err := qb.Merge(srcIDs, dest.ID); err != nil {
+			return err
+		}
+
+		for _, id := range srcIDs {
+			s, err := qb.Find(id)
+			if err != nil {
+				return err
+			}
+			assert.Nil(s)
+		}
+
+		merged, err := qb.Find(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(getStudioNullStringValue(studioIdxWithScene, urlField), merged.URL)
+		assert.False(merged.ParentID.Valid)
+
+		mergedAliases, err := qb.GetAliases(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.ElementsMatch(aliases, mergedAliases)
+
+		scene, err := r.Scene().Find(sceneIDs[sceneIdxWithStudio])
+		if err != nil {
+			return err
+		}
+		assert.Equal(int64(dest.ID), scene.StudioID.Int64)
+
+		image, err := r.Image().Find(imageIDs[imageIdxWithStudio])
+		if err != nil {
+			return err
+		}
+		assert.Equal(int64(dest.ID), image.StudioID.Int64)
+
+		gallery, err := r.Gallery().Find(galleryIDs[galleryIdxWithStudio])
+		if err != nil {
+			return err
+		}
+		assert.Equal(int64(dest.ID), gallery.StudioID.Int64)
+
+		movie, err := r.Movie().Find(movieIDs[movieIdxWithStudio])
+		if err != nil {
+			return err
+		}
+		assert.Equal(int64(dest.ID), movie.StudioID.Int64)
+
+		children, err := qb.FindChildren(dest.ID)
+		if err != nil {
+			return err
+		}
+		assert.Len(children, 1)
+		assert.Equal(studioIDs[studioIdxWithParentStudio], children[0].ID)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 // TODO Create
 // TODO Update
 // TODO Destroy
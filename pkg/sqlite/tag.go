@@ -34,6 +34,10 @@ func (qb *tagQueryBuilder) Create(newObject models.Tag) (*models.Tag, error) {
 		return nil, err
 	}
 
+	if err := tagsFTS.update(qb.tx, ret.ID); err != nil {
+		return nil, err
+	}
+
 	return &ret, nil
 }
 
@@ -43,6 +47,10 @@ func (qb *tagQueryBuilder) Update(updatedObject models.TagPartial) (*models.Tag,
 		return nil, err
 	}
 
+	if err := tagsFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.Find(updatedObject.ID)
 }
 
@@ -52,6 +60,10 @@ func (qb *tagQueryBuilder) UpdateFull(updatedObject models.Tag) (*models.Tag, er
 		return nil, err
 	}
 
+	if err := tagsFTS.update(qb.tx, updatedObject.ID); err != nil {
+		return nil, err
+	}
+
 	return qb.Find(updatedObject.ID)
 }
 
@@ -81,24 +93,33 @@ func (qb *tagQueryBuilder) Destroy(id int) error {
 		return errors.New("cannot delete tag used as a primary tag in scene markers")
 	}
 
-	return qb.destroyExisting([]int{id})
+	if err := qb.destroyExisting([]int{id}); err != nil {
+		return err
+	}
+
+	return tagsFTS.update(qb.tx, id)
 }
 
 func (qb *tagQueryBuilder) Find(id int) (*models.Tag, error) {
-	var ret models.Tag
-	if err := qb.get(id, &ret); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
+	if restricted, err := qb.isRestricted(id); err != nil || restricted {
 		return nil, err
 	}
-	return &ret, nil
+
+	return qb.find(id)
 }
 
+// FindMany returns the tags with the provided ids. Tags hidden by the
+// content restriction are omitted.
 func (qb *tagQueryBuilder) FindMany(ids []int) ([]*models.Tag, error) {
 	var tags []*models.Tag
 	for _, id := range ids {
-		tag, err := qb.Find(id)
+		if restricted, err := qb.isRestricted(id); err != nil {
+			return nil, err
+		} else if restricted {
+			continue
+		}
+
+		tag, err := qb.find(id)
 		if err != nil {
 			return nil, err
 		}
@@ -113,6 +134,17 @@ func (qb *tagQueryBuilder) FindMany(ids []int) ([]*models.Tag, error) {
 	return tags, nil
 }
 
+func (qb *tagQueryBuilder) find(id int) (*models.Tag, error) {
+	var ret models.Tag
+	if err := qb.get(id, &ret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
 func (qb *tagQueryBuilder) FindBySceneID(sceneID int) ([]*models.Tag, error) {
 	query := `
 		SELECT tags.* FROM tags
@@ -219,7 +251,7 @@ func (qb *tagQueryBuilder) FindByChildTagID(parentID int) ([]*models.Tag, error)
 }
 
 func (qb *tagQueryBuilder) Count() (int, error) {
-	return qb.runCountQuery(qb.buildCountQuery("SELECT tags.id FROM tags"), nil)
+	return qb.runCountQuery(qb.buildRestrictedCountQuery("SELECT tags.id FROM tags"), nil)
 }
 
 func (qb *tagQueryBuilder) All() ([]*models.Tag, error) {
@@ -325,11 +357,10 @@ func (qb *tagQueryBuilder) Query(tagFilter *models.TagFilterType, findFilter *mo
 
 	query := qb.newQuery()
 	distinctIDs(&query, tagTable)
+	query.addWhere(qb.restrictionClause())
 
 	if q := findFilter.Q; q != nil && *q != "" {
-		query.join(tagAliasesTable, "", "tag_aliases.tag_id = tags.id")
-		searchColumns := []string{"tags.name", "tag_aliases.alias"}
-		query.parseQueryString(searchColumns, *q)
+		query.addFullTextSearch(tagsFTS, *q)
 	}
 
 	if err := qb.validateFilter(tagFilter); err != nil {
@@ -339,7 +370,11 @@ func (qb *tagQueryBuilder) Query(tagFilter *models.TagFilterType, findFilter *mo
 
 	query.addFilter(filter)
 
-	query.sortAndPagination = qb.getTagSort(&query, findFilter) + getPagination(findFilter)
+	sort, ok := query.getRelevanceSort(tagTable, findFilter)
+	if !ok {
+		sort = qb.getTagSort(&query, findFilter)
+	}
+	query.sortAndPagination = sort + getPagination(findFilter)
 	idsResult, countResult, err := query.executeFind()
 	if err != nil {
 		return nil, 0, err
@@ -602,7 +637,7 @@ func (qb *tagQueryBuilder) queryTag(query string, args []interface{}) (*models.T
 
 func (qb *tagQueryBuilder) queryTags(query string, args []interface{}) ([]*models.Tag, error) {
 	var ret models.Tags
-	if err := qb.query(query, args, &ret); err != nil {
+	if err := qb.query(qb.restrictQuery(query), args, &ret); err != nil {
 		return nil, err
 	}
 
@@ -652,7 +687,11 @@ func (qb *tagQueryBuilder) GetAliases(tagID int) ([]string, error) {
 }
 
 func (qb *tagQueryBuilder) UpdateAliases(tagID int, aliases []string) error {
-	return qb.aliasRepository().replace(tagID, aliases)
+	if err := qb.aliasRepository().replace(tagID, aliases); err != nil {
+		return err
+	}
+
+	return tagsFTS.update(qb.tx, tagID)
 }
 
 func (qb *tagQueryBuilder) Merge(source []int, destination int) error {
@@ -713,7 +752,8 @@ AND NOT EXISTS(SELECT 1 FROM `+table+` o WHERE o.`+idColumn+` = `+table+`.`+idCo
 		}
 	}
 
-	return nil
+	// the destination takes the names and aliases of the sources
+	return tagsFTS.update(qb.tx, destination)
 }
 
 func (qb *tagQueryBuilder) UpdateParentTags(tagID int, parentIDs []int) error {
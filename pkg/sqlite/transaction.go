@@ -9,6 +9,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/stashapp/stash/pkg/database"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/session"
 )
 
 type dbi interface {
@@ -48,6 +49,7 @@ func (t *transaction) Rollback() error {
 	}
 
 	err := t.tx.Rollback()
+	finishPhashIndexes(t.tx, false)
 	if err != nil {
 		return fmt.Errorf("error rolling back transaction: %v", err)
 	}
@@ -62,6 +64,7 @@ func (t *transaction) Commit() error {
 	}
 
 	err := t.tx.Commit()
+	finishPhashIndexes(t.tx, err == nil)
 	if err != nil {
 		return fmt.Errorf("error committing transaction: %v", err)
 	}
@@ -82,12 +85,16 @@ func (t *transaction) ensureTx() {
 
 func (t *transaction) Gallery() models.GalleryReaderWriter {
 	t.ensureTx()
-	return NewGalleryReaderWriter(t.tx)
+	qb := NewGalleryReaderWriter(t.tx)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *transaction) Image() models.ImageReaderWriter {
 	t.ensureTx()
-	return NewImageReaderWriter(t.tx)
+	qb := NewImageReaderWriter(t.tx)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *transaction) Movie() models.MovieReaderWriter {
@@ -97,17 +104,24 @@ func (t *transaction) Movie() models.MovieReaderWriter {
 
 func (t *transaction) Performer() models.PerformerReaderWriter {
 	t.ensureTx()
-	return NewPerformerReaderWriter(t.tx)
+	qb := NewPerformerReaderWriter(t.tx)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *transaction) SceneMarker() models.SceneMarkerReaderWriter {
 	t.ensureTx()
-	return NewSceneMarkerReaderWriter(t.tx)
+	qb := NewSceneMarkerReaderWriter(t.tx)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *transaction) Scene() models.SceneReaderWriter {
 	t.ensureTx()
-	return NewSceneReaderWriter(t.tx)
+	qb := NewSceneReaderWriter(t.tx)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	qb.setUser(session.GetCurrentUserAccountID(t.Ctx))
+	return qb
 }
 
 func (t *transaction) ScrapedItem() models.ScrapedItemReaderWriter {
@@ -117,12 +131,16 @@ func (t *transaction) ScrapedItem() models.ScrapedItemReaderWriter {
 
 func (t *transaction) Studio() models.StudioReaderWriter {
 	t.ensureTx()
-	return NewStudioReaderWriter(t.tx)
+	qb := NewStudioReaderWriter(t.tx)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *transaction) Tag() models.TagReaderWriter {
 	t.ensureTx()
-	return NewTagReaderWriter(t.tx)
+	qb := NewTagReaderWriter(t.tx)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *transaction) SavedFilter() models.SavedFilterReaderWriter {
@@ -130,7 +148,29 @@ func (t *transaction) SavedFilter() models.SavedFilterReaderWriter {
 	return NewSavedFilterReaderWriter(t.tx)
 }
 
-type ReadTransaction struct{}
+func (t *transaction) User() models.UserReaderWriter {
+	t.ensureTx()
+	return NewUserReaderWriter(t.tx)
+}
+
+func (t *transaction) RestrictionProfile() models.RestrictionProfileReaderWriter {
+	t.ensureTx()
+	return NewRestrictionProfileReaderWriter(t.tx)
+}
+
+func (t *transaction) Schedule() models.ScheduleReaderWriter {
+	t.ensureTx()
+	return NewScheduleReaderWriter(t.tx)
+}
+
+func (t *transaction) JobRecord() models.JobRecordReaderWriter {
+	t.ensureTx()
+	return NewJobRecordReaderWriter(t.tx)
+}
+
+type ReadTransaction struct {
+	Ctx context.Context
+}
 
 func (t *ReadTransaction) Begin() error {
 	if err := database.Ready(); err != nil {
@@ -153,11 +193,15 @@ func (t *ReadTransaction) Repository() models.ReaderRepository {
 }
 
 func (t *ReadTransaction) Gallery() models.GalleryReader {
-	return NewGalleryReaderWriter(database.DB)
+	qb := NewGalleryReaderWriter(database.DB)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *ReadTransaction) Image() models.ImageReader {
-	return NewImageReaderWriter(database.DB)
+	qb := NewImageReaderWriter(database.DB)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *ReadTransaction) Movie() models.MovieReader {
@@ -165,15 +209,22 @@ func (t *ReadTransaction) Movie() models.MovieReader {
 }
 
 func (t *ReadTransaction) Performer() models.PerformerReader {
-	return NewPerformerReaderWriter(database.DB)
+	qb := NewPerformerReaderWriter(database.DB)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *ReadTransaction) SceneMarker() models.SceneMarkerReader {
-	return NewSceneMarkerReaderWriter(database.DB)
+	qb := NewSceneMarkerReaderWriter(database.DB)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *ReadTransaction) Scene() models.SceneReader {
-	return NewSceneReaderWriter(database.DB)
+	qb := NewSceneReaderWriter(database.DB)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	qb.setUser(session.GetCurrentUserAccountID(t.Ctx))
+	return qb
 }
 
 func (t *ReadTransaction) ScrapedItem() models.ScrapedItemReader {
@@ -181,17 +232,43 @@ func (t *ReadTransaction) ScrapedItem() models.ScrapedItemReader {
 }
 
 func (t *ReadTransaction) Studio() models.StudioReader {
-	return NewStudioReaderWriter(database.DB)
+	qb := NewStudioReaderWriter(database.DB)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *ReadTransaction) Tag() models.TagReader {
-	return NewTagReaderWriter(database.DB)
+	qb := NewTagReaderWriter(database.DB)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
 }
 
 func (t *ReadTransaction) SavedFilter() models.SavedFilterReader {
 	return NewSavedFilterReaderWriter(database.DB)
 }
 
+func (t *ReadTransaction) User() models.UserReader {
+	return NewUserReaderWriter(database.DB)
+}
+
+func (t *ReadTransaction) RestrictionProfile() models.RestrictionProfileReader {
+	return NewRestrictionProfileReaderWriter(database.DB)
+}
+
+func (t *ReadTransaction) Schedule() models.ScheduleReader {
+	return NewScheduleReaderWriter(database.DB)
+}
+
+func (t *ReadTransaction) JobRecord() models.JobRecordReader {
+	return NewJobRecordReaderWriter(database.DB)
+}
+
+func (t *ReadTransaction) Search() models.SearchReader {
+	qb := NewSearchReader(database.DB)
+	qb.restrict(models.GetContentRestriction(t.Ctx))
+	return qb
+}
+
 type TransactionManager struct {
 }
 
@@ -206,5 +283,5 @@ func (t *TransactionManager) WithTxn(ctx context.Context, fn func(r models.Repos
 }
 
 func (t *TransactionManager) WithReadTxn(ctx context.Context, fn func(r models.ReaderRepository) error) error {
-	return models.WithROTxn(&ReadTransaction{}, fn)
+	return models.WithROTxn(&ReadTransaction{Ctx: ctx}, fn)
 }
@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+const userTable = "users"
+
+type userQueryBuilder struct {
+	repository
+}
+
+func NewUserReaderWriter(tx dbi) *userQueryBuilder {
+	return &userQueryBuilder{
+		repository{
+			tx:        tx,
+			tableName: userTable,
+			idColumn:  idColumn,
+		},
+	}
+}
+
+func (qb *userQueryBuilder) Create(newObject models.User) (*models.User, error) {
+	var ret models.User
+	if err := qb.insertObject(newObject, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *userQueryBuilder) Update(updatedObject models.User) (*models.User, error) {
+	const partial = false
+	if err := qb.update(updatedObject.ID, updatedObject, partial); err != nil {
+		return nil, err
+	}
+
+	var ret models.User
+	if err := qb.get(updatedObject.ID, &ret); err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (qb *userQueryBuilder) Destroy(id int) error {
+	return qb.destroyExisting([]int{id})
+}
+
+func (qb *userQueryBuilder) Find(id int) (*models.User, error) {
+	var ret models.User
+	if err := qb.get(id, &ret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (qb *userQueryBuilder) queryUser(query string, args []interface{}) (*models.User, error) {
+	var ret models.Users
+	if err := qb.query(query, args, &ret); err != nil {
+		return nil, err
+	}
+
+	if len(ret) > 0 {
+		return ret[0], nil
+	}
+
+	return nil, nil
+}
+
+func (qb *userQueryBuilder) FindByUsername(username string) (*models.User, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE username = ? LIMIT 1`, userTable)
+	return qb.queryUser(query, []interface{}{username})
+}
+
+func (qb *userQueryBuilder) FindByAPIKey(apiKey string) (*models.User, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE api_key = ? LIMIT 1`, userTable)
+	return qb.queryUser(query, []interface{}{apiKey})
+}
+
+func (qb *userQueryBuilder) All() ([]*models.User, error) {
+	var ret models.Users
+	if err := qb.query(selectAll(userTable)+getSort("username", "ASC", userTable), nil, &ret); err != nil {
+		return nil, err
+	}
+
+	return []*models.User(ret), nil
+}
+
+func (qb *userQueryBuilder) Count() (int, error) {
+	return qb.runCountQuery(qb.buildCountQuery("SELECT users.id FROM users"), nil)
+}
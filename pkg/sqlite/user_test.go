@@ -0,0 +1,152 @@
+//go:build integration
+// +build integration
+
+package sqlite_test
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/hash/md5"
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestUser(r models.Repository, username string, apiKey string) (*models.User, error) {
+	now := models.SQLiteTimestamp{Timestamp: time.Now()}
+	return r.User().Create(models.User{
+		Username:  username,
+		Password:  "hash",
+		Role:      models.UserRoleViewer,
+		APIKey:    sql.NullString{String: apiKey, Valid: apiKey != ""},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func TestUserFind(t *testing.T) {
+	const username = "TestUserFind"
+	const apiKey = "TestUserFindKey"
+
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.User()
+
+		created, err := createTestUser(r, username, apiKey)
+		if err != nil {
+			return fmt.Errorf("Error creating user: %s", err.Error())
+		}
+
+		found, err := qb.FindByUsername(username)
+		if err != nil {
+			return fmt.Errorf("Error finding user: %s", err.Error())
+		}
+		if assert.NotNil(t, found) {
+			assert.Equal(t, created.ID, found.ID)
+			assert.Equal(t, models.UserRoleViewer, found.Role)
+		}
+
+		found, err = qb.FindByAPIKey(apiKey)
+		if err != nil {
+			return fmt.Errorf("Error finding user: %s", err.Error())
+		}
+		if assert.NotNil(t, found) {
+			assert.Equal(t, created.ID, found.ID)
+		}
+
+		found, err = qb.FindByUsername("missing")
+		if err != nil {
+			return fmt.Errorf("Error finding user: %s", err.Error())
+		}
+		assert.Nil(t, found)
+
+		created.Role = models.UserRoleEditor
+		created.APIKey = sql.NullString{}
+		updated, err := qb.Update(*created)
+		if err != nil {
+			return fmt.Errorf("Error updating user: %s", err.Error())
+		}
+		assert.Equal(t, models.UserRoleEditor, updated.Role)
+		assert.False(t, updated.APIKey.Valid)
+
+		return qb.Destroy(created.ID)
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSceneUserData(t *testing.T) {
+	if err := withTxn(func(r models.Repository) error {
+		qb := r.Scene()
+
+		const name = "TestSceneUserData"
+		created, err := qb.Create(models.Scene{
+			Path:     name,
+			Checksum: sql.NullString{String: md5.FromString(name), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("Error creating scene: %s", err.Error())
+		}
+
+		user, err := createTestUser(r, name, "")
+		if err != nil {
+			return fmt.Errorf("Error creating user: %s", err.Error())
+		}
+
+		// no data stored yet
+		data, err := qb.GetUserData(created.ID, user.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting user data: %s", err.Error())
+		}
+		assert.Equal(t, models.SceneUserData{SceneID: created.ID, UserID: user.ID}, *data)
+
+		data.Rating = sql.NullInt64{Int64: 4, Valid: true}
+		data.OCounter = 2
+		if err := qb.UpdateUserData(*data); err != nil {
+			return fmt.Errorf("Error updating user data: %s", err.Error())
+		}
+
+		resumeTime := 42.0
+		playDuration := 10.0
+		if err := qb.SaveActivity(created.ID, &user.ID, &resumeTime, &playDuration); err != nil {
+			return fmt.Errorf("Error saving activity: %s", err.Error())
+		}
+
+		data, err = qb.GetUserData(created.ID, user.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting user data: %s", err.Error())
+		}
+		assert.Equal(t, int64(4), data.Rating.Int64)
+		assert.Equal(t, 2, data.OCounter)
+		assert.Equal(t, resumeTime, data.ResumeTime)
+
+		// the scene values are unchanged
+		found, err := qb.Find(created.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding scene: %s", err.Error())
+		}
+		assert.False(t, found.Rating.Valid)
+		assert.Equal(t, 0, found.OCounter)
+		assert.Equal(t, 0.0, found.ResumeTime)
+
+		// plays are recorded per user
+		count, err := qb.AddPlay(created.ID, nil, time.Now())
+		if err != nil {
+			return fmt.Errorf("Error adding play: %s", err.Error())
+		}
+		assert.Equal(t, 1, count)
+
+		history, err := qb.GetPlayHistory(created.ID, &user.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting play history: %s", err.Error())
+		}
+		if assert.Len(t, history, 1) {
+			assert.Equal(t, playDuration, history[0].PlayDuration)
+		}
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -1,55 +1,179 @@
 package utils
 
 import (
+	"math/bits"
 	"strconv"
-
-	"github.com/corona10/goimagehash"
 )
 
-type Phash struct {
-	SceneID   int   `db:"id"`
-	Hash      int64 `db:"phash"`
-	Neighbors []int
-	Bucket    int
+// PhashTree is a BK-tree of perceptual hashes, keyed by the hamming
+// distance between hashes. It finds the hashes within a distance of a hash
+// without comparing against every hash in the tree.
+type PhashTree struct {
+	root *phashNode
+}
+
+type phashNode struct {
+	hash     uint64
+	ids      []int
+	children map[int]*phashNode
 }
 
-func FindDuplicates(hashes []*Phash, distance int) [][]int {
-	for i, scene := range hashes {
-		sceneHash := goimagehash.NewImageHash(uint64(scene.Hash), goimagehash.PHash)
-		for j, neighbor := range hashes {
-			if i != j {
-				neighborHash := goimagehash.NewImageHash(uint64(neighbor.Hash), goimagehash.PHash)
-				neighborDistance, _ := sceneHash.Distance(neighborHash)
-				if neighborDistance <= distance {
-					scene.Neighbors = append(scene.Neighbors, j)
+func phashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Add adds the object with the provided id and phash to the tree.
+func (t *PhashTree) Add(phash int64, id int) {
+	hash := uint64(phash)
+	if t.root == nil {
+		t.root = &phashNode{hash: hash}
+	}
+
+	n := t.root
+	for {
+		d := phashDistance(n.hash, hash)
+		if d == 0 {
+			n.ids = append(n.ids, id)
+			return
+		}
+
+		child := n.children[d]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[int]*phashNode)
+			}
+			n.children[d] = &phashNode{hash: hash, ids: []int{id}}
+			return
+		}
+
+		n = child
+	}
+}
+
+// Remove removes the object with the provided id and phash from the tree.
+// Emptied nodes are left in place, since their children are positioned
+// relative to them.
+func (t *PhashTree) Remove(phash int64, id int) {
+	hash := uint64(phash)
+	n := t.root
+	for n != nil {
+		d := phashDistance(n.hash, hash)
+		if d == 0 {
+			for i, v := range n.ids {
+				if v == id {
+					n.ids = append(n.ids[:i], n.ids[i+1:]...)
+					return
 				}
 			}
+			return
 		}
+
+		n = n.children[d]
 	}
+}
 
-	var buckets [][]int
-	for _, scene := range hashes {
-		if len(scene.Neighbors) > 0 && scene.Bucket == -1 {
-			bucket := len(buckets)
-			scenes := []int{scene.SceneID}
-			scene.Bucket = bucket
-			findNeighbors(bucket, scene.Neighbors, hashes, &scenes)
-			buckets = append(buckets, scenes)
+// search calls fn with every node with objects within distance of hash.
+func (t *PhashTree) search(hash uint64, distance int, fn func(n *phashNode)) {
+	if t.root == nil {
+		return
+	}
+
+	queue := []*phashNode{t.root}
+	for len(queue) > 0 {
+		n := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		d := phashDistance(n.hash, hash)
+		if d <= distance && len(n.ids) > 0 {
+			fn(n)
+		}
+
+		// by the triangle inequality, only the children at a distance
+		// within the range can contain matches
+		for cd, child := range n.children {
+			if cd >= d-distance && cd <= d+distance {
+				queue = append(queue, child)
+			}
 		}
 	}
+}
+
+// Search returns the ids of the objects with phashes within distance of
+// phash.
+func (t *PhashTree) Search(phash int64, distance int) []int {
+	var ret []int
+	t.search(uint64(phash), distance, func(n *phashNode) {
+		ret = append(ret, n.ids...)
+	})
 
-	return buckets
+	return ret
 }
 
-func findNeighbors(bucket int, neighbors []int, hashes []*Phash, scenes *[]int) {
-	for _, id := range neighbors {
-		hash := hashes[id]
-		if hash.Bucket == -1 {
-			hash.Bucket = bucket
-			*scenes = append(*scenes, hash.SceneID)
-			findNeighbors(bucket, hash.Neighbors, hashes, scenes)
+// FindDuplicates returns the groups of ids of objects with phashes within
+// distance of another phash in the group. Objects without duplicates are
+// omitted.
+func (t *PhashTree) FindDuplicates(distance int) [][]int {
+	var nodes []*phashNode
+	index := make(map[*phashNode]int)
+	if t.root != nil {
+		queue := []*phashNode{t.root}
+		for len(queue) > 0 {
+			n := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+
+			if len(n.ids) > 0 {
+				index[n] = len(nodes)
+				nodes = append(nodes, n)
+			}
+
+			for _, child := range n.children {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	// union the nodes within distance of each other
+	parents := make([]int, len(nodes))
+	for i := range parents {
+		parents[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
 		}
+		return parents[i]
 	}
+
+	if distance > 0 {
+		for i, n := range nodes {
+			t.search(n.hash, distance, func(neighbor *phashNode) {
+				if a, b := find(i), find(index[neighbor]); a != b {
+					parents[b] = a
+				}
+			})
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i, n := range nodes {
+		root := find(i)
+		if _, found := groups[root]; !found {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], n.ids...)
+	}
+
+	var ret [][]int
+	for _, root := range roots {
+		if len(groups[root]) > 1 {
+			ret = append(ret, groups[root])
+		}
+	}
+
+	return ret
 }
 
 func PhashToString(phash int64) string {
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhashTreeSearch(t *testing.T) {
+	tree := &PhashTree{}
+	tree.Add(0x0, 1)
+	tree.Add(0x1, 2)
+	tree.Add(0x3, 3)
+	tree.Add(0xff, 4)
+	tree.Add(0x0, 5)
+
+	search := func(phash int64, distance int) []int {
+		ret := tree.Search(phash, distance)
+		sort.Ints(ret)
+		return ret
+	}
+
+	assert := assert.New(t)
+	assert.Equal([]int{1, 5}, search(0x0, 0))
+	assert.Equal([]int{1, 2, 5}, search(0x0, 1))
+	assert.Equal([]int{1, 2, 3, 5}, search(0x0, 2))
+	assert.Equal([]int{4}, search(0x7f, 1))
+	assert.Nil(search(0xf00, 2))
+
+	tree.Remove(0x1, 2)
+	assert.Equal([]int{1, 5}, search(0x0, 1))
+	// the children of removed objects are still found
+	assert.Equal([]int{3}, search(0x3, 0))
+}
+
+func TestPhashTreeFindDuplicates(t *testing.T) {
+	tree := &PhashTree{}
+	tree.Add(0x0, 1)
+	tree.Add(0x0, 2)
+	tree.Add(0x1, 3)
+	// two bits from the first hash, but one from the third
+	tree.Add(0x3, 4)
+	// 8 bits from the others
+	tree.Add(0xff00, 5)
+
+	groups := func(distance int) [][]int {
+		ret := tree.FindDuplicates(distance)
+		for _, g := range ret {
+			sort.Ints(g)
+		}
+		sort.Slice(ret, func(i, j int) bool {
+			return ret[i][0] < ret[j][0]
+		})
+		return ret
+	}
+
+	assert := assert.New(t)
+	assert.Equal([][]int{{1, 2}}, groups(0))
+	// groups are joined through their neighbours
+	assert.Equal([][]int{{1, 2, 3, 4}}, groups(1))
+	assert.Equal([][]int{{1, 2, 3, 4}}, groups(7))
+	assert.Equal([][]int{{1, 2, 3, 4, 5}}, groups(8))
+}